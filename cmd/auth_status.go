@@ -13,126 +13,201 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var statusOutputFormat string
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show authentication and connection status",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-
-		// Auth0/JWT status
-		auth0LoggedIn := false
-		var auth0ExpiresAt time.Time
-		var bearerToken string
-
-		a, aerr := auth.NewAuthenticator()
-		if aerr == nil {
-			if err := a.TryExistingAuth(ctx); err == nil {
-				auth0LoggedIn = true
-				auth0ExpiresAt = a.ExpiresAt
-				bearerToken = a.AccessToken
-			}
+		if statusOutputFormat != "text" && statusOutputFormat != "json" {
+			return fmt.Errorf("invalid --output-format %q (choices: text, json)", statusOutputFormat)
 		}
 
-		apiKey := cliconfig.GetAPIKey()
-		_, effectiveMethod := cliconfig.GetAuthMethod(auth0LoggedIn)
-		apiKeyPresent := apiKey != ""
+		status := collectAuthStatus(context.Background())
+		if statusOutputFormat == "json" {
+			return printJSON(status)
+		}
+		printAuthStatus(status)
+		return nil
+	},
+}
 
-		cfg := cliconfig.CLIConfig
-		localClientID, clientSource := cfg.GetClientIDWithSource()
+func init() {
+	statusCmd.Flags().StringVar(&statusOutputFormat, "output-format", "text", `Output format (choices: "text", "json")`)
+	authCmd.AddCommand(statusCmd)
+}
 
-		// Check cloud connection (if we have credentials)
-		var cloudResp *backend.GetAuthInfoResponse
-		var cloudErr error
-		if effectiveMethod != cliconfig.AuthMethodNone {
-			authAPIKey := ""
-			if effectiveMethod == cliconfig.AuthMethodAPIKey {
-				authAPIKey = apiKey
-			}
+// authStatus is the identity and connection state shown by `tusk auth status`.
+type authStatus struct {
+	AuthMethod       cliconfig.AuthMethod `json:"authMethod"`
+	Auth0LoggedIn    bool                 `json:"auth0LoggedIn"`
+	Auth0ExpiresAt   *time.Time           `json:"auth0ExpiresAt,omitempty"`
+	APIKeyConfigured bool                 `json:"apiKeyConfigured"`
 
-			client := api.NewClient(api.GetBaseURL(), authAPIKey)
-			authOpts := api.AuthOptions{
-				BearerToken:  bearerToken,
-				APIKey:       authAPIKey,
-				TuskClientID: localClientID,
-			}
+	UserName  string `json:"userName,omitempty"`
+	UserEmail string `json:"userEmail,omitempty"`
+	UserID    string `json:"userId,omitempty"`
 
-			cloudResp, cloudErr = client.GetAuthInfo(ctx, &backend.GetAuthInfoRequest{}, authOpts)
-		}
+	// Client selected with `tusk auth select-org` (or TUSK_CLIENT_ID)
+	SelectedClientID   string                   `json:"selectedClientId,omitempty"`
+	SelectedClientName string                   `json:"selectedClientName,omitempty"`
+	ClientIDSource     cliconfig.ClientIDSource `json:"clientIdSource,omitempty"`
 
-		// Print status
-		log.Println("⚙️ Tusk CLI status\n")
-
-		// User and Organization (from cloud response)
-		if effectiveMethod == cliconfig.AuthMethodNone {
-			log.Println("User: (not authenticated)")
-			log.Println("Organization: (not authenticated)")
-		} else if cloudErr != nil {
-			log.Println("User: (unknown - connection failed)")
-			log.Println("Organization: (unknown - connection failed)")
-		} else {
-			// User info
-			if effectiveMethod == cliconfig.AuthMethodAPIKey {
-				log.Println("User: (API key)")
-			} else if cloudResp.User != nil && cloudResp.User.GetName() != "" {
-				log.Println(fmt.Sprintf("User: %s", cloudResp.User.GetName()))
-			} else {
-				log.Println("User: (unknown)")
-			}
+	// Client the backend resolved for these credentials
+	OrganizationID   string `json:"organizationId,omitempty"`
+	OrganizationName string `json:"organizationName,omitempty"`
 
-			// Organization info - find active client
-			var activeClientID, activeClientName string
-			if effectiveMethod == cliconfig.AuthMethodJWT && localClientID != "" {
-				// For JWT, use locally selected client ID
-				activeClientID = localClientID
-				for _, c := range cloudResp.Clients {
-					if c.Id == localClientID && c.Name != nil {
-						activeClientName = *c.Name
-						break
-					}
-				}
-			} else if len(cloudResp.Clients) > 0 {
-				// For API key, use client from response (backend derives it)
-				activeClientID = cloudResp.Clients[0].Id
-				if cloudResp.Clients[0].Name != nil {
-					activeClientName = *cloudResp.Clients[0].Name
-				}
-			}
+	CloudConnected bool   `json:"cloudConnected"`
+	CloudError     string `json:"cloudError,omitempty"`
+}
 
-			if activeClientName != "" {
-				if effectiveMethod == cliconfig.AuthMethodJWT && clientSource != cliconfig.ClientIDSourceNone {
-					log.Println(fmt.Sprintf("Organization: %s (%s, %s)", activeClientName, activeClientID, clientSource))
-				} else {
-					log.Println(fmt.Sprintf("Organization: %s (%s)", activeClientName, activeClientID))
-				}
-			} else if activeClientID != "" {
-				log.Println(fmt.Sprintf("Organization: %s", activeClientID))
-			} else {
-				log.Println("Organization: (none)")
+func (s authStatus) authenticated() bool {
+	return s.AuthMethod != cliconfig.AuthMethodNone
+}
+
+func collectAuthStatus(ctx context.Context) authStatus {
+	var status authStatus
+	var bearerToken string
+
+	a, aerr := auth.NewAuthenticator()
+	if aerr == nil {
+		if err := a.TryExistingAuth(ctx); err == nil {
+			status.Auth0LoggedIn = true
+			expiresAt := a.ExpiresAt
+			status.Auth0ExpiresAt = &expiresAt
+			bearerToken = a.AccessToken
+		}
+	}
+
+	apiKey := cliconfig.GetAPIKey()
+	_, status.AuthMethod = cliconfig.GetAuthMethod(status.Auth0LoggedIn)
+	status.APIKeyConfigured = apiKey != ""
+
+	cfg := cliconfig.CLIConfig
+	localClientID, clientSource := cfg.GetClientIDWithSource()
+	status.SelectedClientID = localClientID
+	status.ClientIDSource = clientSource
+	if localClientID == cfg.SelectedClientID {
+		status.SelectedClientName = cfg.SelectedClientName
+	}
+
+	if !status.authenticated() {
+		return status
+	}
+
+	// Check cloud connection
+	authAPIKey := ""
+	if status.AuthMethod == cliconfig.AuthMethodAPIKey {
+		authAPIKey = apiKey
+	}
+	client := api.NewClient(api.GetBaseURL(), authAPIKey)
+	authOpts := api.AuthOptions{
+		BearerToken:  bearerToken,
+		APIKey:       authAPIKey,
+		TuskClientID: localClientID,
+	}
+
+	cloudResp, err := client.GetAuthInfo(ctx, &backend.GetAuthInfoRequest{}, authOpts)
+	if err != nil {
+		status.CloudError = err.Error()
+		return status
+	}
+	status.CloudConnected = true
+
+	if cloudResp.User != nil {
+		status.UserName = cloudResp.User.GetName()
+		status.UserEmail = cloudResp.User.GetEmail()
+		status.UserID = cloudResp.User.GetId()
+	}
+
+	// Find the active client
+	if status.AuthMethod == cliconfig.AuthMethodJWT && localClientID != "" {
+		// For JWT, use locally selected client ID
+		status.OrganizationID = localClientID
+		for _, c := range cloudResp.Clients {
+			if c.Id == localClientID && c.Name != nil {
+				status.OrganizationName = *c.Name
+				break
 			}
 		}
+	} else if len(cloudResp.Clients) > 0 {
+		// For API key, use client from response (backend derives it)
+		status.OrganizationID = cloudResp.Clients[0].Id
+		if cloudResp.Clients[0].Name != nil {
+			status.OrganizationName = *cloudResp.Clients[0].Name
+		}
+	}
 
-		// Auth method details
-		log.Println(fmt.Sprintf("\nAuth method: %s", effectiveMethod))
-		if auth0LoggedIn {
-			log.Println(fmt.Sprintf("Auth0 logged in: true (expires %s)", auth0ExpiresAt.Format(time.RFC3339)))
-		} else {
-			log.Println("Auth0 logged in: false")
+	return status
+}
+
+func printAuthStatus(status authStatus) {
+	log.Println("⚙️ Tusk CLI status\n")
+
+	// User and Organization (from cloud response)
+	switch {
+	case !status.authenticated():
+		log.Println("User: (not authenticated)")
+		log.Println("Organization: (not authenticated)")
+	case !status.CloudConnected:
+		log.Println("User: (unknown - connection failed)")
+		log.Println("Organization: (unknown - connection failed)")
+	default:
+		switch {
+		case status.AuthMethod == cliconfig.AuthMethodAPIKey:
+			log.Println("User: (API key)")
+		case status.UserName != "":
+			log.Println(fmt.Sprintf("User: %s", status.UserName))
+		default:
+			log.Println("User: (unknown)")
 		}
-		log.Println(fmt.Sprintf("API key present: %v (set via TUSK_API_KEY env var)", apiKeyPresent))
-
-		// Cloud connection status
-		if effectiveMethod == cliconfig.AuthMethodNone {
-			log.Println("\nTusk Cloud connection: ⚠️  Not authenticated")
-		} else if cloudErr != nil {
-			log.Println(fmt.Sprintf("\nTusk Cloud connection: ❌ Failed (%v)", cloudErr))
-		} else {
-			log.Println("\nTusk Cloud connection: ✅ Success")
+		if status.UserEmail != "" {
+			log.Println(fmt.Sprintf("Email: %s", status.UserEmail))
+		}
+		if status.UserID != "" {
+			log.Println(fmt.Sprintf("User ID: %s", status.UserID))
 		}
 
-		return nil
-	},
-}
+		switch {
+		case status.OrganizationName != "":
+			if status.AuthMethod == cliconfig.AuthMethodJWT && status.ClientIDSource != cliconfig.ClientIDSourceNone {
+				log.Println(fmt.Sprintf("Organization: %s (%s, %s)", status.OrganizationName, status.OrganizationID, status.ClientIDSource))
+			} else {
+				log.Println(fmt.Sprintf("Organization: %s (%s)", status.OrganizationName, status.OrganizationID))
+			}
+		case status.OrganizationID != "":
+			log.Println(fmt.Sprintf("Organization: %s", status.OrganizationID))
+		default:
+			log.Println("Organization: (none)")
+		}
+	}
 
-func init() {
-	authCmd.AddCommand(statusCmd)
+	// The selection is already shown as the organization when it was used
+	if status.SelectedClientID != "" && status.SelectedClientID != status.OrganizationID {
+		name := status.SelectedClientName
+		if name == "" {
+			name = "(unknown name)"
+		}
+		log.Println(fmt.Sprintf("Selected organization: %s (%s, %s)", name, status.SelectedClientID, status.ClientIDSource))
+	}
+
+	// Auth method details
+	log.Println(fmt.Sprintf("\nAuth method: %s", status.AuthMethod))
+	if status.Auth0LoggedIn {
+		log.Println(fmt.Sprintf("Auth0 logged in: true (expires %s)", status.Auth0ExpiresAt.Format(time.RFC3339)))
+	} else {
+		log.Println("Auth0 logged in: false")
+	}
+	log.Println(fmt.Sprintf("API key present: %v (set via TUSK_API_KEY env var)", status.APIKeyConfigured))
+
+	// Cloud connection status
+	switch {
+	case !status.authenticated():
+		log.Println("\nTusk Cloud connection: ⚠️  Not authenticated")
+		log.Println("Run `tusk auth login` to log in, or set TUSK_API_KEY.")
+	case !status.CloudConnected:
+		log.Println(fmt.Sprintf("\nTusk Cloud connection: ❌ Failed (%s)", status.CloudError))
+	default:
+		log.Println("\nTusk Cloud connection: ✅ Success")
+	}
 }
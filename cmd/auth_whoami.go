@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Use-Tusk/tusk-cli/internal/cliconfig"
+)
+
+var whoamiJSON bool
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Print the authenticated identity",
+	Long: `Print the identity Tusk Cloud requests are made as: email, user ID,
+client (organization) ID and, for logins, when the access token expires.
+
+Exits with status 1 when not authenticated, so scripts can branch on it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := writeWhoami(os.Stdout, collectAuthStatus(context.Background()), whoamiJSON)
+		if err == errSilentFail {
+			cmd.SilenceErrors = true
+		}
+		return err
+	},
+}
+
+func init() {
+	whoamiCmd.Flags().BoolVar(&whoamiJSON, "json", false, "Write the identity as JSON")
+	authCmd.AddCommand(whoamiCmd)
+}
+
+// whoami is the identity printed by `tusk auth whoami`.
+type whoami struct {
+	Authenticated bool                 `json:"authenticated"`
+	AuthMethod    cliconfig.AuthMethod `json:"authMethod"`
+	Email         string               `json:"email,omitempty"`
+	UserID        string               `json:"userId,omitempty"`
+	ClientID      string               `json:"clientId,omitempty"`
+	// ExpiresAt is when the stored login's access token expires; unset for API keys
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	CloudError string     `json:"cloudError,omitempty"`
+}
+
+func whoamiFromStatus(status authStatus) whoami {
+	id := whoami{
+		Authenticated: status.authenticated(),
+		AuthMethod:    status.AuthMethod,
+		Email:         status.UserEmail,
+		UserID:        status.UserID,
+		ClientID:      status.OrganizationID,
+		CloudError:    status.CloudError,
+	}
+	if id.ClientID == "" {
+		id.ClientID = status.SelectedClientID
+	}
+	if status.AuthMethod == cliconfig.AuthMethodJWT {
+		id.ExpiresAt = status.Auth0ExpiresAt
+	}
+	return id
+}
+
+// writeWhoami writes the identity of status to w and returns errSilentFail
+// when it isn't authenticated.
+func writeWhoami(w io.Writer, status authStatus, asJSON bool) error {
+	id := whoamiFromStatus(status)
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(id); err != nil {
+			return fmt.Errorf("encode json: %w", err)
+		}
+	} else {
+		switch {
+		case !id.Authenticated:
+			_, _ = fmt.Fprintln(w, "Not authenticated. Run `tusk auth login` or set TUSK_API_KEY.")
+		case id.Email != "":
+			_, _ = fmt.Fprintln(w, id.Email)
+		case id.AuthMethod == cliconfig.AuthMethodAPIKey:
+			_, _ = fmt.Fprintln(w, "(API key)")
+		default:
+			_, _ = fmt.Fprintln(w, "(unknown user)")
+		}
+		if id.UserID != "" {
+			_, _ = fmt.Fprintf(w, "User ID: %s\n", id.UserID)
+		}
+		if id.ClientID != "" {
+			_, _ = fmt.Fprintf(w, "Client ID: %s\n", id.ClientID)
+		}
+		if id.ExpiresAt != nil {
+			_, _ = fmt.Fprintf(w, "Token expires: %s\n", id.ExpiresAt.Format(time.RFC3339))
+		}
+		if id.CloudError != "" {
+			_, _ = fmt.Fprintf(w, "Tusk Cloud unreachable: %s\n", id.CloudError)
+		}
+	}
+
+	if !id.Authenticated {
+		return errSilentFail
+	}
+	return nil
+}
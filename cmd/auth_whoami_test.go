@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Use-Tusk/tusk-cli/internal/cliconfig"
+)
+
+func TestWriteWhoami(t *testing.T) {
+	t.Run("authenticated exits 0", func(t *testing.T) {
+		expiresAt := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+		status := authStatus{
+			AuthMethod:       cliconfig.AuthMethodJWT,
+			Auth0LoggedIn:    true,
+			Auth0ExpiresAt:   &expiresAt,
+			UserEmail:        "dev@example.com",
+			UserID:           "user-1",
+			SelectedClientID: "client-1",
+			OrganizationID:   "client-1",
+			CloudConnected:   true,
+		}
+
+		var buf bytes.Buffer
+		err := writeWhoami(&buf, status, true)
+		require.NoError(t, err)
+		assert.Equal(t, 0, ExitCodeOf(err))
+
+		var got map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, map[string]any{
+			"authenticated": true,
+			"authMethod":    "jwt",
+			"email":         "dev@example.com",
+			"userId":        "user-1",
+			"clientId":      "client-1",
+			"expiresAt":     "2026-10-15T12:00:00Z",
+		}, got)
+	})
+
+	t.Run("unauthenticated exits 1", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := writeWhoami(&buf, authStatus{AuthMethod: cliconfig.AuthMethodNone}, true)
+		require.Error(t, err)
+		assert.Equal(t, 1, ExitCodeOf(err))
+		assert.JSONEq(t, `{"authenticated": false, "authMethod": "none"}`, buf.String())
+
+		buf.Reset()
+		err = writeWhoami(&buf, authStatus{AuthMethod: cliconfig.AuthMethodNone}, false)
+		assert.Equal(t, 1, ExitCodeOf(err))
+		assert.Contains(t, buf.String(), "Not authenticated")
+	})
+}
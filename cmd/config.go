@@ -27,7 +27,9 @@ Examples:
   tusk config get analytics          # Show current analytics setting
   tusk config set analytics false    # Disable analytics
   tusk config set autoUpdate true    # Enable automatic updates
-  tusk config set autoCheckUpdates false  # Disable update checking`,
+  tusk config set autoCheckUpdates false  # Disable update checking
+
+To check a project's .tusk/config.yaml for mistakes, run ` + "`tusk config validate`" + `.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		_ = cmd.Help()
 	},
@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/Use-Tusk/tusk-cli/internal/cliconfig"
+	"github.com/Use-Tusk/tusk-cli/internal/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -199,3 +202,36 @@ func TestParseBool(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigValidateCmd(t *testing.T) {
+	origFormat := validateOutputFormat
+	t.Cleanup(func() {
+		validateOutputFormat = origFormat
+		config.Invalidate()
+	})
+	validateOutputFormat = "text"
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("service:\n  port: 3000\n  start:\n    command: npm start\n"), 0o600))
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("service:\n  port: 3000\n"), 0o600))
+
+	t.Run("valid config", func(t *testing.T) {
+		require.NoError(t, configValidateCmd.RunE(configValidateCmd, []string{valid}))
+	})
+
+	t.Run("missing required field fails", func(t *testing.T) {
+		err := configValidateCmd.RunE(configValidateCmd, []string{invalid})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "1 error(s)")
+	})
+
+	t.Run("invalid output format", func(t *testing.T) {
+		validateOutputFormat = "yaml"
+		t.Cleanup(func() { validateOutputFormat = "text" })
+		err := configValidateCmd.RunE(configValidateCmd, []string{valid})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "--output-format")
+	})
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+	"github.com/Use-Tusk/tusk-cli/internal/log"
+)
+
+var validateOutputFormat string
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [path]",
+	Short: "Check .tusk/config.yaml for common mistakes",
+	Long: `Check a Tusk Drift config file for common mistakes.
+
+Reports unknown keys, missing required fields and invalid values (such as an
+unsupported service.communication.type or a test_execution.timeout that is
+not a duration). Exits with a nonzero status when the config has errors;
+warnings alone do not fail the check.
+
+If no path is given, the config file is found by searching upward from the
+current directory for .tusk/config.yaml.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if validateOutputFormat != "text" && validateOutputFormat != "json" {
+			return fmt.Errorf("invalid --output-format %q (choices: text, json)", validateOutputFormat)
+		}
+
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		} else if path = config.FindConfigFile(); path == "" {
+			path = ".tusk/config.yaml"
+		}
+
+		result := config.ValidateConfigFile(path)
+		if validateOutputFormat == "json" {
+			if err := printJSON(result); err != nil {
+				return err
+			}
+		} else {
+			printValidationResult(path, result)
+		}
+
+		if !result.Valid {
+			return fmt.Errorf("%s has %d error(s)", path, len(result.Errors))
+		}
+		return nil
+	},
+}
+
+func init() {
+	configValidateCmd.Flags().StringVar(&validateOutputFormat, "output-format", "text", `Output format (choices: "text", "json")`)
+	configCmd.AddCommand(configValidateCmd)
+}
+
+func printValidationResult(path string, result *config.ValidationResult) {
+	for _, e := range result.Errors {
+		log.Println(fmt.Sprintf("❌ %s", e))
+	}
+	for _, w := range result.Warnings {
+		log.Println(fmt.Sprintf("⚠️  %s", w))
+	}
+
+	switch {
+	case !result.Valid:
+		log.Println(fmt.Sprintf("\n%s is invalid", path))
+	case len(result.Warnings) > 0:
+		log.Println(fmt.Sprintf("\n%s is valid, with %d warning(s)", path, len(result.Warnings)))
+	default:
+		log.Println(fmt.Sprintf("✅ %s is valid", path))
+	}
+
+	if result.SchemaHint != "" {
+		log.Println("\nMinimal config for reference:\n" + result.SchemaHint)
+	}
+}
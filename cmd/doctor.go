@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+	"github.com/Use-Tusk/tusk-cli/internal/log"
+	"github.com/Use-Tusk/tusk-cli/internal/runner"
+)
+
+// doctorAuthTimeout bounds the Tusk Cloud round trip of the auth check.
+const doctorAuthTimeout = 10 * time.Second
+
+var doctorOutputFormat string
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose common problems with the local Tusk setup",
+	Long: `Check the local environment for problems that commonly break Tusk Drift runs:
+
+  - the config file is present and valid
+  - the mock server can listen: its Unix socket location is writable and no
+    stale socket is left over, or its TCP port (service.communication.tcp_port)
+    is free
+  - git is installed
+  - Tusk Cloud authentication works
+
+Each check prints pass, warn or fail. Exits with a nonzero status when any
+check fails; warnings alone do not fail.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if doctorOutputFormat != "text" && doctorOutputFormat != "json" {
+			return fmt.Errorf("invalid --output-format %q (choices: text, json)", doctorOutputFormat)
+		}
+
+		checks := runDoctorChecks(cmd.Context())
+		if doctorOutputFormat == "json" {
+			if err := printJSON(checks); err != nil {
+				return err
+			}
+		} else {
+			printDoctorChecks(checks)
+		}
+
+		failed := 0
+		for _, check := range checks {
+			if check.Status == runner.DoctorFail {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().StringVar(&doctorOutputFormat, "output-format", "text", `Output format (choices: "text", "json")`)
+	doctorCmd.Flags().StringVar(&cfgFile, "config", "", configFlagUsage)
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctorChecks(ctx context.Context) []runner.DoctorCheck {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	path := cfgFile
+	if path == "" {
+		path = config.FindConfigFile()
+	}
+	checks := []runner.DoctorCheck{doctorConfigCheck(path)}
+
+	// Fall back to the defaults when the config can't be used
+	_ = config.Load(cfgFile)
+	serviceCfg := &config.ServiceConfig{Communication: config.CommunicationConfig{Type: "auto", TCPPort: 9001}}
+	if cfg, err := config.Get(); err == nil && cfg != nil {
+		serviceCfg = &cfg.Service
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		checks = append(checks, runner.DoctorCheck{
+			Name:   "Mock server socket",
+			Status: runner.DoctorFail,
+			Detail: fmt.Sprintf("failed to determine working directory: %v", err),
+		})
+	} else {
+		checks = append(checks, runner.CheckMockServer(serviceCfg, cwd))
+	}
+
+	checks = append(checks, doctorGitCheck())
+
+	authCtx, cancel := context.WithTimeout(ctx, doctorAuthTimeout)
+	defer cancel()
+	checks = append(checks, doctorAuthCheck(collectAuthStatus(authCtx)))
+	return checks
+}
+
+func doctorConfigCheck(path string) runner.DoctorCheck {
+	check := runner.DoctorCheck{Name: "Config"}
+	if path == "" {
+		check.Status = runner.DoctorFail
+		check.Detail = "no .tusk/config.yaml found in this directory or its parents; run `tusk init`"
+		return check
+	}
+
+	result := config.ValidateConfigFile(path)
+	switch {
+	case !result.Valid:
+		check.Status = runner.DoctorFail
+		check.Detail = fmt.Sprintf("%s is invalid: %s", path, strings.Join(result.Errors, "; "))
+	case len(result.Warnings) > 0:
+		check.Status = runner.DoctorWarn
+		check.Detail = fmt.Sprintf("%s is valid, with warnings: %s", path, strings.Join(result.Warnings, "; "))
+	default:
+		check.Status = runner.DoctorPass
+		check.Detail = fmt.Sprintf("%s is valid", path)
+	}
+	return check
+}
+
+func doctorGitCheck() runner.DoctorCheck {
+	check := runner.DoctorCheck{Name: "Git"}
+	path, err := exec.LookPath("git")
+	if err != nil {
+		// Only needed for `tusk review` and CI metadata, not for local replays
+		check.Status = runner.DoctorWarn
+		check.Detail = "git not found on PATH; `tusk review` and cloud runs need it"
+		return check
+	}
+	check.Status = runner.DoctorPass
+	check.Detail = path
+	return check
+}
+
+func doctorAuthCheck(status authStatus) runner.DoctorCheck {
+	check := runner.DoctorCheck{Name: "Tusk Cloud auth"}
+	switch {
+	case !status.authenticated():
+		// Local replays work without an account
+		check.Status = runner.DoctorWarn
+		check.Detail = "not authenticated; run `tusk auth login` or set TUSK_API_KEY to use Tusk Cloud"
+	case !status.CloudConnected:
+		check.Status = runner.DoctorWarn
+		check.Detail = fmt.Sprintf("authenticated via %s, but Tusk Cloud is unreachable: %s", status.AuthMethod, status.CloudError)
+	default:
+		check.Status = runner.DoctorPass
+		check.Detail = fmt.Sprintf("authenticated via %s", status.AuthMethod)
+	}
+	return check
+}
+
+func printDoctorChecks(checks []runner.DoctorCheck) {
+	icons := map[runner.DoctorStatus]string{
+		runner.DoctorPass: "✅",
+		runner.DoctorWarn: "⚠️ ",
+		runner.DoctorFail: "❌",
+	}
+	for _, check := range checks {
+		log.Println(fmt.Sprintf("%s %s: %s", icons[check.Status], check.Name, check.Detail))
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Use-Tusk/tusk-cli/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffBaseFile     string
+	diffHeadFile     string
+	diffOutputFormat string
+)
+
+var driftDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare two saved result files",
+	Long: `Compare two results files written by "tusk drift run --save-results" (for example from main and
+from your branch). Reports tests that went from pass to fail, fail to pass, and failing tests whose set of
+deviating fields changed. Exits with an error when any test newly fails.`,
+	SilenceUsage: true,
+	RunE:         diffResults,
+}
+
+func init() {
+	driftCmd.AddCommand(driftDiffCmd)
+
+	f := driftDiffCmd.Flags()
+	f.StringVar(&diffBaseFile, "base", "", "Results file from the baseline run (e.g. main)")
+	f.StringVar(&diffHeadFile, "head", "", "Results file from the run to compare (e.g. your branch)")
+	f.StringVar(&diffOutputFormat, "output-format", "text", `Output format (choices: "text", "json")`)
+	_ = driftDiffCmd.MarkFlagRequired("base")
+	_ = driftDiffCmd.MarkFlagRequired("head")
+}
+
+func diffResults(cmd *cobra.Command, args []string) error {
+	if diffOutputFormat != "text" && diffOutputFormat != "json" {
+		return fmt.Errorf("invalid --output-format %q (choices: text, json)", diffOutputFormat)
+	}
+
+	base, err := runner.LoadResultsFile(diffBaseFile)
+	if err != nil {
+		return err
+	}
+	head, err := runner.LoadResultsFile(diffHeadFile)
+	if err != nil {
+		return err
+	}
+
+	diff := runner.DiffResults(base, head)
+
+	if diffOutputFormat == "json" {
+		if err := printJSON(diff); err != nil {
+			return err
+		}
+	} else {
+		fmt.Print(formatResultsDiff(diff))
+	}
+
+	if diff.HasRegressions() {
+		return fmt.Errorf("%d tests newly failing", len(diff.NewlyFailing))
+	}
+	return nil
+}
+
+func formatResultsDiff(diff *runner.ResultsDiff) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Newly failing: %d\n", len(diff.NewlyFailing))
+	for _, e := range diff.NewlyFailing {
+		fmt.Fprintf(&sb, "  - %s", e.TraceTestID)
+		if len(e.HeadFields) > 0 {
+			fmt.Fprintf(&sb, "  fields: %s", strings.Join(e.HeadFields, ", "))
+		}
+		sb.WriteString("\n")
+		if e.Message != "" {
+			fmt.Fprintf(&sb, "      %s\n", e.Message)
+		}
+	}
+
+	fmt.Fprintf(&sb, "\nNewly passing: %d\n", len(diff.NewlyPassing))
+	for _, e := range diff.NewlyPassing {
+		fmt.Fprintf(&sb, "  - %s\n", e.TraceTestID)
+	}
+
+	fmt.Fprintf(&sb, "\nChanged deviations: %d\n", len(diff.ChangedFields))
+	for _, e := range diff.ChangedFields {
+		fmt.Fprintf(&sb, "  - %s\n", e.TraceTestID)
+		for _, f := range e.Added {
+			fmt.Fprintf(&sb, "      + %s\n", f)
+		}
+		for _, f := range e.Removed {
+			fmt.Fprintf(&sb, "      - %s\n", f)
+		}
+	}
+
+	if len(diff.OnlyInBase) > 0 {
+		fmt.Fprintf(&sb, "\nOnly in base: %s\n", strings.Join(diff.OnlyInBase, ", "))
+	}
+	if len(diff.OnlyInHead) > 0 {
+		fmt.Fprintf(&sb, "\nOnly in head: %s\n", strings.Join(diff.OnlyInHead, ", "))
+	}
+
+	fmt.Fprintf(&sb, "\nUnchanged: %d\n", diff.UnchangedCount)
+	return sb.String()
+}
@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Use-Tusk/tusk-cli/internal/runner"
+	"github.com/Use-Tusk/tusk-cli/internal/utils"
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mocksTraceFile    string
+	mocksOutputFormat string
+
+	validateSchemaTraceDir     string
+	validateSchemaOutputFormat string
+
+	exportTraceID         string
+	exportTraceDir        string
+	exportOut             string
+	exportGlobalSpansFile string
+)
+
+var driftMocksCmd = &cobra.Command{
+	Use:   "mocks",
+	Short: "Inspect recorded mocks",
+	Long:  "Inspect the outbound spans recorded in local trace files. These spans are served as mocks during replay.",
+}
+
+var driftMocksInspectCmd = &cobra.Command{
+	Use:          "inspect",
+	Short:        "List recorded outbound spans in a trace file, grouped by package",
+	SilenceUsage: true,
+	RunE:         inspectMocks,
+}
+
+var driftMocksValidateSchemaCmd = &cobra.Command{
+	Use:   "validate-schema",
+	Short: "Check that recorded spans have consistent schema hashes",
+	Long: `Recompute the inputSchemaHash of every span in the local trace files from
+its recorded inputSchema and report the spans whose stored hash is missing or
+differs. Schema-based matching relies on these hashes, so a mismatch means the
+span silently never matches by schema.
+
+Trace files are only read. Exits non-zero when a mismatch is found; run
+"tusk drift traces rehash" to rewrite the stale hashes.`,
+	SilenceUsage: true,
+	RunE:         validateMockSchemas,
+}
+
+var driftMocksExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a trace and the mocks its replay uses as a standalone bundle",
+	Long: `Write a trace's spans, plus the pre-app-start spans of other traces its
+replay loads, into a directory that replays on its own:
+
+  tusk drift mocks export --trace-id <traceId> --out bundle
+  tusk drift run --trace-dir bundle
+
+Span lines are copied unchanged from the trace files. Use it to share a
+minimal reproduction when reporting a bug. With --global-spans-file the
+global spans file is copied into the bundle too; pass the copy to
+"tusk drift run --global-spans-file" when replaying.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         exportMocks,
+}
+
+func init() {
+	driftCmd.AddCommand(driftMocksCmd)
+	driftMocksCmd.AddCommand(driftMocksInspectCmd)
+	driftMocksCmd.AddCommand(driftMocksValidateSchemaCmd)
+	driftMocksCmd.AddCommand(driftMocksExportCmd)
+
+	f := driftMocksInspectCmd.Flags()
+	f.StringVar(&mocksTraceFile, "trace-file", "", "Path to a trace file (.jsonl)")
+	f.StringVar(&mocksOutputFormat, "output-format", "text", `Output format (choices: "text", "json")`)
+	_ = driftMocksInspectCmd.MarkFlagRequired("trace-file")
+
+	f = driftMocksValidateSchemaCmd.Flags()
+	f.StringVar(&validateSchemaTraceDir, "trace-dir", "", "Path to local folder containing recorded trace files (default: traces.dir from config)")
+	f.StringVar(&validateSchemaOutputFormat, "output-format", "text", `Output format (choices: "text", "json")`)
+
+	f = driftMocksExportCmd.Flags()
+	f.StringVar(&exportTraceID, "trace-id", "", "ID of the trace to export")
+	f.StringVar(&exportOut, "out", "", "Directory to write the bundle to")
+	f.StringVar(&exportTraceDir, "trace-dir", "", "Path to local folder containing recorded trace files (default: traces.dir from config)")
+	f.StringVar(&exportGlobalSpansFile, "global-spans-file", "", "JSONL file of global spans to include in the bundle")
+	_ = driftMocksExportCmd.MarkFlagRequired("trace-id")
+	_ = driftMocksExportCmd.MarkFlagRequired("out")
+}
+
+// mockSpanSummary is the inspect view of a single outbound span.
+type mockSpanSummary struct {
+	SpanID         string `json:"spanId"`
+	Name           string `json:"name"`
+	Operation      string `json:"operation,omitempty"`
+	InputValueHash string `json:"inputValueHash"`
+	IsPreAppStart  bool   `json:"isPreAppStart"`
+}
+
+// mockPackageSummary groups outbound spans recorded for one package.
+type mockPackageSummary struct {
+	PackageName string            `json:"packageName"`
+	Spans       []mockSpanSummary `json:"spans"`
+}
+
+func inspectMocks(cmd *cobra.Command, args []string) error {
+	if mocksOutputFormat != "text" && mocksOutputFormat != "json" {
+		return fmt.Errorf("invalid --output-format %q (choices: text, json)", mocksOutputFormat)
+	}
+
+	spans, err := utils.ParseSpansFromFile(mocksTraceFile, func(s *core.Span) bool { return !s.IsRootSpan })
+	if err != nil {
+		return fmt.Errorf("failed to parse trace file: %w", err)
+	}
+
+	packages := groupMockSpansByPackage(spans)
+
+	if mocksOutputFormat == "json" {
+		return printJSON(packages)
+	}
+
+	fmt.Print(formatMockPackages(mocksTraceFile, packages))
+	return nil
+}
+
+// groupMockSpansByPackage groups spans by package name, sorted by package.
+// Spans keep their order from the trace file within each package.
+func groupMockSpansByPackage(spans []*core.Span) []mockPackageSummary {
+	byPackage := make(map[string][]mockSpanSummary)
+	for _, s := range spans {
+		if s == nil {
+			continue
+		}
+		byPackage[s.PackageName] = append(byPackage[s.PackageName], mockSpanSummary{
+			SpanID:         s.SpanId,
+			Name:           s.Name,
+			Operation:      s.SubmoduleName,
+			InputValueHash: s.InputValueHash,
+			IsPreAppStart:  s.IsPreAppStart,
+		})
+	}
+
+	out := make([]mockPackageSummary, 0, len(byPackage))
+	for pkg, summaries := range byPackage {
+		out = append(out, mockPackageSummary{PackageName: pkg, Spans: summaries})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].PackageName < out[j].PackageName
+	})
+	return out
+}
+
+func formatMockPackages(traceFile string, packages []mockPackageSummary) string {
+	var sb strings.Builder
+
+	total := 0
+	for _, p := range packages {
+		total += len(p.Spans)
+	}
+	fmt.Fprintf(&sb, "%s: %d outbound spans across %d packages\n", traceFile, total, len(packages))
+
+	for _, p := range packages {
+		name := p.PackageName
+		if name == "" {
+			name = "(unknown package)"
+		}
+		fmt.Fprintf(&sb, "\n%s (%d)\n", name, len(p.Spans))
+		for _, s := range p.Spans {
+			fmt.Fprintf(&sb, "  - %s", s.Name)
+			if s.Operation != "" {
+				fmt.Fprintf(&sb, " [%s]", s.Operation)
+			}
+			fmt.Fprintf(&sb, "  hash=%s", s.InputValueHash)
+			if s.IsPreAppStart {
+				sb.WriteString("  (pre-app-start)")
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func validateMockSchemas(cmd *cobra.Command, args []string) error {
+	if validateSchemaOutputFormat != "text" && validateSchemaOutputFormat != "json" {
+		return fmt.Errorf("invalid --output-format %q (choices: text, json)", validateSchemaOutputFormat)
+	}
+
+	report, err := runner.ValidateSchemaHashes(resolveTracesDir(validateSchemaTraceDir))
+	if err != nil {
+		return err
+	}
+
+	if validateSchemaOutputFormat == "json" {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Print(formatSchemaHashReport(report))
+	}
+
+	if len(report.Mismatches) > 0 {
+		return fmt.Errorf("%d spans have inconsistent schema hashes", len(report.Mismatches))
+	}
+	return nil
+}
+
+func formatSchemaHashReport(report runner.SchemaHashReport) string {
+	var sb strings.Builder
+	for _, m := range report.Mismatches {
+		name := m.Name
+		if name == "" {
+			name = m.SpanID
+		}
+		stored := m.StoredHash
+		if stored == "" {
+			stored = "(missing)"
+		}
+		fmt.Fprintf(&sb, "%s:%d  %s (trace %s)\n", m.FilePath, m.Line, name, m.TraceID)
+		fmt.Fprintf(&sb, "  stored=%s  computed=%s\n", stored, m.ComputedHash)
+	}
+	if len(report.Mismatches) > 0 {
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "%d of %d spans with an input schema in %d files have inconsistent schema hashes\n", len(report.Mismatches), report.Spans, report.Files)
+	return sb.String()
+}
+
+func exportMocks(cmd *cobra.Command, args []string) error {
+	tracesDir := resolveTracesDir(exportTraceDir)
+	// Pre-app-start spans of other traces are read from the traces directories
+	utils.SetTracesDirOverride(tracesDir)
+
+	tests, err := runner.NewExecutor().LoadTestsFromFolder(tracesDir)
+	if err != nil {
+		return err
+	}
+	var test *runner.Test
+	for i := range tests {
+		if tests[i].TraceID == exportTraceID {
+			test = &tests[i]
+			break
+		}
+	}
+	if test == nil {
+		return fmt.Errorf("trace %s not found", exportTraceID)
+	}
+
+	bundle, err := runner.ExportTraceBundle(context.Background(), *test, exportOut, exportGlobalSpansFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported trace %s to %s\n", exportTraceID, bundle.Dir)
+	fmt.Printf("  %s: %d spans\n", filepath.Base(bundle.TraceFile), bundle.Spans)
+	if bundle.PreAppStartFile != "" {
+		fmt.Printf("  %s: %d pre-app-start spans from other traces\n", filepath.Base(bundle.PreAppStartFile), bundle.PreAppStartSpans)
+	}
+	replay := "tusk drift run --trace-dir " + bundle.Dir
+	if bundle.GlobalSpansFile != "" {
+		fmt.Printf("  %s: global spans\n", filepath.Base(bundle.GlobalSpansFile))
+		replay += " --global-spans-file " + bundle.GlobalSpansFile
+	}
+	fmt.Printf("\nReplay it with: %s\n", replay)
+	return nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Use-Tusk/tusk-cli/internal/runner"
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupMockSpansByPackage(t *testing.T) {
+	spans := []*core.Span{
+		{SpanId: "s1", PackageName: "pg", Name: "pg.query", SubmoduleName: "query", InputValueHash: "h1"},
+		{SpanId: "s2", PackageName: "http", Name: "GET /users", SubmoduleName: "GET", InputValueHash: "h2", IsPreAppStart: true},
+		nil,
+		{SpanId: "s3", PackageName: "pg", Name: "pg.query", SubmoduleName: "query", InputValueHash: "h3"},
+	}
+
+	packages := groupMockSpansByPackage(spans)
+	require.Len(t, packages, 2)
+
+	assert.Equal(t, "http", packages[0].PackageName)
+	require.Len(t, packages[0].Spans, 1)
+	assert.True(t, packages[0].Spans[0].IsPreAppStart)
+	assert.Equal(t, "GET", packages[0].Spans[0].Operation)
+
+	assert.Equal(t, "pg", packages[1].PackageName)
+	require.Len(t, packages[1].Spans, 2)
+	assert.Equal(t, "s1", packages[1].Spans[0].SpanID)
+	assert.Equal(t, "h3", packages[1].Spans[1].InputValueHash)
+
+	out := formatMockPackages("trace.jsonl", packages)
+	assert.Contains(t, out, "3 outbound spans across 2 packages")
+	assert.Contains(t, out, "GET /users [GET]  hash=h2  (pre-app-start)")
+}
+
+func TestFormatSchemaHashReport(t *testing.T) {
+	out := formatSchemaHashReport(runner.SchemaHashReport{
+		Files: 1,
+		Spans: 2,
+		Mismatches: []runner.SchemaHashMismatch{
+			{FilePath: "trace.jsonl", Line: 3, TraceID: "t1", SpanID: "s1", Name: "pg.query", ComputedHash: "abc"},
+		},
+	})
+	assert.Contains(t, out, "trace.jsonl:3  pg.query (trace t1)")
+	assert.Contains(t, out, "stored=(missing)  computed=abc")
+	assert.Contains(t, out, "1 of 2 spans with an input schema in 1 files have inconsistent schema hashes")
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+	"github.com/Use-Tusk/tusk-cli/internal/runner"
+)
+
+var (
+	selfcheckTraceID      string
+	selfcheckTraceDir     string
+	selfcheckOutputFormat string
+)
+
+var driftSelfcheckCmd = &cobra.Command{
+	Use:   "selfcheck",
+	Short: "Compare a trace's recorded response with a replay and a live request",
+	Long: `Check whether a trace can be replayed deterministically.
+
+Replays the trace with mocks, as "tusk drift run" does, then restarts the
+service with the SDK disabled (TUSK_DRIFT_MODE=DISABLED) and sends the
+recorded request again so outbound calls reach the service's real
+dependencies. Both responses are diffed against the recorded one.
+
+Fields that differ live but not in replay are nondeterministic in the
+service or its dependencies (timestamps, random IDs, changed data) rather
+than regressions. The live request is not sandboxed and may write to real
+databases or call real APIs, so only run it against a development setup.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runSelfcheck,
+}
+
+func init() {
+	driftCmd.AddCommand(driftSelfcheckCmd)
+
+	f := driftSelfcheckCmd.Flags()
+	f.StringVar(&selfcheckTraceID, "trace-id", "", "ID of the trace to check")
+	f.StringVar(&selfcheckTraceDir, "trace-dir", "", "Path to local folder containing recorded trace files (default: traces.dir from config)")
+	f.StringVar(&selfcheckOutputFormat, "output-format", "text", `Output format (choices: "text", "json")`)
+	_ = driftSelfcheckCmd.MarkFlagRequired("trace-id")
+}
+
+func runSelfcheck(cmd *cobra.Command, args []string) error {
+	if selfcheckOutputFormat != "text" && selfcheckOutputFormat != "json" {
+		return fmt.Errorf("invalid --output-format %q (choices: text, json)", selfcheckOutputFormat)
+	}
+
+	_ = config.Load(cfgFile)
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	executor := runner.NewExecutor()
+	executor.SetDebug(debug)
+	if cfg.TestExecution.Timeout != "" {
+		// Already validated for correct duration
+		d, _ := time.ParseDuration(cfg.TestExecution.Timeout)
+		executor.SetTestTimeout(d)
+	}
+	if cfg.Replay.Sandbox.Mode != "" {
+		if err := executor.SetSandboxMode(cfg.Replay.Sandbox.Mode); err != nil {
+			return err
+		}
+	}
+	executor.SetAllowSDKVersionMismatch(cfg.Replay.AllowSDKVersionMismatch)
+
+	tests, err := executor.LoadTestsFromFolder(resolveTracesDir(selfcheckTraceDir))
+	if err != nil {
+		return err
+	}
+	var test *runner.Test
+	for i := range tests {
+		if tests[i].TraceID == selfcheckTraceID {
+			test = &tests[i]
+			break
+		}
+	}
+	if test == nil {
+		return fmt.Errorf("trace %s not found", selfcheckTraceID)
+	}
+
+	// Other traces' spans are still needed for pre-app-start and suite-wide mocks
+	if err := runner.PrepareAndSetSuiteSpans(context.Background(), executor, runner.SuiteSpanOptions{Quiet: true}, tests); err != nil {
+		return fmt.Errorf("failed to prepare suite spans: %w", err)
+	}
+
+	result, err := executor.SelfCheck(*test)
+	if err != nil {
+		return err
+	}
+
+	if selfcheckOutputFormat == "json" {
+		return printJSON(result)
+	}
+	fmt.Print(formatSelfCheckResult(result))
+	return nil
+}
+
+func formatSelfCheckResult(result *runner.SelfCheckResult) string {
+	var sb strings.Builder
+	writeRun := func(name string, r runner.TestResult) {
+		switch {
+		case r.Error != "":
+			fmt.Fprintf(&sb, "%s: error: %s\n", name, r.Error)
+		case len(r.Deviations) == 0:
+			fmt.Fprintf(&sb, "%s: matches recording\n", name)
+		default:
+			fmt.Fprintf(&sb, "%s: %d deviation(s)\n", name, len(r.Deviations))
+			for _, d := range r.Deviations {
+				fmt.Fprintf(&sb, "  - %s: expected %v, got %v\n", d.Field, d.Expected, d.Actual)
+			}
+		}
+	}
+
+	fmt.Fprintf(&sb, "Trace %s\n\n", result.TraceID)
+	writeRun("Replay", result.Replay)
+	writeRun("Live", result.Live)
+
+	if fields := result.LiveOnlyFields(); len(fields) > 0 {
+		fmt.Fprintf(&sb, "\nNondeterministic outside replay: %s\n", strings.Join(fields, ", "))
+	}
+	return sb.String()
+}
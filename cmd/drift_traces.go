@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+	"github.com/Use-Tusk/tusk-cli/internal/log"
+	"github.com/Use-Tusk/tusk-cli/internal/runner"
+	"github.com/Use-Tusk/tusk-cli/internal/utils"
+)
+
+var (
+	pruneTraceDir   string
+	pruneRoutes     []string
+	pruneRoutesFile string
+	pruneDryRun     bool
+	pruneConfirm    bool
+
+	rehashTraceDir string
+	rehashDryRun   bool
+)
+
+var driftTracesCmd = &cobra.Command{
+	Use:   "traces",
+	Short: "Manage local trace files",
+}
+
+var driftTracesPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete trace files for routes your API no longer serves",
+	Long: `Delete local trace files whose recorded request matches none of the
+routes your API currently serves. Such traces can only fail with a 404 or
+missing mocks.
+
+Routes are given with --route (repeatable) or --routes-file (one per line,
+# for comments) as "[METHOD] /path". Path segments may be :name or {name}
+parameters, * for any single segment, or ** for any number of segments:
+
+  GET /users/:id
+  POST /users
+  /health
+  /admin/**
+
+Only traces whose root span is an HTTP request are considered. Nothing is
+deleted unless --confirm is passed; without it (or with --dry-run) the
+traces that would be deleted are listed.`,
+	SilenceUsage: true,
+	RunE:         pruneTraces,
+}
+
+var driftTracesRehashCmd = &cobra.Command{
+	Use:   "rehash",
+	Short: "Recompute input hashes of local trace files",
+	Long: `Recompute the inputValueHash and inputSchemaHash of every span in the
+local trace files from its recorded inputValue and inputSchema, and rewrite
+the files whose hashes changed.
+
+Use this when traces recorded with an older hashing scheme stop matching
+outbound calls by hash. With --dry-run the files that would change are
+listed without rewriting them.`,
+	SilenceUsage: true,
+	RunE:         rehashTraces,
+}
+
+func init() {
+	driftCmd.AddCommand(driftTracesCmd)
+	driftTracesCmd.AddCommand(driftTracesPruneCmd)
+	driftTracesCmd.AddCommand(driftTracesRehashCmd)
+
+	f := driftTracesPruneCmd.Flags()
+	f.StringVar(&pruneTraceDir, "trace-dir", "", "Path to local folder containing recorded trace files (default: traces.dir from config)")
+	f.StringArrayVar(&pruneRoutes, "route", nil, `Route the API serves, as "[METHOD] /path" (repeatable)`)
+	f.StringVar(&pruneRoutesFile, "routes-file", "", "File listing the routes the API serves, one per line")
+	f.BoolVar(&pruneDryRun, "dry-run", false, "List the traces that would be deleted without deleting them")
+	f.BoolVar(&pruneConfirm, "confirm", false, "Delete the stale trace files")
+
+	f = driftTracesRehashCmd.Flags()
+	f.StringVar(&rehashTraceDir, "trace-dir", "", "Path to local folder containing recorded trace files (default: traces.dir from config)")
+	f.BoolVar(&rehashDryRun, "dry-run", false, "Report the spans whose hashes would change without rewriting files")
+}
+
+// resolveTracesDir returns dir resolved against the .tusk folder, or the
+// traces folder from config when dir is empty.
+func resolveTracesDir(dir string) string {
+	if dir != "" {
+		return utils.ResolveTuskPath(dir)
+	}
+	_ = config.Load(cfgFile)
+	if cfg, err := config.Get(); err == nil && cfg.Traces.Dir != "" {
+		return cfg.Traces.Dir
+	}
+	return utils.GetTracesDir()
+}
+
+func pruneTraces(cmd *cobra.Command, args []string) error {
+	var routes []runner.Route
+	if pruneRoutesFile != "" {
+		fileRoutes, err := runner.ParseRoutesFile(pruneRoutesFile)
+		if err != nil {
+			return err
+		}
+		routes = append(routes, fileRoutes...)
+	}
+	for _, r := range pruneRoutes {
+		route, err := runner.ParseRoute(r)
+		if err != nil {
+			return err
+		}
+		routes = append(routes, route)
+	}
+	// With no routes every HTTP trace would be deleted
+	if len(routes) == 0 {
+		return fmt.Errorf("no routes given; pass --route or --routes-file")
+	}
+
+	tracesDir := resolveTracesDir(pruneTraceDir)
+
+	stale, err := runner.FindStaleTraces(tracesDir, routes)
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		log.Println(fmt.Sprintf("No stale traces in %s", tracesDir))
+		return nil
+	}
+
+	deleting := pruneConfirm && !pruneDryRun
+	files := make([]string, 0, len(stale))
+	for _, s := range stale {
+		files = append(files, s.FilePath)
+		log.Println(fmt.Sprintf("%s %s  %s", s.Method, s.Path, s.FilePath))
+	}
+
+	if !deleting {
+		log.Println(fmt.Sprintf("\n%d traces in %s match no route. Re-run with --confirm to delete them.", len(stale), tracesDir))
+		return nil
+	}
+	if err := runner.RemoveTraceFiles(tracesDir, files); err != nil {
+		return err
+	}
+	log.Println(fmt.Sprintf("\nDeleted %d stale trace files from %s", len(stale), tracesDir))
+	return nil
+}
+
+func rehashTraces(cmd *cobra.Command, args []string) error {
+	tracesDir := resolveTracesDir(rehashTraceDir)
+
+	result, err := runner.RehashTraces(tracesDir, rehashDryRun)
+	if err != nil {
+		return err
+	}
+	for _, f := range result.Changed {
+		log.Println(fmt.Sprintf("%d spans  %s", f.SpansChanged, f.FilePath))
+	}
+
+	if rehashDryRun {
+		log.Println(fmt.Sprintf("\n%d of %d spans in %d files have stale hashes. Re-run without --dry-run to rewrite them.", result.SpansChanged, result.Spans, result.Files))
+		return nil
+	}
+	log.Println(fmt.Sprintf("\nRehashed %d of %d spans in %d files under %s", result.SpansChanged, result.Spans, result.Files, tracesDir))
+	return nil
+}
@@ -56,7 +56,7 @@ func init() {
 }
 
 func bindListFlags(cmd *cobra.Command) {
-	cmd.Flags().StringVar(&traceDir, "trace-dir", "", "Path to local folder containing recorded trace files")
+	cmd.Flags().StringArrayVar(&traceDirs, "trace-dir", nil, "Path to local folder (or .tar.gz/.zip archive) containing recorded trace files (repeatable)")
 	cmd.Flags().StringVarP(&filter, "filter", "f", "", "Filter tests (see above help)")
 	cmd.Flags().BoolVarP(&cloud, "cloud", "c", false, "List trace tests from Tusk Drift Cloud")
 	cmd.Flags().BoolVar(&enableServiceLogs, "enable-service-logs", false, "Send logs from your service to a file in .tusk/logs if you start a test. Logs from the SDK will be present.")
@@ -127,25 +127,24 @@ func listTests(cmd *cobra.Command, args []string) error {
 		_ = config.Load("")
 		cfg, getConfigErr := config.Get()
 
-		selected := traceDir
+		var selected []string
+		for _, dir := range traceDirs {
+			// Resolve --trace-dir flags relative to tusk root if they're relative paths
+			selected = append(selected, utils.ResolveTuskPath(dir))
+		}
 
-		if selected == "" && getConfigErr == nil && cfg.Traces.Dir != "" {
-			selected = cfg.Traces.Dir
+		if len(selected) == 0 && getConfigErr == nil && cfg.Traces.Dir != "" {
+			selected = []string{cfg.Traces.Dir}
 		}
 
 		// Default to standard traces directory if nothing specified
-		if selected == "" {
-			selected = utils.GetTracesDir()
-		} else if traceDir != "" {
-			// Resolve --trace-dir flag relative to tusk root if it's a relative path
-			selected = utils.ResolveTuskPath(selected)
+		if len(selected) == 0 {
+			selected = []string{utils.GetTracesDir()}
 		}
 
-		if selected != "" {
-			utils.SetTracesDirOverride(selected)
-		}
+		utils.SetTracesDirOverride(selected...)
 
-		tests, err = executor.LoadTestsFromFolder(selected)
+		tests, err = executor.LoadTestsFromFolder(selected...)
 		if err != nil {
 			return fmt.Errorf("failed to load traces: %w", err)
 		}
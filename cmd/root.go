@@ -15,13 +15,17 @@ import (
 	"github.com/Use-Tusk/tusk-cli/internal/tui/styles"
 	"github.com/Use-Tusk/tusk-cli/internal/utils"
 	"github.com/Use-Tusk/tusk-cli/internal/version"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/spf13/cobra"
 )
 
 var (
 	cfgFile     string
 	debug       bool
+	logFormat   string
 	showVersion bool
+	noColor     bool
 
 	// Cleanup infrastructure
 	cleanupFuncs []func()
@@ -44,6 +48,14 @@ var rootCmd = &cobra.Command{
 			version.PrintVersion()
 			os.Exit(0)
 		}
+		if logFormat != log.FormatText && logFormat != log.FormatJSON {
+			return fmt.Errorf("--log-format must be %q or %q, got %q", log.FormatText, log.FormatJSON, logFormat)
+		}
+		if noColor {
+			styles.SetNoColor(true)
+			// Also strips the colors of the TUI and of lipgloss styles
+			lipgloss.SetColorProfile(termenv.Ascii)
+		}
 		setupLogger()
 
 		// Initialize analytics tracker
@@ -64,7 +76,7 @@ func showASCIIArt() {
 	purple := ""
 	reset := ""
 
-	if os.Getenv("NO_COLOR") == "" {
+	if !styles.NoColor() {
 		purple = "\x1b[38;5;053m"
 		if styles.HasDarkBackground {
 			purple = "\x1b[38;5;213m"
@@ -143,6 +155,8 @@ func showASCIIArt() {
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "debug output")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", log.FormatText, `Log format on stderr: "text" or "json" (JSON lines, with structured test events; disables the interactive TUI)`)
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output (same as setting NO_COLOR)")
 	rootCmd.PersistentFlags().BoolVarP(&showVersion, "version", "v", false, "show version and exit")
 	rootCmd.PersistentFlags().BoolVarP(&showVersion, "ver", "V", false, "show version and exit")
 
@@ -153,7 +167,7 @@ func init() {
 
 func setupLogger() {
 	// Default to headless mode; run command will set TUI mode if needed
-	log.Setup(debug, log.ModeHeadless)
+	log.Setup(debug, log.ModeHeadless, logFormat)
 }
 
 // RegisterCleanup adds a cleanup function to be called on program termination
@@ -4,10 +4,12 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
@@ -26,25 +28,55 @@ import (
 	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
 )
 
+// stdinTraceFile as --trace-file reads the trace from stdin.
+const stdinTraceFile = "-"
+
 var (
-	traceDir          string
+	traceDirs         []string
 	traceFile         string
 	traceID           string
+	traceIDFile       string
 	print             bool
 	outputFormat      string
 	filter            string
 	quiet             bool
+	summaryOnly       bool
 	verbose           bool
-	concurrency       int
+	concurrency       string
+	repeat            int
 	enableServiceLogs bool
 	saveResultsFormat string
 	resultsDir        string
 	sandboxMode       string
 	sandboxConfigPath string
+	envFilePath       string
+	allowSDKMismatch  bool
+	quietMocks        bool
+	strictTraceMatch  bool
+	matchReportPath   string
+	mockNotFoundPath  string
+	dryRun            bool
+	compareMocks      bool
+	failOnSeverity    string
+	shardSpec         string
+	maxFailures       int
+	dedupe            bool
+	onlyChangedBase   string
+	seed              int64
+	printMetrics      bool
+	watch             bool
+	watchDir          string
+	stateFile         string
+	globalSpansFile   string
+	listOnly          bool
+	reportUnused      bool
+	preAppStartReport bool
+	skipPreAppStart   bool
 
 	// Cloud mode
 	cloud              bool
 	ci                 bool
+	cloudOptional      bool
 	allCloudTraceTests bool
 	commitSha          string
 	prNumber           string
@@ -90,24 +122,53 @@ func init() {
 }
 
 func bindRunFlags(cmd *cobra.Command) {
-	cmd.Flags().StringVar(&traceDir, "trace-dir", "", "Path to local recordings folder")
-	cmd.Flags().StringVar(&traceFile, "trace-file", "", "Path to a single test file")
+	cmd.Flags().StringArrayVar(&traceDirs, "trace-dir", nil, "Path to local recordings folder, or a .tar.gz/.zip archive of trace files (repeatable)")
+	cmd.Flags().StringVar(&traceFile, "trace-file", "", "Path to a single test file, or - to read it from stdin")
 	cmd.Flags().StringVar(&traceID, "trace-id", "", "ID of a single test")
+	cmd.Flags().StringVar(&traceIDFile, "trace-id-file", "", "Path to a file of newline-separated trace IDs to run; IDs without a trace file are reported")
 	cmd.Flags().BoolVarP(&print, "print", "p", false, "Print response and exit (useful for pipes)")
-	cmd.Flags().StringVar(&outputFormat, "output-format", "text", `Output format (only works with --print): "text" (default) or "json" (single result) (choices: "text", "json")"`)
+	cmd.Flags().StringVar(&outputFormat, "output-format", "text", `Output format (only works with --print): "text" (default), "json" (single result), "ndjson" (one JSON line per test as it completes), or "junit" (JUnit XML report written at the end) (choices: "text", "json", "ndjson", "junit")`)
 	cmd.Flags().StringVarP(&filter, "filter", "f", "", "Filter tests (see above help)")
 	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Quiet output, only show deviations (only works with --print and --output-format text)")
+	cmd.Flags().BoolVar(&summaryOnly, "summary-only", false, "Don't print each test as it completes, only the summary at the end (runs headless)")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "", false, "Verbose output, show detailed deviation information (only works with --print)")
-	cmd.Flags().IntVar(&concurrency, "concurrency", 1, "Maximum number of concurrent tests. If set, overrides the concurrency setting in the config file.")
+	cmd.Flags().StringVar(&concurrency, "concurrency", "1", `Maximum number of concurrent tests, or "auto" to use the number of CPUs (at most 16). If set, overrides the concurrency setting in the config file.`)
+	cmd.Flags().IntVar(&repeat, "repeat", 1, "Run each test N times and report how many runs passed; tests with mixed results are marked flaky")
+	cmd.Flags().Int64Var(&seed, "seed", 0, "Seed for randomized mock matching choices, to reproduce a run exactly (matching is currently deterministic)")
 	cmd.Flags().BoolVar(&enableServiceLogs, "enable-service-logs", false, "Send logs from your service to a file in .tusk/logs. Logs from the SDK will be present.")
 	cmd.Flags().StringVar(&saveResultsFormat, "save-results", "", `Save results to .tusk/results/ (formats: "json", "agent")`)
 	cmd.Flags().StringVar(&resultsDir, "results-dir", "", "Override output directory for --save-results (default: .tusk/results/)")
 	cmd.Flags().StringVar(&sandboxMode, "sandbox-mode", "", "Replay sandbox mode: strict by default on supported platforms; choices: strict, auto, off")
 	cmd.Flags().StringVar(&sandboxConfigPath, "sandbox-config", "", "Path to a Fence config file to merge into the replay sandbox policy")
+	cmd.Flags().BoolVar(&allowSDKMismatch, "allow-sdk-version-mismatch", false, "Warn instead of failing when the SDK is older than the minimum version this CLI supports")
+	cmd.Flags().BoolVar(&quietMocks, "quiet-mocks", false, "Don't log each mock match to the test's logs; mock-not-found messages are still shown")
+	cmd.Flags().BoolVar(&strictTraceMatch, "strict-trace-matching", false, "Fail tests whose outbound calls (other than pre-app-start) were mocked with a span from another trace")
+	cmd.Flags().StringVar(&envFilePath, "env-file", "", "Path to a dotenv file whose variables are set on the service at startup, overriding recorded env vars")
+	cmd.Flags().StringVar(&matchReportPath, "match-report", "", "Write every mock match decision to a JSON file at this path after the run")
+	cmd.Flags().BoolVar(&printMetrics, "print-metrics", false, "Print mock request counts and search times to stderr after the run")
+	cmd.Flags().StringVar(&mockNotFoundPath, "mock-not-found-report", "", "Write every outbound call that found no mock to a JSON file at this path after the run")
+	cmd.Flags().BoolVar(&preAppStartReport, "include-preappstart-report", false, "Before the run, list each environment group with its trace count and the env var keys extracted from pre-app-start spans")
+	cmd.Flags().BoolVar(&skipPreAppStart, "skip-preappstart", false, "Don't load pre-app-start spans from other traces or group tests by environment; on by default for local --trace-file runs")
+	cmd.Flags().BoolVar(&reportUnused, "report-unused", false, "After the run, list the recorded outbound spans of each trace that no call matched")
+	cmd.Flags().BoolVar(&listOnly, "list", false, `Print the tests that would run after filters, sharding and environment grouping, then exit (use --output-format json for JSON)`)
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report whether each test's outbound calls would find a mock, without starting the service")
+	cmd.Flags().BoolVar(&compareMocks, "compare-mocks", false, "Report pairs of outbound spans within a trace that the mock matcher could confuse, without starting the service")
+	cmd.Flags().StringVar(&failOnSeverity, "fail-on-severity", "", `Only fail on deviations at or above this severity (choices: "info", "warn", "error"; pass as --fail-on-severity=warn, "error" if given without a value)`)
+	cmd.Flags().Lookup("fail-on-severity").NoOptDefVal = runner.SeverityError
+	cmd.Flags().IntVar(&maxFailures, "max-failures", 0, "Stop after N tests fail and skip the remaining tests (0 = no limit)")
+	cmd.Flags().StringVar(&onlyChangedBase, "only-changed", "", `Only run tests whose route is mapped (test_execution.route_mappings) from a file changed since this git ref (pass as --only-changed=origin/main; "origin/HEAD" if given without a value)`)
+	cmd.Flags().Lookup("only-changed").NoOptDefVal = "origin/HEAD"
+	cmd.Flags().StringVar(&globalSpansFile, "global-spans-file", "", "Load global spans for cross-trace mock matching from a JSONL file instead of Tusk Drift Cloud")
+	cmd.Flags().BoolVar(&dedupe, "dedupe", false, "Collapse tests whose root request input is identical, keeping one of each")
+	cmd.Flags().StringVar(&shardSpec, "shard", "", "Run only shard i of n (e.g. 2/5) to split the suite across parallel CI jobs")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Keep the service running and re-run tests when files in --watch-dir change")
+	cmd.Flags().StringVar(&watchDir, "watch-dir", ".", "Source directory to watch with --watch")
+	cmd.Flags().StringVar(&stateFile, "state-file", "", "Record each completed test in this file and skip the tests it already records, so an interrupted run resumes where it stopped; deleted once the run completes")
 
 	// Cloud mode
 	cmd.Flags().BoolVarP(&cloud, "cloud", "c", false, "[Cloud] Use Tusk Drift Cloud backend for orchestration/reporting")
 	cmd.Flags().BoolVar(&ci, "ci", false, "[Cloud] Create a Tusk Drift run and upload results to Tusk Drift Cloud")
+	cmd.Flags().BoolVar(&cloudOptional, "cloud-optional", false, "[Cloud] If Tusk Drift Cloud can't be reached, replay local trace files instead, without uploading results or reporting CI status")
 	cmd.Flags().BoolVarP(&allCloudTraceTests, "all-cloud-trace-tests", "a", false, "[Cloud] Run against all trace tests from Tusk Drift Cloud for this run (not just the current suite)")
 	cmd.Flags().StringVar(&commitSha, "commit-sha", "", "[Cloud] Commit SHA for this run (only works with --ci)")
 	cmd.Flags().StringVar(&prNumber, "pr-number", "", "[Cloud] Pull request number (only works with --ci)")
@@ -132,20 +193,35 @@ func runTests(cmd *cobra.Command, args []string) error {
 	setupSignalHandling()
 
 	log.Debug("Starting test execution",
-		"trace-dir", traceDir,
+		"trace-dir", traceDirs,
 		"trace-file", traceFile,
 		"trace-id", traceID,
+		"trace-id-file", traceIDFile,
 		"print", print,
 		"output-format", outputFormat,
 		"filter", filter,
 		"quiet", quiet,
+		"summary-only", summaryOnly,
 		"concurrency", concurrency,
+		"repeat", repeat,
+		"dry-run", dryRun,
+		"compare-mocks", compareMocks,
+		"fail-on-severity", failOnSeverity,
+		"shard", shardSpec,
+		"state-file", stateFile,
+		"max-failures", maxFailures,
 		"enable-service-logs", enableServiceLogs,
 		"save-results", saveResultsFormat,
 		"results-dir", resultsDir,
 		"sandbox-mode", sandboxMode,
 		"sandbox-config", sandboxConfigPath,
+		"env-file", envFilePath,
+		"allow-sdk-version-mismatch", allowSDKMismatch,
+		"quiet-mocks", quietMocks,
+		"strict-trace-matching", strictTraceMatch,
+		"skip-preappstart", skipPreAppStart,
 		"cloud", cloud,
+		"cloud-optional", cloudOptional,
 		"ci", ci,
 		"commitSha", commitSha,
 		"prNumber", prNumber,
@@ -162,11 +238,18 @@ func runTests(cmd *cobra.Command, args []string) error {
 	if getConfigErr == nil && cfg.TestExecution.Concurrency > 0 {
 		executor.SetConcurrency(cfg.TestExecution.Concurrency)
 	}
+	if getConfigErr == nil && cfg.TestExecution.EnvConcurrency > 0 {
+		executor.SetEnvConcurrency(cfg.TestExecution.EnvConcurrency)
+	}
+	if getConfigErr == nil {
+		executor.SetCrashRetries(cfg.TestExecution.CrashRetries)
+	}
 	if getConfigErr == nil && cfg.TestExecution.Timeout != "" {
 		// Already validated for correct duration
 		d, _ := time.ParseDuration(cfg.TestExecution.Timeout)
 		executor.SetTestTimeout(d)
 	}
+	dedupeTests := dedupe || (getConfigErr == nil && cfg.TestExecution.Dedupe)
 	if getConfigErr == nil && cfg.Replay.Sandbox.Mode != "" {
 		if err := executor.SetSandboxMode(cfg.Replay.Sandbox.Mode); err != nil {
 			cmd.SilenceUsage = true
@@ -186,9 +269,31 @@ func runTests(cmd *cobra.Command, args []string) error {
 	if cmd.Flags().Changed("sandbox-config") {
 		executor.SetReplaySandboxConfigPath(sandboxConfigPath)
 	}
+	if getConfigErr == nil && cfg.Results.Redact != nil {
+		if err := executor.SetResultsRedaction(*cfg.Results.Redact, cfg.Logging.RedactFields); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+	}
+	if getConfigErr == nil {
+		executor.SetAllowSDKVersionMismatch(cfg.Replay.AllowSDKVersionMismatch)
+	}
+	if cmd.Flags().Changed("allow-sdk-version-mismatch") {
+		executor.SetAllowSDKVersionMismatch(allowSDKMismatch)
+	}
+	executor.SetQuietMocks(quietMocks)
+	executor.SetStrictTraceMatching(strictTraceMatch)
+	if envFilePath != "" {
+		envFileVars, err := runner.LoadEnvFile(envFilePath)
+		if err != nil {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("--env-file: %w", err)
+		}
+		executor.SetEnvFileVars(envFileVars)
+	}
 
-	if traceDir != "" {
-		utils.SetTracesDirOverride(traceDir)
+	if len(traceDirs) > 0 {
+		utils.SetTracesDirOverride(traceDirs...)
 	} else if getConfigErr == nil && cfg.Traces.Dir != "" {
 		utils.SetTracesDirOverride(cfg.Traces.Dir)
 	}
@@ -202,115 +307,146 @@ func runTests(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--results-dir requires --save-results")
 	}
 
-	interactive := !print && (utils.IsTerminal() || utils.TUICIMode())
+	if failOnSeverity != "" && !runner.IsValidSeverity(failOnSeverity) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("--fail-on-severity must be \"info\", \"warn\" or \"error\", got %q", failOnSeverity)
+	}
 
-	var driftRunID string
-	var client *api.TuskClient
-	var authOptions api.AuthOptions
-	isValidation := false
+	if skipPreAppStart && preAppStartReport {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("--skip-preappstart cannot be combined with --include-preappstart-report")
+	}
+	// A single local trace file rarely needs startup mocks from other traces,
+	// and reading every trace file for them dominates its startup time
+	skipPreAppStartSpans := skipPreAppStart ||
+		(!cmd.Flags().Changed("skip-preappstart") && traceFile != "" && !cloud && !preAppStartReport)
 
-	if cloud {
+	if cloudOptional && (!cloud || traceTestID != "") {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("--cloud-optional requires --cloud and cannot be combined with --trace-test-id")
+	}
+
+	if traceIDFile != "" && (traceFile != "" || traceID != "" || cloud) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("--trace-id-file cannot be combined with --trace-file, --trace-id or --cloud")
+	}
+
+	var shard runner.Shard
+	if shardSpec != "" {
 		var err error
-		client, authOptions, cfg, err = api.SetupCloud(context.Background(), true)
-		if err != nil {
+		if shard, err = runner.ParseShard(shardSpec); err != nil {
 			cmd.SilenceUsage = true
-			return formatApiError(err)
+			return fmt.Errorf("--shard: %w", err)
 		}
+		if validateSuite || validateSuiteIfDefaultBranch {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("--shard cannot be combined with suite validation")
+		}
+	}
 
-		// Check for validation mode
-		// --validate-suite forces validation regardless of branch
-		// --validate-suite-if-default-branch only validates on default branch
-		if validateSuite && traceTestID == "" {
-			log.Debug("Validation mode forced with --validate-suite flag")
-			isValidation = true
-		} else if validateSuiteIfDefaultBranch && traceTestID == "" {
-			// Get default branch from backend
-			infoReq := &backend.GetObservableServiceInfoRequest{
-				ObservableServiceId: cfg.Service.ID,
-			}
-			info, err := client.GetObservableServiceInfo(context.Background(), infoReq, authOptions)
-			if err != nil {
-				return formatApiError(fmt.Errorf("failed to get observable service info: %w", err))
-			}
-
-			// Check if we're on the default branch
-			currentBranch := getBranchFromEnv()
-			if currentBranch == info.DefaultBranch {
-				log.Debug("On default branch, running validation run", "currentBranch", currentBranch, "defaultBranch", info.DefaultBranch)
-				isValidation = true
-			} else {
-				log.Debug("Not on default branch, running regular run", "currentBranch", currentBranch, "defaultBranch", info.DefaultBranch)
-			}
+	var changedRoutes *runner.ChangedRoutes
+	if onlyChangedBase != "" {
+		cmd.SilenceUsage = true
+		if validateSuite || validateSuiteIfDefaultBranch {
+			return fmt.Errorf("--only-changed cannot be combined with suite validation")
+		}
+		if getConfigErr != nil || len(cfg.TestExecution.RouteMappings) == 0 {
+			return fmt.Errorf("--only-changed requires test_execution.route_mappings in the config")
+		}
+		changedFiles, err := changedFilesSince(onlyChangedBase)
+		if err != nil {
+			return fmt.Errorf("--only-changed: %w", err)
 		}
+		if changedRoutes, err = runner.NewChangedRoutes(changedFiles, cfg.TestExecution.RouteMappings); err != nil {
+			return err
+		}
+		if !quiet {
+			log.Stderrln(fmt.Sprintf("➤ %d files changed since %s, affecting %d routes", len(changedFiles), onlyChangedBase, len(changedRoutes.Routes)))
+		}
+	}
 
-		if ci || isValidation {
-			var req *backend.CreateDriftRunRequest
+	if listOnly && (dryRun || compareMocks || watch || saveResultsFormat != "" || ci || validateSuite || validateSuiteIfDefaultBranch) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("--list cannot be combined with --dry-run, --compare-mocks, --watch, --save-results, --ci or suite validation")
+	}
 
-			if isValidation {
-				commitSha = getCommitSHAFromEnv()
-				req = &backend.CreateDriftRunRequest{
-					ObservableServiceId: cfg.Service.ID,
-					CliVersion:          version.Version,
-					IsValidationRun:     true,
-					CommitSha:           stringPtr(commitSha),
-					BranchName:          stringPtr(getBranchFromEnv()),
-				}
-			} else {
-				// Regular CI mode: validate and include CI metadata
-				ciMetadata := CIMetadata{
-					CommitSha:          commitSha,
-					PRNumber:           prNumber,
-					BranchName:         branchName,
-					ExternalCheckRunID: externalCheckRunID,
-				}
+	if dryRun && (ci || validateSuite || validateSuiteIfDefaultBranch) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("--dry-run cannot be combined with --ci or suite validation")
+	}
 
-				ciMetadata, err = validateCIMetadata(ciMetadata)
-				if err != nil {
-					cmd.SilenceUsage = true
-					return err
-				}
+	if compareMocks && (dryRun || ci || validateSuite || validateSuiteIfDefaultBranch) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("--compare-mocks cannot be combined with --dry-run, --ci or suite validation")
+	}
 
-				commitSha = ciMetadata.CommitSha
-				prNumber = ciMetadata.PRNumber
-				branchName = ciMetadata.BranchName
-				externalCheckRunID = ciMetadata.ExternalCheckRunID
+	if maxFailures < 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("--max-failures must not be negative, got %d", maxFailures)
+	}
 
-				req = &backend.CreateDriftRunRequest{
-					ObservableServiceId: cfg.Service.ID,
-					CliVersion:          version.Version,
-					CommitSha:           stringPtr(commitSha),
-					PrNumber:            stringPtr(prNumber),
-					BranchName:          stringPtr(branchName),
-					ExternalCheckRunId:  stringPtr(externalCheckRunID),
-					IsValidationRun:     false,
-				}
-			}
+	var fileGlobalSpans []*core.Span
+	if globalSpansFile != "" {
+		cmd.SilenceUsage = true
+		if cloud {
+			return fmt.Errorf("--global-spans-file cannot be combined with --cloud, which fetches global spans from Tusk Drift Cloud")
+		}
+		var err error
+		if fileGlobalSpans, err = runner.LoadGlobalSpansFile(globalSpansFile); err != nil {
+			return err
+		}
+		if !quiet {
+			log.Stderrln(fmt.Sprintf("➤ Loaded %d global spans from %s", len(fileGlobalSpans), globalSpansFile))
+		}
+	}
 
-			id, err := client.CreateDriftRun(context.Background(), req, authOptions)
-			if err != nil {
-				// Handle skippable errors as a no-op in CI mode
-				// (e.g. no seat, paused by label, feature disabled after trial expiry, repo disabled)
-				if api.IsSkippableError(err) && ci {
-					log.Stderrln("Skipping: " + err.Error())
-					utils.CIWarning("Tusk Drift skipped: " + err.Error())
-					return nil
-				}
-				return formatApiError(fmt.Errorf("failed to create drift run: %w", err))
-			}
+	if stateFile != "" && (cloud || watch) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("--state-file cannot be combined with --cloud or --watch")
+	}
 
-			driftRunID = id
-			if !interactive {
-				log.Stderrln(fmt.Sprintf("Tusk Drift run ID: %s", driftRunID))
-			}
+	if watch && (cloud || dryRun || compareMocks || shardSpec != "" || maxFailures > 0) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("--watch cannot be combined with --cloud, --dry-run, --compare-mocks, --shard or --max-failures")
+	}
 
-			statusReq := &backend.UpdateDriftRunCIStatusRequest{
-				DriftRunId: driftRunID,
-				CiStatus:   backend.DriftRunCIStatus_DRIFT_RUN_CI_STATUS_RUNNING,
-			}
-			if err := client.UpdateDriftRunCIStatus(context.Background(), statusReq, authOptions); err != nil {
-				log.Warn("Failed to update CI status to RUNNING", "error", err)
-			}
+	// Dry runs, --compare-mocks and --list only print a report, shards run
+	// in CI, the TUI schedules tests itself so it can't stop at
+	// --max-failures, and JSON logs are for aggregators; none of these open
+	// the TUI. Watch mode streams results run after run instead
+	// A trace piped on stdin leaves the TUI without keyboard input
+	interactive := !print && !summaryOnly && !dryRun && !compareMocks && !listOnly && !watch && !reportUnused && !preAppStartReport && traceFile != stdinTraceFile && shardSpec == "" && stateFile == "" && maxFailures == 0 && logFormat != log.FormatJSON && (utils.IsTerminal() || utils.TUICIMode())
+
+	// Keep stdout parseable when it carries a JSON or JUnit report
+	if !interactive && (outputFormat == "json" || outputFormat == "ndjson" || outputFormat == "junit") {
+		log.SetUserOutput(os.Stderr)
+	}
+
+	var driftRunID string
+	var client *api.TuskClient
+	var authOptions api.AuthOptions
+	isValidation := false
+
+	if cloud {
+		var err error
+		client, authOptions, cfg, err = api.SetupCloud(context.Background(), true)
+		if err != nil {
+			cmd.SilenceUsage = true
+			return formatApiError(err)
+		}
+
+		run, err := startCloudRun(context.Background(), cmd, client, authOptions, cfg.Service.ID, interactive)
+		if err != nil {
+			return err
 		}
+		if run.skipped {
+			return nil
+		}
+		if !cloud {
+			client = nil
+		}
+		isValidation = run.isValidation
+		driftRunID = run.driftRunID
 	} else if getConfigErr != nil {
 		// Non-cloud mode: config is required
 		cmd.SilenceUsage = true
@@ -318,8 +454,20 @@ func runTests(cmd *cobra.Command, args []string) error {
 	}
 
 	if cmd.Flags().Changed("concurrency") {
-		executor.SetConcurrency(concurrency)
+		n, err := parseConcurrency(concurrency, runtime.NumCPU())
+		if err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		executor.SetConcurrency(n)
 	}
+	if repeat < 1 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("--repeat must be at least 1, got %d", repeat)
+	}
+	executor.SetRepeat(repeat)
+	executor.SetSeed(seed)
+	executor.SetMaxFailures(maxFailures)
 
 	executor.SetEnableServiceLogs(enableServiceLogs || debug)
 
@@ -411,7 +559,8 @@ func runTests(cmd *cobra.Command, args []string) error {
 			return
 		}
 		if !res.Passed {
-			if err := agentWriter.WriteDeviation(test, res, executor.GetServer()); err != nil {
+			test, res := executor.RedactForResults(test, res)
+			if err := agentWriter.WriteDeviation(test, res, executor.ExecutorForTrace(test.TraceID).GetServer()); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to write agent deviation file: %v\n", err)
 			}
 		} else {
@@ -421,12 +570,12 @@ func runTests(cmd *cobra.Command, args []string) error {
 
 	if !interactive {
 		executor.SetOnTestCompleted(func(res runner.TestResult, test runner.Test) {
-			runner.OutputSingleResult(res, test, outputFormat, quiet, verbose)
+			outputStreamedResult(res, test)
 			writeAgentResult(res, test)
 
 			// Cleanup trace spans after the test is completed
-			if executor.GetServer() != nil {
-				executor.GetServer().CleanupTraceSpans(test.TraceID)
+			if server := executor.ExecutorForTrace(test.TraceID).GetServer(); server != nil {
+				server.CleanupTraceSpans(test.TraceID)
 			}
 		})
 	}
@@ -444,7 +593,7 @@ func runTests(cmd *cobra.Command, args []string) error {
 		existingCallback := func(res runner.TestResult, test runner.Test) {}
 		if !interactive {
 			existingCallback = func(res runner.TestResult, test runner.Test) {
-				runner.OutputSingleResult(res, test, outputFormat, quiet, verbose)
+				outputStreamedResult(res, test)
 			}
 		}
 
@@ -459,11 +608,17 @@ func runTests(cmd *cobra.Command, args []string) error {
 				client,
 				driftRunID,
 				authOptions,
-				executor,
+				executor.ExecutorForTrace(test.TraceID),
 				res,
 				test,
 			)
 
+			if err != nil {
+				log.Event("upload_attempted", "traceId", test.TraceID, "driftRunId", driftRunID, "uploaded", false, "error", err.Error())
+			} else {
+				log.Event("upload_attempted", "traceId", test.TraceID, "driftRunId", driftRunID, "uploaded", true)
+			}
+
 			mu.Lock()
 			attemptedCount++
 			if err != nil {
@@ -480,8 +635,8 @@ func runTests(cmd *cobra.Command, args []string) error {
 			mu.Unlock()
 
 			// Cleanup trace spans after the test is completed
-			if executor.GetServer() != nil {
-				executor.GetServer().CleanupTraceSpans(test.TraceID)
+			if server := executor.ExecutorForTrace(test.TraceID).GetServer(); server != nil {
+				server.CleanupTraceSpans(test.TraceID)
 			}
 		})
 	}
@@ -490,8 +645,8 @@ func runTests(cmd *cobra.Command, args []string) error {
 	if executor.OnTestCompleted == nil {
 		executor.SetOnTestCompleted(func(res runner.TestResult, test runner.Test) {
 			writeAgentResult(res, test)
-			if executor.GetServer() != nil {
-				executor.GetServer().CleanupTraceSpans(test.TraceID)
+			if server := executor.ExecutorForTrace(test.TraceID).GetServer(); server != nil {
+				server.CleanupTraceSpans(test.TraceID)
 			}
 		})
 	}
@@ -541,6 +696,119 @@ func runTests(cmd *cobra.Command, args []string) error {
 		})
 	}
 
+	// Match report: capture match events before the existing callback cleans up trace spans
+	// The match report also feeds results.include_match_events
+	includeMatchEvents := saveResultsFormat == "json" && getConfigErr == nil && cfg.Results.IncludeMatchEvents
+	var matchReport *runner.MatchReport
+	if matchReportPath != "" || includeMatchEvents {
+		matchReport = runner.NewMatchReport()
+		if includeMatchEvents {
+			executor.SetResultsMatchEvents(matchReport)
+		}
+		existingCallback := executor.OnTestCompleted
+		executor.SetOnTestCompleted(func(res runner.TestResult, test runner.Test) {
+			// Repeated tests are recorded per run by OnRepeatRunCompleted
+			if res.Runs <= 1 {
+				if server := executor.ExecutorForTrace(test.TraceID).GetServer(); server != nil {
+					matchReport.Record(test.TraceID, server.GetMatchEvents(test.TraceID))
+				}
+			}
+			if existingCallback != nil {
+				existingCallback(res, test)
+			}
+		})
+		executor.SetOnRepeatRunCompleted(func(res runner.TestResult, test runner.Test, run int) {
+			if server := executor.ExecutorForTrace(test.TraceID).GetServer(); server != nil {
+				matchReport.RecordRun(test.TraceID, run, server.GetMatchEvents(test.TraceID))
+			}
+		})
+	}
+	// Mock-not-found report and JUnit output: capture mock-not-found events
+	// before the existing callback cleans up trace spans
+	var mockNotFoundReport *runner.MockNotFoundReport
+	if mockNotFoundPath != "" || (outputFormat == "junit" && !interactive) {
+		mockNotFoundReport = runner.NewMockNotFoundReport()
+		existingCallback := executor.OnTestCompleted
+		executor.SetOnTestCompleted(func(res runner.TestResult, test runner.Test) {
+			if server := executor.ExecutorForTrace(test.TraceID).GetServer(); server != nil {
+				mockNotFoundReport.Record(test.TraceID, server.GetMockNotFoundEvents(test.TraceID))
+			}
+			if existingCallback != nil {
+				existingCallback(res, test)
+			}
+		})
+	}
+	// Unused spans: capture span usage before the existing callback cleans up trace spans
+	var unusedSpans *runner.UnusedSpansReport
+	if !interactive {
+		unusedSpans = runner.NewUnusedSpansReport()
+		existingCallback := executor.OnTestCompleted
+		executor.SetOnTestCompleted(func(res runner.TestResult, test runner.Test) {
+			if server := executor.ExecutorForTrace(test.TraceID).GetServer(); server != nil && !res.Cancelled {
+				unusedSpans.Record(test.TraceID, server.GetUnusedSpans(test.TraceID))
+			}
+			if existingCallback != nil {
+				existingCallback(res, test)
+			}
+		})
+	}
+	// Match statistics: fold match events before the existing callback cleans up trace spans
+	var matchStats *runner.MatchStatistics
+	if !interactive {
+		matchStats = &runner.MatchStatistics{}
+		addMatchEvents := func(traceID string) {
+			if server := executor.ExecutorForTrace(traceID).GetServer(); server != nil {
+				events := server.GetMatchEvents(traceID)
+				mu.Lock()
+				matchStats.Add(events)
+				mu.Unlock()
+			}
+		}
+		existingCallback := executor.OnTestCompleted
+		executor.SetOnTestCompleted(func(res runner.TestResult, test runner.Test) {
+			// Repeated tests are counted per run by OnRepeatRunCompleted
+			if res.Runs <= 1 {
+				addMatchEvents(test.TraceID)
+			}
+			if existingCallback != nil {
+				existingCallback(res, test)
+			}
+		})
+		existingRepeatCallback := executor.OnRepeatRunCompleted
+		executor.SetOnRepeatRunCompleted(func(res runner.TestResult, test runner.Test, run int) {
+			addMatchEvents(test.TraceID)
+			if existingRepeatCallback != nil {
+				existingRepeatCallback(res, test, run)
+			}
+		})
+	}
+	// Written on every return path so failed runs still leave a report behind
+	defer func() {
+		if matchReport == nil || matchReportPath == "" {
+			return
+		}
+		if err := matchReport.WriteToFile(matchReportPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write match report: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Match report written to: %s\n", matchReportPath)
+		}
+	}()
+	defer func() {
+		if printMetrics {
+			fmt.Fprintln(os.Stderr, executor.GetMockMetrics().String())
+		}
+	}()
+	defer func() {
+		if mockNotFoundPath == "" {
+			return
+		}
+		if err := mockNotFoundReport.WriteToFile(mockNotFoundPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write mock-not-found report: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Mock-not-found report written to: %s\n", mockNotFoundPath)
+		}
+	}()
+
 	var tests []runner.Test
 	var err error
 
@@ -566,6 +834,7 @@ func runTests(cmd *cobra.Command, args []string) error {
 				traceTestID,
 				allCloudTraceTests || !ci,
 				filter,
+				changedRoutes,
 				quiet,
 			)
 			tests, err = loadTests(context.Background())
@@ -582,14 +851,63 @@ func runTests(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if !deferLoadTests && len(tests) == 0 {
+	// Collapsed and other shards' tests still provide spans for mock matching
+	allTests := tests
+	if dedupeTests && !deferLoadTests {
+		var collapsed int
+		tests, collapsed = runner.DedupeTests(tests)
+		if collapsed > 0 && !quiet {
+			log.Stderrln(fmt.Sprintf("➤ Collapsed %d duplicate tests with identical root inputs", collapsed))
+		}
+	}
+	if shardSpec != "" {
+		total := len(tests)
+		tests = runner.ShardTests(tests, shard)
+		if !quiet {
+			log.Stderrln(fmt.Sprintf("➤ Shard %s: %d of %d tests", shard, len(tests), total))
+		}
+	}
+
+	// --state-file: skip the tests an interrupted run already completed, and
+	// record each test as it completes
+	plannedTests := tests
+	var resumedResults []runner.TestResult
+	var runState *runner.RunState
+	if stateFile != "" && !deferLoadTests {
+		if runState, err = runner.LoadRunState(stateFile); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		tests, resumedResults = runState.Resume(tests)
+		if len(resumedResults) > 0 && !quiet {
+			log.Stderrln(fmt.Sprintf("➤ Resuming from %s: %d tests already completed, %d remaining", stateFile, len(resumedResults), len(tests)))
+		}
+		existingCallback := executor.OnTestCompleted
+		executor.SetOnTestCompleted(func(res runner.TestResult, test runner.Test) {
+			if err := runState.Record(res); err != nil {
+				log.Warn("Failed to record test in state file", "testID", test.TraceID, "error", err)
+			}
+			if existingCallback != nil {
+				existingCallback(res, test)
+			}
+		})
+	}
+
+	if !deferLoadTests && len(tests) == 0 && len(resumedResults) == 0 {
 		noTestsMsg := "No tests found"
 		if isValidation {
 			noTestsMsg = "No traces to validate"
 		}
 
 		if print && outputFormat == "json" {
-			log.Println("[]")
+			// The report itself goes to stdout even though user output is on stderr
+			fmt.Fprintln(os.Stdout, "[]")
+			log.Stderrln(noTestsMsg)
+		} else if print && outputFormat == "junit" {
+			_ = runner.OutputResultsSummary(nil, runner.SummaryOptions{Format: outputFormat, Quiet: true})
+			log.Stderrln(noTestsMsg)
+		} else if print && outputFormat == "ndjson" {
+			// An empty stream
 			log.Stderrln(noTestsMsg)
 		} else {
 			log.Println(noTestsMsg)
@@ -619,7 +937,7 @@ func runTests(cmd *cobra.Command, args []string) error {
 
 	// Fetch pre-app-start spans before grouping (needed for ENV_VARS extraction)
 	var preAppStartSpans []*core.Span
-	if !deferLoadTests {
+	if !deferLoadTests && !skipPreAppStartSpans {
 		if cloud && client != nil {
 			preAppStartSpans, err = runner.FetchPreAppStartSpansFromCloudWithCache(
 				context.Background(),
@@ -646,7 +964,7 @@ func runTests(cmd *cobra.Command, args []string) error {
 		// For local traces: build suite spans with ALL tests first (for mock matching),
 		// then filter out error responses (status >= 300) for execution only.
 		// This ensures spans from error traces are still available for mock matching.
-		testsForSuiteSpans := tests
+		testsForSuiteSpans := allTests
 		if !cloud {
 			// Build suite spans with ALL tests before filtering
 			if err := runner.PrepareAndSetSuiteSpans(
@@ -661,6 +979,8 @@ func runTests(cmd *cobra.Command, args []string) error {
 					Interactive:            false,
 					Quiet:                  quiet,
 					AllowSuiteWideMatching: isValidation,
+					PreloadedGlobalSpans:   fileGlobalSpans,
+					SkipPreAppStart:        skipPreAppStartSpans,
 				},
 				testsForSuiteSpans,
 			); err != nil {
@@ -687,25 +1007,68 @@ func runTests(cmd *cobra.Command, args []string) error {
 					Interactive:            false,
 					Quiet:                  quiet,
 					AllowSuiteWideMatching: isValidation,
+					SkipPreAppStart:        skipPreAppStartSpans,
 				},
-				tests,
+				allTests,
 			); err != nil {
 				log.Warn("Failed to prepare suite spans", "error", err)
 			}
 		}
 
-		groupResult, err = runner.GroupTestsByEnvironment(tests, preAppStartSpans)
-		if err != nil {
-			cmd.SilenceUsage = true
-			return fmt.Errorf("failed to group tests by environment: %w", err)
+		// Without pre-app-start spans there are no environments to group by,
+		// so tests run in a single environment
+		if !skipPreAppStartSpans {
+			groupResult, err = runner.GroupTestsByEnvironment(tests, preAppStartSpans)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("failed to group tests by environment: %w", err)
+			}
+
+			// Log warnings if any
+			for _, warning := range groupResult.Warnings {
+				if !quiet {
+					log.Stderrln(fmt.Sprintf("⚠️  %s", warning))
+				}
+			}
 		}
 
-		// Log warnings if any
-		for _, warning := range groupResult.Warnings {
-			if !quiet {
-				log.Stderrln(fmt.Sprintf("⚠️  %s", warning))
+		if preAppStartReport {
+			// Keep stdout machine-readable for json and junit output
+			w := os.Stdout
+			if outputFormat != "text" {
+				w = os.Stderr
 			}
+			if err := runner.WriteEnvironmentGroupsReport(w, runner.ReportEnvironmentGroups(groupResult)); err != nil {
+				log.Warn("Failed to write environment groups report", "error", err)
+			}
+		}
+	}
+
+	if listOnly {
+		cmd.SilenceUsage = true
+		groups := []*runner.EnvironmentGroup{{Name: "default", Tests: tests}}
+		if groupResult != nil {
+			groups = groupResult.Groups
+		}
+		return runner.OutputTestPlan(os.Stdout, runner.PlanTests(groups), outputFormat)
+	}
+
+	if dryRun {
+		cmd.SilenceUsage = true
+		coverage, err := executor.DryRunMockCoverage(tests)
+		if err != nil {
+			return fmt.Errorf("dry run failed: %w", err)
+		}
+		return runner.OutputMockCoverage(coverage, outputFormat)
+	}
+
+	if compareMocks {
+		cmd.SilenceUsage = true
+		collisions, err := executor.CompareMocks(tests)
+		if err != nil {
+			return fmt.Errorf("mock comparison failed: %w", err)
 		}
+		return runner.OutputMockCollisions(collisions, len(tests), outputFormat)
 	}
 
 	RegisterCleanup(func() {
@@ -729,6 +1092,31 @@ func runTests(cmd *cobra.Command, args []string) error {
 		}
 	})
 
+	// Export spans of the replay itself when OTEL_EXPORTER_OTLP_ENDPOINT is set
+	shutdownTelemetry, telemetryErr := runner.SetupTelemetry(context.Background())
+	if telemetryErr != nil {
+		log.Warn("Failed to set up OpenTelemetry export", "error", telemetryErr)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTelemetry(ctx); err != nil {
+			log.Debug("Failed to flush OpenTelemetry spans", "error", err)
+		}
+	}()
+	var runSpanResults []runner.TestResult
+	executor.StartRunSpan()
+	defer func() { executor.EndRunSpan(runSpanResults) }()
+
+	if watch {
+		cmd.SilenceUsage = true
+		var routeMappings []config.RouteMapping
+		if getConfigErr == nil {
+			routeMappings = cfg.TestExecution.RouteMappings
+		}
+		return watchTests(executor, tests, groupResult, routeMappings)
+	}
+
 	if interactive {
 		initialLogs := []string{}
 		if driftRunID != "" {
@@ -765,18 +1153,30 @@ func runTests(cmd *cobra.Command, args []string) error {
 					return fmt.Errorf("invalid filter: %w", err)
 				}
 			}
+			if changedRoutes != nil {
+				preloadedTests = changedRoutes.Filter(preloadedTests)
+			}
 			allTestsForSuiteSpans = preloadedTests
+			if dedupeTests {
+				var collapsed int
+				preloadedTests, collapsed = runner.DedupeTests(preloadedTests)
+				if collapsed > 0 {
+					initialLogs = append(initialLogs, fmt.Sprintf("Collapsed %d duplicate tests with identical root inputs", collapsed))
+				}
+			}
 
-			preloadedPreAppStartSpans, err = runner.FetchPreAppStartSpansFromCloudWithCache(
-				context.Background(),
-				client,
-				authOptions,
-				cfg.Service.ID,
-				true,
-				false,
-			)
-			if err != nil {
-				log.Warn("Failed to pre-fetch pre-app-start spans", "error", err)
+			if !skipPreAppStartSpans {
+				preloadedPreAppStartSpans, err = runner.FetchPreAppStartSpansFromCloudWithCache(
+					context.Background(),
+					client,
+					authOptions,
+					cfg.Service.ID,
+					true,
+					false,
+				)
+				if err != nil {
+					log.Warn("Failed to pre-fetch pre-app-start spans", "error", err)
+				}
 			}
 
 			preloadedGlobalSpans, err = runner.FetchGlobalSpansFromCloudWithCache(
@@ -791,6 +1191,7 @@ func runTests(cmd *cobra.Command, args []string) error {
 				log.Warn("Failed to pre-fetch global spans", "error", err)
 			}
 		} else {
+			preloadedGlobalSpans = fileGlobalSpans
 			initialLogs = append(initialLogs, "📁 Loading tests from local traces...")
 			baseLoadTestsFn := makeLoadTestsFunc(
 				executor,
@@ -802,6 +1203,7 @@ func runTests(cmd *cobra.Command, args []string) error {
 				traceTestID,
 				false,
 				filter,
+				changedRoutes,
 				quiet,
 			)
 			loadTestsFn = func(ctx context.Context) ([]runner.Test, error) {
@@ -820,6 +1222,13 @@ func runTests(cmd *cobra.Command, args []string) error {
 						log.ServiceLog(fmt.Sprintf("Skipping %d tests with HTTP status >= 300 (spans still available for mocking)", excludedCount))
 					}
 				}
+				if dedupeTests {
+					var collapsed int
+					tests, collapsed = runner.DedupeTests(tests)
+					if collapsed > 0 {
+						log.ServiceLog(fmt.Sprintf("Collapsed %d duplicate tests with identical root inputs", collapsed))
+					}
+				}
 				return tests, nil
 			}
 		}
@@ -848,11 +1257,13 @@ func runTests(cmd *cobra.Command, args []string) error {
 						AllowSuiteWideMatching:    isValidation,
 						PreloadedPreAppStartSpans: preloadedPreAppStartSpans,
 						PreloadedGlobalSpans:      preloadedGlobalSpans,
+						SkipPreAppStart:           skipPreAppStartSpans,
 					},
 					testsForSpans,
 				)
 			},
 			OnAllCompleted: func(results []runner.TestResult, tests []runner.Test, exec *runner.Executor) {
+				runSpanResults = results
 				// Write agent index after all tests complete (interactive mode)
 				if agentWriter != nil {
 					passed, _ := countPassedFailed(results)
@@ -945,8 +1356,9 @@ func runTests(cmd *cobra.Command, args []string) error {
 
 			return fmt.Errorf("environment-based test execution failed: %w", err)
 		}
-	} else {
-		// Fallback: Original single-environment flow (for interactive mode or edge cases)
+	} else if len(tests) > 0 {
+		// Fallback: Original single-environment flow (for interactive mode or edge cases).
+		// No tests are left when the run being resumed completed them all (--state-file)
 		if !interactive && !quiet {
 			log.Stderrln("➤ Starting environment...")
 		}
@@ -1027,6 +1439,20 @@ func runTests(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if runState != nil {
+		results = append(resumedResults, results...)
+		tests = plannedTests
+		completed := !executor.FailureLimitReached()
+		for _, r := range results {
+			completed = completed && !r.Cancelled
+		}
+		if completed {
+			if err := runState.Clear(); err != nil {
+				log.Warn("Failed to clear state file", "error", err)
+			}
+		}
+	}
+
 	// Write saved results after all tests complete (non-interactive mode)
 	if !interactive && saveResultsFormat != "" {
 		switch saveResultsFormat {
@@ -1047,6 +1473,8 @@ func runTests(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	runSpanResults = results
+
 	_ = os.Stdout.Sync()
 	time.Sleep(1 * time.Millisecond)
 
@@ -1062,7 +1490,46 @@ func runTests(cmd *cobra.Command, args []string) error {
 	var outputErr error
 	if !interactive {
 		// Results already streamed, just print summary
-		outputErr = runner.OutputResultsSummary(results, outputFormat, quiet)
+		var junitMockNotFound map[string][]runner.MockNotFoundEvent
+		if outputFormat == "junit" {
+			junitMockNotFound = mockNotFoundReport.Events()
+		}
+		outputErr = runner.OutputResultsSummary(results, runner.SummaryOptions{
+			Format:         outputFormat,
+			Quiet:          quiet,
+			FailOnSeverity: failOnSeverity,
+			MockNotFound:   junitMockNotFound,
+			MatchStats:     matchStats,
+			UnusedSpans:    unusedSpans,
+		})
+		if reportUnused {
+			// Keep stdout machine-readable for json and junit output
+			w := os.Stdout
+			if outputFormat != "text" {
+				w = os.Stderr
+			}
+			if spans, _ := unusedSpans.Counts(); spans == 0 {
+				_, _ = fmt.Fprintln(w, "Every recorded outbound span was used.")
+			} else {
+				_, _ = fmt.Fprintln(w, "Unused recorded spans:")
+				if err := unusedSpans.WriteText(w); err != nil {
+					log.Warn("Failed to write unused spans", "error", err)
+				}
+			}
+		}
+	}
+
+	if executor.FailureLimitReached() {
+		failed, skipped := 0, 0
+		for _, r := range results {
+			switch {
+			case r.Cancelled:
+				skipped++
+			case !r.Passed:
+				failed++
+			}
+		}
+		log.Stderrln(fmt.Sprintf("⚠️  Stopped early after %d failed tests (--max-failures %d); %d tests were skipped", failed, maxFailures, skipped))
 	}
 
 	if !interactive && !quiet {
@@ -1112,6 +1579,15 @@ func runTests(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// outputStreamedResult prints a headless run's result as its test completes,
+// unless --summary-only leaves the run to its summary.
+func outputStreamedResult(res runner.TestResult, test runner.Test) {
+	if summaryOnly {
+		return
+	}
+	runner.OutputSingleResult(res, test, outputFormat, quiet, verbose)
+}
+
 func loadCloudTests(ctx context.Context, client *api.TuskClient, auth api.AuthOptions, serviceID, driftRunID, traceTestID string, allCloud bool, quiet bool, suiteStatusFilter *backend.TraceTestStatus) ([]runner.Test, error) {
 	if traceTestID != "" {
 		req := &backend.GetTraceTestRequest{
@@ -1171,6 +1647,7 @@ func makeLoadTestsFunc(
 	traceTestID string,
 	allCloud bool,
 	filter string,
+	changedRoutes *runner.ChangedRoutes,
 	quiet bool,
 ) func(ctx context.Context) ([]runner.Test, error) {
 	return func(ctx context.Context) ([]runner.Test, error) {
@@ -1191,8 +1668,24 @@ func makeLoadTestsFunc(
 			}
 		} else {
 			switch {
-			case traceDir != "":
-				tests, err = executor.LoadTestsFromFolder(traceDir)
+			case traceIDFile != "":
+				// Resolved against --trace-dir too, via the traces dir override
+				var traceIDs []string
+				if traceIDs, err = runner.ReadTraceIDFile(traceIDFile); err == nil {
+					var missing []string
+					tests, missing, err = executor.LoadTestsByTraceIDs(traceIDs)
+					if err == nil && len(missing) > 0 {
+						log.UserWarn(fmt.Sprintf("⚠️  %d of %d trace IDs in %s could not be resolved", len(missing), len(traceIDs), traceIDFile))
+					}
+				}
+			case len(traceDirs) > 0:
+				tests, err = executor.LoadTestsFromFolder(traceDirs...)
+			case traceFile == stdinTraceFile:
+				var test *runner.Test
+				test, err = executor.LoadTestFromReader(os.Stdin, "stdin")
+				if test != nil {
+					tests = []runner.Test{*test}
+				}
 			case traceFile != "":
 				var test *runner.Test
 				test, err = executor.LoadTestFromTraceFile(traceFile)
@@ -1218,7 +1711,12 @@ func makeLoadTestsFunc(
 		}
 
 		if filter != "" {
-			return runner.FilterTests(tests, filter)
+			if tests, err = runner.FilterTests(tests, filter); err != nil {
+				return nil, err
+			}
+		}
+		if changedRoutes != nil {
+			tests = changedRoutes.Filter(tests)
 		}
 		return tests, nil
 	}
@@ -1234,11 +1732,14 @@ type CIMetadata struct {
 // validateCIMetadata validates and populates CI metadata from environment variables
 // Only attempts to populate from environment variables when running in a recognized CI environment.
 func validateCIMetadata(metadata CIMetadata) (CIMetadata, error) {
-	// Note: we only detect GitHub/GitLab CI environments for now since they are the most common.
-	// Other CI providers can use flags to provide this metadata.
+	// Note: we only detect GitHub, GitLab, Bitbucket, CircleCI and Buildkite for
+	// now. Other CI providers can use flags to provide this metadata.
 	isGitHub := os.Getenv("GITHUB_ACTIONS") == "true"
 	isGitLab := os.Getenv("GITLAB_CI") == "true"
-	inCI := isGitHub || isGitLab
+	isBitbucket := os.Getenv("BITBUCKET_BUILD_NUMBER") != ""
+	isCircleCI := os.Getenv("CIRCLECI") == "true"
+	isBuildkite := os.Getenv("BUILDKITE") == "true"
+	inCI := isGitHub || isGitLab || isBitbucket || isCircleCI || isBuildkite
 
 	// Only populate from environment variables if in CI
 	if inCI {
@@ -1247,7 +1748,8 @@ func validateCIMetadata(metadata CIMetadata) (CIMetadata, error) {
 		}
 
 		if metadata.PRNumber == "" {
-			if isGitHub {
+			switch {
+			case isGitHub:
 				if ref := os.Getenv("GITHUB_REF"); ref != "" {
 					// Only for pull request events
 					// Example: refs/pull/123/merge -> 123
@@ -1256,8 +1758,24 @@ func validateCIMetadata(metadata CIMetadata) (CIMetadata, error) {
 						metadata.PRNumber = parts[2]
 					}
 				}
-			} else if isGitLab {
+			case isGitLab:
 				metadata.PRNumber = os.Getenv("CI_MERGE_REQUEST_IID")
+				if metadata.PRNumber == "" {
+					// Pipelines for pull requests of repositories mirrored from GitHub
+					metadata.PRNumber = os.Getenv("CI_EXTERNAL_PULL_REQUEST_IID")
+				}
+			case isBitbucket:
+				metadata.PRNumber = os.Getenv("BITBUCKET_PR_ID")
+			case isCircleCI:
+				// Example: https://github.com/org/repo/pull/123 -> 123
+				if prURL := os.Getenv("CIRCLE_PULL_REQUEST"); prURL != "" {
+					metadata.PRNumber = prURL[strings.LastIndex(prURL, "/")+1:]
+				}
+			case isBuildkite:
+				// "false" when the build is not for a pull request
+				if pr := os.Getenv("BUILDKITE_PULL_REQUEST"); pr != "false" {
+					metadata.PRNumber = pr
+				}
 			}
 		}
 
@@ -1266,15 +1784,22 @@ func validateCIMetadata(metadata CIMetadata) (CIMetadata, error) {
 		}
 
 		if metadata.ExternalCheckRunID == "" {
-			if isGitHub {
+			switch {
+			case isGitHub:
 				metadata.ExternalCheckRunID = os.Getenv("GITHUB_CHECK_RUN_ID")
-			} else if isGitLab {
+			case isGitLab:
 				// GitLab doesn't have an exact equivalent to check runs
 				// Use pipeline ID as the external identifier
 				metadata.ExternalCheckRunID = os.Getenv("CI_PIPELINE_ID")
 				if metadata.ExternalCheckRunID == "" {
 					metadata.ExternalCheckRunID = os.Getenv("CI_JOB_ID")
 				}
+			case isBitbucket:
+				metadata.ExternalCheckRunID = os.Getenv("BITBUCKET_PIPELINE_UUID")
+			case isCircleCI:
+				metadata.ExternalCheckRunID = os.Getenv("CIRCLE_WORKFLOW_ID")
+			case isBuildkite:
+				metadata.ExternalCheckRunID = os.Getenv("BUILDKITE_BUILD_ID")
 			}
 		}
 	}
@@ -1337,16 +1862,25 @@ func stringPtr(s string) *string {
 // getCommitSHAFromEnv returns the commit SHA from CI environment variables.
 // Returns empty string if no SHA can be determined from the environment.
 func getCommitSHAFromEnv() string {
-	isGitHub := os.Getenv("GITHUB_ACTIONS") == "true"
-	isGitLab := os.Getenv("GITLAB_CI") == "true"
-
-	if isGitHub {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
 		if sha := getGitHubPRHeadSHA(); sha != "" {
 			return sha
 		}
 		return os.Getenv("GITHUB_SHA")
-	} else if isGitLab {
+	case os.Getenv("GITLAB_CI") == "true":
+		// Merged results pipelines run on a temporary merge commit; report the
+		// MR's source commit instead
+		if sha := os.Getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_SHA"); sha != "" {
+			return sha
+		}
 		return os.Getenv("CI_COMMIT_SHA")
+	case os.Getenv("BITBUCKET_BUILD_NUMBER") != "":
+		return os.Getenv("BITBUCKET_COMMIT")
+	case os.Getenv("CIRCLECI") == "true":
+		return os.Getenv("CIRCLE_SHA1")
+	case os.Getenv("BUILDKITE") == "true":
+		return os.Getenv("BUILDKITE_COMMIT")
 	}
 
 	return ""
@@ -1362,13 +1896,26 @@ func getBranchFromEnv() string {
 	if branch := os.Getenv("GITHUB_REF_NAME"); branch != "" {
 		return branch
 	}
-	// GitLab CI - prefer merge request source branch when available
+	// GitLab CI - prefer merge request source branch when available. MR
+	// pipelines check out a detached HEAD, so git can't name the branch.
 	if branch := os.Getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME"); branch != "" {
 		return branch
 	}
 	if branch := os.Getenv("CI_COMMIT_REF_NAME"); branch != "" {
 		return branch
 	}
+	// Bitbucket Pipelines
+	if branch := os.Getenv("BITBUCKET_BRANCH"); branch != "" {
+		return branch
+	}
+	// CircleCI
+	if branch := os.Getenv("CIRCLE_BRANCH"); branch != "" {
+		return branch
+	}
+	// Buildkite
+	if branch := os.Getenv("BUILDKITE_BRANCH"); branch != "" {
+		return branch
+	}
 
 	// Fallback: git rev-parse
 	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
@@ -1376,7 +1923,58 @@ func getBranchFromEnv() string {
 	if err != nil {
 		return ""
 	}
-	return strings.TrimSpace(string(output))
+	return branchFromRevParse(string(output))
+}
+
+// changedFilesSince lists the files that differ between base and the working
+// tree, relative to the repository root.
+func changedFilesSince(base string) ([]string, error) {
+	out, err := exec.Command("git", "diff", "--name-only", base, "--").Output() //nolint:gosec
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("git diff against %s failed: %s", base, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git diff against %s failed: %w", base, err)
+	}
+	return parseChangedFiles(string(out)), nil
+}
+
+func parseChangedFiles(output string) []string {
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files
+}
+
+// maxAutoConcurrency caps --concurrency auto. Every test hits the same
+// service, so beyond this more workers mostly add contention.
+const maxAutoConcurrency = 16
+
+// parseConcurrency resolves --concurrency: a positive number, or "auto" for
+// numCPU capped at maxAutoConcurrency.
+func parseConcurrency(value string, numCPU int) (int, error) {
+	if strings.EqualFold(strings.TrimSpace(value), "auto") {
+		return max(1, min(numCPU, maxAutoConcurrency)), nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf(`--concurrency must be a positive number or "auto", got %q`, value)
+	}
+	return n, nil
+}
+
+// branchFromRevParse returns the branch printed by `git rev-parse --abbrev-ref
+// HEAD`, or "" for a detached HEAD, where git prints "HEAD".
+func branchFromRevParse(output string) string {
+	branch := strings.TrimSpace(output)
+	if branch == "HEAD" {
+		return ""
+	}
+	return branch
 }
 
 // fetchValidationTraceTests fetches all traces for validation (draft + in_suite)
@@ -1409,6 +2007,134 @@ func fetchValidationTraceTests(ctx context.Context, client *api.TuskClient, auth
 	return runner.ConvertTraceTestsToRunnerTests(allTests), nil
 }
 
+// cloudRun is the outcome of startCloudRun.
+type cloudRun struct {
+	isValidation bool
+	driftRunID   string
+	// skipped is set when the backend skipped a CI run; nothing is replayed
+	skipped bool
+}
+
+// startCloudRun decides whether this is a validation run and creates the
+// drift run in Tusk Drift Cloud when one is needed. With --cloud-optional, an
+// unreachable backend turns the run into a local one by clearing cloud: tests
+// replay from local trace files and nothing is reported.
+func startCloudRun(ctx context.Context, cmd *cobra.Command, client *api.TuskClient, authOptions api.AuthOptions, serviceID string, interactive bool) (cloudRun, error) {
+	var run cloudRun
+	fallBackToLocal := func(err error) {
+		msg := fmt.Sprintf("Tusk Drift Cloud is unreachable (%v); replaying local trace files without uploading results", err)
+		log.Stderrln("⚠️  " + msg)
+		utils.CIWarning(msg)
+		cloud = false
+		run = cloudRun{}
+	}
+
+	// Check for validation mode
+	// --validate-suite forces validation regardless of branch
+	// --validate-suite-if-default-branch only validates on default branch
+	if validateSuite && traceTestID == "" {
+		log.Debug("Validation mode forced with --validate-suite flag")
+		run.isValidation = true
+	} else if validateSuiteIfDefaultBranch && traceTestID == "" {
+		// Get default branch from backend
+		infoReq := &backend.GetObservableServiceInfoRequest{
+			ObservableServiceId: serviceID,
+		}
+		info, err := client.GetObservableServiceInfo(ctx, infoReq, authOptions)
+		if cloudUnavailable(err, cloudOptional) {
+			fallBackToLocal(err)
+			return run, nil
+		} else if err != nil {
+			return run, formatApiError(fmt.Errorf("failed to get observable service info: %w", err))
+		} else if currentBranch := getBranchFromEnv(); currentBranch == info.DefaultBranch {
+			// On the default branch
+			log.Debug("On default branch, running validation run", "currentBranch", currentBranch, "defaultBranch", info.DefaultBranch)
+			run.isValidation = true
+		} else {
+			log.Debug("Not on default branch, running regular run", "currentBranch", currentBranch, "defaultBranch", info.DefaultBranch)
+		}
+	}
+
+	if !ci && !run.isValidation {
+		return run, nil
+	}
+
+	var req *backend.CreateDriftRunRequest
+	if run.isValidation {
+		commitSha = getCommitSHAFromEnv()
+		req = &backend.CreateDriftRunRequest{
+			ObservableServiceId: serviceID,
+			CliVersion:          version.Version,
+			IsValidationRun:     true,
+			CommitSha:           stringPtr(commitSha),
+			BranchName:          stringPtr(getBranchFromEnv()),
+		}
+	} else {
+		// Regular CI mode: validate and include CI metadata
+		ciMetadata := CIMetadata{
+			CommitSha:          commitSha,
+			PRNumber:           prNumber,
+			BranchName:         branchName,
+			ExternalCheckRunID: externalCheckRunID,
+		}
+
+		ciMetadata, err := validateCIMetadata(ciMetadata)
+		if err != nil {
+			cmd.SilenceUsage = true
+			return run, err
+		}
+
+		commitSha = ciMetadata.CommitSha
+		prNumber = ciMetadata.PRNumber
+		branchName = ciMetadata.BranchName
+		externalCheckRunID = ciMetadata.ExternalCheckRunID
+
+		req = &backend.CreateDriftRunRequest{
+			ObservableServiceId: serviceID,
+			CliVersion:          version.Version,
+			CommitSha:           stringPtr(commitSha),
+			PrNumber:            stringPtr(prNumber),
+			BranchName:          stringPtr(branchName),
+			ExternalCheckRunId:  stringPtr(externalCheckRunID),
+			IsValidationRun:     false,
+		}
+	}
+
+	id, err := client.CreateDriftRun(ctx, req, authOptions)
+	switch {
+	case err == nil:
+		run.driftRunID = id
+		if !interactive {
+			log.Stderrln(fmt.Sprintf("Tusk Drift run ID: %s", run.driftRunID))
+		}
+
+		statusReq := &backend.UpdateDriftRunCIStatusRequest{
+			DriftRunId: run.driftRunID,
+			CiStatus:   backend.DriftRunCIStatus_DRIFT_RUN_CI_STATUS_RUNNING,
+		}
+		if err := client.UpdateDriftRunCIStatus(ctx, statusReq, authOptions); err != nil {
+			log.Warn("Failed to update CI status to RUNNING", "error", err)
+		}
+	case api.IsSkippableError(err) && ci:
+		// Handle skippable errors as a no-op in CI mode
+		// (e.g. no seat, paused by label, feature disabled after trial expiry, repo disabled)
+		log.Stderrln("Skipping: " + err.Error())
+		utils.CIWarning("Tusk Drift skipped: " + err.Error())
+		run.skipped = true
+	case cloudUnavailable(err, cloudOptional):
+		fallBackToLocal(err)
+	default:
+		return run, formatApiError(fmt.Errorf("failed to create drift run: %w", err))
+	}
+	return run, nil
+}
+
+// cloudUnavailable reports whether a failed Tusk Drift Cloud request should
+// turn the run into a local one (--cloud-optional).
+func cloudUnavailable(err error, cloudOptional bool) bool {
+	return cloudOptional && err != nil && api.IsUnreachableError(err)
+}
+
 func updateStatusToFailure(ctx context.Context, client *api.TuskClient, driftRunID string, auth api.AuthOptions, message string) {
 	statusReq := &backend.UpdateDriftRunCIStatusRequest{
 		DriftRunId:      driftRunID,
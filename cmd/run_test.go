@@ -1,12 +1,22 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/Use-Tusk/tusk-cli/internal/api"
+	"github.com/Use-Tusk/tusk-cli/internal/runner"
+	"github.com/Use-Tusk/tusk-cli/internal/utils"
 )
 
 func writeEventFile(t *testing.T, payload any) string {
@@ -164,3 +174,301 @@ func TestValidateCIMetadata_GitHubPRSHA(t *testing.T) {
 		require.Equal(t, "flag-sha", meta.CommitSha)
 	})
 }
+
+// ciEnvVars are the CI variables read by CI metadata detection.
+var ciEnvVars = []string{
+	"GITHUB_ACTIONS", "GITHUB_SHA", "GITHUB_REF", "GITHUB_HEAD_REF", "GITHUB_REF_NAME",
+	"GITHUB_EVENT_NAME", "GITHUB_EVENT_PATH", "GITHUB_CHECK_RUN_ID",
+	"GITLAB_CI", "CI_COMMIT_SHA", "CI_MERGE_REQUEST_SOURCE_BRANCH_SHA", "CI_MERGE_REQUEST_IID",
+	"CI_EXTERNAL_PULL_REQUEST_IID", "CI_MERGE_REQUEST_SOURCE_BRANCH_NAME", "CI_COMMIT_REF_NAME",
+	"CI_PIPELINE_ID", "CI_JOB_ID",
+	"BITBUCKET_BUILD_NUMBER", "BITBUCKET_COMMIT", "BITBUCKET_BRANCH", "BITBUCKET_PR_ID", "BITBUCKET_PIPELINE_UUID",
+	"CIRCLECI", "CIRCLE_SHA1", "CIRCLE_BRANCH", "CIRCLE_PULL_REQUEST", "CIRCLE_WORKFLOW_ID",
+	"BUILDKITE", "BUILDKITE_COMMIT", "BUILDKITE_BRANCH", "BUILDKITE_PULL_REQUEST", "BUILDKITE_BUILD_ID",
+}
+
+func clearCIEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range ciEnvVars {
+		t.Setenv(name, "")
+	}
+}
+
+func TestValidateCIMetadata_ProviderMatrix(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected CIMetadata
+	}{
+		{
+			name: "GitLab MR pipeline",
+			env: map[string]string{
+				"GITLAB_CI":                           "true",
+				"CI_COMMIT_SHA":                       "mr-sha",
+				"CI_MERGE_REQUEST_IID":                "17",
+				"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME": "feature/login",
+				"CI_COMMIT_REF_NAME":                  "refs/merge-requests/17/head",
+				"CI_PIPELINE_ID":                      "9001",
+			},
+			expected: CIMetadata{CommitSha: "mr-sha", PRNumber: "17", BranchName: "feature/login", ExternalCheckRunID: "9001"},
+		},
+		{
+			name: "GitLab merged results pipeline reports source commit",
+			env: map[string]string{
+				"GITLAB_CI":                           "true",
+				"CI_COMMIT_SHA":                       "merge-result-sha",
+				"CI_MERGE_REQUEST_SOURCE_BRANCH_SHA":  "source-sha",
+				"CI_MERGE_REQUEST_IID":                "18",
+				"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME": "feature/cart",
+				"CI_JOB_ID":                           "77",
+			},
+			expected: CIMetadata{CommitSha: "source-sha", PRNumber: "18", BranchName: "feature/cart", ExternalCheckRunID: "77"},
+		},
+		{
+			name: "GitLab external pull request pipeline",
+			env: map[string]string{
+				"GITLAB_CI":                    "true",
+				"CI_COMMIT_SHA":                "ext-sha",
+				"CI_EXTERNAL_PULL_REQUEST_IID": "5",
+				"CI_COMMIT_REF_NAME":           "feature/ext",
+			},
+			expected: CIMetadata{CommitSha: "ext-sha", PRNumber: "5", BranchName: "feature/ext"},
+		},
+		{
+			name: "Bitbucket pull request pipeline",
+			env: map[string]string{
+				"BITBUCKET_BUILD_NUMBER":  "12",
+				"BITBUCKET_COMMIT":        "bb-sha",
+				"BITBUCKET_PR_ID":         "3",
+				"BITBUCKET_BRANCH":        "feature/bb",
+				"BITBUCKET_PIPELINE_UUID": "{uuid}",
+			},
+			expected: CIMetadata{CommitSha: "bb-sha", PRNumber: "3", BranchName: "feature/bb", ExternalCheckRunID: "{uuid}"},
+		},
+		{
+			name: "CircleCI pull request",
+			env: map[string]string{
+				"CIRCLECI":            "true",
+				"CIRCLE_SHA1":         "circle-sha",
+				"CIRCLE_BRANCH":       "feature/circle",
+				"CIRCLE_PULL_REQUEST": "https://github.com/org/repo/pull/123",
+				"CIRCLE_WORKFLOW_ID":  "wf-1",
+			},
+			expected: CIMetadata{CommitSha: "circle-sha", PRNumber: "123", BranchName: "feature/circle", ExternalCheckRunID: "wf-1"},
+		},
+		{
+			name: "Buildkite pull request",
+			env: map[string]string{
+				"BUILDKITE":              "true",
+				"BUILDKITE_COMMIT":       "bk-sha",
+				"BUILDKITE_BRANCH":       "feature/bk",
+				"BUILDKITE_PULL_REQUEST": "88",
+				"BUILDKITE_BUILD_ID":     "build-1",
+			},
+			expected: CIMetadata{CommitSha: "bk-sha", PRNumber: "88", BranchName: "feature/bk", ExternalCheckRunID: "build-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearCIEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			meta, err := validateCIMetadata(CIMetadata{})
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, meta)
+		})
+	}
+}
+
+func TestValidateCIMetadata_ProviderWithoutPullRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"CircleCI branch build", map[string]string{"CIRCLECI": "true", "CIRCLE_SHA1": "sha", "CIRCLE_BRANCH": "main"}},
+		{"Buildkite branch build", map[string]string{"BUILDKITE": "true", "BUILDKITE_COMMIT": "sha", "BUILDKITE_BRANCH": "main", "BUILDKITE_PULL_REQUEST": "false"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearCIEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			meta, err := validateCIMetadata(CIMetadata{})
+			require.ErrorContains(t, err, "pull/merge request number is required")
+			require.Equal(t, "sha", meta.CommitSha)
+			require.Empty(t, meta.PRNumber)
+		})
+	}
+}
+
+func TestGetBranchFromEnv_Precedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{"GitHub PR head ref", map[string]string{"GITHUB_HEAD_REF": "gh-pr", "GITHUB_REF_NAME": "42/merge"}, "gh-pr"},
+		{"GitHub ref name", map[string]string{"GITHUB_REF_NAME": "main"}, "main"},
+		{"GitLab MR source branch over ref name", map[string]string{"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME": "gl-mr", "CI_COMMIT_REF_NAME": "gl-ref"}, "gl-mr"},
+		{"GitLab ref name", map[string]string{"CI_COMMIT_REF_NAME": "gl-ref"}, "gl-ref"},
+		{"Bitbucket branch", map[string]string{"BITBUCKET_BRANCH": "bb-branch"}, "bb-branch"},
+		{"CircleCI branch", map[string]string{"CIRCLE_BRANCH": "circle-branch"}, "circle-branch"},
+		{"Buildkite branch", map[string]string{"BUILDKITE_BRANCH": "bk-branch"}, "bk-branch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearCIEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			require.Equal(t, tt.expected, getBranchFromEnv())
+		})
+	}
+}
+
+func TestBranchFromRevParse_DetachedHead(t *testing.T) {
+	require.Equal(t, "", branchFromRevParse("HEAD\n"))
+	require.Equal(t, "main", branchFromRevParse("main\n"))
+}
+
+func TestParseChangedFiles(t *testing.T) {
+	require.Equal(t, []string{"src/users/get.ts", "README.md"}, parseChangedFiles("src/users/get.ts\nREADME.md\n"))
+	require.Empty(t, parseChangedFiles("\n"))
+}
+
+func TestParseConcurrency(t *testing.T) {
+	tests := []struct {
+		value  string
+		numCPU int
+		want   int
+	}{
+		{value: "4", numCPU: 8, want: 4},
+		{value: "auto", numCPU: 8, want: 8},
+		{value: "AUTO", numCPU: 2, want: 2},
+		{value: "auto", numCPU: 64, want: maxAutoConcurrency},
+		{value: "auto", numCPU: 0, want: 1},
+	}
+	for _, tt := range tests {
+		got, err := parseConcurrency(tt.value, tt.numCPU)
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, got, "%s with %d CPUs", tt.value, tt.numCPU)
+	}
+
+	for _, value := range []string{"0", "-2", "many", ""} {
+		_, err := parseConcurrency(value, 8)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestStartCloudRun_CloudOptional(t *testing.T) {
+	defer func(c, o, v, vd, ct bool, id, sha string) {
+		cloud, cloudOptional, validateSuite, validateSuiteIfDefaultBranch, ci, traceTestID, commitSha = c, o, v, vd, ct, id, sha
+	}(cloud, cloudOptional, validateSuite, validateSuiteIfDefaultBranch, ci, traceTestID, commitSha)
+	traceTestID = ""
+	ci = false
+	auth := api.AuthOptions{APIKey: "test-key"}
+
+	// A closed server leaves the backend unreachable
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+	downClient := api.NewClient(down.URL, "test-key")
+
+	start := func(t *testing.T, client *api.TuskClient, optional, ifDefaultBranch bool) (cloudRun, error) {
+		t.Helper()
+		cloud, cloudOptional = true, optional
+		validateSuite, validateSuiteIfDefaultBranch = !ifDefaultBranch, ifDefaultBranch
+		return startCloudRun(context.Background(), &cobra.Command{}, client, auth, "svc", false)
+	}
+
+	t.Run("backend down replays local traces", func(t *testing.T) {
+		dir := t.TempDir()
+		root, err := json.Marshal(map[string]any{
+			"traceId":     "trace-local",
+			"spanId":      "span-root",
+			"name":        "GET /health",
+			"packageName": "http",
+			"isRootSpan":  true,
+			"inputValue":  map[string]any{"method": "GET", "target": "/health"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "trace-local.jsonl"), append(root, '\n'), 0o600))
+		utils.SetTracesDirOverride(dir)
+		t.Cleanup(func() { utils.SetTracesDirOverride() })
+
+		for _, ifDefaultBranch := range []bool{false, true} {
+			run, err := start(t, downClient, true, ifDefaultBranch)
+			require.NoError(t, err)
+			assert.False(t, cloud)
+			assert.False(t, run.isValidation)
+			assert.Empty(t, run.driftRunID)
+		}
+
+		// runTests drops the client once cloud is cleared
+		tests, err := makeLoadTestsFunc(&runner.Executor{}, nil, auth, "svc", "", "", "", false, "", nil, true)(context.Background())
+		require.NoError(t, err)
+		require.Len(t, tests, 1)
+		assert.Equal(t, "trace-local", tests[0].TraceID)
+	})
+
+	t.Run("without --cloud-optional the run aborts", func(t *testing.T) {
+		_, err := start(t, downClient, false, false)
+		assert.Error(t, err)
+		assert.True(t, cloud)
+	})
+
+	t.Run("rejected request still aborts", func(t *testing.T) {
+		rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer rejecting.Close()
+
+		_, err := start(t, api.NewClient(rejecting.URL, "test-key"), true, false)
+		assert.Error(t, err)
+		assert.True(t, cloud)
+	})
+}
+
+func TestOutputStreamedResult_SummaryOnly(t *testing.T) {
+	results := []runner.TestResult{
+		{TestID: "trace-pass", Passed: true},
+		{TestID: "trace-deviation", Deviations: []runner.Deviation{{Field: "response.status", Expected: 200, Actual: 500}}},
+	}
+	run := func(t *testing.T) string {
+		oldStdout := os.Stdout
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		os.Stdout = w
+
+		for _, res := range results {
+			outputStreamedResult(res, runner.Test{TraceID: res.TestID})
+		}
+		_ = runner.OutputResultsSummary(results, runner.SummaryOptions{Format: outputFormat, Quiet: quiet})
+
+		_ = w.Close()
+		os.Stdout = oldStdout
+		out, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(out)
+	}
+
+	oldFormat, oldQuiet := outputFormat, quiet
+	outputFormat, quiet = "text", false
+	t.Cleanup(func() { outputFormat, quiet, summaryOnly = oldFormat, oldQuiet, false })
+
+	out := run(t)
+	assert.Contains(t, out, "NO DEVIATION - trace-pass")
+	assert.Contains(t, out, "DEVIATION - trace-deviation")
+
+	summaryOnly = true
+	out = run(t)
+	assert.NotContains(t, out, "trace-pass")
+	assert.NotContains(t, out, "trace-deviation")
+	assert.Contains(t, out, "Tests: 2 total, 1 passed, 1 deviations")
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+	"github.com/Use-Tusk/tusk-cli/internal/log"
+	"github.com/Use-Tusk/tusk-cli/internal/runner"
+)
+
+// watchTests runs tests once, then re-runs them whenever files under
+// --watch-dir change, keeping the mock server and service up in between.
+// With test_execution.route_mappings only the tests mapped from the changed
+// files re-run. Ctrl-C stops the service through the registered cleanup.
+func watchTests(executor *runner.Executor, tests []runner.Test, groupResult *runner.EnvironmentExtractionResult, routeMappings []config.RouteMapping) error {
+	group := &runner.EnvironmentGroup{Name: "default", Tests: tests}
+	if groupResult != nil && len(groupResult.Groups) > 0 {
+		if len(groupResult.Groups) > 1 {
+			return fmt.Errorf("--watch needs every test recorded in one environment, found %d; narrow the tests with --filter", len(groupResult.Groups))
+		}
+		group = groupResult.Groups[0]
+	}
+
+	dir, err := filepath.Abs(watchDir)
+	if err != nil {
+		return fmt.Errorf("--watch-dir: %w", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	RegisterCleanup(cancel)
+
+	changes, err := runner.WatchSourceChanges(ctx, dir, runner.DefaultWatchDebounce)
+	if err != nil {
+		return err
+	}
+
+	selectTests := func(files []string) []runner.Test {
+		if len(routeMappings) == 0 {
+			return group.Tests
+		}
+		changed, err := runner.NewChangedRoutes(files, routeMappings)
+		if err != nil {
+			log.Warn("Failed to map changed files to routes, re-running all tests", "error", err)
+			return group.Tests
+		}
+		return changed.Filter(group.Tests)
+	}
+	onRun := func(results []runner.TestResult) {
+		// Failing tests are reported in the summary; keep watching either way
+		_ = runner.OutputResultsSummary(results, runner.SummaryOptions{Format: outputFormat, Quiet: quiet, FailOnSeverity: failOnSeverity})
+	}
+
+	if !quiet {
+		log.Stderrln(fmt.Sprintf("➤ Running %d tests, then watching %s for changes...\n", len(group.Tests), dir))
+	}
+	return runner.WatchAndReplay(ctx, executor, group, changes, selectTests, onRun)
+}
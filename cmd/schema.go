@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/Use-Tusk/tusk-cli/internal/runner"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print JSON schemas of files written by Tusk CLI",
+}
+
+var schemaResultsCmd = &cobra.Command{
+	Use:   "results",
+	Short: "Print the JSON schema of the results file written by --save-results",
+	Long: `Print the JSON schema (draft 2020-12) of the results file written by
+"tusk drift run --save-results". The schema is generated from the types the
+CLI writes the file from, so it matches this CLI version. Use it to validate
+saved results in downstream tooling.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printJSON(runner.ResultsFileSchema())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	schemaCmd.AddCommand(schemaResultsCmd)
+}
@@ -1,19 +1,27 @@
 package cmd
 
 import (
+	"context"
+
 	"github.com/spf13/cobra"
 
 	"github.com/Use-Tusk/tusk-cli/internal/version"
 )
 
+var checkLatestVersion bool
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Show the version of Tusk CLI",
 	Run: func(cmd *cobra.Command, args []string) {
 		version.PrintVersion()
+		if checkLatestVersion {
+			version.PrintUpdateCheck(context.Background(), cmd.OutOrStdout())
+		}
 	},
 }
 
 func init() {
+	versionCmd.Flags().BoolVar(&checkLatestVersion, "check", false, "Check whether a newer version of Tusk CLI has been released")
 	rootCmd.AddCommand(versionCmd)
 }
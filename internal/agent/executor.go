@@ -678,13 +678,17 @@ func toolDefinitions() map[ToolName]*ToolDefinition {
 		},
 		ToolCloudUploadTraces: {
 			Name:        ToolCloudUploadTraces,
-			Description: "Upload local traces from .tusk/traces/ to Tusk Cloud. Returns the number of traces uploaded.",
+			Description: "Upload local traces from .tusk/traces/ to Tusk Cloud in batches of spans. Returns the number of traces, spans and batches uploaded.",
 			InputSchema: json.RawMessage(`{
 				"type": "object",
 				"properties": {
 					"service_id": {
 						"type": "string",
 						"description": "The observable service ID"
+					},
+					"batch_size": {
+						"type": "integer",
+						"description": "Spans per upload request. Defaults to 500."
 					}
 				},
 				"required": ["service_id"]
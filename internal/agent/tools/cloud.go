@@ -24,7 +24,7 @@ import (
 	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
 )
 
-// SpanUploadBatchSize is the number of spans to upload in each batch.
+// SpanUploadBatchSize is the default number of spans to upload in each batch.
 // 500 spans × ~32 columns = ~16K params, well under PostgreSQL's 65K param limit.
 const SpanUploadBatchSize = 500
 
@@ -865,6 +865,7 @@ func (ct *CloudTools) WaitForAuth(input json.RawMessage) (string, error) {
 func (ct *CloudTools) UploadTraces(input json.RawMessage) (string, error) {
 	var params struct {
 		ServiceID string `json:"service_id"`
+		BatchSize int    `json:"batch_size"`
 	}
 	if err := json.Unmarshal(input, &params); err != nil {
 		return "", fmt.Errorf("invalid input: %w", err)
@@ -873,6 +874,13 @@ func (ct *CloudTools) UploadTraces(input json.RawMessage) (string, error) {
 	if params.ServiceID == "" {
 		return "", fmt.Errorf("service_id is required")
 	}
+	if params.BatchSize < 0 {
+		return "", fmt.Errorf("batch_size must be positive")
+	}
+	batchSize := params.BatchSize
+	if batchSize == 0 {
+		batchSize = SpanUploadBatchSize
+	}
 
 	// Find all trace files in .tusk/traces/
 	tracesDir := utils.GetTracesDir()
@@ -940,7 +948,7 @@ func (ct *CloudTools) UploadTraces(input json.RawMessage) (string, error) {
 	}
 
 	// Split spans into batches
-	spanBatches := utils.BatchSlice(allSpans, SpanUploadBatchSize)
+	spanBatches := utils.BatchSlice(allSpans, batchSize)
 	sdkInstanceId := fmt.Sprintf("setup-%d", time.Now().Unix())
 
 	// Upload batches concurrently with max concurrency of 5
@@ -949,6 +957,7 @@ func (ct *CloudTools) UploadTraces(input json.RawMessage) (string, error) {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var totalUploaded int
+	var batchesSucceeded, batchesDone int
 	var uploadErrors []string
 
 	for i, batch := range spanBatches {
@@ -971,6 +980,15 @@ func (ct *CloudTools) UploadTraces(input json.RawMessage) (string, error) {
 			mu.Lock()
 			defer mu.Unlock()
 
+			batchesDone++
+			defer func() {
+				log.Debug("Span upload progress",
+					"batchesDone", batchesDone,
+					"batchesTotal", len(spanBatches),
+					"spansUploaded", totalUploaded,
+					"totalSpans", len(allSpans))
+			}()
+
 			if err != nil {
 				uploadErrors = append(uploadErrors, fmt.Sprintf("batch %d/%d: %v", batchIdx+1, len(spanBatches), err))
 				return
@@ -982,6 +1000,7 @@ func (ct *CloudTools) UploadTraces(input json.RawMessage) (string, error) {
 			}
 
 			totalUploaded += len(batchSpans)
+			batchesSucceeded++
 		}(i, batch)
 	}
 
@@ -994,17 +1013,20 @@ func (ct *CloudTools) UploadTraces(input json.RawMessage) (string, error) {
 	case len(uploadErrors) == 0:
 		message = "Traces uploaded successfully"
 	case totalUploaded > 0:
-		message = fmt.Sprintf("Partial upload: %d/%d spans uploaded. Errors: %v", totalUploaded, len(allSpans), uploadErrors)
+		message = fmt.Sprintf("Partial upload: %d/%d batches (%d/%d spans) uploaded. Errors: %v",
+			batchesSucceeded, len(spanBatches), totalUploaded, len(allSpans), uploadErrors)
 	default:
 		message = fmt.Sprintf("Upload failed: %v", uploadErrors)
 	}
 
 	result := map[string]interface{}{
-		"success":         success,
-		"message":         message,
-		"traces_uploaded": len(traceFiles),
-		"spans_uploaded":  totalUploaded,
-		"total_spans":     len(allSpans),
+		"success":           success,
+		"message":           message,
+		"traces_uploaded":   len(traceFiles),
+		"spans_uploaded":    totalUploaded,
+		"total_spans":       len(allSpans),
+		"batches_succeeded": batchesSucceeded,
+		"total_batches":     len(spanBatches),
 	}
 	if len(uploadErrors) > 0 {
 		result["errors"] = uploadErrors
@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Use-Tusk/tusk-cli/internal/config"
@@ -176,7 +177,10 @@ func (tt *TuskTools) Run(input json.RawMessage) (string, error) {
 		return "No tests found", nil
 	}
 
-	var results []string
+	var (
+		results   []string
+		resultsMu sync.Mutex // environment groups may complete tests concurrently
+	)
 	executor.SetOnTestCompleted(func(res runner.TestResult, test runner.Test) {
 		status := "✓ PASS"
 		if res.Error != "" {
@@ -184,7 +188,9 @@ func (tt *TuskTools) Run(input json.RawMessage) (string, error) {
 		} else if !res.Passed {
 			status = "✗ FAIL"
 		}
+		resultsMu.Lock()
 		results = append(results, fmt.Sprintf("%s %s %s", status, test.Method, test.Path))
+		resultsMu.Unlock()
 	})
 
 	preAppStartSpans, _ := runner.FetchLocalPreAppStartSpans(true)
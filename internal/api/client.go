@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
@@ -12,9 +13,12 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Use-Tusk/tusk-cli/internal/config"
+	"github.com/Use-Tusk/tusk-cli/internal/log"
 	backend "github.com/Use-Tusk/tusk-drift-schemas/generated/go/backend"
 	"google.golang.org/protobuf/proto"
 )
@@ -23,8 +27,26 @@ type TuskClient struct {
 	baseURL    string
 	apiKey     string
 	httpClient *http.Client
+	// Set once the backend rejects a gzip-encoded span export, so later
+	// exports are sent uncompressed straight away.
+	gzipUnsupported atomic.Bool
+
+	// When set, bearer tokens are refreshed before they expire
+	tokenSource BearerTokenSource
+	tokenMu     sync.Mutex
+}
+
+// BearerTokenSource provides the logged-in user's bearer token and can
+// refresh it. auth.Authenticator implements it.
+type BearerTokenSource interface {
+	BearerToken() (token string, expiresAt time.Time)
+	RefreshBearerToken(ctx context.Context) error
 }
 
+// tokenRefreshMargin is how long before expiry a bearer token is refreshed, so
+// long runs don't start failing with 401s partway through.
+const tokenRefreshMargin = 2 * time.Minute
+
 type AuthOptions struct {
 	APIKey       string
 	BearerToken  string
@@ -52,6 +74,9 @@ type RetryConfig struct {
 	MaxBackoff  time.Duration
 	JitterMin   float64
 	JitterMax   float64
+	// RetryTransient also retries any 5xx response and network errors,
+	// not just 502/503/504.
+	RetryTransient bool
 }
 
 // DefaultRetryConfig returns normal retry configuration
@@ -65,6 +90,23 @@ func DefaultRetryConfig(maxRetries int) RetryConfig {
 	}
 }
 
+// DefaultUploadRetries is the retry count for test result uploads when
+// tusk_api.upload_retries is not configured.
+const DefaultUploadRetries = 3
+
+// UploadRetryConfig returns retry configuration for per-test result uploads:
+// short backoff (250ms, 500ms, 1s) on 5xx and network errors.
+func UploadRetryConfig(maxRetries int) RetryConfig {
+	return RetryConfig{
+		MaxRetries:     maxRetries,
+		BaseBackoff:    250 * time.Millisecond,
+		MaxBackoff:     1 * time.Second,
+		JitterMin:      1.0,
+		JitterMax:      1.0,
+		RetryTransient: true,
+	}
+}
+
 // FastRetryConfig returns retry configuration for testing
 func FastRetryConfig(maxRetries int) RetryConfig {
 	return RetryConfig{
@@ -102,6 +144,14 @@ func GetBaseURL() string {
 	return DefaultBaseURL
 }
 
+// uploadRetries returns tusk_api.upload_retries, or DefaultUploadRetries if unset.
+func uploadRetries() int {
+	if cfg, err := config.Get(); err == nil && cfg.TuskAPI.UploadRetries != nil {
+		return *cfg.TuskAPI.UploadRetries
+	}
+	return DefaultUploadRetries
+}
+
 func NewClient(baseURL, apiKey string) *TuskClient {
 	// https://app.usetusk.ai/api/vi/drift/test_run_service -> https://app.usetusk.ai
 	u, _ := url.Parse(baseURL)
@@ -116,13 +166,50 @@ func NewClient(baseURL, apiKey string) *TuskClient {
 	}
 }
 
-func buildAuthenticatedRequest(
+// SetTokenSource makes the client refresh the bearer token from src before it
+// expires. Requests then use src's token instead of AuthOptions.BearerToken.
+func (c *TuskClient) SetTokenSource(src BearerTokenSource) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.tokenSource = src
+}
+
+// ensureFreshToken swaps in the token source's bearer token, refreshing it
+// first when it expires within tokenRefreshMargin. A failed refresh keeps the
+// current token; the request may still succeed, or fail with a 401 as before.
+func (c *TuskClient) ensureFreshToken(ctx context.Context, auth AuthOptions) AuthOptions {
+	if auth.BearerToken == "" {
+		return auth
+	}
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if c.tokenSource == nil {
+		return auth
+	}
+
+	token, expiresAt := c.tokenSource.BearerToken()
+	if time.Until(expiresAt) < tokenRefreshMargin {
+		if err := c.tokenSource.RefreshBearerToken(ctx); err != nil {
+			log.Warn("Failed to refresh auth token", "error", err)
+		} else {
+			token, _ = c.tokenSource.BearerToken()
+		}
+	}
+	if token != "" {
+		auth.BearerToken = token
+	}
+	return auth
+}
+
+func (c *TuskClient) buildAuthenticatedRequest(
 	ctx context.Context,
 	method string,
 	fullURL string,
 	body io.Reader,
 	auth AuthOptions,
 ) (*http.Request, error) {
+	auth = c.ensureFreshToken(ctx, auth)
+
 	httpReq, err := http.NewRequestWithContext(ctx, method, fullURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("build request: %w", err)
@@ -158,9 +245,17 @@ func (c *TuskClient) executeRequest(httpReq *http.Request) ([]byte, *http.Respon
 	return body, httpResp, nil
 }
 
+// errCompressRequest wraps failures to gzip a request body.
+var errCompressRequest = errors.New("compress request")
+
 // Helper method to make protobuf requests
 // If overrideBaseURL is provided, it's used instead of c.baseURL
 func (c *TuskClient) makeProtoRequest(ctx context.Context, serviceAPIPath string, endpoint string, req proto.Message, resp proto.Message, auth AuthOptions) error {
+	return c.makeEncodedProtoRequest(ctx, serviceAPIPath, endpoint, req, resp, auth, false)
+}
+
+// makeEncodedProtoRequest is makeProtoRequest with an optionally gzipped body.
+func (c *TuskClient) makeEncodedProtoRequest(ctx context.Context, serviceAPIPath string, endpoint string, req proto.Message, resp proto.Message, auth AuthOptions, gzipBody bool) error {
 	fullURL := fmt.Sprintf("%s/%s", serviceAPIPath, endpoint)
 
 	bin, err := proto.Marshal(req)
@@ -168,12 +263,28 @@ func (c *TuskClient) makeProtoRequest(ctx context.Context, serviceAPIPath string
 		return fmt.Errorf("marshal proto: %w", err)
 	}
 
-	httpReq, err := buildAuthenticatedRequest(ctx, http.MethodPost, fullURL, bytes.NewReader(bin), auth)
+	if gzipBody {
+		compressed, err := gzipBytes(bin)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errCompressRequest, err)
+		}
+		log.Debug("Compressed request body",
+			"endpoint", endpoint,
+			"bytes", len(bin),
+			"compressedBytes", len(compressed),
+			"ratio", fmt.Sprintf("%.2f", float64(len(compressed))/float64(max(len(bin), 1))))
+		bin = compressed
+	}
+
+	httpReq, err := c.buildAuthenticatedRequest(ctx, http.MethodPost, fullURL, bytes.NewReader(bin), auth)
 	if err != nil {
 		return err
 	}
 	httpReq.Header.Set("Content-Type", "application/protobuf")
 	httpReq.Header.Set("Accept", "application/protobuf")
+	if gzipBody {
+		httpReq.Header.Set("Content-Encoding", "gzip")
+	}
 
 	body, httpResp, err := c.executeRequest(httpReq)
 	if err != nil {
@@ -192,7 +303,27 @@ func (c *TuskClient) makeProtoRequest(ctx context.Context, serviceAPIPath string
 	return nil
 }
 
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func (c *TuskClient) makeProtoRequestWithRetryConfig(ctx context.Context, serviceAPIPath string, endpoint string, req proto.Message, resp proto.Message, auth AuthOptions, config RetryConfig) error {
+	return withRetries(ctx, config, func() error {
+		return c.makeProtoRequest(ctx, serviceAPIPath, endpoint, req, resp, auth)
+	})
+}
+
+// withRetries calls do until it succeeds, fails with a non-retryable error, or
+// config.MaxRetries retries have been made, backing off between attempts.
+func withRetries(ctx context.Context, config RetryConfig, do func() error) error {
 	var lastErr error
 	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
 		if attempt > 0 {
@@ -208,13 +339,12 @@ func (c *TuskClient) makeProtoRequestWithRetryConfig(ctx context.Context, servic
 			}
 		}
 
-		err := c.makeProtoRequest(ctx, serviceAPIPath, endpoint, req, resp, auth)
+		err := do()
 		if err == nil {
 			return nil
 		}
 
-		var apiErr *ApiError
-		if errors.As(err, &apiErr) && (apiErr.StatusCode == 502 || apiErr.StatusCode == 503 || apiErr.StatusCode == 504) {
+		if isRetryableError(ctx, err, config) {
 			lastErr = err
 			continue
 		}
@@ -222,7 +352,24 @@ func (c *TuskClient) makeProtoRequestWithRetryConfig(ctx context.Context, servic
 		// Non-retryable error
 		return err
 	}
-	return fmt.Errorf("max retries exceeded: %w", lastErr)
+	return fmt.Errorf("max retries exceeded after %d attempts: %w", config.MaxRetries+1, lastErr)
+}
+
+func isRetryableError(ctx context.Context, err error, config RetryConfig) bool {
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode == 502 || apiErr.StatusCode == 503 || apiErr.StatusCode == 504 {
+			return true
+		}
+		return config.RetryTransient && apiErr.StatusCode >= 500
+	}
+
+	if !config.RetryTransient || ctx.Err() != nil {
+		return false
+	}
+	// http.Client.Do wraps transport failures (refused, reset, timeouts) in *url.Error
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
 }
 
 func (c *TuskClient) makeTestRunServiceRequest(ctx context.Context, endpoint string, req proto.Message, resp proto.Message, auth AuthOptions, config RetryConfig) error {
@@ -368,7 +515,7 @@ func (c *TuskClient) GetTraceTest(ctx context.Context, in *backend.GetTraceTestR
 
 func (c *TuskClient) UploadTraceTestResults(ctx context.Context, in *backend.UploadTraceTestResultsRequest, auth AuthOptions) error {
 	var out backend.UploadTraceTestResultsResponse
-	if err := c.makeTestRunServiceRequest(ctx, "upload_trace_test_results", in, &out, auth, DefaultRetryConfig(3)); err != nil {
+	if err := c.makeTestRunServiceRequest(ctx, "upload_trace_test_results", in, &out, auth, UploadRetryConfig(uploadRetries())); err != nil {
 		return err
 	}
 
@@ -562,12 +709,39 @@ func (c *TuskClient) GetGlobalSpansByIds(ctx context.Context, in *backend.GetGlo
 	return nil, fmt.Errorf("invalid response")
 }
 
-// ExportSpans uploads spans to Tusk Cloud
+// ExportSpans sends spans gzip-compressed. If the backend rejects the
+// compressed payload, it is resent uncompressed, as are all later exports.
 func (c *TuskClient) ExportSpans(ctx context.Context, in *backend.ExportSpansRequest, auth AuthOptions) (*backend.ExportSpansResponse, error) {
 	var out backend.ExportSpansResponse
 	fullServiceAPIPath := c.baseURL + SpanExportServiceAPIPath
+
+	if !c.gzipUnsupported.Load() {
+		err := withRetries(ctx, DefaultRetryConfig(3), func() error {
+			return c.makeEncodedProtoRequest(ctx, fullServiceAPIPath, "ExportSpans", in, &out, auth, true)
+		})
+		if err == nil {
+			return &out, nil
+		}
+		if !isCompressionRejected(err) {
+			return nil, err
+		}
+		log.Debug("Compressed span export failed, retrying uncompressed", "error", err)
+		c.gzipUnsupported.Store(true)
+	}
+
 	if err := c.makeProtoRequestWithRetryConfig(ctx, fullServiceAPIPath, "ExportSpans", in, &out, auth, DefaultRetryConfig(3)); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
+
+// isCompressionRejected reports whether err means the request body could not
+// be compressed or the server did not accept a compressed body. Other client
+// errors, such as a 400 for an invalid request, leave compression on.
+func isCompressionRejected(err error) bool {
+	if errors.Is(err, errCompressRequest) {
+		return true
+	}
+	var apiErr *ApiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnsupportedMediaType
+}
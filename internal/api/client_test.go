@@ -1,7 +1,10 @@
 package api
 
 import (
+	"compress/gzip"
 	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -243,3 +246,239 @@ func TestMakeProtoRequestWithRetry_BackoffCap(t *testing.T) {
 	assert.Less(t, duration, 300*time.Millisecond, "Should complete quickly with fast config")
 	assert.Greater(t, duration, 50*time.Millisecond, "Should have some backoff delays")
 }
+
+func TestMakeProtoRequestWithRetry_TransientRetriesAny5xx(t *testing.T) {
+	attemptCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attemptCount++
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("Internal Server Error"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	req := &backend.CreateDriftRunRequest{}
+	resp := &backend.CreateDriftRunResponse{}
+	auth := AuthOptions{APIKey: "test-key"}
+
+	// Without RetryTransient, 500 is not retried
+	err := client.makeProtoRequestWithRetryConfig(context.Background(), server.URL, "test_endpoint", req, resp, auth, FastRetryConfig(3))
+	assert.Error(t, err)
+	assert.Equal(t, 1, attemptCount)
+
+	attemptCount = 0
+	config := FastRetryConfig(3)
+	config.RetryTransient = true
+	err = client.makeProtoRequestWithRetryConfig(context.Background(), server.URL, "test_endpoint", req, resp, auth, config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "after 4 attempts")
+	assert.Contains(t, err.Error(), "http 500")
+	assert.Equal(t, 4, attemptCount)
+}
+
+func TestMakeProtoRequestWithRetry_TransientRetriesNetworkErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serverURL := server.URL
+	server.Close() // connections will be refused
+
+	client := NewClient(serverURL, "test-key")
+	req := &backend.CreateDriftRunRequest{}
+	resp := &backend.CreateDriftRunResponse{}
+	auth := AuthOptions{APIKey: "test-key"}
+
+	err := client.makeProtoRequestWithRetryConfig(context.Background(), serverURL, "test_endpoint", req, resp, auth, FastRetryConfig(2))
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "max retries exceeded", "network errors are not retried by default")
+
+	config := FastRetryConfig(2)
+	config.RetryTransient = true
+	err = client.makeProtoRequestWithRetryConfig(context.Background(), serverURL, "test_endpoint", req, resp, auth, config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "max retries exceeded after 3 attempts")
+}
+
+func TestMakeProtoRequestWithRetry_TransientNoRetryOn4xx(t *testing.T) {
+	attemptCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attemptCount++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	config := FastRetryConfig(3)
+	config.RetryTransient = true
+
+	err := client.makeProtoRequestWithRetryConfig(context.Background(), server.URL, "test_endpoint", &backend.CreateDriftRunRequest{}, &backend.CreateDriftRunResponse{}, AuthOptions{APIKey: "test-key"}, config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "http 401")
+	assert.Equal(t, 1, attemptCount)
+}
+
+func TestIsUnreachableError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serverURL := server.URL
+	server.Close() // connections will be refused
+
+	client := NewClient(serverURL, "test-key")
+	_, err := client.CreateDriftRun(context.Background(), &backend.CreateDriftRunRequest{}, AuthOptions{APIKey: "test-key"})
+	assert.Error(t, err)
+	assert.True(t, IsUnreachableError(err))
+
+	assert.True(t, IsUnreachableError(fmt.Errorf("max retries exceeded: %w", &ApiError{StatusCode: 503})))
+	assert.False(t, IsUnreachableError(&ApiError{StatusCode: 400}))
+	assert.False(t, IsUnreachableError(&SkippableError{Code: "NO_SEAT"}))
+}
+
+func TestUploadRetryConfig_Backoff(t *testing.T) {
+	config := UploadRetryConfig(DefaultUploadRetries)
+	assert.Equal(t, 3, config.MaxRetries)
+	assert.True(t, config.RetryTransient)
+	assert.Equal(t, 250*time.Millisecond, config.BaseBackoff)
+	assert.Equal(t, time.Second, config.MaxBackoff)
+	assert.Equal(t, config.JitterMin, config.JitterMax, "upload backoff should be deterministic")
+}
+
+func TestExportSpans_SendsGzippedBody(t *testing.T) {
+	var gotEncoding string
+	var gotReq backend.ExportSpansRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEncoding = r.Header.Get("Content-Encoding")
+		zr, err := gzip.NewReader(r.Body)
+		if !assert.NoError(t, err) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(zr)
+		assert.NoError(t, proto.Unmarshal(body, &gotReq))
+
+		w.Header().Set("Content-Type", "application/protobuf")
+		bin, _ := proto.Marshal(&backend.ExportSpansResponse{Success: true})
+		_, _ = w.Write(bin)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	req := &backend.ExportSpansRequest{ObservableServiceId: "svc"}
+
+	resp, err := client.ExportSpans(context.Background(), req, AuthOptions{APIKey: "test-key"})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "gzip", gotEncoding)
+	assert.Equal(t, "svc", gotReq.ObservableServiceId)
+}
+
+func TestExportSpans_FallsBackToUncompressed(t *testing.T) {
+	var encodings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		w.Header().Set("Content-Type", "application/protobuf")
+		bin, _ := proto.Marshal(&backend.ExportSpansResponse{Success: true})
+		_, _ = w.Write(bin)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	auth := AuthOptions{APIKey: "test-key"}
+
+	_, err := client.ExportSpans(context.Background(), &backend.ExportSpansRequest{}, auth)
+	assert.NoError(t, err)
+	_, err = client.ExportSpans(context.Background(), &backend.ExportSpansRequest{}, auth)
+	assert.NoError(t, err)
+
+	// Once rejected, gzip is not attempted again
+	assert.Equal(t, []string{"gzip", "", ""}, encodings)
+}
+
+func TestExportSpans_BadRequestKeepsCompression(t *testing.T) {
+	var encodings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		if len(encodings) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/protobuf")
+		bin, _ := proto.Marshal(&backend.ExportSpansResponse{Success: true})
+		_, _ = w.Write(bin)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	auth := AuthOptions{APIKey: "test-key"}
+
+	_, err := client.ExportSpans(context.Background(), &backend.ExportSpansRequest{}, auth)
+	assert.Error(t, err, "an invalid request is not resent uncompressed")
+	_, err = client.ExportSpans(context.Background(), &backend.ExportSpansRequest{}, auth)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"gzip", "gzip"}, encodings)
+}
+
+type fakeTokenSource struct {
+	token     string
+	expiresAt time.Time
+	refreshes int
+}
+
+func (f *fakeTokenSource) BearerToken() (string, time.Time) {
+	return f.token, f.expiresAt
+}
+
+func (f *fakeTokenSource) RefreshBearerToken(ctx context.Context) error {
+	f.refreshes++
+	f.token = "refreshed-token"
+	f.expiresAt = time.Now().Add(time.Hour)
+	return nil
+}
+
+func TestEnsureFreshToken_RefreshesNearExpiry(t *testing.T) {
+	var gotAuth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/protobuf")
+		bin, _ := proto.Marshal(&backend.CreateDriftRunResponse{})
+		_, _ = w.Write(bin)
+	}))
+	defer server.Close()
+
+	src := &fakeTokenSource{token: "old-token", expiresAt: time.Now().Add(30 * time.Second)}
+	client := NewClient(server.URL, "")
+	client.SetTokenSource(src)
+	auth := AuthOptions{BearerToken: "old-token"}
+
+	for range 2 {
+		err := client.makeProtoRequest(context.Background(), server.URL, "test_endpoint", &backend.CreateDriftRunRequest{}, &backend.CreateDriftRunResponse{}, auth)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, src.refreshes, "Should refresh once, then reuse the fresh token")
+	assert.Equal(t, []string{"Bearer refreshed-token", "Bearer refreshed-token"}, gotAuth)
+}
+
+func TestEnsureFreshToken_KeepsValidToken(t *testing.T) {
+	src := &fakeTokenSource{token: "valid-token", expiresAt: time.Now().Add(time.Hour)}
+	client := NewClient("https://example.com", "")
+	client.SetTokenSource(src)
+
+	auth := client.ensureFreshToken(context.Background(), AuthOptions{BearerToken: "valid-token"})
+
+	assert.Equal(t, 0, src.refreshes)
+	assert.Equal(t, "valid-token", auth.BearerToken)
+}
+
+func TestEnsureFreshToken_IgnoresAPIKeyAuth(t *testing.T) {
+	src := &fakeTokenSource{token: "old-token", expiresAt: time.Now()}
+	client := NewClient("https://example.com", "key")
+	client.SetTokenSource(src)
+
+	auth := client.ensureFreshToken(context.Background(), AuthOptions{APIKey: "key"})
+
+	assert.Equal(t, 0, src.refreshes)
+	assert.Empty(t, auth.BearerToken)
+}
@@ -58,6 +58,9 @@ func SetupCloud(ctx context.Context, requireServiceID bool) (*TuskClient, AuthOp
 	tuskClientID := cliconfig.CLIConfig.GetClientID()
 
 	client := NewClient(cfg.TuskAPI.URL, apiKey)
+	if bearer != "" {
+		client.SetTokenSource(authenticator)
+	}
 	authOptions := AuthOptions{
 		APIKey:       apiKey,
 		BearerToken:  bearer,
@@ -80,7 +80,7 @@ func (c *TuskClient) makeProtoJSONRequestWithBody(ctx context.Context, method st
 		return fmt.Errorf("encode proto json: %w", err)
 	}
 
-	httpReq, err := buildAuthenticatedRequest(ctx, method, c.baseURL+path, bytes.NewReader(body), auth)
+	httpReq, err := c.buildAuthenticatedRequest(ctx, method, c.baseURL+path, bytes.NewReader(body), auth)
 	if err != nil {
 		return err
 	}
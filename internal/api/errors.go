@@ -2,7 +2,9 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 )
 
 const DocsSetupURL = "https://docs.usetusk.ai/onboarding"
@@ -22,6 +24,18 @@ func (e *ApiError) Error() string {
 	return fmt.Sprintf("http %d: %s", e.StatusCode, e.RawBody)
 }
 
+// IsUnreachableError reports whether err means the Tusk API could not be
+// reached: a transport failure (refused, reset, timed out) or a 502, 503 or
+// 504 from the gateway in front of it.
+func IsUnreachableError(err error) bool {
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == 502 || apiErr.StatusCode == 503 || apiErr.StatusCode == 504
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
 func newApiError(statusCode int, body []byte) *ApiError {
 	return &ApiError{
 		StatusCode: statusCode,
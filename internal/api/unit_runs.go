@@ -36,7 +36,7 @@ func (c *TuskClient) makeJSONRequestWithBody(ctx context.Context, method string,
 		bodyReader = bytes.NewReader(body)
 	}
 
-	httpReq, err := buildAuthenticatedRequest(ctx, method, fullURL, bodyReader, auth)
+	httpReq, err := c.buildAuthenticatedRequest(ctx, method, fullURL, bodyReader, auth)
 	if err != nil {
 		return err
 	}
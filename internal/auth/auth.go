@@ -204,6 +204,22 @@ func (a *Authenticator) TryExistingAuth(ctx context.Context) error {
 	return errors.New("no valid auth available")
 }
 
+// BearerToken returns the current access token and when it expires.
+func (a *Authenticator) BearerToken() (string, time.Time) {
+	return a.AccessToken, a.ExpiresAt
+}
+
+// RefreshBearerToken refreshes the access token and saves it to the auth file.
+func (a *Authenticator) RefreshBearerToken(ctx context.Context) error {
+	if err := a.refreshAccessToken(ctx); err != nil {
+		return fmt.Errorf("token refresh failed: %w", err)
+	}
+	if err := a.SaveTokenFile(); err != nil {
+		return fmt.Errorf("failed to save refreshed token: %w", err)
+	}
+	return nil
+}
+
 func (a *Authenticator) Logout() error {
 	a.AccessToken = ""
 	a.RefreshToken = ""
@@ -3,15 +3,19 @@ package config
 import (
 	"errors"
 	"fmt"
+	"math"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/Use-Tusk/tusk-cli/internal/log"
 	"github.com/Use-Tusk/tusk-cli/internal/utils"
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/knadh/koanf/parsers/yaml"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/v2"
@@ -27,6 +31,11 @@ var (
 	configFileFound bool
 )
 
+// bodyPathPattern matches deviations.ignore_body_paths entries: dot-separated
+// keys, each optionally followed by [N] or [*], with leading [N] or [*] for
+// array bodies.
+var bodyPathPattern = regexp.MustCompile(`^(\[(\*|\d+)\])*([^.\[\]]+(\[(\*|\d+)\])*)?(\.[^.\[\]]+(\[(\*|\d+)\])*)*$`)
+
 type Config struct {
 	Service       ServiceConfig       `koanf:"service"`
 	TuskAPI       TuskAPIConfig       `koanf:"tusk_api"`
@@ -34,9 +43,12 @@ type Config struct {
 	TestExecution TestExecutionConfig `koanf:"test_execution"`
 	Recording     RecordingConfig     `koanf:"recording"`
 	Replay        ReplayConfig        `koanf:"replay"`
+	MockMatching  MockMatchingConfig  `koanf:"mock_matching"`
 	Traces        TracesConfig        `koanf:"traces"`
 	Results       ResultsConfig       `koanf:"results"`
 	Coverage      CoverageConfig      `koanf:"coverage"`
+	Logging       LoggingConfig       `koanf:"logging"`
+	Deviations    DeviationsConfig    `koanf:"deviations"`
 }
 
 type ServiceConfig struct {
@@ -45,6 +57,7 @@ type ServiceConfig struct {
 	Port          int                 `koanf:"port"`
 	Start         StartConfig         `koanf:"start"`
 	Stop          StopConfig          `koanf:"stop"`
+	PreRun        PreRunConfig        `koanf:"pre_run"`
 	Readiness     ReadinessConfig     `koanf:"readiness_check"`
 	Communication CommunicationConfig `koanf:"communication"`
 }
@@ -55,15 +68,37 @@ type StartConfig struct {
 
 type StopConfig struct {
 	Command string `koanf:"command"`
+	// Timeout bounds the stop command. Default: 60s.
+	Timeout string `koanf:"timeout"`
+	// GracePeriod is how long to wait after SIGTERM before SIGKILL. Default: 3s.
+	GracePeriod string `koanf:"grace_period"`
+}
+
+// PreRunConfig holds commands run before the service starts, e.g. to migrate
+// or seed a database.
+type PreRunConfig struct {
+	// Command runs once per run, before the first environment starts.
+	Command string `koanf:"command"`
+	// GroupCommand runs before each environment group's service starts,
+	// with the group's recorded env vars.
+	GroupCommand string `koanf:"group_command"`
+	// Timeout bounds each command. Default: 5m.
+	Timeout string `koanf:"timeout"`
 }
 
 type CommunicationConfig struct {
-	Type    string `koanf:"type"`     // "auto", "unix", "tcp"
-	TCPPort int    `koanf:"tcp_port"` // Default: 9001
+	Type    string `koanf:"type"`     // "auto", "unix", "tcp", "websocket"
+	TCPPort int    `koanf:"tcp_port"` // Default: 9001. 0 = dynamic. Also used for websocket
+	// MaxMessageBytes caps a single SDK message. Default: 10MB.
+	MaxMessageBytes int `koanf:"max_message_bytes"`
 }
 
 type ReadinessConfig struct {
-	Command  string `koanf:"command"`
+	Command string `koanf:"command"`
+	// HTTPURL is polled until it answers with a 2xx or 3xx status
+	HTTPURL string `koanf:"http_url"`
+	// TCPPort is polled until it accepts a connection
+	TCPPort  int    `koanf:"tcp_port"`
 	Timeout  string `koanf:"timeout"`
 	Interval string `koanf:"interval"`
 }
@@ -72,11 +107,30 @@ type TuskAPIConfig struct {
 	URL           string `koanf:"url"`
 	Auth0Domain   string `koanf:"auth0_domain"`
 	Auth0ClientID string `koanf:"auth0_client_id"`
+	// UploadRetries is how many times a failed test result upload is retried. Default: 3.
+	UploadRetries *int `koanf:"upload_retries"`
 }
 
 type TestExecutionConfig struct {
-	Concurrency int    `koanf:"concurrency"`
-	Timeout     string `koanf:"timeout"`
+	Concurrency       int    `koanf:"concurrency"`
+	Timeout           string `koanf:"timeout"`
+	MockSearchTimeout string `koanf:"mock_search_timeout"`
+	// EnvConcurrency is how many environment groups replay at once. Default: 1.
+	EnvConcurrency int `koanf:"env_concurrency"`
+	// CrashRetries is how many times a test that crashes the service is
+	// retried after a restart before it is marked failed. Default: 0.
+	CrashRetries int `koanf:"crash_retries"`
+	// Dedupe collapses tests with identical root span inputs into one.
+	Dedupe bool `koanf:"dedupe"`
+	// RouteMappings map source files to the routes they serve, for
+	// selecting tests with --only-changed.
+	RouteMappings []RouteMapping `koanf:"route_mappings"`
+}
+
+// RouteMapping says that changes to files matching Files can affect Routes.
+type RouteMapping struct {
+	Files  string   `koanf:"files"`  // Glob relative to the repository root, e.g. "src/users/**"
+	Routes []string `koanf:"routes"` // "[METHOD] /path", e.g. "GET /users/:id"
 }
 
 type ComparisonConfig struct {
@@ -105,6 +159,65 @@ type RecordingConfig struct {
 
 type ReplayConfig struct {
 	Sandbox ReplaySandboxConfig `koanf:"sandbox"`
+	// SimilarityScanLimit caps how many candidate spans are similarity-scored per mock match.
+	SimilarityScanLimit int `koanf:"similarity_scan_limit"`
+	// AllowSDKVersionMismatch lets SDKs older than the CLI's minimum connect
+	// with a warning instead of failing the run.
+	AllowSDKVersionMismatch bool `koanf:"allow_sdk_version_mismatch"`
+	// SDKConnectRetries is how many more times to wait for the SDK to connect
+	// after the first wait times out, while the service is still running.
+	SDKConnectRetries int `koanf:"sdk_connect_retries"`
+}
+
+type MockMatchingConfig struct {
+	// IgnoreFields maps package name -> JSON paths in span inputs that are
+	// treated as matchImportance 0 when matching mocks.
+	IgnoreFields map[string][]string `koanf:"ignore_fields"`
+	// PoolIdenticalSpans hands out spans with the same input value hash
+	// round-robin instead of always oldest-first.
+	PoolIdenticalSpans bool `koanf:"pool_identical_spans"`
+	// LenientSchema lets schema-based matching fall back to spans whose input
+	// schema differs only in fields with matchImportance below 1.
+	LenientSchema bool `koanf:"lenient_schema"`
+	// GlobalFallbackHosts limits suite-wide and global fallback matches of
+	// HTTP calls to spans recorded against these hosts. Empty allows any host.
+	GlobalFallbackHosts []string `koanf:"global_fallback_hosts"`
+	// AmbiguityEpsilon flags a similarity match as ambiguous when the runner-up
+	// scored within this much of the best candidate. Default: 0.05. 0 disables.
+	AmbiguityEpsilon *float64 `koanf:"ambiguity_epsilon"`
+	// SimilarityAlgorithm picks how strings are compared when ranking spans by
+	// similarity: "levenshtein" (default, character edits) or "token" (overlap
+	// of word tokens, insensitive to reordering).
+	SimilarityAlgorithm string `koanf:"similarity_algorithm"`
+	// PrimaryKeys maps package name -> JSON path of a stable key in span
+	// inputs (e.g. an idempotency key). Spans with the same key value are
+	// matched before any hash or similarity matching.
+	PrimaryKeys map[string]string `koanf:"primary_keys"`
+	// InjectLatency maps package name -> duration (e.g. "500ms") to delay
+	// found mocks by before they are sent back, to simulate slow upstreams.
+	InjectLatency map[string]string `koanf:"inject_latency"`
+	// SignificantQueryParams are HTTP query params whose values, not just
+	// presence, must match for schema-based matching of outbound calls.
+	SignificantQueryParams []SignificantQueryParamsRule `koanf:"significant_query_params"`
+	// PathTemplates are HTTP routes such as "/users/:id". Outbound calls whose
+	// paths fall under the same template match by schema even when the
+	// parameter segments differ.
+	PathTemplates []string `koanf:"path_templates"`
+	// Pins force a recorded span to be served for matching outbound calls,
+	// ahead of every other matching priority. Meant for debugging.
+	Pins []MockPin `koanf:"pins"`
+}
+
+type SignificantQueryParamsRule struct {
+	Path   string   `koanf:"path"`   // Glob over the request path, e.g. "/api/reports/**"; empty matches any path
+	Params []string `koanf:"params"` // Query param names, e.g. "view"
+}
+
+type MockPin struct {
+	Package string `koanf:"package"` // Instrumentation package name, e.g. "http"
+	Method  string `koanf:"method"`  // HTTP method or operation; empty matches any
+	Path    string `koanf:"path"`    // Glob over the request path; empty matches any path
+	SpanID  string `koanf:"span_id"` // Recorded span to serve
 }
 
 type ReplaySandboxConfig struct {
@@ -122,6 +235,12 @@ type TracesConfig struct {
 
 type ResultsConfig struct {
 	Dir string `koanf:"dir"`
+	// Redact replaces the values of logging.redact_fields in request and
+	// response data before saved results files are written. Defaults to true.
+	Redact *bool `koanf:"redact"`
+	// IncludeMatchEvents embeds each test's mock match decisions in the
+	// results file.
+	IncludeMatchEvents bool `koanf:"include_match_events"`
 }
 
 type CoverageConfig struct {
@@ -131,6 +250,46 @@ type CoverageConfig struct {
 	StripPathPrefix string   `koanf:"strip_path_prefix"`
 }
 
+type LoggingConfig struct {
+	// RedactFields are field names (matched exactly, ignoring case) or regexes
+	// whose values are redacted from logged request payloads, in addition to
+	// token, authorization, secret and secretOrPublicKey.
+	RedactFields []string `koanf:"redact_fields"`
+}
+
+type DeviationsConfig struct {
+	// SeverityRules tag deviations whose field path matches Field. The first
+	// matching rule wins; unmatched deviations are "error".
+	SeverityRules []SeverityRule `koanf:"severity_rules"`
+	// CompareResponseHeaders reports recorded response headers that are
+	// missing or different in the replayed response.
+	CompareResponseHeaders bool `koanf:"compare_response_headers"`
+	// IgnoreResponseHeaders are header names or globs (case-insensitive)
+	// left out of the header comparison, e.g. "date" or "x-request-*".
+	IgnoreResponseHeaders []string `koanf:"ignore_response_headers"`
+	// IgnoreBodyPaths are response body paths removed from both the recorded
+	// and replayed body before comparing, e.g. "meta.traceId" or
+	// "items[*].updatedAt".
+	IgnoreBodyPaths []string `koanf:"ignore_body_paths"`
+	// FloatTolerance treats response body numbers within the tolerance as equal.
+	FloatTolerance FloatToleranceConfig `koanf:"float_tolerance"`
+	// LatencyFactor reports a latency deviation when a replay takes more than
+	// this many times the recorded root span duration. Zero disables it.
+	LatencyFactor float64 `koanf:"latency_factor"`
+}
+
+// FloatToleranceConfig holds epsilons for comparing numbers. Two numbers are
+// equal when they are within either tolerance; zero disables that tolerance.
+type FloatToleranceConfig struct {
+	Absolute float64 `koanf:"absolute"` // |a - b| <= absolute
+	Relative float64 `koanf:"relative"` // |a - b| <= relative * max(|a|, |b|)
+}
+
+type SeverityRule struct {
+	Field    string `koanf:"field"`    // Glob over the deviation field path, e.g. "response.body.*"
+	Severity string `koanf:"severity"` // "info", "warn" or "error"
+}
+
 // Load loads the config file and applies environment overrides.
 // This function is idempotent - calling it multiple times will only load once.
 func Load(configFile string) error {
@@ -262,13 +421,18 @@ func parseAndValidate() (*Config, error) {
 	if cfg.Results.Dir == "" {
 		cfg.Results.Dir = ".tusk/results"
 	}
+	if cfg.Results.Redact == nil {
+		defaultRedact := true
+		cfg.Results.Redact = &defaultRedact
+	}
 	if cfg.Traces.Dir == "" {
 		cfg.Traces.Dir = ".tusk/traces"
 	}
 	if cfg.Service.Communication.Type == "" {
 		cfg.Service.Communication.Type = "auto"
 	}
-	if cfg.Service.Communication.TCPPort == 0 {
+	// An explicit tcp_port: 0 requests a dynamically allocated port
+	if cfg.Service.Communication.TCPPort == 0 && !k.Exists("service.communication.tcp_port") {
 		cfg.Service.Communication.TCPPort = 9001
 	}
 	if cfg.TuskAPI.URL == "" {
@@ -281,6 +445,10 @@ func parseAndValidate() (*Config, error) {
 		cfg.TuskAPI.Auth0ClientID = "gXktT8e38sBmmXGWCGeXMLpwlpeECJS5"
 	}
 
+	if err := cfg.interpolateServiceCommands(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
 	// Resolve directory paths relative to tusk root
 	cfg.Results.Dir = utils.ResolveTuskPath(cfg.Results.Dir)
 	cfg.Traces.Dir = utils.ResolveTuskPath(cfg.Traces.Dir)
@@ -305,6 +473,178 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if cfg.TuskAPI.UploadRetries != nil && *cfg.TuskAPI.UploadRetries < 0 {
+		errs = append(errs, fmt.Errorf("tusk_api.upload_retries: must be non-negative, got %d", *cfg.TuskAPI.UploadRetries))
+	}
+
+	if cfg.TestExecution.EnvConcurrency < 0 {
+		errs = append(errs, fmt.Errorf("test_execution.env_concurrency: must be non-negative, got %d", cfg.TestExecution.EnvConcurrency))
+	}
+	if cfg.TestExecution.CrashRetries < 0 {
+		errs = append(errs, fmt.Errorf("test_execution.crash_retries: must be non-negative, got %d", cfg.TestExecution.CrashRetries))
+	}
+
+	for pkg, paths := range cfg.MockMatching.IgnoreFields {
+		for _, path := range paths {
+			if err := utils.ValidateSchemaPath(path); err != nil {
+				errs = append(errs, fmt.Errorf("mock_matching.ignore_fields.%s: %w", pkg, err))
+			}
+		}
+	}
+
+	for pkg, path := range cfg.MockMatching.PrimaryKeys {
+		if err := utils.ValidateSchemaPath(path); err != nil {
+			errs = append(errs, fmt.Errorf("mock_matching.primary_keys.%s: %w", pkg, err))
+		} else if strings.Contains(path, "[") {
+			errs = append(errs, fmt.Errorf("mock_matching.primary_keys.%s: path %q must name a single field, not array items", pkg, path))
+		}
+	}
+
+	for pkg, latency := range cfg.MockMatching.InjectLatency {
+		if d, err := time.ParseDuration(latency); err != nil {
+			errs = append(errs, fmt.Errorf("mock_matching.inject_latency.%s: invalid duration %q", pkg, latency))
+		} else if d < 0 {
+			errs = append(errs, fmt.Errorf("mock_matching.inject_latency.%s: must be non-negative, got %s", pkg, latency))
+		}
+	}
+
+	for i, rule := range cfg.MockMatching.SignificantQueryParams {
+		if rule.Path != "" && !doublestar.ValidatePattern(rule.Path) {
+			errs = append(errs, fmt.Errorf("mock_matching.significant_query_params[%d].path: invalid glob %q", i, rule.Path))
+		}
+		if len(rule.Params) == 0 {
+			errs = append(errs, fmt.Errorf("mock_matching.significant_query_params[%d].params: must list at least one param", i))
+		}
+		for j, param := range rule.Params {
+			if strings.TrimSpace(param) == "" {
+				errs = append(errs, fmt.Errorf("mock_matching.significant_query_params[%d].params[%d]: must not be empty", i, j))
+			}
+		}
+	}
+
+	for i, template := range cfg.MockMatching.PathTemplates {
+		if !strings.HasPrefix(template, "/") {
+			errs = append(errs, fmt.Errorf("mock_matching.path_templates[%d]: must start with \"/\", got %q", i, template))
+			continue
+		}
+		for _, segment := range strings.Split(template, "/") {
+			if segment == ":" {
+				errs = append(errs, fmt.Errorf("mock_matching.path_templates[%d]: parameter without a name in %q", i, template))
+				break
+			}
+		}
+	}
+
+	for i, pin := range cfg.MockMatching.Pins {
+		if strings.TrimSpace(pin.Package) == "" {
+			errs = append(errs, fmt.Errorf("mock_matching.pins[%d].package: must not be empty", i))
+		}
+		if strings.TrimSpace(pin.SpanID) == "" {
+			errs = append(errs, fmt.Errorf("mock_matching.pins[%d].span_id: must not be empty", i))
+		}
+		if pin.Path != "" && !doublestar.ValidatePattern(pin.Path) {
+			errs = append(errs, fmt.Errorf("mock_matching.pins[%d].path: invalid glob %q", i, pin.Path))
+		}
+	}
+
+	for i, host := range cfg.MockMatching.GlobalFallbackHosts {
+		if strings.TrimSpace(host) == "" {
+			errs = append(errs, fmt.Errorf("mock_matching.global_fallback_hosts[%d]: must not be empty", i))
+		}
+	}
+
+	if eps := cfg.MockMatching.AmbiguityEpsilon; eps != nil && (*eps < 0 || *eps > 1) {
+		errs = append(errs, fmt.Errorf("mock_matching.ambiguity_epsilon: must be between 0 and 1, got %g", *eps))
+	}
+
+	switch cfg.MockMatching.SimilarityAlgorithm {
+	case "", "levenshtein", "token":
+	default:
+		errs = append(errs, fmt.Errorf("mock_matching.similarity_algorithm: must be \"levenshtein\" or \"token\", got %q", cfg.MockMatching.SimilarityAlgorithm))
+	}
+
+	for i, field := range cfg.Logging.RedactFields {
+		if strings.TrimSpace(field) == "" {
+			errs = append(errs, fmt.Errorf("logging.redact_fields[%d]: must not be empty", i))
+		} else if _, err := regexp.Compile(field); err != nil {
+			errs = append(errs, fmt.Errorf("logging.redact_fields[%d]: invalid pattern %q: %w", i, field, err))
+		}
+	}
+
+	for i, m := range cfg.TestExecution.RouteMappings {
+		if m.Files == "" || !doublestar.ValidatePattern(m.Files) {
+			errs = append(errs, fmt.Errorf("test_execution.route_mappings[%d].files: invalid glob %q", i, m.Files))
+		}
+		if len(m.Routes) == 0 {
+			errs = append(errs, fmt.Errorf("test_execution.route_mappings[%d].routes: must list at least one route", i))
+		}
+	}
+
+	for i, rule := range cfg.Deviations.SeverityRules {
+		if rule.Field == "" || !doublestar.ValidatePattern(rule.Field) {
+			errs = append(errs, fmt.Errorf("deviations.severity_rules[%d].field: invalid glob %q", i, rule.Field))
+		}
+		switch rule.Severity {
+		case "info", "warn", "error":
+		default:
+			errs = append(errs, fmt.Errorf("deviations.severity_rules[%d].severity: must be 'info', 'warn' or 'error', got %q", i, rule.Severity))
+		}
+	}
+
+	for i, header := range cfg.Deviations.IgnoreResponseHeaders {
+		if header == "" || !doublestar.ValidatePattern(header) {
+			errs = append(errs, fmt.Errorf("deviations.ignore_response_headers[%d]: invalid glob %q", i, header))
+		}
+	}
+
+	for i, p := range cfg.Deviations.IgnoreBodyPaths {
+		if p == "" || strings.HasPrefix(p, ".") || !bodyPathPattern.MatchString(p) {
+			errs = append(errs, fmt.Errorf("deviations.ignore_body_paths[%d]: invalid path %q (expected e.g. data.serverTime or items[*].updatedAt)", i, p))
+		}
+	}
+
+	if cfg.Deviations.FloatTolerance.Absolute < 0 {
+		errs = append(errs, fmt.Errorf("deviations.float_tolerance.absolute: must not be negative, got %v", cfg.Deviations.FloatTolerance.Absolute))
+	}
+	if cfg.Deviations.FloatTolerance.Relative < 0 {
+		errs = append(errs, fmt.Errorf("deviations.float_tolerance.relative: must not be negative, got %v", cfg.Deviations.FloatTolerance.Relative))
+	}
+
+	if f := cfg.Deviations.LatencyFactor; f != 0 && f < 1 {
+		errs = append(errs, fmt.Errorf("deviations.latency_factor: must be at least 1 (or 0 to disable), got %v", f))
+	}
+
+	if cfg.Replay.SimilarityScanLimit < 0 {
+		errs = append(errs, fmt.Errorf("replay.similarity_scan_limit: must be non-negative, got %d", cfg.Replay.SimilarityScanLimit))
+	}
+	if cfg.Replay.SDKConnectRetries < 0 {
+		errs = append(errs, fmt.Errorf("replay.sdk_connect_retries: must be non-negative, got %d", cfg.Replay.SDKConnectRetries))
+	}
+
+	if cfg.TestExecution.MockSearchTimeout != "" {
+		if _, err := time.ParseDuration(cfg.TestExecution.MockSearchTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("test_execution.mock_search_timeout: invalid duration %q", cfg.TestExecution.MockSearchTimeout))
+		}
+	}
+
+	if cfg.Service.Stop.Timeout != "" {
+		if _, err := time.ParseDuration(cfg.Service.Stop.Timeout); err != nil {
+			errs = append(errs, fmt.Errorf("service.stop.timeout: invalid duration %q", cfg.Service.Stop.Timeout))
+		}
+	}
+
+	if cfg.Service.Stop.GracePeriod != "" {
+		if _, err := time.ParseDuration(cfg.Service.Stop.GracePeriod); err != nil {
+			errs = append(errs, fmt.Errorf("service.stop.grace_period: invalid duration %q", cfg.Service.Stop.GracePeriod))
+		}
+	}
+
+	if cfg.Service.PreRun.Timeout != "" {
+		if _, err := time.ParseDuration(cfg.Service.PreRun.Timeout); err != nil {
+			errs = append(errs, fmt.Errorf("service.pre_run.timeout: invalid duration %q", cfg.Service.PreRun.Timeout))
+		}
+	}
+
 	if cfg.Service.Readiness.Timeout != "" {
 		if _, err := time.ParseDuration(cfg.Service.Readiness.Timeout); err != nil {
 			errs = append(errs, fmt.Errorf("service.readiness_check.timeout: invalid duration %q", cfg.Service.Readiness.Timeout))
@@ -317,13 +657,37 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
-	validCommTypes := map[string]bool{"auto": true, "unix": true, "tcp": true}
+	probes := 0
+	if cfg.Service.Readiness.Command != "" {
+		probes++
+	}
+	if cfg.Service.Readiness.HTTPURL != "" {
+		probes++
+		if u, err := url.Parse(cfg.Service.Readiness.HTTPURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("service.readiness_check.http_url: must be an http or https URL, got %q", cfg.Service.Readiness.HTTPURL))
+		}
+	}
+	if cfg.Service.Readiness.TCPPort != 0 {
+		probes++
+		if cfg.Service.Readiness.TCPPort < 0 || cfg.Service.Readiness.TCPPort > 65535 {
+			errs = append(errs, fmt.Errorf("service.readiness_check.tcp_port: must be between 1 and 65535, got %d", cfg.Service.Readiness.TCPPort))
+		}
+	}
+	if probes > 1 {
+		errs = append(errs, fmt.Errorf("service.readiness_check: set only one of command, http_url or tcp_port"))
+	}
+
+	validCommTypes := map[string]bool{"auto": true, "unix": true, "tcp": true, "websocket": true}
 	if !validCommTypes[cfg.Service.Communication.Type] {
-		errs = append(errs, fmt.Errorf("service.communication.type must be 'auto', 'unix', or 'tcp', got %s", cfg.Service.Communication.Type))
+		errs = append(errs, fmt.Errorf("service.communication.type must be 'auto', 'unix', 'tcp', or 'websocket', got %s", cfg.Service.Communication.Type))
+	}
+
+	if cfg.Service.Communication.TCPPort < 0 || cfg.Service.Communication.TCPPort > 65535 {
+		errs = append(errs, fmt.Errorf("service.communication.tcp_port must be between 0-65535, got %d", cfg.Service.Communication.TCPPort))
 	}
 
-	if cfg.Service.Communication.TCPPort < 1 || cfg.Service.Communication.TCPPort > 65535 {
-		errs = append(errs, fmt.Errorf("service.communication.tcp_port must be between 1-65535, got %d", cfg.Service.Communication.TCPPort))
+	if cfg.Service.Communication.MaxMessageBytes < 0 || cfg.Service.Communication.MaxMessageBytes > math.MaxUint32 {
+		errs = append(errs, fmt.Errorf("service.communication.max_message_bytes must be between 0-%d, got %d", uint32(math.MaxUint32), cfg.Service.Communication.MaxMessageBytes))
 	}
 
 	validSandboxModes := map[string]bool{"auto": true, "strict": true, "off": true}
@@ -406,10 +770,22 @@ func ValidateConfigFile(configPath string) *ValidationResult {
 	cfg, err := Get()
 	if err != nil {
 		result.Valid = false
-		result.Errors = append(result.Errors, err.Error())
+		// Report each failed check on its own line
+		var joined interface{ Unwrap() []error }
+		if errors.As(err, &joined) {
+			for _, e := range joined.Unwrap() {
+				result.Errors = append(result.Errors, e.Error())
+			}
+		} else {
+			result.Errors = append(result.Errors, err.Error())
+		}
 		return result
 	}
 
+	if cfg.Service.Communication.Type == "tcp" && !k.Exists("service.communication.tcp_port") {
+		result.Warnings = append(result.Warnings, "service.communication.type is 'tcp' but service.communication.tcp_port is not set; the default port 9001 will be used")
+	}
+
 	missingRequired := cfg.CheckRequiredForReplay()
 	if len(missingRequired) > 0 {
 		result.Valid = false
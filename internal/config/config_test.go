@@ -1,6 +1,7 @@
 package config
 
 import (
+	"math"
 	"os"
 	"path/filepath"
 	"testing"
@@ -120,6 +121,362 @@ func TestValidateRejectsInvalidRecordingSamplingMode(t *testing.T) {
 	assert.ErrorContains(t, err, "recording.sampling.mode must be 'fixed' or 'adaptive'")
 }
 
+func TestValidateRejectsMaxMessageBytesAboveUint32(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port: 3000,
+			Communication: CommunicationConfig{
+				Type:            "auto",
+				TCPPort:         9001,
+				MaxMessageBytes: math.MaxUint32 + 1,
+			},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "service.communication.max_message_bytes must be between")
+}
+
+func TestValidateReadinessProbes(t *testing.T) {
+	newConfig := func(readiness ReadinessConfig) *Config {
+		return &Config{
+			Service: ServiceConfig{
+				Port:          3000,
+				Readiness:     readiness,
+				Communication: CommunicationConfig{Type: "auto", TCPPort: 9001},
+			},
+		}
+	}
+
+	require.NoError(t, newConfig(ReadinessConfig{HTTPURL: "http://localhost:3000/health"}).Validate())
+	require.NoError(t, newConfig(ReadinessConfig{TCPPort: 3000}).Validate())
+
+	err := newConfig(ReadinessConfig{HTTPURL: "localhost:3000/health"}).Validate()
+	assert.ErrorContains(t, err, "service.readiness_check.http_url: must be an http or https URL")
+
+	err = newConfig(ReadinessConfig{TCPPort: 70000}).Validate()
+	assert.ErrorContains(t, err, "service.readiness_check.tcp_port: must be between 1 and 65535")
+
+	err = newConfig(ReadinessConfig{Command: "true", HTTPURL: "http://localhost:3000/health"}).Validate()
+	assert.ErrorContains(t, err, "set only one of command, http_url or tcp_port")
+}
+
+func TestValidateRejectsInvalidServiceDurations(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port:          3000,
+			Stop:          StopConfig{Timeout: "1 minute", GracePeriod: "soon"},
+			PreRun:        PreRunConfig{Timeout: "5 mins"},
+			Communication: CommunicationConfig{Type: "auto", TCPPort: 9001},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `service.stop.timeout: invalid duration "1 minute"`)
+	assert.ErrorContains(t, err, `service.stop.grace_period: invalid duration "soon"`)
+	assert.ErrorContains(t, err, `service.pre_run.timeout: invalid duration "5 mins"`)
+}
+
+func TestValidateRejectsMalformedIgnoreFieldsPath(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port: 3000,
+			Communication: CommunicationConfig{
+				Type:    "auto",
+				TCPPort: 9001,
+			},
+		},
+		MockMatching: MockMatchingConfig{
+			IgnoreFields: map[string][]string{"redis": {"$.connectionId", "args[0]"}},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `mock_matching.ignore_fields.redis: path "args[0]"`)
+	assert.NotContains(t, err.Error(), "connectionId")
+}
+
+func TestValidateRejectsMalformedPrimaryKeyPath(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port: 3000,
+			Communication: CommunicationConfig{
+				Type:    "auto",
+				TCPPort: 9001,
+			},
+		},
+		MockMatching: MockMatchingConfig{
+			PrimaryKeys: map[string]string{
+				"payments": "$.body.idempotencyKey",
+				"redis":    "args[*]",
+				"http":     "$.",
+			},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `mock_matching.primary_keys.redis: path "args[*]" must name a single field`)
+	assert.ErrorContains(t, err, "mock_matching.primary_keys.http:")
+	assert.NotContains(t, err.Error(), "primary_keys.payments")
+}
+
+func TestValidateRejectsInvalidInjectLatency(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port: 3000,
+			Communication: CommunicationConfig{
+				Type:    "auto",
+				TCPPort: 9001,
+			},
+		},
+		MockMatching: MockMatchingConfig{
+			InjectLatency: map[string]string{
+				"pg":    "500ms",
+				"redis": "slow",
+				"http":  "-1s",
+			},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `mock_matching.inject_latency.redis: invalid duration "slow"`)
+	assert.ErrorContains(t, err, "mock_matching.inject_latency.http: must be non-negative")
+	assert.NotContains(t, err.Error(), "inject_latency.pg")
+}
+
+func TestValidateRejectsInvalidSignificantQueryParams(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port: 3000,
+			Communication: CommunicationConfig{
+				Type:    "auto",
+				TCPPort: 9001,
+			},
+		},
+		MockMatching: MockMatchingConfig{
+			SignificantQueryParams: []SignificantQueryParamsRule{
+				{Path: "/reports/**", Params: []string{"view"}},
+				{Path: "/users/[id", Params: []string{"expand"}},
+				{Params: []string{"page", " "}},
+				{Path: "/search"},
+			},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `mock_matching.significant_query_params[1].path: invalid glob "/users/[id"`)
+	assert.ErrorContains(t, err, "mock_matching.significant_query_params[2].params[1]: must not be empty")
+	assert.ErrorContains(t, err, "mock_matching.significant_query_params[3].params: must list at least one param")
+	assert.NotContains(t, err.Error(), "significant_query_params[0]")
+}
+
+func TestValidateRejectsInvalidPathTemplates(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port: 3000,
+			Communication: CommunicationConfig{
+				Type:    "auto",
+				TCPPort: 9001,
+			},
+		},
+		MockMatching: MockMatchingConfig{
+			PathTemplates: []string{"/users/:id", "users/:id", "/orgs/:/repos"},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `mock_matching.path_templates[1]: must start with "/", got "users/:id"`)
+	assert.ErrorContains(t, err, `mock_matching.path_templates[2]: parameter without a name in "/orgs/:/repos"`)
+	assert.NotContains(t, err.Error(), "path_templates[0]")
+}
+
+func TestValidateRejectsInvalidMockPins(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port: 3000,
+			Communication: CommunicationConfig{
+				Type:    "auto",
+				TCPPort: 9001,
+			},
+		},
+		MockMatching: MockMatchingConfig{
+			Pins: []MockPin{
+				{Package: "http", Method: "GET", Path: "/users/*", SpanID: "span-1"},
+				{Package: "http", Path: "/users/[id", SpanID: "span-2"},
+				{Method: "GET", SpanID: "span-3"},
+				{Package: "pg"},
+			},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `mock_matching.pins[1].path: invalid glob "/users/[id"`)
+	assert.ErrorContains(t, err, "mock_matching.pins[2].package: must not be empty")
+	assert.ErrorContains(t, err, "mock_matching.pins[3].span_id: must not be empty")
+	assert.NotContains(t, err.Error(), "pins[0]")
+}
+
+func TestValidateRejectsInvalidRedactFieldPattern(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port: 3000,
+			Communication: CommunicationConfig{
+				Type:    "auto",
+				TCPPort: 9001,
+			},
+		},
+		Logging: LoggingConfig{
+			RedactFields: []string{".*password.*", "(unclosed"},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `logging.redact_fields[1]: invalid pattern "(unclosed"`)
+	assert.NotContains(t, err.Error(), "redact_fields[0]")
+}
+
+func TestValidateRejectsInvalidSeverityRules(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port: 3000,
+			Communication: CommunicationConfig{
+				Type:    "auto",
+				TCPPort: 9001,
+			},
+		},
+		Deviations: DeviationsConfig{
+			SeverityRules: []SeverityRule{
+				{Field: "response.body.*", Severity: "warn"},
+				{Field: "response.[", Severity: "info"},
+				{Field: "response.status", Severity: "critical"},
+			},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `deviations.severity_rules[1].field: invalid glob "response.["`)
+	assert.ErrorContains(t, err, `deviations.severity_rules[2].severity: must be 'info', 'warn' or 'error', got "critical"`)
+	assert.NotContains(t, err.Error(), "severity_rules[0]")
+}
+
+func TestValidateRejectsInvalidIgnoreResponseHeaders(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port: 3000,
+			Communication: CommunicationConfig{
+				Type:    "auto",
+				TCPPort: 9001,
+			},
+		},
+		Deviations: DeviationsConfig{
+			IgnoreResponseHeaders: []string{"x-request-*", "", "etag["},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `deviations.ignore_response_headers[1]: invalid glob ""`)
+	assert.ErrorContains(t, err, `deviations.ignore_response_headers[2]: invalid glob "etag["`)
+	assert.NotContains(t, err.Error(), "ignore_response_headers[0]")
+}
+
+func TestValidateRejectsNegativeFloatTolerance(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port: 3000,
+			Communication: CommunicationConfig{
+				Type:    "auto",
+				TCPPort: 9001,
+			},
+		},
+		Deviations: DeviationsConfig{
+			FloatTolerance: FloatToleranceConfig{Absolute: -1, Relative: -0.5},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "deviations.float_tolerance.absolute: must not be negative")
+	assert.ErrorContains(t, err, "deviations.float_tolerance.relative: must not be negative")
+}
+
+func TestValidateLatencyFactor(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port: 3000,
+			Communication: CommunicationConfig{
+				Type:    "auto",
+				TCPPort: 9001,
+			},
+		},
+	}
+	for _, factor := range []float64{0, 1, 2.5} {
+		cfg.Deviations.LatencyFactor = factor
+		assert.NoError(t, cfg.Validate(), factor)
+	}
+
+	cfg.Deviations.LatencyFactor = 0.5
+	assert.ErrorContains(t, cfg.Validate(), "deviations.latency_factor: must be at least 1")
+}
+
+func TestValidateRouteMappings(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port: 3000,
+			Communication: CommunicationConfig{
+				Type:    "auto",
+				TCPPort: 9001,
+			},
+		},
+		TestExecution: TestExecutionConfig{
+			RouteMappings: []RouteMapping{
+				{Files: "src/users/**", Routes: []string{"GET /users/:id"}},
+				{Files: "src/[", Routes: []string{"/health"}},
+				{Files: "src/orders/**"},
+			},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `test_execution.route_mappings[1].files: invalid glob "src/["`)
+	assert.ErrorContains(t, err, "test_execution.route_mappings[2].routes: must list at least one route")
+	assert.NotContains(t, err.Error(), "route_mappings[0]")
+}
+
+func TestValidateRejectsInvalidIgnoreBodyPaths(t *testing.T) {
+	cfg := &Config{
+		Service: ServiceConfig{
+			Port: 3000,
+			Communication: CommunicationConfig{
+				Type:    "auto",
+				TCPPort: 9001,
+			},
+		},
+		Deviations: DeviationsConfig{
+			IgnoreBodyPaths: []string{"data.serverTime", "items[*].updatedAt", "[0].id", ".meta", "items[x]"},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `deviations.ignore_body_paths[3]: invalid path ".meta"`)
+	assert.ErrorContains(t, err, `deviations.ignore_body_paths[4]: invalid path "items[x]"`)
+	assert.NotContains(t, err.Error(), "ignore_body_paths[0]")
+	assert.NotContains(t, err.Error(), "ignore_body_paths[1]")
+	assert.NotContains(t, err.Error(), "ignore_body_paths[2]")
+}
+
 func TestValidateRejectsOutOfRangeRecordingSamplingRates(t *testing.T) {
 	baseRate := 5.0
 	minRate := -0.1
@@ -268,4 +625,39 @@ traces:
 	// Paths should be resolved relative to tusk root (tmp), not current directory (tmp/src/api)
 	assert.Equal(t, filepath.Join(tmp, ".tusk/results"), cfg.Results.Dir)
 	assert.Equal(t, filepath.Join(tmp, ".tusk/traces"), cfg.Traces.Dir)
+	require.NotNil(t, cfg.Results.Redact)
+	assert.True(t, *cfg.Results.Redact, "results.redact defaults to true")
+}
+
+func TestTCPPortZeroRequestsDynamicPort(t *testing.T) {
+	defer Invalidate()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+service:
+  communication:
+    type: tcp
+    tcp_port: 0
+test_execution:
+  env_concurrency: 3
+`), 0o600))
+
+	require.NoError(t, Load(configPath))
+	cfg, err := Get()
+	require.NoError(t, err)
+	assert.Equal(t, 0, cfg.Service.Communication.TCPPort)
+	assert.Equal(t, 3, cfg.TestExecution.EnvConcurrency)
+
+	// Omitting tcp_port keeps the 9001 default
+	Invalidate()
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+service:
+  communication:
+    type: tcp
+`), 0o600))
+	require.NoError(t, Load(configPath))
+	cfg, err = Get()
+	require.NoError(t, err)
+	assert.Equal(t, 9001, cfg.Service.Communication.TCPPort)
 }
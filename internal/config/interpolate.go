@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// envReferencePattern matches the ${NAME} and ${NAME:-default} references
+// resolved at load time. Other shell expansions are left to the shell.
+var envReferencePattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)(:-(.*))?$`)
+
+// cliProvidedEnvPrefix marks variables the CLI sets when starting the service
+// (e.g. TUSK_MOCK_PORT). Unset references to them are left for the shell.
+const cliProvidedEnvPrefix = "TUSK_"
+
+// interpolateServiceCommands resolves environment variable references in the
+// service commands from the process environment.
+func (cfg *Config) interpolateServiceCommands(lookup func(string) (string, bool)) error {
+	fields := []struct {
+		key   string
+		value *string
+	}{
+		{"service.start.command", &cfg.Service.Start.Command},
+		{"service.stop.command", &cfg.Service.Stop.Command},
+		{"service.pre_run.command", &cfg.Service.PreRun.Command},
+		{"service.pre_run.group_command", &cfg.Service.PreRun.GroupCommand},
+		{"service.readiness_check.command", &cfg.Service.Readiness.Command},
+	}
+
+	var errs []error
+	for _, f := range fields {
+		resolved, err := interpolateEnv(*f.value, lookup)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.key, err))
+			continue
+		}
+		*f.value = resolved
+	}
+	return errors.Join(errs...)
+}
+
+// interpolateEnv replaces ${NAME} with the value of NAME and ${NAME:-default}
+// with the value of NAME, or default when NAME is unset or empty. $${ is
+// written as a literal ${. It fails when a referenced variable is unset and
+// has no default.
+func interpolateEnv(s string, lookup func(string) (string, bool)) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	var missing []string
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			break
+		}
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1])
+			b.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			b.WriteString(s)
+			break
+		}
+		end += start
+
+		b.WriteString(s[:start])
+		ref := s[start : end+1]
+		s = s[end+1:]
+
+		m := envReferencePattern.FindStringSubmatch(ref[2 : len(ref)-1])
+		if m == nil {
+			b.WriteString(ref)
+			continue
+		}
+		name, hasDefault, def := m[1], m[2] != "", m[3]
+		value, ok := lookup(name)
+		switch {
+		case ok && (value != "" || !hasDefault):
+			b.WriteString(value)
+		case hasDefault:
+			b.WriteString(def)
+		case strings.HasPrefix(name, cliProvidedEnvPrefix):
+			b.WriteString(ref)
+		default:
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set (use ${%s:-default} to provide a default)", strings.Join(missing, ", "), missing[0])
+	}
+	return b.String(), nil
+}
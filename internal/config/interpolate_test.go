@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolateEnv(t *testing.T) {
+	env := map[string]string{
+		"API_KEY": "secret",
+		"EMPTY":   "",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "no references", input: "npm start", want: "npm start"},
+		{name: "present", input: "API_KEY=${API_KEY} npm start", want: "API_KEY=secret npm start"},
+		{name: "present ignores default", input: "${API_KEY:-other}", want: "secret"},
+		{name: "absent with default", input: "node ${ENTRY:-index.js}", want: "node index.js"},
+		{name: "empty uses default", input: "${EMPTY:-fallback}", want: "fallback"},
+		{name: "empty without default", input: "x${EMPTY}y", want: "xy"},
+		{name: "empty default", input: "${MISSING:-}", want: ""},
+		{name: "absent", input: "API_KEY=${MISSING} npm start", wantErr: "environment variable MISSING is not set"},
+		{name: "several absent", input: "${A} ${B}", wantErr: "environment variable A, B is not set"},
+		{name: "escaped", input: "echo $${API_KEY}", want: "echo ${API_KEY}"},
+		{name: "plain dollar left for shell", input: "echo $API_KEY", want: "echo $API_KEY"},
+		{name: "other expansion left for shell", input: "echo ${#API_KEY} ${1:-x}", want: "echo ${#API_KEY} ${1:-x}"},
+		{name: "unterminated", input: "echo ${API_KEY", want: "echo ${API_KEY"},
+		{name: "cli provided variable left for shell", input: "--port ${TUSK_MOCK_PORT}", want: "--port ${TUSK_MOCK_PORT}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interpolateEnv(tt.input, lookup)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoadInterpolatesServiceCommands(t *testing.T) {
+	defer Invalidate()
+	t.Setenv("DB_URL", "postgres://ci")
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+service:
+  start:
+    command: DATABASE_URL=${DB_URL} npm run ${DRIFT_TEST_SCRIPT:-start}
+  readiness_check:
+    command: curl -fsS http://localhost:${DRIFT_TEST_PORT:-3000}/health
+`), 0o600))
+
+	require.NoError(t, Load(configPath))
+
+	cfg, err := Get()
+	require.NoError(t, err)
+	assert.Equal(t, "DATABASE_URL=postgres://ci npm run start", cfg.Service.Start.Command)
+	assert.Equal(t, "curl -fsS http://localhost:3000/health", cfg.Service.Readiness.Command)
+}
+
+func TestLoadRejectsUnsetVariableInStartCommand(t *testing.T) {
+	defer Invalidate()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+service:
+  start:
+    command: API_KEY=${UNSET_SECRET_XYZ} npm start
+`), 0o600))
+
+	require.NoError(t, Load(configPath))
+
+	_, err := Get()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service.start.command: environment variable UNSET_SECRET_XYZ is not set")
+}
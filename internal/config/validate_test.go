@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -101,6 +102,70 @@ func TestValidateConfigFile_MissingRequired(t *testing.T) {
 	}
 }
 
+func TestValidateConfigFile_InvalidValues(t *testing.T) {
+	tmpDir := t.TempDir()
+	Invalidate()
+
+	invalidConfig := `service:
+  port: 3000
+  start:
+    command: npm start
+  communication:
+    type: grpc
+test_execution:
+  timeout: 30
+`
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	_ = os.WriteFile(configPath, []byte(invalidConfig), 0o600)
+
+	result := ValidateConfigFile(configPath)
+
+	if result.Valid {
+		t.Fatal("Expected invalid config due to bad communication type and timeout")
+	}
+	// Each failed check is reported separately
+	if len(result.Errors) != 2 {
+		t.Fatalf("Expected 2 errors, got: %v", result.Errors)
+	}
+	if !strings.Contains(result.Errors[0], "test_execution.timeout") {
+		t.Errorf("Expected timeout error, got: %s", result.Errors[0])
+	}
+	if !strings.Contains(result.Errors[1], "service.communication.type") {
+		t.Errorf("Expected communication type error, got: %s", result.Errors[1])
+	}
+}
+
+func TestValidateConfigFile_TCPWithoutPort(t *testing.T) {
+	tmpDir := t.TempDir()
+	Invalidate()
+
+	tcpConfig := `service:
+  port: 3000
+  start:
+    command: npm start
+  communication:
+    type: tcp
+`
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	_ = os.WriteFile(configPath, []byte(tcpConfig), 0o600)
+
+	result := ValidateConfigFile(configPath)
+
+	if !result.Valid {
+		t.Fatalf("Expected valid config, got errors: %v", result.Errors)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "tcp_port") {
+		t.Errorf("Expected a tcp_port warning, got: %v", result.Warnings)
+	}
+
+	Invalidate()
+	_ = os.WriteFile(configPath, []byte(tcpConfig+"    tcp_port: 9100\n"), 0o600)
+	result = ValidateConfigFile(configPath)
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected no warnings with tcp_port set, got: %v", result.Warnings)
+	}
+}
+
 func TestValidateConfigFile_NotFound(t *testing.T) {
 	Invalidate()
 	result := ValidateConfigFile("/nonexistent/path/config.yaml")
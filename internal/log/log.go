@@ -20,6 +20,12 @@ const (
 	ModeHeadless
 )
 
+// Log formats for developer logging (slog) output
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
 // TUILogger interface for logging to TUI panels
 type TUILogger interface {
 	LogToCurrentTest(testID, message string)
@@ -43,10 +49,13 @@ const (
 type Logger struct {
 	mode      atomic.Int32
 	tuiLogger atomic.Pointer[TUILogger]
+	userOut   atomic.Pointer[io.Writer] // headless user output; nil means stdout
 	logChan   chan logMessage
 	stopChan  chan struct{}
 	wg        sync.WaitGroup
 	level     slog.Level
+	// jsonEvents is set with --log-format json, enabling Event records
+	jsonEvents atomic.Bool
 }
 
 var (
@@ -112,8 +121,9 @@ func (l *Logger) handleLogMessage(msg logMessage) {
 	}
 }
 
-// Setup configures the singleton logger (call once at startup)
-func Setup(debug bool, mode OutputMode) {
+// Setup configures the singleton logger (call once at startup). format is
+// FormatText or FormatJSON; JSON also enables Event records.
+func Setup(debug bool, mode OutputMode, format string) {
 	l := Get()
 	l.mode.Store(int32(mode)) //nolint:gosec // OutputMode is a small enum (0-1)
 
@@ -123,11 +133,15 @@ func Setup(debug bool, mode OutputMode) {
 		l.level = slog.LevelInfo
 	}
 
+	opts := &slog.HandlerOptions{Level: l.level}
+	l.jsonEvents.Store(format == FormatJSON)
+	if format == FormatJSON {
+		slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, opts)))
+		return
+	}
+
 	// Configure slog with our custom handler
-	handler := NewHandler(os.Stderr, &slog.HandlerOptions{
-		Level: l.level,
-	})
-	slog.SetDefault(slog.New(handler))
+	slog.SetDefault(slog.New(NewHandler(os.Stderr, opts)))
 }
 
 // SetTUILogger sets the TUI logger (called when TUI starts)
@@ -145,6 +159,23 @@ func SetMode(mode OutputMode) {
 	Get().mode.Store(int32(mode)) //nolint:gosec // OutputMode is a small enum (0-1)
 }
 
+// SetUserOutput redirects headless user-facing output. Use it when stdout
+// carries a machine-readable report. A nil writer restores stdout.
+func SetUserOutput(w io.Writer) {
+	if w == nil {
+		Get().userOut.Store(nil)
+		return
+	}
+	Get().userOut.Store(&w)
+}
+
+func userOutput() io.Writer {
+	if w := Get().userOut.Load(); w != nil {
+		return *w
+	}
+	return os.Stdout
+}
+
 // GetMode returns the current output mode
 func GetMode() OutputMode {
 	return OutputMode(Get().mode.Load())
@@ -179,6 +210,15 @@ func Error(msg string, args ...any) {
 	slog.Error(msg, args...)
 }
 
+// Event logs a structured event (e.g. "test_completed") for log
+// aggregators. It is a no-op unless the log format is JSON.
+func Event(name string, args ...any) {
+	if !Get().jsonEvents.Load() {
+		return
+	}
+	slog.Info(name, args...)
+}
+
 // --- User-Facing Output (styled, mode-aware) ---
 
 // UserError prints a styled error message to the user
@@ -214,14 +254,14 @@ func UserDeviation(msg string) {
 // Print prints a message without styling or newline
 func Print(msg string) {
 	if GetMode() == ModeHeadless {
-		_, _ = io.WriteString(os.Stdout, msg)
+		_, _ = io.WriteString(userOutput(), msg)
 	}
 }
 
 // Println prints a message with newline but no styling
 func Println(msg string) {
 	if GetMode() == ModeHeadless {
-		_, _ = io.WriteString(os.Stdout, msg+"\n")
+		_, _ = io.WriteString(userOutput(), msg+"\n")
 	}
 }
 
@@ -240,10 +280,10 @@ func Stderrln(msg string) {
 }
 
 func printStyled(msg string) {
-	// User output always goes to stdout for headless mode
+	// User output goes to stdout (or the SetUserOutput writer) in headless mode
 	// TUI mode handles its own display
 	if GetMode() == ModeHeadless {
-		_, _ = io.WriteString(os.Stdout, msg+"\n")
+		_, _ = io.WriteString(userOutput(), msg+"\n")
 	}
 }
 
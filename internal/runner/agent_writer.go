@@ -271,7 +271,11 @@ func buildDeviationBody(test Test, result TestResult, server *Server) string {
 			for _, ev := range matchEvents {
 				opName := matchEventOperationName(ev)
 				quality, scope := matchLevelToStrings(ev.MatchLevel)
-				fmt.Fprintf(&sb, "| %d | %s | %s | %s | |\n", idx, opName, quality, scope)
+				notes := ""
+				if ev.Ambiguous {
+					notes = "⚠ ambiguous match"
+				}
+				fmt.Fprintf(&sb, "| %d | %s | %s | %s | %s |\n", idx, opName, quality, scope, notes)
 				idx++
 			}
 			for _, ev := range mockNotFoundEvents {
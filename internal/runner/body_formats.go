@@ -0,0 +1,144 @@
+package runner
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// bodyNormalizer turns a text response body into a value whose comparison
+// ignores differences that don't change its meaning.
+type bodyNormalizer func(body string) (any, error)
+
+// bodyNormalizers are keyed by media type. Media types not listed here, and
+// bodies that fail to parse, are compared as recorded.
+var bodyNormalizers = map[string]bodyNormalizer{
+	"application/xml":                   normalizeXMLBody,
+	"text/xml":                          normalizeXMLBody,
+	"application/x-www-form-urlencoded": normalizeFormBody,
+}
+
+// normalizerForHeaders returns the normalizer for the recorded Content-Type.
+func normalizerForHeaders(headers map[string]string) bodyNormalizer {
+	for name, value := range headers {
+		if !strings.EqualFold(name, "content-type") {
+			continue
+		}
+		mediaType, _, err := mime.ParseMediaType(value)
+		if err != nil {
+			return nil
+		}
+		if normalizer, ok := bodyNormalizers[mediaType]; ok {
+			return normalizer
+		}
+		if strings.HasSuffix(mediaType, "+xml") {
+			return normalizeXMLBody
+		}
+		return nil
+	}
+	return nil
+}
+
+// normalizeBodies applies normalizer to both bodies. They are returned
+// unchanged unless both are strings that parse.
+func normalizeBodies(normalizer bodyNormalizer, expected, actual any) (any, any) {
+	expectedStr, ok1 := expected.(string)
+	actualStr, ok2 := actual.(string)
+	if normalizer == nil || !ok1 || !ok2 {
+		return expected, actual
+	}
+	normExpected, err := normalizer(expectedStr)
+	if err != nil {
+		return expected, actual
+	}
+	normActual, err := normalizer(actualStr)
+	if err != nil {
+		return expected, actual
+	}
+	return normExpected, normActual
+}
+
+// normalizeXMLBody converts an XML document into nested maps so attribute
+// order and whitespace between elements are ignored. Each element becomes a
+// map holding its name under "#name", its attributes under "@<name>", its
+// trimmed text under "#text" and its child elements, in order, under
+// "#children".
+func normalizeXMLBody(body string) (any, error) {
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	var stack []map[string]any
+	var root map[string]any
+	for {
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			elem := map[string]any{"#name": xmlName(t.Name)}
+			for _, attr := range t.Attr {
+				elem["@"+xmlName(attr.Name)] = attr.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				children, _ := parent["#children"].([]any)
+				parent["#children"] = append(children, elem)
+			} else if root != nil {
+				return nil, errors.New("xml: multiple root elements")
+			} else {
+				root = elem
+			}
+			stack = append(stack, elem)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			text := strings.TrimSpace(string(t))
+			if text == "" || len(stack) == 0 {
+				continue
+			}
+			elem := stack[len(stack)-1]
+			existing, _ := elem["#text"].(string)
+			elem["#text"] = existing + text
+		}
+	}
+	if root == nil {
+		return nil, errors.New("xml: no root element")
+	}
+	return root, nil
+}
+
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// normalizeFormBody converts a form-encoded body into a map so field order is
+// ignored. Repeated fields become a sorted list of their values.
+func normalizeFormBody(body string) (any, error) {
+	values, err := url.ParseQuery(strings.TrimSpace(body))
+	if err != nil {
+		return nil, err
+	}
+	form := make(map[string]any, len(values))
+	for key, vals := range values {
+		if len(vals) == 1 {
+			form[key] = vals[0]
+			continue
+		}
+		sorted := slices.Sorted(slices.Values(vals))
+		list := make([]any, len(sorted))
+		for i, v := range sorted {
+			list[i] = v
+		}
+		form[key] = list
+	}
+	return form, nil
+}
@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"testing"
+
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareAndGenerateResult_XMLReorderedAttributes(t *testing.T) {
+	executor := &Executor{}
+	test := Test{
+		TraceID: "t-xml",
+		Spans:   []*core.Span{makeSpanWithOutputSchema(core.DecodedType_DECODED_TYPE_XML)},
+		Response: Response{
+			Status:  200,
+			Headers: map[string]string{"content-type": "application/xml; charset=utf-8"},
+			Body:    `<user id="1" role="admin"><name>Alice</name><tags><tag>a</tag><tag>b</tag></tags></user>`,
+		},
+	}
+
+	resp := makeResponse(200, nil, `<?xml version="1.0"?>
+<user role="admin" id="1">
+  <name>Alice</name>
+  <tags>
+    <tag>a</tag>
+    <tag>b</tag>
+  </tags>
+</user>`)
+	res, err := executor.compareAndGenerateResult(test, resp, 10)
+	require.NoError(t, err)
+	require.True(t, res.Passed, "attribute order and whitespace should be ignored: %v", res.Deviations)
+
+	// Attribute values and element order still matter
+	resp = makeResponse(200, nil, `<user id="1" role="viewer"><name>Alice</name><tags><tag>a</tag><tag>b</tag></tags></user>`)
+	res, err = executor.compareAndGenerateResult(test, resp, 10)
+	require.NoError(t, err)
+	require.False(t, res.Passed)
+
+	resp = makeResponse(200, nil, `<user id="1" role="admin"><name>Alice</name><tags><tag>b</tag><tag>a</tag></tags></user>`)
+	res, err = executor.compareAndGenerateResult(test, resp, 10)
+	require.NoError(t, err)
+	require.False(t, res.Passed)
+	require.Equal(t, "response.body", res.Deviations[0].Field)
+	require.Equal(t, test.Response.Body, res.Deviations[0].Expected, "deviations keep the raw body")
+}
+
+func TestCompareAndGenerateResult_FormReorderedFields(t *testing.T) {
+	executor := &Executor{}
+	test := Test{
+		TraceID: "t-form",
+		Spans:   []*core.Span{makeSpanWithOutputSchema(core.DecodedType_DECODED_TYPE_FORM_DATA)},
+		Response: Response{
+			Status:  200,
+			Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			Body:    "status=ok&id=42&tag=a&tag=b",
+		},
+	}
+
+	resp := makeResponse(200, nil, "tag=b&id=42&status=ok&tag=a")
+	res, err := executor.compareAndGenerateResult(test, resp, 10)
+	require.NoError(t, err)
+	require.True(t, res.Passed, "field order should be ignored: %v", res.Deviations)
+
+	resp = makeResponse(200, nil, "id=43&status=ok&tag=a&tag=b")
+	res, err = executor.compareAndGenerateResult(test, resp, 10)
+	require.NoError(t, err)
+	require.False(t, res.Passed)
+}
+
+func TestNormalizeBodies_FallsBackOnUnparseableBody(t *testing.T) {
+	expected, actual := normalizeBodies(normalizeXMLBody, "<a>", "<a></a>")
+	require.Equal(t, "<a>", expected)
+	require.Equal(t, "<a></a>", actual)
+
+	require.Nil(t, normalizerForHeaders(map[string]string{"Content-Type": "text/plain"}))
+	require.NotNil(t, normalizerForHeaders(map[string]string{"Content-Type": "application/atom+xml"}))
+}
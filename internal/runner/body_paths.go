@@ -0,0 +1,132 @@
+package runner
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/Use-Tusk/tusk-cli/internal/log"
+)
+
+// bodyPathSegment is one step of a body path: an object key, an array index,
+// or any array index ("[*]").
+type bodyPathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	anyIndex bool
+}
+
+// parseBodyPath parses paths like "data.serverTime", "items[*].updatedAt" or
+// "[0].id" (for array bodies).
+func parseBodyPath(p string) ([]bodyPathSegment, error) {
+	var segs []bodyPathSegment
+	rest := p
+	for rest != "" {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid body path %q: unclosed [", p)
+			}
+			inner := rest[1:end]
+			if inner == "*" {
+				segs = append(segs, bodyPathSegment{isIndex: true, anyIndex: true})
+			} else {
+				idx, err := strconv.Atoi(inner)
+				if err != nil || idx < 0 {
+					return nil, fmt.Errorf("invalid body path %q: index must be a number or *", p)
+				}
+				segs = append(segs, bodyPathSegment{isIndex: true, index: idx})
+			}
+			rest = rest[end+1:]
+		case rest[0] == '.' && len(segs) > 0:
+			rest = rest[1:]
+			if rest == "" || rest[0] == '.' || rest[0] == '[' {
+				return nil, fmt.Errorf("invalid body path %q: empty key", p)
+			}
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid body path %q: empty key", p)
+			}
+			segs = append(segs, bodyPathSegment{key: rest[:end]})
+			rest = rest[end:]
+		}
+	}
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("invalid body path %q: empty path", p)
+	}
+	return segs, nil
+}
+
+// pruneBodyPath returns a copy of v without the values at path. Containers on
+// the path are copied, so v itself is not modified.
+func pruneBodyPath(v any, path []bodyPathSegment) any {
+	if len(path) == 0 {
+		return v
+	}
+	seg, rest := path[0], path[1:]
+
+	switch val := v.(type) {
+	case map[string]any:
+		if seg.isIndex {
+			return v
+		}
+		child, ok := val[seg.key]
+		if !ok {
+			return v
+		}
+		out := maps.Clone(val)
+		if len(rest) == 0 {
+			delete(out, seg.key)
+		} else {
+			out[seg.key] = pruneBodyPath(child, rest)
+		}
+		return out
+	case []any:
+		if !seg.isIndex {
+			return v
+		}
+		out := make([]any, 0, len(val))
+		for i, item := range val {
+			if !seg.anyIndex && i != seg.index {
+				out = append(out, item)
+				continue
+			}
+			if len(rest) > 0 {
+				out = append(out, pruneBodyPath(item, rest))
+			}
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// pruneBodyPaths removes every path in paths from v; see pruneBodyPath.
+func pruneBodyPaths(v any, paths [][]bodyPathSegment) any {
+	for _, path := range paths {
+		v = pruneBodyPath(v, path)
+	}
+	return v
+}
+
+// parseIgnoreBodyPaths parses deviations.ignore_body_paths, skipping invalid
+// entries (config validation reports them).
+func parseIgnoreBodyPaths(raw []string) [][]bodyPathSegment {
+	var paths [][]bodyPathSegment
+	for _, p := range raw {
+		path, err := parseBodyPath(p)
+		if err != nil {
+			log.Debug("Skipping invalid ignore_body_paths entry", "path", p, "error", err)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
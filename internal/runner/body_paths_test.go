@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBodyPath(t *testing.T) {
+	segs, err := parseBodyPath("items[*].tags[1].name")
+	require.NoError(t, err)
+	assert.Equal(t, []bodyPathSegment{
+		{key: "items"},
+		{isIndex: true, anyIndex: true},
+		{key: "tags"},
+		{isIndex: true, index: 1},
+		{key: "name"},
+	}, segs)
+
+	segs, err = parseBodyPath("[*].id")
+	require.NoError(t, err)
+	assert.Equal(t, []bodyPathSegment{{isIndex: true, anyIndex: true}, {key: "id"}}, segs)
+
+	for _, invalid := range []string{"", ".a", "a..b", "a.", "a[", "a[x]", "a[-1]"} {
+		_, err := parseBodyPath(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestPruneBodyPaths(t *testing.T) {
+	body := map[string]any{
+		"data": map[string]any{"serverTime": "12:00", "user": "alice"},
+		"items": []any{
+			map[string]any{"id": 1.0, "updatedAt": "a"},
+			map[string]any{"id": 2.0, "updatedAt": "b"},
+		},
+	}
+	paths := parseIgnoreBodyPaths([]string{"data.serverTime", "items[*].updatedAt", "missing.path"})
+
+	pruned := pruneBodyPaths(body, paths)
+	assert.Equal(t, map[string]any{
+		"data": map[string]any{"user": "alice"},
+		"items": []any{
+			map[string]any{"id": 1.0},
+			map[string]any{"id": 2.0},
+		},
+	}, pruned)
+
+	// The original body is left untouched
+	assert.Contains(t, body["data"], "serverTime")
+	assert.Contains(t, body["items"].([]any)[0], "updatedAt")
+
+	// A trailing index removes that element
+	assert.Equal(t, []any{"a", "c"}, pruneBodyPaths([]any{"a", "b", "c"}, parseIgnoreBodyPaths([]string{"[1]"})))
+}
+
+func TestCompareResponseBodies_IgnoreBodyPaths(t *testing.T) {
+	config.Invalidate()
+	t.Cleanup(config.Invalidate)
+	require.NoError(t, config.Load(writeTempConfig(t, `
+deviations:
+  ignore_body_paths: ["meta.traceId", "items[*].updatedAt"]
+`)))
+
+	expected := map[string]any{
+		"meta":  map[string]any{"traceId": "abc", "version": "1"},
+		"items": []any{map[string]any{"id": 1.0, "updatedAt": "2023-01-01"}},
+	}
+	executor := &Executor{}
+
+	// Only ignored paths differ (updatedAt is missing entirely in actual)
+	actual := map[string]any{
+		"meta":  map[string]any{"traceId": "xyz", "version": "1"},
+		"items": []any{map[string]any{"id": 1.0}},
+	}
+	assert.True(t, executor.compareResponseBodies(expected, actual, "t-paths"))
+
+	// A non-ignored nested field still deviates
+	actual = map[string]any{
+		"meta":  map[string]any{"traceId": "xyz", "version": "2"},
+		"items": []any{map[string]any{"id": 1.0, "updatedAt": "2024-01-01"}},
+	}
+	assert.False(t, executor.compareResponseBodies(expected, actual, "t-paths"))
+}
@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+)
+
+// ChangedRoutes are the routes mapped from a set of changed files.
+type ChangedRoutes struct {
+	Files  []string
+	Routes []Route
+}
+
+// NewChangedRoutes collects the routes of every mapping whose files glob
+// matches one of changedFiles (paths relative to the repository root).
+func NewChangedRoutes(changedFiles []string, mappings []config.RouteMapping) (*ChangedRoutes, error) {
+	c := &ChangedRoutes{Files: changedFiles}
+	seen := make(map[string]bool)
+	for i, m := range mappings {
+		if !anyFileMatches(m.Files, changedFiles) {
+			continue
+		}
+		for _, spec := range m.Routes {
+			route, err := ParseRoute(spec)
+			if err != nil {
+				return nil, fmt.Errorf("test_execution.route_mappings[%d]: %w", i, err)
+			}
+			if seen[route.String()] {
+				continue
+			}
+			seen[route.String()] = true
+			c.Routes = append(c.Routes, route)
+		}
+	}
+	return c, nil
+}
+
+func anyFileMatches(pattern string, files []string) bool {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	for _, f := range files {
+		if matched, _ := doublestar.Match(pattern, filepath.ToSlash(f)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the tests whose recorded request matches one of the routes.
+func (c *ChangedRoutes) Filter(tests []Test) []Test {
+	var out []Test
+	for _, t := range tests {
+		if t.Path == "" {
+			continue
+		}
+		for _, route := range c.Routes {
+			if route.Matches(t.Method, t.Path) {
+				out = append(out, t)
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+)
+
+var testRouteMappings = []config.RouteMapping{
+	{Files: "src/users/**", Routes: []string{"GET /users/:id", "POST /users"}},
+	{Files: "src/orders/*.ts", Routes: []string{"/orders/**"}},
+	{Files: "src/shared/db.ts", Routes: []string{"GET /users/:id", "/health"}},
+}
+
+func TestNewChangedRoutes(t *testing.T) {
+	changed, err := NewChangedRoutes([]string{"src/users/handlers/get.ts", "README.md", "src/shared/db.ts"}, testRouteMappings)
+	require.NoError(t, err)
+
+	var routes []string
+	for _, r := range changed.Routes {
+		routes = append(routes, r.String())
+	}
+	assert.Equal(t, []string{"GET /users/:id", "POST /users", "/health"}, routes)
+
+	changed, err = NewChangedRoutes([]string{"docs/index.md"}, testRouteMappings)
+	require.NoError(t, err)
+	assert.Empty(t, changed.Routes)
+
+	_, err = NewChangedRoutes([]string{"src/a.ts"}, []config.RouteMapping{{Files: "src/**", Routes: []string{"users"}}})
+	assert.ErrorContains(t, err, "route_mappings[0]")
+}
+
+func TestChangedRoutesFilter(t *testing.T) {
+	tests := []Test{
+		{TraceID: "get-user", Method: "GET", Path: "/users/42"},
+		{TraceID: "delete-user", Method: "DELETE", Path: "/users/42"},
+		{TraceID: "create-order", Method: "POST", Path: "/orders/7/items"},
+		{TraceID: "health", Method: "GET", Path: "/health?full=1"},
+		{TraceID: "graphql", Method: "", Path: ""},
+	}
+
+	changed, err := NewChangedRoutes([]string{"src/users/model.ts", "src/orders/create.ts"}, testRouteMappings)
+	require.NoError(t, err)
+
+	var ids []string
+	for _, test := range changed.Filter(tests) {
+		ids = append(ids, test.TraceID)
+	}
+	assert.Equal(t, []string{"get-user", "create-order"}, ids)
+
+	changed, err = NewChangedRoutes(nil, testRouteMappings)
+	require.NoError(t, err)
+	assert.Empty(t, changed.Filter(tests))
+}
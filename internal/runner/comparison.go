@@ -3,11 +3,16 @@ package runner
 import (
 	"fmt"
 	"io"
+	"maps"
+	"math"
 	"net/http"
 	"reflect"
 	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/bmatcuk/doublestar/v4"
+
 	"github.com/Use-Tusk/tusk-cli/internal/config"
 	"github.com/Use-Tusk/tusk-cli/internal/log"
 	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
@@ -55,9 +60,15 @@ func (e *Executor) compareAndGenerateResult(test Test, actualResp *http.Response
 		})
 	}
 
-	// Note: response headers are not compared. They can be too dynamic to compare reliably.
+	// Response headers are often too dynamic to compare reliably, so they are
+	// only compared when deviations.compare_response_headers is set
+	if cfg, err := config.Get(); err == nil && cfg.Deviations.CompareResponseHeaders {
+		deviations = append(deviations, compareResponseHeaders(test.Response.Headers, actualResp.Header, cfg.Deviations.IgnoreResponseHeaders)...)
+	}
 
-	if !e.compareResponseBodies(test.Response.Body, actualBody, test.TraceID) {
+	// XML and form-encoded bodies are compared by structure rather than as text
+	expectedCmp, actualCmp := normalizeBodies(normalizerForHeaders(test.Response.Headers), test.Response.Body, actualBody)
+	if !e.compareResponseBodies(expectedCmp, actualCmp, test.TraceID) {
 		log.Debug("Body mismatch detected", "traceID", test.TraceID, "expected", test.Response.Body, "actual", actualBody)
 		deviations = append(deviations, Deviation{
 			Field:       "response.body",
@@ -67,6 +78,12 @@ func (e *Executor) compareAndGenerateResult(test Test, actualResp *http.Response
 		})
 	}
 
+	if cfg, err := config.Get(); err == nil && cfg.Deviations.LatencyFactor > 0 {
+		if dev, ok := latencyDeviation(test.Duration, duration, cfg.Deviations.LatencyFactor); ok {
+			deviations = append(deviations, dev)
+		}
+	}
+
 	passed := len(deviations) == 0
 
 	log.Debug("Comparison result", "traceID", test.TraceID, "expected", test.Response.Body, "actual", actualBody, "passed", passed, "deviations", deviations)
@@ -89,12 +106,85 @@ func (e *Executor) compareAndGenerateResult(test Test, actualResp *http.Response
 	return result, nil
 }
 
+// latencyDeviationField is the field of latency deviations. It sits outside
+// response.* so severity rules for response content don't apply to it.
+const latencyDeviationField = "latency"
+
+// latencyDeviation reports a latency regression when the replay took more
+// than factor times the recorded root span duration (both in milliseconds).
+// Tests without a recorded duration are never flagged.
+func latencyDeviation(recordedMs, actualMs int, factor float64) (Deviation, bool) {
+	if recordedMs <= 0 || factor <= 0 || float64(actualMs) <= float64(recordedMs)*factor {
+		return Deviation{}, false
+	}
+	return Deviation{
+		Field:       latencyDeviationField,
+		Expected:    fmt.Sprintf("<= %gx %dms", factor, recordedMs),
+		Actual:      fmt.Sprintf("%dms", actualMs),
+		Description: fmt.Sprintf("Replay latency regression: %.1fx the recorded %dms", float64(actualMs)/float64(recordedMs), recordedMs),
+	}, true
+}
+
+// transportHeaders describe how a response was transferred rather than its
+// content, and are rewritten by proxies and the HTTP client.
+var transportHeaders = []string{"connection", "content-length", "keep-alive", "transfer-encoding"}
+
+// compareResponseHeaders returns a deviation for each recorded header that is
+// missing or has a different value in the actual response. Headers the service
+// adds during replay are ignored, as are transport headers and headers matching
+// ignore (case-insensitive globs).
+func compareResponseHeaders(expected map[string]string, actual http.Header, ignore []string) []Deviation {
+	var deviations []Deviation
+	for _, name := range slices.Sorted(maps.Keys(expected)) {
+		lower := strings.ToLower(name)
+		if slices.Contains(transportHeaders, lower) || headerIgnored(lower, ignore) {
+			continue
+		}
+
+		expectedValue := expected[name]
+		values := actual.Values(name)
+		actualValue := strings.Join(values, ", ")
+		if len(values) > 0 && actualValue == expectedValue {
+			continue
+		}
+
+		dev := Deviation{
+			Field:       "response.headers." + lower,
+			Expected:    expectedValue,
+			Description: fmt.Sprintf("Response header %s mismatch", lower),
+		}
+		if len(values) == 0 {
+			dev.Description = fmt.Sprintf("Response header %s missing", lower)
+		} else {
+			dev.Actual = actualValue
+		}
+		deviations = append(deviations, dev)
+	}
+	return deviations
+}
+
+func headerIgnored(name string, ignore []string) bool {
+	for _, pattern := range ignore {
+		if matched, _ := doublestar.Match(strings.ToLower(pattern), name); matched {
+			return true
+		}
+	}
+	return false
+}
+
 // compareResponseBodies performs comparison of response bodies,
 // ignoring dynamic fields like UUIDs, timestamps, and dates
 func (e *Executor) compareResponseBodies(expected, actual any, testID string) bool {
 	var comparisonConfig *config.ComparisonConfig
+	var floatTolerance config.FloatToleranceConfig
 	cfg, err := config.Get()
 	if err == nil {
+		floatTolerance = cfg.Deviations.FloatTolerance
+		if paths := parseIgnoreBodyPaths(cfg.Deviations.IgnoreBodyPaths); len(paths) > 0 {
+			expected = pruneBodyPaths(expected, paths)
+			actual = pruneBodyPaths(actual, paths)
+		}
+
 		// Check if comparison config has any non-default values
 		comp := &cfg.Comparison
 
@@ -132,6 +222,7 @@ func (e *Executor) compareResponseBodies(expected, actual any, testID string) bo
 		"actual", actual)
 
 	matcher := NewDynamicFieldMatcherWithConfig(comparisonConfig)
+	matcher.floatTolerance = floatTolerance
 	result := e.compareJSONValues("", expected, actual, matcher, testID)
 
 	log.Debug("Final comparison result", "result", result)
@@ -164,6 +255,9 @@ func (e *Executor) compareJSONValues(fieldPath string, expected, actual any, mat
 		if expected == actual {
 			return true
 		}
+		if a, ok := expected.(float64); ok && floatsWithinTolerance(a, actual.(float64), matcher.floatTolerance) {
+			return true
+		}
 		fieldName := getFieldName(fieldPath)
 		if matcher.ShouldIgnoreField(fieldName, expected, actual, testID) {
 			return true
@@ -256,6 +350,16 @@ func (e *Executor) compareSlices(fieldPath string, expected, actual any, matcher
 	return true
 }
 
+// floatsWithinTolerance reports whether a and b are within the absolute or
+// relative tolerance of each other.
+func floatsWithinTolerance(a, b float64, tol config.FloatToleranceConfig) bool {
+	diff := math.Abs(a - b)
+	if tol.Absolute > 0 && diff <= tol.Absolute {
+		return true
+	}
+	return tol.Relative > 0 && diff <= tol.Relative*math.Max(math.Abs(a), math.Abs(b))
+}
+
 // getFieldName extracts the field name from a field path (e.g., "user.profile.name" -> "name")
 func getFieldName(fieldPath string) string {
 	if fieldPath == "" {
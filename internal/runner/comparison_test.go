@@ -1,6 +1,8 @@
 package runner
 
 import (
+	"fmt"
+	"net/http"
 	"testing"
 
 	"github.com/Use-Tusk/tusk-cli/internal/config"
@@ -399,3 +401,156 @@ func TestSafeEqual(t *testing.T) {
 	_, ok = safeEqual(map[string]any{"a": 1.0}, map[string]any{"a": 1.0})
 	require.False(t, ok)
 }
+
+func TestCompareAndGenerateResult_IgnoredResponseHeaders(t *testing.T) {
+	config.Invalidate()
+	t.Cleanup(config.Invalidate)
+	cfgPath := writeTempConfig(t, `
+deviations:
+  compare_response_headers: true
+  ignore_response_headers: ["Date", "x-request-*"]
+`)
+	require.NoError(t, config.Load(cfgPath))
+
+	executor := &Executor{}
+	test := Test{
+		TraceID: "t-headers",
+		Response: Response{
+			Status: 200,
+			Headers: map[string]string{
+				"content-type":   "application/json",
+				"date":           "Mon, 01 Jan 2024 00:00:00 GMT",
+				"x-request-id":   "abc",
+				"content-length": "2",
+			},
+			Body: map[string]any{},
+		},
+	}
+
+	resp := makeResponse(200, map[string]string{
+		"Content-Type": "application/json",
+		"Date":         "Tue, 15 Oct 2024 12:00:00 GMT",
+		"X-Request-Id": "def",
+	}, `{}`)
+	res, err := executor.compareAndGenerateResult(test, resp, 1)
+	require.NoError(t, err)
+	require.True(t, res.Passed, "ignored headers must not deviate: %v", res.Deviations)
+
+	resp = makeResponse(200, map[string]string{
+		"Content-Type": "text/plain",
+		"Date":         "Tue, 15 Oct 2024 12:00:00 GMT",
+	}, `{}`)
+	res, err = executor.compareAndGenerateResult(test, resp, 1)
+	require.NoError(t, err)
+	require.False(t, res.Passed)
+	require.Len(t, res.Deviations, 1)
+	require.Equal(t, "response.headers.content-type", res.Deviations[0].Field)
+	require.Equal(t, "application/json", res.Deviations[0].Expected)
+	require.Equal(t, "text/plain", res.Deviations[0].Actual)
+}
+
+func TestCompareAndGenerateResult_ResponseHeadersNotComparedByDefault(t *testing.T) {
+	config.Invalidate()
+	t.Cleanup(config.Invalidate)
+	require.NoError(t, config.Load(writeTempConfig(t, "service:\n  port: 3000\n")))
+
+	executor := &Executor{}
+	test := Test{
+		TraceID:  "t-headers-off",
+		Response: Response{Status: 200, Headers: map[string]string{"content-type": "application/json"}, Body: map[string]any{}},
+	}
+	res, err := executor.compareAndGenerateResult(test, makeResponse(200, map[string]string{"Content-Type": "text/plain"}, `{}`), 1)
+	require.NoError(t, err)
+	require.True(t, res.Passed)
+}
+
+func TestLatencyDeviation(t *testing.T) {
+	tests := []struct {
+		name     string
+		recorded int
+		actual   int
+		factor   float64
+		want     bool
+	}{
+		{name: "faster than recorded", recorded: 100, actual: 50, factor: 2, want: false},
+		{name: "exactly at threshold", recorded: 100, actual: 200, factor: 2, want: false},
+		{name: "just over threshold", recorded: 100, actual: 201, factor: 2, want: true},
+		{name: "fractional factor", recorded: 100, actual: 151, factor: 1.5, want: true},
+		{name: "no recorded duration", recorded: 0, actual: 5000, factor: 2, want: false},
+		{name: "disabled", recorded: 100, actual: 5000, factor: 0, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dev, ok := latencyDeviation(tt.recorded, tt.actual, tt.factor)
+			require.Equal(t, tt.want, ok)
+			if ok {
+				require.Equal(t, latencyDeviationField, dev.Field)
+				require.Equal(t, fmt.Sprintf("%dms", tt.actual), dev.Actual)
+			}
+		})
+	}
+}
+
+func TestCompareAndGenerateResult_LatencyRegression(t *testing.T) {
+	config.Invalidate()
+	t.Cleanup(config.Invalidate)
+	require.NoError(t, config.Load(writeTempConfig(t, "deviations:\n  latency_factor: 3\n")))
+
+	executor := &Executor{}
+	test := Test{
+		TraceID:  "t-latency",
+		Duration: 20,
+		Response: Response{Status: 200, Body: map[string]any{"ok": true}},
+	}
+
+	res, err := executor.compareAndGenerateResult(test, makeResponse(200, nil, `{"ok":true}`), 60)
+	require.NoError(t, err)
+	require.True(t, res.Passed, "latency within the factor must pass: %v", res.Deviations)
+
+	res, err = executor.compareAndGenerateResult(test, makeResponse(200, nil, `{"ok":true}`), 61)
+	require.NoError(t, err)
+	require.False(t, res.Passed)
+	require.Len(t, res.Deviations, 1)
+	require.Equal(t, "latency", res.Deviations[0].Field)
+	require.Contains(t, res.Deviations[0].Description, "latency regression")
+}
+
+func TestCompareResponseHeaders_MissingHeader(t *testing.T) {
+	devs := compareResponseHeaders(map[string]string{"ETag": `"v1"`}, http.Header{}, nil)
+	require.Len(t, devs, 1)
+	require.Equal(t, "response.headers.etag", devs[0].Field)
+	require.Nil(t, devs[0].Actual)
+	require.Contains(t, devs[0].Description, "missing")
+}
+
+func TestCompareResponseBodies_FloatTolerance(t *testing.T) {
+	expected := map[string]any{"total": 1.0, "items": []any{map[string]any{"price": 2.5}}}
+	actual := map[string]any{"total": 1.0000001, "items": []any{map[string]any{"price": 2.5000001}}}
+
+	for _, tt := range []struct {
+		name   string
+		config string
+		passes bool
+	}{
+		{"no tolerance", "deviations: {}\n", false},
+		{"absolute", "deviations:\n  float_tolerance:\n    absolute: 0.000001\n", true},
+		{"relative", "deviations:\n  float_tolerance:\n    relative: 0.000001\n", true},
+		{"too tight", "deviations:\n  float_tolerance:\n    absolute: 0.00000001\n", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Invalidate()
+			t.Cleanup(config.Invalidate)
+			require.NoError(t, config.Load(writeTempConfig(t, tt.config)))
+
+			executor := &Executor{}
+			require.Equal(t, tt.passes, executor.compareResponseBodies(expected, actual, "t-float"))
+		})
+	}
+}
+
+func TestFloatsWithinTolerance(t *testing.T) {
+	require.True(t, floatsWithinTolerance(1.0, 1.0000001, config.FloatToleranceConfig{Absolute: 1e-6}))
+	require.False(t, floatsWithinTolerance(1.0, 1.0000001, config.FloatToleranceConfig{}))
+	require.True(t, floatsWithinTolerance(1e9, 1e9+1, config.FloatToleranceConfig{Relative: 1e-6}))
+	require.False(t, floatsWithinTolerance(1e9, 1e9+1, config.FloatToleranceConfig{Absolute: 1e-6}))
+}
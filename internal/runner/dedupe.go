@@ -0,0 +1,30 @@
+package runner
+
+// DedupeTests collapses tests whose root span has the same input value hash,
+// keeping the first test of each group in its original position. Tests
+// without a root span or input hash are always kept. It returns the remaining
+// tests and how many were dropped.
+func DedupeTests(tests []Test) ([]Test, int) {
+	seen := make(map[string]bool, len(tests))
+	out := make([]Test, 0, len(tests))
+	for _, t := range tests {
+		hash := rootInputValueHash(t)
+		if hash != "" {
+			if seen[hash] {
+				continue
+			}
+			seen[hash] = true
+		}
+		out = append(out, t)
+	}
+	return out, len(tests) - len(out)
+}
+
+func rootInputValueHash(t Test) string {
+	for _, span := range t.Spans {
+		if span != nil && span.IsRootSpan {
+			return span.InputValueHash
+		}
+	}
+	return ""
+}
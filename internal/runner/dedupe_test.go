@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"testing"
+
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func testWithRootHash(traceID, hash string) Test {
+	return Test{
+		TraceID: traceID,
+		Spans: []*core.Span{
+			{TraceId: traceID, SpanId: traceID + "-child", InputValueHash: "child"},
+			{TraceId: traceID, SpanId: traceID + "-root", IsRootSpan: true, InputValueHash: hash},
+		},
+	}
+}
+
+func TestDedupeTests(t *testing.T) {
+	tests := []Test{
+		testWithRootHash("a", "hash-1"),
+		testWithRootHash("b", "hash-2"),
+		testWithRootHash("c", "hash-1"),
+	}
+
+	deduped, collapsed := DedupeTests(tests)
+	assert.Equal(t, 1, collapsed)
+	if assert.Len(t, deduped, 2) {
+		assert.Equal(t, "a", deduped[0].TraceID)
+		assert.Equal(t, "b", deduped[1].TraceID)
+	}
+}
+
+func TestDedupeTestsKeepsTestsWithoutRootHash(t *testing.T) {
+	tests := []Test{
+		{TraceID: "no-spans"},
+		testWithRootHash("empty-1", ""),
+		testWithRootHash("empty-2", ""),
+	}
+
+	deduped, collapsed := DedupeTests(tests)
+	assert.Equal(t, 0, collapsed)
+	assert.Len(t, deduped, 3)
+}
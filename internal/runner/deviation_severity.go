@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"slices"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+)
+
+// Deviation severities, from least to most interesting.
+const (
+	SeverityInfo  = "info"
+	SeverityWarn  = "warn"
+	SeverityError = "error"
+)
+
+// severityRank orders severities so errors sort first.
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityInfo:
+		return 2
+	case SeverityWarn:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// severityForField returns the severity of the first rule whose glob matches
+// field, or SeverityError when none does.
+func severityForField(field string, rules []config.SeverityRule) string {
+	for _, rule := range rules {
+		if matched, _ := doublestar.Match(rule.Field, field); matched {
+			return rule.Severity
+		}
+	}
+	return SeverityError
+}
+
+// classifyDeviations tags each deviation with a severity from
+// deviations.severity_rules and orders them most severe first.
+func classifyDeviations(deviations []Deviation) {
+	var rules []config.SeverityRule
+	if cfg, err := config.Get(); err == nil {
+		rules = cfg.Deviations.SeverityRules
+	}
+	for i := range deviations {
+		deviations[i].Severity = severityForField(deviations[i].Field, rules)
+	}
+	slices.SortStableFunc(deviations, func(a, b Deviation) int {
+		return severityRank(a.Severity) - severityRank(b.Severity)
+	})
+}
+
+// failsAtSeverity reports whether a failed result should fail the run when
+// only deviations at or above threshold count. An empty threshold counts every
+// failure, as do failures without deviations (no response, missing mocks).
+func failsAtSeverity(result TestResult, threshold string) bool {
+	if threshold == "" || len(result.Deviations) == 0 {
+		return true
+	}
+	for _, dev := range result.Deviations {
+		if severityRank(dev.Severity) <= severityRank(threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidSeverity reports whether s is one of the deviation severities.
+func IsValidSeverity(s string) bool {
+	switch s {
+	case SeverityInfo, SeverityWarn, SeverityError:
+		return true
+	default:
+		return false
+	}
+}
+
+// countDeviationsBySeverity counts the deviations of failed tests per severity.
+func countDeviationsBySeverity(results []TestResult) map[string]int {
+	counts := make(map[string]int)
+	for _, result := range results {
+		if result.Passed {
+			continue
+		}
+		for _, dev := range result.Deviations {
+			severity := dev.Severity
+			if severity == "" {
+				severity = SeverityError
+			}
+			counts[severity]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+)
+
+func TestSeverityForField(t *testing.T) {
+	rules := []config.SeverityRule{
+		{Field: "response.headers.*", Severity: SeverityInfo},
+		{Field: "response.body.total", Severity: SeverityError},
+		{Field: "response.body*", Severity: SeverityWarn},
+	}
+
+	assert.Equal(t, SeverityInfo, severityForField("response.headers.x-request-id", rules))
+	assert.Equal(t, SeverityError, severityForField("response.body.total", rules))
+	assert.Equal(t, SeverityWarn, severityForField("response.body", rules))
+	assert.Equal(t, SeverityError, severityForField("response.status", rules), "unmatched fields default to error")
+}
+
+func TestCountDeviationsBySeverity(t *testing.T) {
+	results := []TestResult{
+		{Passed: true, Deviations: []Deviation{{Field: "x", Severity: SeverityError}}},
+		{Deviations: []Deviation{{Field: "a", Severity: SeverityWarn}, {Field: "b", Severity: SeverityInfo}}},
+		{Deviations: []Deviation{{Field: "c"}}},
+	}
+
+	assert.Equal(t, map[string]int{SeverityWarn: 1, SeverityInfo: 1, SeverityError: 1}, countDeviationsBySeverity(results))
+}
+
+func TestOutputResultsSummary_FailOnSeverity(t *testing.T) {
+	results := []TestResult{
+		{TestID: "passed", Passed: true},
+		{TestID: "info", Deviations: []Deviation{{Field: "response.headers.x-request-id", Severity: SeverityInfo}}},
+		{TestID: "warn", Deviations: []Deviation{
+			{Field: "response.body", Severity: SeverityWarn},
+			{Field: "response.headers.date", Severity: SeverityInfo},
+		}},
+	}
+
+	tests := []struct {
+		threshold string
+		wantErr   string
+	}{
+		{threshold: "", wantErr: "2 tests with deviations"},
+		{threshold: SeverityInfo, wantErr: "2 tests with deviations"},
+		{threshold: SeverityWarn, wantErr: "1 tests with deviations"},
+		{threshold: SeverityError},
+	}
+	for _, tt := range tests {
+		t.Run("threshold="+tt.threshold, func(t *testing.T) {
+			err := OutputResultsSummary(results, SummaryOptions{Format: "json", Quiet: true, FailOnSeverity: tt.threshold})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr+", 0 crashed server")
+			}
+		})
+	}
+}
+
+func TestFailsAtSeverity_FailuresWithoutDeviationsAlwaysCount(t *testing.T) {
+	result := TestResult{Error: "connection refused"}
+	assert.True(t, failsAtSeverity(result, SeverityError))
+
+	unclassified := TestResult{Deviations: []Deviation{{Field: "response.status"}}}
+	assert.True(t, failsAtSeverity(unclassified, SeverityError))
+}
@@ -0,0 +1,130 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+)
+
+// DoctorStatus is the outcome of one `tusk doctor` check.
+type DoctorStatus string
+
+const (
+	DoctorPass DoctorStatus = "pass"
+	DoctorWarn DoctorStatus = "warn"
+	DoctorFail DoctorStatus = "fail"
+)
+
+// DoctorCheck is the result of one `tusk doctor` check.
+type DoctorCheck struct {
+	Name   string       `json:"name"`
+	Status DoctorStatus `json:"status"`
+	Detail string       `json:"detail"`
+}
+
+// socketDialTimeout bounds how long the stale-socket probe waits for a live
+// mock server to accept.
+const socketDialTimeout = 500 * time.Millisecond
+
+// CheckMockServer checks that the mock server can listen the way cfg
+// configures it: on a free TCP port for tcp and websocket communication,
+// otherwise on a Unix socket under cwd.
+func CheckMockServer(cfg *config.ServiceConfig, cwd string) DoctorCheck {
+	switch determineCommunicationType(cfg) {
+	case CommunicationTCP, CommunicationWebSocket:
+		return checkMockPort(cfg.Communication.TCPPort)
+	}
+	return checkMockSocket(cwd)
+}
+
+// checkMockSocket reports whether any of the Unix socket paths the mock server
+// tries (see unixSocketCandidates) is writable, and warns about sockets left
+// behind by earlier runs.
+func checkMockSocket(cwd string) DoctorCheck {
+	check := DoctorCheck{Name: "Mock server socket"}
+
+	var stale, live []string
+	usable := ""
+	for _, candidate := range unixSocketCandidates(cwd, "") {
+		if info, err := os.Lstat(candidate); err == nil && info.Mode()&os.ModeSocket != 0 {
+			if conn, err := net.DialTimeout("unix", candidate, socketDialTimeout); err != nil {
+				stale = append(stale, candidate)
+			} else {
+				_ = conn.Close()
+				live = append(live, candidate)
+			}
+		}
+		if usable == "" && dirWritable(filepath.Dir(candidate)) {
+			usable = candidate
+		}
+	}
+
+	switch {
+	case usable == "":
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("no writable directory for the mock server socket under %s or its parents", cwd)
+	case len(live) > 0:
+		check.Status = DoctorWarn
+		check.Detail = fmt.Sprintf("%s is in use, probably by another tusk run in this directory; a new run will take it over", strings.Join(live, ", "))
+	case len(stale) > 0:
+		check.Status = DoctorWarn
+		check.Detail = fmt.Sprintf("stale socket left by an earlier run: %s (replaced on the next run; safe to delete)", strings.Join(stale, ", "))
+	default:
+		check.Status = DoctorPass
+		check.Detail = fmt.Sprintf("socket will be created at %s", usable)
+	}
+	return check
+}
+
+// checkMockPort reports whether the configured TCP port is free.
+func checkMockPort(port int) DoctorCheck {
+	check := DoctorCheck{Name: "Mock server TCP port"}
+	if port == 0 {
+		check.Status = DoctorPass
+		check.Detail = "port is allocated dynamically"
+		return check
+	}
+	if inUse, _ := checkTCPPortAvailable(port); inUse {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("port %d is already in use; stop the process holding it or change service.communication.tcp_port", port)
+		return check
+	}
+	check.Status = DoctorPass
+	check.Detail = fmt.Sprintf("port %d is free", port)
+	return check
+}
+
+// dirWritable reports whether a file can be created in dir, or, if dir
+// doesn't exist yet, in its closest existing ancestor (the mock server
+// creates missing parents).
+func dirWritable(dir string) bool {
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return false
+			}
+			f, err := os.CreateTemp(dir, ".tusk-doctor-*")
+			if err != nil {
+				return false
+			}
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+			return true
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return false
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
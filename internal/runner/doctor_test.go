@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+)
+
+func listenUnixSocket(t *testing.T, cwd string) *net.UnixListener {
+	t.Helper()
+	path := filepath.Join(cwd, unixSocketDirName, unixSocketName)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	require.NoError(t, err)
+	return listener
+}
+
+func TestCheckMockSocket(t *testing.T) {
+	t.Run("clean directory passes", func(t *testing.T) {
+		cwd := t.TempDir()
+		check := checkMockSocket(cwd)
+		assert.Equal(t, DoctorPass, check.Status)
+		assert.Contains(t, check.Detail, filepath.Join(cwd, unixSocketDirName, unixSocketName))
+	})
+
+	t.Run("stale socket warns", func(t *testing.T) {
+		cwd := t.TempDir()
+		listener := listenUnixSocket(t, cwd)
+		// Leave the socket file behind, as a killed run would
+		listener.SetUnlinkOnClose(false)
+		require.NoError(t, listener.Close())
+
+		check := checkMockSocket(cwd)
+		assert.Equal(t, DoctorWarn, check.Status)
+		assert.Contains(t, check.Detail, "stale socket")
+	})
+
+	t.Run("socket in use warns", func(t *testing.T) {
+		cwd := t.TempDir()
+		listener := listenUnixSocket(t, cwd)
+		defer func() { _ = listener.Close() }()
+
+		check := checkMockSocket(cwd)
+		assert.Equal(t, DoctorWarn, check.Status)
+		assert.Contains(t, check.Detail, "in use")
+	})
+}
+
+func TestCheckMockPort(t *testing.T) {
+	t.Run("port in use fails", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() { _ = listener.Close() }()
+		port := listener.Addr().(*net.TCPAddr).Port
+
+		check := checkMockPort(port)
+		assert.Equal(t, DoctorFail, check.Status)
+		assert.Contains(t, check.Detail, "already in use")
+	})
+
+	t.Run("free port passes", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		port := listener.Addr().(*net.TCPAddr).Port
+		require.NoError(t, listener.Close())
+
+		assert.Equal(t, DoctorPass, checkMockPort(port).Status)
+	})
+
+	t.Run("dynamic port passes", func(t *testing.T) {
+		assert.Equal(t, DoctorPass, checkMockPort(0).Status)
+	})
+}
+
+func TestCheckMockServerChecksPortForTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	cfg := &config.ServiceConfig{Communication: config.CommunicationConfig{
+		Type:    "tcp",
+		TCPPort: listener.Addr().(*net.TCPAddr).Port,
+	}}
+	check := CheckMockServer(cfg, t.TempDir())
+	assert.Equal(t, "Mock server TCP port", check.Name)
+	assert.Equal(t, DoctorFail, check.Status)
+}
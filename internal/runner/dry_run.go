@@ -0,0 +1,143 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Use-Tusk/tusk-cli/internal/log"
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+)
+
+// MockCoverage describes how many outbound calls of a trace would be answered
+// by a recorded mock during replay.
+type MockCoverage struct {
+	TraceID   string              `json:"traceId"`
+	Name      string              `json:"name,omitempty"`
+	Total     int                 `json:"total"`
+	Matched   int                 `json:"matched"`
+	Unmatched []UnmatchedOutbound `json:"unmatched,omitempty"`
+}
+
+// UnmatchedOutbound is an outbound span for which no mock was found.
+type UnmatchedOutbound struct {
+	SpanID      string `json:"spanId"`
+	PackageName string `json:"packageName"`
+	Name        string `json:"name"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Percent returns the share of outbound calls that found a mock. Traces
+// without outbound calls are fully covered.
+func (c MockCoverage) Percent() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return float64(c.Matched) * 100 / float64(c.Total)
+}
+
+// DryRunMockCoverage checks, without starting the service, whether each
+// outbound call recorded in tests would find a mock. Every outbound span of a
+// trace is sent to the mock matcher in recorded order, as the SDK would do
+// during replay, using the executor's suite and global spans for fallbacks.
+func (e *Executor) DryRunMockCoverage(tests []Test) ([]MockCoverage, error) {
+	server, err := e.newConfiguredServer()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = server.Stop() }()
+	e.applySuiteSpans(server)
+
+	coverage := make([]MockCoverage, 0, len(tests))
+	for _, test := range tests {
+		spans := test.Spans
+		if len(spans) == 0 {
+			spans, err = e.LoadSpansForTrace(test.TraceID, test.FileName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load spans for trace %s: %w", test.TraceID, err)
+			}
+		}
+		coverage = append(coverage, dryRunTrace(server, test, spans))
+	}
+	return coverage, nil
+}
+
+func dryRunTrace(server *Server, test Test, spans []*core.Span) MockCoverage {
+	server.LoadSpansForTrace(test.TraceID, spans)
+	defer server.CleanupTraceSpans(test.TraceID)
+
+	result := MockCoverage{TraceID: test.TraceID, Name: test.DisplayName}
+	outbound := make([]*core.Span, 0, len(spans))
+	for _, span := range spans {
+		if span.Kind == core.SpanKind_SPAN_KIND_CLIENT && !span.IsRootSpan {
+			outbound = append(outbound, span)
+		}
+	}
+	// Request mocks in the order the calls were recorded, as the SDK would
+	sort.SliceStable(outbound, func(i, j int) bool {
+		return outbound[i].GetTimestamp().AsTime().Before(outbound[j].GetTimestamp().AsTime())
+	})
+
+	for _, span := range outbound {
+		result.Total++
+		resp := server.findMockWithTimeout(&core.GetMockRequest{
+			RequestId:    span.SpanId,
+			TestId:       test.TraceID,
+			OutboundSpan: span,
+			Operation:    span.SubmoduleName,
+		})
+		if resp.Found {
+			result.Matched++
+			continue
+		}
+		result.Unmatched = append(result.Unmatched, UnmatchedOutbound{
+			SpanID:      span.SpanId,
+			PackageName: span.PackageName,
+			Name:        span.Name,
+			Error:       resp.Error,
+		})
+	}
+	return result
+}
+
+// OutputMockCoverage prints the dry-run coverage of each test and returns an
+// error when any outbound call would go unmatched.
+func OutputMockCoverage(coverage []MockCoverage, format string) error {
+	uncovered := 0
+	for _, c := range coverage {
+		if len(c.Unmatched) > 0 {
+			uncovered++
+		}
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(coverage); err != nil {
+			return err
+		}
+	} else {
+		for _, c := range coverage {
+			label := c.TraceID
+			if c.Name != "" {
+				label = fmt.Sprintf("%s (%s)", c.Name, c.TraceID)
+			}
+			msg := fmt.Sprintf("%.0f%% MOCK COVERAGE - %s (%d/%d outbound calls)", c.Percent(), label, c.Matched, c.Total)
+			if len(c.Unmatched) == 0 {
+				log.UserSuccess(msg)
+				continue
+			}
+			log.UserDeviation(msg)
+			for _, u := range c.Unmatched {
+				log.Println(fmt.Sprintf("  No mock: %s %s (span %s)", u.PackageName, u.Name, u.SpanID))
+			}
+		}
+	}
+
+	log.Stderrln(fmt.Sprintf("\nDry run: %d tests, %d with outbound calls that would not find a mock", len(coverage), uncovered))
+	if uncovered > 0 {
+		return fmt.Errorf("%d tests have outbound calls without a matching mock", uncovered)
+	}
+	return nil
+}
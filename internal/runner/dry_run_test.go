@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"testing"
+
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunMockCoverage_MatchesOutboundSpans(t *testing.T) {
+	traceID := "dry-run-trace"
+	root := makeSpan(t, traceID, "root", "http", map[string]any{"method": "GET", "path": "/orders"}, nil, 100)
+	root.IsRootSpan = true
+	root.Kind = core.SpanKind_SPAN_KIND_SERVER
+	query := makeSpan(t, traceID, "query", "pg", map[string]any{"query": "SELECT 1"}, nil, 200)
+	query.Kind = core.SpanKind_SPAN_KIND_CLIENT
+	call := makeSpan(t, traceID, "call", "http", map[string]any{"method": "GET", "path": "/rates"}, nil, 300)
+	call.Kind = core.SpanKind_SPAN_KIND_CLIENT
+
+	e := NewExecutor()
+	coverage, err := e.DryRunMockCoverage([]Test{{TraceID: traceID, Spans: []*core.Span{call, root, query}}})
+	require.NoError(t, err)
+	require.Len(t, coverage, 1)
+	assert.Equal(t, traceID, coverage[0].TraceID)
+	assert.Equal(t, 2, coverage[0].Total, "only outbound spans are replayed")
+	assert.Equal(t, 2, coverage[0].Matched)
+	assert.Empty(t, coverage[0].Unmatched)
+	assert.InDelta(t, 100.0, coverage[0].Percent(), 0.001)
+}
+
+func TestOutputMockCoverage(t *testing.T) {
+	covered := MockCoverage{TraceID: "a", Total: 2, Matched: 2}
+	partial := MockCoverage{
+		TraceID:   "b",
+		Total:     4,
+		Matched:   3,
+		Unmatched: []UnmatchedOutbound{{SpanID: "s1", PackageName: "http", Name: "GET /rates"}},
+	}
+
+	assert.InDelta(t, 75.0, partial.Percent(), 0.001)
+	assert.InDelta(t, 100.0, MockCoverage{TraceID: "c"}.Percent(), 0.001, "no outbound calls")
+
+	require.NoError(t, OutputMockCoverage([]MockCoverage{covered}, "text"))
+	err := OutputMockCoverage([]MockCoverage{covered, partial}, "text")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 tests have outbound calls without a matching mock")
+}
@@ -29,6 +29,8 @@ type DynamicFieldMatcher struct {
 	ignoreJWT bool
 	// Whether to ignore numeric epoch timestamps (seconds and milliseconds)
 	ignoreEpoch bool
+	// Numbers within this tolerance compare equal
+	floatTolerance config.FloatToleranceConfig
 }
 
 // jwtRegex matches the general JWT format: three base64url segments separated by dots.
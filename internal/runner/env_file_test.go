@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	input := `# secrets for local replay
+API_KEY=abc123
+export DB_URL=postgres://localhost/app
+
+EMPTY=
+SPACED = value with spaces   # trailing comment
+HASH_IN_VALUE=abc#def
+SINGLE='literal $HOME \n # not a comment'
+DOUBLE="line1\nline2 \"quoted\" \\ end" # comment
+QUOTED_EMPTY=""
+API_KEY=override
+`
+	vars, err := parseEnvFile(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"API_KEY":       "override",
+		"DB_URL":        "postgres://localhost/app",
+		"EMPTY":         "",
+		"SPACED":        "value with spaces",
+		"HASH_IN_VALUE": "abc#def",
+		"SINGLE":        `literal $HOME \n # not a comment`,
+		"DOUBLE":        "line1\nline2 \"quoted\" \\ end",
+		"QUOTED_EMPTY":  "",
+	}, vars)
+}
+
+func TestParseEnvFileErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "missing equals", input: "A=1\nNOT_AN_ASSIGNMENT\n", err: "line 2: expected KEY=value"},
+		{name: "invalid key", input: "1BAD=x\n", err: `line 1: invalid variable name "1BAD"`},
+		{name: "unterminated quote", input: `A="open` + "\n", err: `line 1: unterminated " quote`},
+		{name: "text after quote", input: `A='x' y` + "\n", err: "line 1: unexpected text after quoted value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseEnvFile(strings.NewReader(tt.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestEnvFileVarsOverrideRecordedEnvVars(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte("SECRET=from-file\nSHARED=file\n"), 0o600))
+	vars, err := LoadEnvFile(path)
+	require.NoError(t, err)
+
+	e := NewExecutor()
+	e.SetReplayEnvVars(map[string]string{"SHARED": "recorded", "RECORDED": "yes"})
+	e.SetEnvFileVars(vars)
+
+	env := e.buildCommandEnv()
+	assert.Contains(t, env, "SECRET=from-file")
+	assert.Contains(t, env, "SHARED=file")
+	assert.Contains(t, env, "RECORDED=yes")
+	assert.NotContains(t, env, "SHARED=recorded")
+	assert.Contains(t, e.newEnvironmentExecutor("b", 2).buildCommandEnv(), "SECRET=from-file")
+
+	_, err = LoadEnvFile(filepath.Join(t.TempDir(), "missing.env"))
+	assert.Error(t, err)
+}
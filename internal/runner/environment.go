@@ -1,18 +1,25 @@
 package runner
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/Use-Tusk/tusk-cli/internal/config"
 	"github.com/Use-Tusk/tusk-cli/internal/log"
 )
 
-// StartEnvironment starts the mock server and service, then waits for the SDK ack.
+// StartEnvironment runs the pre-run command if it hasn't run yet, starts the
+// mock server and service, then waits for the SDK ack.
 // It performs best-effort cleanup on failure.
 func (e *Executor) StartEnvironment() error {
+	if err := e.runPreRunCommand(); err != nil {
+		return err
+	}
+
 	log.ServiceLog("Starting mock server...")
 	if err := e.StartServer(); err != nil {
 		log.ServiceLog(fmt.Sprintf("❌ Failed to start mock server: %v", err))
@@ -90,6 +97,19 @@ func (e *Executor) StopEnvironment() error {
 func (e *Executor) ForceStopEnvironment() error {
 	log.Debug("Force stopping environment")
 
+	e.forceStopService()
+
+	// Stop the server
+	if err := e.StopServer(); err != nil {
+		log.Debug("Failed to stop server during force stop", "error", err)
+	}
+
+	return nil
+}
+
+// forceStopService kills the service process and closes its log file, leaving
+// the mock server running.
+func (e *Executor) forceStopService() {
 	// Force kill the service if it's running
 	if e.serviceCmd != nil && e.serviceCmd.Process != nil {
 		if err := e.serviceCmd.Process.Kill(); err != nil {
@@ -98,41 +118,56 @@ func (e *Executor) ForceStopEnvironment() error {
 		e.serviceCmd = nil
 	}
 
-	// Stop the server
-	if err := e.StopServer(); err != nil {
-		log.Debug("Failed to stop server during force stop", "error", err)
-	}
-
 	// Close service log file if open
 	if e.serviceLogFile != nil {
 		_ = e.serviceLogFile.Close()
 		e.serviceLogFile = nil
 	}
+}
 
+// restartService starts the service again against the running mock server
+// and waits for its SDK to connect. The old connection must already have been
+// forgotten with ResetSDKConnection, so it can't satisfy the wait.
+func (e *Executor) restartService() error {
+	log.ServiceLog("Starting service...")
+	if err := e.StartService(); err != nil {
+		log.ServiceLog(fmt.Sprintf("❌ Failed to start service: %v", err))
+		return fmt.Errorf("start service: %w", err)
+	}
+
+	log.ServiceLog("Waiting for SDK to reconnect...")
+	if err := e.WaitForSDKReconnection(); err != nil {
+		log.ServiceLog(fmt.Sprintf("❌ SDK did not reconnect: %v", err))
+		_ = e.StopService()
+		return fmt.Errorf("sdk reconnection: %w", err)
+	}
+	log.ServiceLog("✅ SDK reconnected")
+
+	e.DiscardStartupBuffer()
 	return nil
 }
 
 // StartServer initializes and starts the mock server
 func (e *Executor) StartServer() error {
-	if err := config.Load(""); err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
-	}
-
-	cfg, err := config.Get()
+	server, err := e.newConfiguredServer()
 	if err != nil {
-		return fmt.Errorf("failed to get config: %w", err)
+		return err
 	}
 
-	server, err := NewServer(cfg.Service.ID, &cfg.Service)
-	if err != nil {
-		return fmt.Errorf("failed to create mock server: %w", err)
+	// Environment groups replayed in parallel each need their own socket and port
+	if e.envGroupIndex > 0 {
+		server.SetInstanceLabel(strconv.Itoa(e.envGroupIndex))
+		server.SetTCPPort(0)
 	}
 
 	// Check if TCP port is available before starting
-	if server.GetCommunicationType() == CommunicationTCP {
+	if commType := server.GetCommunicationType(); commType == CommunicationTCP || commType == CommunicationWebSocket {
 		_, tcpPort := server.GetConnectionInfo()
-		if portInUse, err := checkTCPPortAvailable(tcpPort); err == nil && portInUse {
-			return fmt.Errorf("TCP mock port %d is already in use. Please choose a different port in config.yaml (communication.tcp_port)", tcpPort)
+		// Port 0 is allocated dynamically on Start, so there is nothing to check
+		if tcpPort != 0 {
+			if portInUse, err := checkTCPPortAvailable(tcpPort); err == nil && portInUse {
+				return fmt.Errorf("TCP mock port %d is already in use. Please choose a different port in config.yaml (communication.tcp_port)", tcpPort)
+			}
 		}
 	}
 
@@ -143,6 +178,95 @@ func (e *Executor) StartServer() error {
 	e.server = server
 
 	// Apply suite spans immediately so pre-app-start mocks work
+	e.applySuiteSpans(server)
+
+	switch server.GetCommunicationType() {
+	case CommunicationTCP:
+		_, port := server.GetConnectionInfo()
+		log.Debug("Mock server ready", "type", "TCP", "port", port)
+	case CommunicationWebSocket:
+		wsURL, _ := server.GetConnectionInfo()
+		log.Debug("Mock server ready", "type", "WebSocket", "url", wsURL)
+	default:
+		socketPath, _ := server.GetConnectionInfo()
+		log.Debug("Mock server ready", "type", "Unix", "socket", socketPath)
+	}
+
+	return nil
+}
+
+// newConfiguredServer creates a mock server with the matching settings from
+// the config file. The server is not started.
+func (e *Executor) newConfiguredServer() (*Server, error) {
+	if err := config.Load(""); err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+
+	server, err := NewServer(cfg.Service.ID, &cfg.Service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mock server: %w", err)
+	}
+
+	if cfg.TestExecution.MockSearchTimeout != "" {
+		// Already validated for correct duration
+		d, _ := time.ParseDuration(cfg.TestExecution.MockSearchTimeout)
+		server.SetMockSearchTimeout(d)
+	}
+	if cfg.Replay.SimilarityScanLimit > 0 {
+		server.SetSimilarityScanLimit(cfg.Replay.SimilarityScanLimit)
+	}
+	if len(cfg.MockMatching.IgnoreFields) > 0 {
+		server.SetIgnoreFields(cfg.MockMatching.IgnoreFields)
+	}
+	if len(cfg.MockMatching.PrimaryKeys) > 0 {
+		server.SetPrimaryKeys(cfg.MockMatching.PrimaryKeys)
+	}
+	if len(cfg.MockMatching.InjectLatency) > 0 {
+		latency := make(map[string]time.Duration, len(cfg.MockMatching.InjectLatency))
+		for pkg, d := range cfg.MockMatching.InjectLatency {
+			// Already validated for correct duration
+			latency[pkg], _ = time.ParseDuration(d)
+		}
+		server.SetInjectLatency(latency)
+	}
+	if len(cfg.MockMatching.SignificantQueryParams) > 0 {
+		server.SetSignificantQueryParams(cfg.MockMatching.SignificantQueryParams)
+	}
+	if len(cfg.MockMatching.PathTemplates) > 0 {
+		server.SetPathTemplates(cfg.MockMatching.PathTemplates)
+	}
+	if len(cfg.MockMatching.Pins) > 0 {
+		server.SetPins(cfg.MockMatching.Pins)
+	}
+	server.SetPoolIdenticalSpans(cfg.MockMatching.PoolIdenticalSpans)
+	server.SetLenientSchema(cfg.MockMatching.LenientSchema)
+	server.SetAllowSDKVersionMismatch(e.allowSDKVersionMismatch)
+	server.SetQuietMocks(e.quietMocks)
+	server.SetSeed(e.seed)
+	if e.mockMetrics != nil {
+		server.shareMetrics(e.mockMetrics)
+	}
+	server.SetGlobalFallbackHosts(cfg.MockMatching.GlobalFallbackHosts)
+	if cfg.MockMatching.AmbiguityEpsilon != nil {
+		server.SetAmbiguityEpsilon(*cfg.MockMatching.AmbiguityEpsilon)
+	}
+	server.SetSimilarityAlgorithm(cfg.MockMatching.SimilarityAlgorithm)
+	if len(cfg.Logging.RedactFields) > 0 {
+		if err := server.SetRedactFields(cfg.Logging.RedactFields); err != nil {
+			return nil, fmt.Errorf("invalid logging.redact_fields: %w", err)
+		}
+	}
+
+	return server, nil
+}
+
+// applySuiteSpans hands the executor's suite and global spans to server.
+func (e *Executor) applySuiteSpans(server *Server) {
 	if len(e.suiteSpans) > 0 {
 		server.SetSuiteSpans(e.suiteSpans)
 	}
@@ -156,16 +280,6 @@ func (e *Executor) StartServer() error {
 	if e.allowSuiteWideMatching {
 		server.SetAllowSuiteWideMatching(true)
 	}
-
-	if server.GetCommunicationType() == CommunicationTCP {
-		_, port := server.GetConnectionInfo()
-		log.Debug("Mock server ready", "type", "TCP", "port", port)
-	} else {
-		socketPath, _ := server.GetConnectionInfo()
-		log.Debug("Mock server ready", "type", "Unix", "socket", socketPath)
-	}
-
-	return nil
 }
 
 func (e *Executor) StopServer() error {
@@ -176,11 +290,64 @@ func (e *Executor) StopServer() error {
 }
 
 // WaitForSDKAcknowledgement waits for the SDK to acknowledge the connection.
+// A timeout is retried replay.sdk_connect_retries times while the service is
+// still running, for services that are slow to boot.
 func (e *Executor) WaitForSDKAcknowledgement() error {
 	if e.server == nil {
 		return fmt.Errorf("mock server not started")
 	}
+	e.sdkAcknowledgementWait = 0
+
+	retries := 0
+	if cfg, err := config.Get(); err == nil {
+		retries = cfg.Replay.SDKConnectRetries
+	}
+	timeout := sdkAcknowledgementTimeout()
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		log.Debug(fmt.Sprintf("Waiting for SDK acknowledgement from the service (timeout: %v)...", timeout))
+		err := e.server.WaitForSDKConnection(timeout)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, errSDKAcknowledgementTimeout) {
+			return err
+		}
+		if attempt >= retries || e.serviceHasExited() {
+			e.sdkAcknowledgementWait = time.Since(start)
+			if attempt == 0 {
+				return err
+			}
+			return fmt.Errorf("%w (waited %v over %d attempts)", err, e.sdkAcknowledgementWait.Round(time.Millisecond), attempt+1)
+		}
+		log.ServiceLog(fmt.Sprintf("⏳ SDK hasn't connected after %v; waiting again in case the service is still booting (retry %d/%d)...", time.Since(start).Round(time.Second), attempt+1, retries))
+	}
+}
+
+// serviceHasExited reports whether the service process has exited, so there
+// is no point waiting longer for its SDK.
+func (e *Executor) serviceHasExited() bool {
+	if e.serviceExited == nil {
+		return false
+	}
+	select {
+	case <-e.serviceExited:
+		return true
+	default:
+		return false
+	}
+}
+
+// WaitForSDKReconnection waits until the SDK of a restarted service has
+// connected, so the next test doesn't race the new process.
+func (e *Executor) WaitForSDKReconnection() error {
+	if e.server == nil {
+		return fmt.Errorf("mock server not started")
+	}
+	return e.server.WaitForSDKReconnection(sdkAcknowledgementTimeout())
+}
 
+func sdkAcknowledgementTimeout() time.Duration {
 	timeout := 10 * time.Second
 	// Allow tests to override the default wait time
 	if testWait := os.Getenv("TUSK_TEST_DEFAULT_WAIT"); testWait != "" {
@@ -188,13 +355,7 @@ func (e *Executor) WaitForSDKAcknowledgement() error {
 			timeout = parsed
 		}
 	}
-
-	log.Debug(fmt.Sprintf("Waiting for SDK acknowledgement from the service (timeout: %v)...", timeout))
-	err := e.server.WaitForSDKConnection(timeout)
-	if err != nil {
-		return err
-	}
-	return nil
+	return timeout
 }
 
 // Restart constants
@@ -212,8 +373,13 @@ func (e *Executor) RestartServerWithRetry(attempt int) error {
 	attemptNum := attempt + 1
 	log.ServiceLog(fmt.Sprintf("🔄 Attempting to restart server (attempt %d/%d)...", attemptNum, MaxServerRestartAttempts))
 
-	// 1. Force stop existing server/service
-	if err := e.ForceStopEnvironment(); err != nil {
+	// 1. Force stop the service. A running mock server is kept, so the spans
+	// loaded into it survive and the restarted SDK reconnects to it.
+	reuseServer := e.server != nil
+	if reuseServer {
+		e.server.ResetSDKConnection()
+		e.forceStopService()
+	} else if err := e.ForceStopEnvironment(); err != nil {
 		log.Warn("Force stop failed during restart", "error", err)
 	}
 
@@ -226,8 +392,14 @@ func (e *Executor) RestartServerWithRetry(attempt int) error {
 	log.Debug("Waiting before restart", "backoff", backoff, "attempt", attemptNum)
 	time.Sleep(backoff)
 
-	// 3. Restart environment
-	if err := e.StartEnvironment(); err != nil {
+	// 3. Restart the service, or the whole environment without a mock server
+	var err error
+	if reuseServer {
+		err = e.restartService()
+	} else {
+		err = e.StartEnvironment()
+	}
+	if err != nil {
 		log.ServiceLog(fmt.Sprintf("❌ Restart attempt %d failed: %v", attemptNum, err))
 		// Try again with next attempt
 		if attempt+1 < MaxServerRestartAttempts {
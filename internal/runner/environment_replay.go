@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Use-Tusk/tusk-cli/internal/config"
@@ -18,16 +19,26 @@ import (
 //  3. Run tests for that environment
 //  4. Stop environment
 //  5. Clear replay environment variable configuration
+//
+// When the executor's env concurrency is above 1, groups run in parallel, each
+// on its own executor with a separate mock server and service process.
 func ReplayTestsByEnvironment(
 	ctx context.Context,
 	executor *Executor,
 	groups []*EnvironmentGroup,
 ) ([]TestResult, error) {
+	if envConcurrency := executor.GetEnvConcurrency(); envConcurrency > 1 && len(groups) > 1 {
+		if executor.IsCoverageEnabled() {
+			// Coverage snapshots are collected on a single executor
+			log.Debug("Coverage is enabled; replaying environment groups sequentially")
+		} else {
+			return replayEnvironmentGroupsInParallel(executor, groups, envConcurrency)
+		}
+	}
+
 	allResults := make([]TestResult, 0)
 
 	for i, group := range groups {
-		envStart := time.Now()
-
 		log.Debug("Starting replay for environment group",
 			"environment", group.Name,
 			"test_count", len(group.Tests),
@@ -35,79 +46,220 @@ func ReplayTestsByEnvironment(
 			"group_index", i+1,
 			"total_groups", len(groups))
 
-		log.ServiceLog(fmt.Sprintf("Running %d tests for environment: %s", len(group.Tests), group.Name))
-
-		// 1. Configure replay env vars and prepare compose replay override (if needed)
-		cleanup, err := PrepareReplayEnvironmentGroup(executor, group)
+		results, err := replayEnvironmentGroup(executor, group)
 		if err != nil {
-			return allResults, fmt.Errorf("failed to set env vars for %s: %w", group.Name, err)
+			return allResults, err
 		}
+		allResults = append(allResults, results...)
+	}
 
-		// 2. Start environment (server + service)
-		envStartTime := time.Now()
-		if err := executor.StartEnvironment(); err != nil {
-			// Dump startup logs before returning so the caller's help message makes sense
-			startupLogs := executor.GetStartupLogs()
-			if startupLogs != "" {
-				log.ServiceLog("📋 Service startup logs:")
-				for _, line := range strings.Split(strings.TrimRight(startupLogs, "\n"), "\n") {
-					log.ServiceLog(line)
-				}
+	log.Debug("Completed all environment group replays",
+		"total_groups", len(groups),
+		"total_results", len(allResults))
+
+	return allResults, nil
+}
+
+// replayEnvironmentGroupsInParallel replays up to envConcurrency groups at once.
+// Results are returned in group order. On failure, results from the groups that
+// succeeded are returned along with the error of the first failing group.
+func replayEnvironmentGroupsInParallel(
+	executor *Executor,
+	groups []*EnvironmentGroup,
+	envConcurrency int,
+) ([]TestResult, error) {
+	log.Debug("Replaying environment groups in parallel",
+		"total_groups", len(groups),
+		"env_concurrency", envConcurrency)
+
+	groupResults := make([][]TestResult, len(groups))
+	groupErrs := make([]error, len(groups))
+	groupExecutors := make([]*Executor, len(groups))
+
+	sem := make(chan struct{}, envConcurrency)
+	var wg sync.WaitGroup
+	for i, group := range groups {
+		envExecutor := executor.newEnvironmentExecutor(group.Name, i+1)
+		groupExecutors[i] = envExecutor
+		for _, test := range group.Tests {
+			executor.envExecutors.Store(test.TraceID, envExecutor)
+		}
+
+		wg.Add(1)
+		go func(i int, group *EnvironmentGroup) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			log.Debug("Starting replay for environment group",
+				"environment", group.Name,
+				"test_count", len(group.Tests),
+				"env_var_count", len(group.EnvVars),
+				"group_index", i+1,
+				"total_groups", len(groups))
+
+			groupResults[i], groupErrs[i] = replayEnvironmentGroup(groupExecutors[i], group)
+		}(i, group)
+	}
+	wg.Wait()
+
+	allResults := make([]TestResult, 0)
+	var firstErr error
+	for i := range groups {
+		if groupErrs[i] != nil {
+			if firstErr == nil {
+				firstErr = groupErrs[i]
+				// Let the caller surface startup logs from the failing environment
+				executor.serviceLogPath = groupExecutors[i].serviceLogPath
+				executor.startupLogBuffer = groupExecutors[i].startupLogBuffer
 			}
-			cleanup() // Restore env vars before returning
-			return allResults, fmt.Errorf("failed to start environment for %s: %w", group.Name, err)
+			continue
 		}
+		allResults = append(allResults, groupResults[i]...)
+	}
 
-		envStartDuration := time.Since(envStartTime).Seconds()
-		log.ServiceLog(fmt.Sprintf("✓ Environment ready (%.1fs)", envStartDuration))
-		log.Stderrln(fmt.Sprintf("✓ Environment ready (%.1fs)", envStartDuration))
+	log.Debug("Completed all environment group replays",
+		"total_groups", len(groups),
+		"total_results", len(allResults))
 
-		// Coverage: take baseline snapshot to capture all coverable lines and reset counters
-		if executor.IsCoverageEnabled() {
-			baseline, err := executor.TakeCoverageBaseline()
-			if err != nil {
-				log.Warn("Failed to take baseline coverage snapshot", "error", err)
-			} else {
-				executor.SetCoverageBaseline(baseline)
-				log.Debug("Coverage baseline taken (counters reset, all coverable lines captured)")
+	return allResults, firstErr
+}
+
+// replayEnvironmentGroup runs a single environment group on executor: prepare
+// env vars, start the environment, run the group's tests, and stop it again.
+func replayEnvironmentGroup(executor *Executor, group *EnvironmentGroup) ([]TestResult, error) {
+	// Don't start another environment once the run has been cancelled
+	if executor.testsCancelled.Load() {
+		results := make([]TestResult, 0, len(group.Tests))
+		for _, test := range group.Tests {
+			result := TestResult{
+				TestID:      test.TraceID,
+				Passed:      false,
+				Cancelled:   true,
+				Error:       "Test execution interrupted",
+				Environment: group.Name,
 			}
+			results = append(results, result)
+			executor.completeTest(result, test)
 		}
+		return results, nil
+	}
 
-		// 3. Run tests for this environment
-		results, err := executor.RunTests(group.Tests)
-		if err != nil {
-			// Attempt cleanup even on error
-			_ = executor.StopEnvironment()
-			cleanup()
-			return allResults, fmt.Errorf("failed to run tests for %s: %w", group.Name, err)
+	envStart := time.Now()
+
+	log.ServiceLog(fmt.Sprintf("Running %d tests for environment: %s", len(group.Tests), group.Name))
+
+	// 1. Configure replay env vars and prepare compose replay override (if needed)
+	cleanup, err := PrepareReplayEnvironmentGroup(executor, group)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set env vars for %s: %w", group.Name, err)
+	}
+
+	// 2. Start environment (server + service), after the pre-run commands
+	envStartTime := time.Now()
+	err = executor.runPreRunCommand()
+	if err == nil {
+		err = executor.runGroupPreRunCommand(group.Name)
+	}
+	if err == nil {
+		err = executor.StartEnvironment()
+	}
+	if err != nil {
+		// Dump startup logs before returning so the caller's help message makes sense
+		startupLogs := executor.GetStartupLogs()
+		if startupLogs != "" {
+			log.ServiceLog("📋 Service startup logs:")
+			for _, line := range strings.Split(strings.TrimRight(startupLogs, "\n"), "\n") {
+				log.ServiceLog(line)
+			}
 		}
+		cleanup() // Restore env vars before returning
+		return nil, fmt.Errorf("failed to start environment for %s: %w", group.Name, err)
+	}
 
-		// 4. Collect results
-		allResults = append(allResults, results...)
+	envStartDuration := time.Since(envStartTime).Seconds()
+	log.ServiceLog(fmt.Sprintf("✓ Environment ready (%.1fs)", envStartDuration))
+	log.Stderrln(fmt.Sprintf("✓ Environment ready (%.1fs)", envStartDuration))
 
-		// 5. Stop environment
-		if err := executor.StopEnvironment(); err != nil {
-			log.Warn("Failed to stop environment cleanly",
-				"environment", group.Name,
-				"error", err)
-			log.ServiceLog(fmt.Sprintf("⚠️  Warning: failed to stop environment for %s: %v", group.Name, err))
+	// Coverage: take baseline snapshot to capture all coverable lines and reset counters
+	if executor.IsCoverageEnabled() {
+		baseline, err := executor.TakeCoverageBaseline()
+		if err != nil {
+			log.Warn("Failed to take baseline coverage snapshot", "error", err)
+		} else {
+			executor.SetCoverageBaseline(baseline)
+			log.Debug("Coverage baseline taken (counters reset, all coverable lines captured)")
 		}
+	}
 
-		// 6. Restore environment variables
+	// 3. Run tests for this environment
+	executor.environment = group.Name
+	results, err := executor.RunTests(group.Tests)
+	executor.environment = ""
+	for i := range results {
+		results[i].Environment = group.Name
+	}
+	if err != nil {
+		// Attempt cleanup even on error
+		_ = executor.StopEnvironment()
 		cleanup()
+		return nil, fmt.Errorf("failed to run tests for %s: %w", group.Name, err)
+	}
 
-		envDuration := time.Since(envStart).Seconds()
-		log.Debug("Completed replay for environment group",
+	// 4. Stop environment
+	if err := executor.StopEnvironment(); err != nil {
+		log.Warn("Failed to stop environment cleanly",
 			"environment", group.Name,
-			"results_count", len(results),
-			"duration_seconds", envDuration)
+			"error", err)
+		log.ServiceLog(fmt.Sprintf("⚠️  Warning: failed to stop environment for %s: %v", group.Name, err))
 	}
 
-	log.Debug("Completed all environment group replays",
-		"total_groups", len(groups),
-		"total_results", len(allResults))
+	// 5. Restore environment variables
+	cleanup()
 
-	return allResults, nil
+	envDuration := time.Since(envStart).Seconds()
+	log.Debug("Completed replay for environment group",
+		"environment", group.Name,
+		"results_count", len(results),
+		"duration_seconds", envDuration)
+
+	return results, nil
+}
+
+// newEnvironmentExecutor returns an executor with e's settings and callbacks but
+// its own mock server and service, for replaying one environment group in parallel.
+// groupIndex is 1-based and selects the group's socket name and service port.
+func (e *Executor) newEnvironmentExecutor(groupName string, groupIndex int) *Executor {
+	return &Executor{
+		envGroupIndex:           groupIndex,
+		serviceURL:              e.serviceURL,
+		parallel:                e.parallel,
+		repeat:                  e.repeat,
+		seed:                    e.seed,
+		testTimeout:             e.testTimeout,
+		enableServiceLogs:       e.enableServiceLogs,
+		serviceLogLabel:         groupName,
+		resultsDir:              e.resultsDir,
+		ResultsFile:             e.ResultsFile,
+		OnTestCompleted:         e.OnTestCompleted,
+		OnRepeatRunCompleted:    e.OnRepeatRunCompleted,
+		suiteSpans:              e.suiteSpans,
+		globalSpans:             e.globalSpans,
+		allowSuiteWideMatching:  e.allowSuiteWideMatching,
+		sandboxMode:             e.sandboxMode,
+		allowSDKVersionMismatch: e.allowSDKVersionMismatch,
+		quietMocks:              e.quietMocks,
+		debug:                   e.debug,
+		requireInboundReplay:    e.requireInboundReplay,
+		strictTraceMatching:     e.strictTraceMatching,
+		crashRetries:            e.crashRetries,
+		replaySandboxConfigPath: e.replaySandboxConfigPath,
+		envFileVars:             e.envFileVars,
+		failureLimit:            e.failureLimit,
+		mockMetrics:             e.mockMetrics,
+		telemetry:               e.telemetry,
+		preRun:                  e.preRun,
+	}
 }
 
 // PrepareReplayEnvironmentGroup sets recorded env vars on the process and, if applicable,
@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// EnvironmentGroupReport describes one environment group for
+// --include-preappstart-report. Only env var keys are reported; values may
+// hold secrets.
+type EnvironmentGroupReport struct {
+	Name          string
+	Traces        int
+	EnvVarsSpanID string // ENV_VARS span the keys came from
+	EnvVarKeys    []string
+}
+
+// ReportEnvironmentGroups summarizes how tests were grouped by environment
+// and which env var keys were extracted for each group, sorted by name.
+func ReportEnvironmentGroups(result *EnvironmentExtractionResult) []EnvironmentGroupReport {
+	if result == nil {
+		return nil
+	}
+	reports := make([]EnvironmentGroupReport, 0, len(result.Groups))
+	for _, group := range result.Groups {
+		report := EnvironmentGroupReport{
+			Name:       group.Name,
+			Traces:     len(group.Tests),
+			EnvVarKeys: slices.Sorted(maps.Keys(group.EnvVars)),
+		}
+		if group.EnvVarsSpan != nil {
+			report.EnvVarsSpanID = group.EnvVarsSpan.SpanId
+		}
+		reports = append(reports, report)
+	}
+	slices.SortFunc(reports, func(a, b EnvironmentGroupReport) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return reports
+}
+
+// WriteEnvironmentGroupsReport writes reports as text.
+func WriteEnvironmentGroupsReport(w io.Writer, reports []EnvironmentGroupReport) error {
+	if _, err := fmt.Fprintf(w, "Environment groups (%d):\n", len(reports)); err != nil {
+		return err
+	}
+	for _, r := range reports {
+		line := fmt.Sprintf("  %s: %d traces, no ENV_VARS span", r.Name, r.Traces)
+		if r.EnvVarsSpanID != "" {
+			line = fmt.Sprintf("  %s: %d traces, %d env vars from span %s", r.Name, r.Traces, len(r.EnvVarKeys), r.EnvVarsSpanID)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		if len(r.EnvVarKeys) > 0 {
+			if _, err := fmt.Fprintf(w, "    %s\n", strings.Join(r.EnvVarKeys, ", ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
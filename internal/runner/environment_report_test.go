@@ -0,0 +1,46 @@
+package runner
+
+import (
+	"bytes"
+	"testing"
+
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestReportEnvironmentGroups(t *testing.T) {
+	envVarsSpan := &core.Span{
+		SpanId:        "env-span-1",
+		PackageName:   "process.env",
+		IsPreAppStart: true,
+		Environment:   proto.String("production"),
+		OutputValue: toStruct(t, map[string]any{
+			"ENV_VARS": map[string]any{"NODE_ENV": "production", "DB_PASSWORD": "hunter2", "API_URL": "https://api.example.com"},
+		}),
+	}
+	tests := []Test{
+		{TraceID: "t1", Environment: "production"},
+		{TraceID: "t2", Environment: "production"},
+		{TraceID: "t3"},
+	}
+	result, err := GroupTestsByEnvironment(tests, []*core.Span{envVarsSpan})
+	require.NoError(t, err)
+
+	reports := ReportEnvironmentGroups(result)
+	assert.Equal(t, []EnvironmentGroupReport{
+		{Name: "default", Traces: 1},
+		{Name: "production", Traces: 2, EnvVarsSpanID: "env-span-1", EnvVarKeys: []string{"API_URL", "DB_PASSWORD", "NODE_ENV"}},
+	}, reports)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteEnvironmentGroupsReport(&buf, reports))
+	out := buf.String()
+	assert.Contains(t, out, "Environment groups (2):")
+	assert.Contains(t, out, "default: 1 traces, no ENV_VARS span")
+	assert.Contains(t, out, "production: 2 traces, 3 env vars from span env-span-1")
+	assert.Contains(t, out, "API_URL, DB_PASSWORD, NODE_ENV")
+	assert.NotContains(t, out, "hunter2", "values are never printed")
+	assert.NotContains(t, out, "https://api.example.com")
+}
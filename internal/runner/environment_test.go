@@ -2,6 +2,7 @@ package runner
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"os"
 	"os/exec"
@@ -454,6 +455,73 @@ func TestWaitForSDKAcknowledgement(t *testing.T) {
 	}
 }
 
+func TestWaitForSDKAcknowledgementRetriesAfterTimeout(t *testing.T) {
+	t.Setenv("TUSK_TEST_DEFAULT_WAIT", "200ms")
+	t.Cleanup(config.Invalidate)
+
+	newExecutor := func(t *testing.T, yaml string) (*Executor, *Server) {
+		t.Helper()
+		config.Invalidate()
+		require.NoError(t, config.Load(writeTempConfig(t, yaml)))
+		cfg, _ := config.Get()
+		server, err := NewServer("test", &cfg.Service)
+		require.NoError(t, err)
+		require.NoError(t, server.Start())
+		t.Cleanup(func() { _ = server.Stop() })
+		e := NewExecutor()
+		e.server = server
+		return e, server
+	}
+
+	t.Run("connection after the first timeout succeeds", func(t *testing.T) {
+		e, server := newExecutor(t, "replay:\n  sdk_connect_retries: 2\n")
+
+		// Connect just after the first 200ms wait has timed out
+		go func() {
+			time.Sleep(300 * time.Millisecond)
+			server.mu.Lock()
+			if !server.sdkConnected {
+				server.sdkConnected = true
+				close(server.sdkConnectedChan)
+			}
+			server.mu.Unlock()
+		}()
+
+		require.NoError(t, e.WaitForSDKAcknowledgement())
+		assert.Empty(t, e.GetStartupFailureHelpMessage())
+	})
+
+	t.Run("failure reports elapsed time and service output", func(t *testing.T) {
+		e, _ := newExecutor(t, "replay:\n  sdk_connect_retries: 1\n")
+		e.startupLogBuffer = &syncBuffer{}
+		for i := 1; i <= 12; i++ {
+			_, _ = fmt.Fprintf(e.startupLogBuffer, "boot step %d\n", i)
+		}
+
+		err := e.WaitForSDKAcknowledgement()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "over 2 attempts")
+
+		help := e.GetStartupFailureHelpMessage()
+		assert.Contains(t, help, "The SDK didn't connect within")
+		assert.Contains(t, help, "boot step 3\n")
+		assert.Contains(t, help, "boot step 12\n")
+		assert.NotContains(t, help, "boot step 2\n")
+	})
+
+	t.Run("exited service is not waited on again", func(t *testing.T) {
+		e, _ := newExecutor(t, "replay:\n  sdk_connect_retries: 5\n")
+		e.serviceExited = make(chan struct{})
+		close(e.serviceExited)
+
+		start := time.Now()
+		err := e.WaitForSDKAcknowledgement()
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.NotContains(t, err.Error(), "attempts")
+	})
+}
+
 func TestStartServerWithSuiteSpans(t *testing.T) {
 	config.Invalidate()
 
@@ -593,3 +661,208 @@ service:
 	err = newServer.Start()
 	assert.NoError(t, err)
 }
+
+func TestStartServerTCPModeDynamicPort(t *testing.T) {
+	config.Invalidate()
+
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "tusk.yaml")
+	configContent := `
+service:
+  id: test-tcp-dynamic
+  port: 3000
+  start:
+    command: "docker compose up"
+  communication:
+    type: tcp
+    tcp_port: 0
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
+	require.NoError(t, config.Load(configPath))
+
+	first := NewExecutor()
+	require.NoError(t, first.StartServer())
+	defer func() { _ = first.StopServer() }()
+
+	second := NewExecutor()
+	require.NoError(t, second.StartServer())
+	defer func() { _ = second.StopServer() }()
+
+	_, firstPort := first.server.GetConnectionInfo()
+	_, secondPort := second.server.GetConnectionInfo()
+	assert.NotZero(t, firstPort)
+	assert.NotZero(t, secondPort)
+	assert.NotEqual(t, firstPort, secondPort, "each mock server should get its own port")
+}
+
+func TestNewEnvironmentExecutorAndExecutorForTrace(t *testing.T) {
+	e := NewExecutor()
+	e.SetConcurrency(3)
+	assert.Equal(t, 1, e.GetEnvConcurrency())
+	e.SetEnvConcurrency(4)
+	assert.Equal(t, 4, e.GetEnvConcurrency())
+	e.SetEnvConcurrency(0)
+	assert.Equal(t, 4, e.GetEnvConcurrency(), "non-positive values are ignored")
+
+	called := false
+	e.SetOnTestCompleted(func(TestResult, Test) { called = true })
+
+	envExecutor := e.newEnvironmentExecutor("staging", 2)
+	assert.Equal(t, 3, envExecutor.GetConcurrency())
+	assert.Equal(t, 2, envExecutor.envGroupIndex)
+	assert.Equal(t, "staging", envExecutor.serviceLogLabel)
+	assert.Nil(t, envExecutor.GetServer())
+	require.NotNil(t, envExecutor.OnTestCompleted)
+	envExecutor.OnTestCompleted(TestResult{}, Test{})
+	assert.True(t, called)
+
+	e.envExecutors.Store("trace-1", envExecutor)
+	assert.Same(t, envExecutor, e.ExecutorForTrace("trace-1"))
+	assert.Same(t, e, e.ExecutorForTrace("trace-unknown"))
+}
+
+func TestStartServer_ParallelEnvironmentGroupsUseSeparateTransports(t *testing.T) {
+	for _, commType := range []string{"unix", "tcp"} {
+		t.Run(commType, func(t *testing.T) {
+			config.Invalidate()
+
+			tempDir := t.TempDir()
+			t.Chdir(tempDir)
+			configPath := filepath.Join(tempDir, "tusk.yaml")
+			configContent := fmt.Sprintf(`
+service:
+  id: test-parallel-transport
+  port: 3000
+  start:
+    command: "npm start"
+  communication:
+    type: %s
+    tcp_port: 0
+`, commType)
+			require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
+			require.NoError(t, config.Load(configPath))
+
+			parent := NewExecutor()
+			first := parent.newEnvironmentExecutor("a", 1)
+			second := parent.newEnvironmentExecutor("b", 2)
+			require.NoError(t, first.StartServer())
+			defer func() { _ = first.StopServer() }()
+			require.NoError(t, second.StartServer())
+			defer func() { _ = second.StopServer() }()
+
+			firstSocket, firstPort := first.server.GetConnectionInfo()
+			secondSocket, secondPort := second.server.GetConnectionInfo()
+			if commType == "unix" {
+				assert.NotEqual(t, firstSocket, secondSocket, "each group should get its own socket")
+				_, err := os.Stat(firstSocket)
+				assert.NoError(t, err, "starting the second group must not remove the first socket")
+			} else {
+				assert.NotEqual(t, firstPort, secondPort, "each group should get its own mock port")
+			}
+		})
+	}
+}
+
+func TestParallelEnvironmentGroupsUseConsecutiveServicePorts(t *testing.T) {
+	parent := NewExecutor()
+	assert.Equal(t, 3000, parent.groupServicePort(3000))
+	assert.Equal(t, 3000, parent.newEnvironmentExecutor("a", 1).groupServicePort(3000))
+	assert.Equal(t, 3002, parent.newEnvironmentExecutor("c", 3).groupServicePort(3000))
+
+	envExecutor := parent.newEnvironmentExecutor("b", 2)
+	envExecutor.servicePort = envExecutor.groupServicePort(3000)
+	env := envExecutor.buildCommandEnv()
+	assert.Contains(t, env, "PORT=3001")
+	assert.Contains(t, env, "TUSK_SERVICE_PORT=3001")
+
+	parent.servicePort = 3000
+	assert.NotContains(t, parent.buildCommandEnv(), "TUSK_SERVICE_PORT=3000", "sequential replay leaves the environment alone")
+}
+
+func TestReplayTestsByEnvironmentLabelsResults(t *testing.T) {
+	executor := NewExecutor()
+	streamed := map[string]string{}
+	executor.SetOnTestCompleted(func(res TestResult, test Test) {
+		streamed[res.TestID] = res.Environment
+	})
+	// A cancelled run reports every test without starting an environment
+	executor.testsCancelled.Store(true)
+
+	results, err := ReplayTestsByEnvironment(context.Background(), executor, []*EnvironmentGroup{
+		{Name: "production", Tests: []Test{{TraceID: "prod-1"}, {TraceID: "prod-2"}}},
+		{Name: "staging", Tests: []Test{{TraceID: "staging-1"}}},
+	})
+	require.NoError(t, err)
+
+	got := map[string]string{}
+	for _, r := range results {
+		got[r.TestID] = r.Environment
+	}
+	want := map[string]string{"prod-1": "production", "prod-2": "production", "staging-1": "staging"}
+	assert.Equal(t, want, got)
+	assert.Equal(t, want, streamed)
+}
+
+func TestCompleteTestStampsEnvironment(t *testing.T) {
+	executor := NewExecutor()
+	var streamed TestResult
+	executor.SetOnTestCompleted(func(res TestResult, test Test) { streamed = res })
+
+	executor.environment = "staging"
+	executor.completeTest(TestResult{TestID: "trace-1", Passed: true}, Test{TraceID: "trace-1"})
+	assert.Equal(t, "staging", streamed.Environment)
+
+	executor.environment = ""
+	executor.completeTest(TestResult{TestID: "trace-2", Passed: true}, Test{TraceID: "trace-2"})
+	assert.Empty(t, streamed.Environment)
+}
+
+func TestRestartServiceKeepsMockServerAndWaitsForReconnect(t *testing.T) {
+	config.Invalidate()
+	t.Cleanup(config.Invalidate)
+
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "tusk.yaml")
+	configContent := fmt.Sprintf(`
+service:
+  id: test-restart-reuse
+  port: 14006
+  start:
+    command: "%s"
+  readiness_check:
+    command: "true"
+    timeout: "2s"
+    interval: "100ms"
+  communication:
+    type: tcp
+    tcp_port: 0
+`, getMediumSleepCommand())
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
+	require.NoError(t, config.Load(configPath))
+
+	e := newExecutorForServiceLifecycleTests()
+	require.NoError(t, e.StartServer())
+	defer func() { _ = e.StopEnvironment() }()
+	server := e.server
+	_, port := server.GetConnectionInfo()
+
+	first := connectSDK(t, port)
+	defer func() { _ = first.Close() }()
+	require.NoError(t, e.WaitForSDKAcknowledgement())
+
+	// The restart path forgets the old connection before starting the service
+	server.ResetSDKConnection()
+	done := make(chan error, 1)
+	go func() { done <- e.restartService() }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("restartService returned before the SDK reconnected: %v", err)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	second := connectSDK(t, port)
+	defer func() { _ = second.Close() }()
+	require.NoError(t, <-done)
+	assert.Same(t, server, e.server, "the mock server is reused across the restart")
+}
@@ -6,17 +6,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"math"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Use-Tusk/tusk-cli/internal/config"
 	"github.com/Use-Tusk/tusk-cli/internal/log"
+	"github.com/Use-Tusk/tusk-cli/internal/tui/styles"
 	"github.com/Use-Tusk/tusk-cli/internal/utils"
 	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
 )
@@ -25,6 +29,7 @@ const (
 	requireInboundReplaySpanEnvVar = "TUSK_REQUIRE_INBOUND_REPLAY_SPAN"
 	inboundSpanCheckTimeout        = 3 * time.Second
 	inboundSpanDeviationField      = "replay.inbound_span"
+	mockScopeDeviationField        = "replay.mock_scope"
 )
 
 const (
@@ -66,31 +71,56 @@ func (s *syncBuffer) Discard() {
 type Executor struct {
 	serviceURL              string
 	parallel                int
+	envConcurrency          int
+	repeat                  int   // times each test is run; results are aggregated
+	seed                    int64 // passed to mock servers for randomized matcher choices
+	liveMode                bool  // service runs with the SDK disabled and no sandbox (tusk drift selfcheck)
 	testTimeout             time.Duration
 	serviceCmd              *exec.Cmd
 	server                  *Server
 	serviceLogFile          *os.File
-	serviceLogPath          string      // persists across StopService so GetStartupLogs can read it back
-	startupLogBuffer        *syncBuffer // in-memory buffer when --enable-service-logs is off
-	processExitCh           chan error  // signals early process exit
+	serviceLogPath          string        // persists across StopService so GetStartupLogs can read it back
+	serviceLogLabel         string        // distinguishes log files of environments replayed in parallel
+	startupLogBuffer        *syncBuffer   // in-memory buffer when --enable-service-logs is off
+	processExitCh           chan error    // signals early process exit
+	serviceExited           chan struct{} // closed once the service process has been reaped
 	enableServiceLogs       bool
 	servicePort             int
 	resultsDir              string
 	ResultsFile             string // Will be set by the run command if --save-results is true
 	OnTestCompleted         func(TestResult, Test)
+	OnRepeatRunCompleted    func(result TestResult, test Test, run int) // called after each run when --repeat > 1
 	suiteSpans              []*core.Span
 	globalSpans             []*core.Span // Explicitly marked global spans for cross-trace matching
 	allowSuiteWideMatching  bool         // When true, allows cross-trace matching from any suite span
 	cancelTests             context.CancelFunc
-	sandboxBypass           bool // Internal runtime bypass used by auto-mode fallback retry
+	testsCancelled          atomic.Bool // set by CancelTests; stops --repeat runs early
+	sandboxBypass           bool        // Internal runtime bypass used by auto-mode fallback retry
 	sandboxMode             string
+	allowSDKVersionMismatch bool
+	quietMocks              bool
 	lastServiceSandboxed    bool
+	failedReadinessProbe    string        // probe that timed out during the last service start
+	sdkAcknowledgementWait  time.Duration // how long the last failed SDK acknowledgement wait took
 	debug                   bool
 	sandbox                 sandboxManager
 	requireInboundReplay    bool
+	strictTraceMatching     bool                    // --strict-trace-matching
+	crashRetries            int                     // test_execution.crash_retries
+	restartServerFn         func(attempt int) error // replaces RestartServerWithRetry in tests
 	replayComposeOverride   string
 	replayEnvVars           map[string]string
+	envFileVars             map[string]string // from --env-file; override recorded env vars
 	replaySandboxConfigPath string
+	failureLimit            *failureLimit  // set by SetMaxFailures; shared with environment executors
+	mockMetrics             *mockMetrics   // shared by every mock server the executor creates
+	telemetry               *runTelemetry  // shared with environment executors
+	preRun                  *preRunState   // service.pre_run.command; shared with environment executors
+	resultsRedactor         *fieldRedactor // results.redact; nil when disabled
+	resultsMatchEvents      *MatchReport   // results.include_match_events; nil when disabled
+	envExecutors            sync.Map       // traceID -> *Executor during parallel environment replay
+	envGroupIndex           int            // 1-based group index when replaying environment groups in parallel, else 0
+	environment             string         // environment group being replayed, stamped on results
 
 	// Coverage
 	coverageEnabled         bool
@@ -109,14 +139,25 @@ type Executor struct {
 }
 
 func NewExecutor() *Executor {
+	resultsRedactor, _ := newFieldRedactor(nil)
 	return &Executor{
 		serviceURL:           "http://localhost:3000",
 		parallel:             5,
 		testTimeout:          30 * time.Second,
 		requireInboundReplay: isTruthyEnv(os.Getenv(requireInboundReplaySpanEnvVar)),
+		mockMetrics:          &mockMetrics{},
+		telemetry:            &runTelemetry{},
+		preRun:               &preRunState{},
+		resultsRedactor:      resultsRedactor,
 	}
 }
 
+// GetMockMetrics returns the mock request counters of every mock server the
+// executor has run, including those of environment groups.
+func (e *Executor) GetMockMetrics() MockMetrics {
+	return e.mockMetrics.snapshot()
+}
+
 // SetSandboxMode configures replay sandbox behavior.
 // Supported values: auto, strict, off.
 func (e *Executor) SetSandboxMode(mode string) error {
@@ -147,6 +188,33 @@ func (e *Executor) GetEffectiveSandboxMode() string {
 	return SandboxModeAuto
 }
 
+// SetAllowSDKVersionMismatch lets SDKs older than version.MinSDKVersion
+// connect to the mock servers the executor creates, with a warning.
+func (e *Executor) SetAllowSDKVersionMismatch(allow bool) {
+	e.allowSDKVersionMismatch = allow
+}
+
+// SetQuietMocks stops per-request mock matching messages from being logged
+// to tests (--quiet-mocks).
+func (e *Executor) SetQuietMocks(quiet bool) {
+	e.quietMocks = quiet
+}
+
+// SetStrictTraceMatching fails tests whose outbound calls were mocked with
+// spans recorded by other traces (--strict-trace-matching). Pre-app-start
+// calls are exempt, since they are never part of the trace being replayed.
+func (e *Executor) SetStrictTraceMatching(strict bool) {
+	e.strictTraceMatching = strict
+}
+
+// SetCrashRetries sets how many times a test that crashes the service is
+// retried, after restarting the service, before it is marked failed.
+func (e *Executor) SetCrashRetries(retries int) {
+	if retries >= 0 {
+		e.crashRetries = retries
+	}
+}
+
 // SetDebug enables debug mode for fence sandbox
 func (e *Executor) SetDebug(debug bool) {
 	e.debug = debug
@@ -167,6 +235,14 @@ func (e *Executor) SetReplayEnvVars(envVars map[string]string) {
 	e.replayEnvVars = copied
 }
 
+// SetEnvFileVars configures environment variables, typically loaded from a
+// dotenv file, to inject into every replay service subprocess. They take
+// precedence over recorded env vars. This does not mutate the CLI process
+// environment.
+func (e *Executor) SetEnvFileVars(envVars map[string]string) {
+	e.envFileVars = maps.Clone(envVars)
+}
+
 func (e *Executor) getReplayEnvVars() map[string]string {
 	if len(e.replayEnvVars) == 0 {
 		return nil
@@ -221,17 +297,15 @@ func (e *Executor) runTestsWithResilience(tests []Test) ([]TestResult, error) {
 		if !serverCrashed {
 			// No crash detected - invoke callbacks manually for all results
 			log.Debug("Batch completed successfully, no crash detected", "batch_size", len(batch))
-			if e.OnTestCompleted != nil {
-				// Create a map of tests by TraceID for matching
-				testsByID := make(map[string]Test, len(batch))
-				for _, test := range batch {
-					testsByID[test.TraceID] = test
-				}
-				// Invoke callbacks with correct test for each result
-				for _, result := range results {
-					if test, found := testsByID[result.TestID]; found {
-						e.OnTestCompleted(result, test)
-					}
+			// Create a map of tests by TraceID for matching
+			testsByID := make(map[string]Test, len(batch))
+			for _, test := range batch {
+				testsByID[test.TraceID] = test
+			}
+			// Invoke callbacks with correct test for each result
+			for _, result := range results {
+				if test, found := testsByID[result.TestID]; found {
+					e.completeTest(result, test)
 				}
 			}
 			allResults = append(allResults, results...)
@@ -242,7 +316,7 @@ func (e *Executor) runTestsWithResilience(tests []Test) ([]TestResult, error) {
 		// Callbacks will fire during sequential execution from each test
 		log.ServiceLog(fmt.Sprintf("❌  Server crashed during batch execution. Restarting and retrying %d tests sequentially...", len(batch)))
 
-		if err := e.RestartServerWithRetry(0); err != nil {
+		if err := e.restartServer(0); err != nil {
 			// Can't restart - mark all remaining tests as failed
 			log.ServiceLog(fmt.Sprintf("❌ Failed to restart server: %v", err))
 			log.ServiceLog("Marking all remaining tests as failed")
@@ -257,9 +331,7 @@ func (e *Executor) runTestsWithResilience(tests []Test) ([]TestResult, error) {
 				}
 				allResults = append(allResults, result)
 				// Invoke callback for these failed results
-				if e.OnTestCompleted != nil {
-					e.OnTestCompleted(result, tests[j])
-				}
+				e.completeTest(result, tests[j])
 			}
 			return allResults, nil
 		}
@@ -285,6 +357,10 @@ func (e *Executor) RunTestsConcurrently(tests []Test, maxConcurrency int) ([]Tes
 
 	// Store cancel function so signal handler can call it
 	e.cancelTests = cancel
+	if e.testsCancelled.Load() {
+		// Cancelled before this batch started, e.g. by --max-failures
+		cancel()
+	}
 
 	testChan := make(chan Test, len(tests))
 	resultChan := make(chan TestResult, len(tests))
@@ -390,14 +466,36 @@ func (e *Executor) RunBatchSequentialWithCrashHandling(batch []Test, hasMoreTest
 	consecutiveRestartAttempt := 0
 
 	for idx, test := range batch {
+		if e.testsCancelled.Load() {
+			result := TestResult{
+				TestID:    test.TraceID,
+				Passed:    false,
+				Cancelled: true,
+				Error:     "Test execution interrupted",
+			}
+			results = append(results, result)
+			e.completeTest(result, test)
+			continue
+		}
+
 		log.Debug("Running test sequentially", "index", idx+1, "total", len(batch), "testID", test.TraceID)
 		log.ServiceLog(fmt.Sprintf("Running test %d/%d sequentially: %s", idx+1, len(batch), test.TraceID))
 
 		result, err := e.RunSingleTest(test)
+		crashed := err != nil && !e.CheckServerHealth()
+		for retry := 1; crashed && retry <= e.crashRetries; retry++ {
+			log.ServiceLog(fmt.Sprintf("⚠️  Test %s crashed the server. Restarting and retrying (%d/%d)...", test.TraceID, retry, e.crashRetries))
+			if restartErr := e.restartServer(0); restartErr != nil {
+				log.ServiceLog(fmt.Sprintf("❌ Failed to restart server: %v", restartErr))
+				break
+			}
+			result, err = e.RunSingleTest(test)
+			crashed = err != nil && !e.CheckServerHealth()
+		}
 		result.RetriedAfterCrash = true
 
 		// Check if this test crashed the server
-		if err != nil && !e.CheckServerHealth() {
+		if crashed {
 			log.Warn("Test crashed the server", "testID", test.TraceID, "error", err)
 			log.ServiceLog(fmt.Sprintf("⚠️  Test %s crashed the server", test.TraceID))
 
@@ -407,7 +505,7 @@ func (e *Executor) RunBatchSequentialWithCrashHandling(batch []Test, hasMoreTest
 			shouldRestart := (idx < len(batch)-1) || hasMoreTestsAfterBatch
 			if shouldRestart {
 				log.ServiceLog("Restarting server for next test...")
-				if restartErr := e.RestartServerWithRetry(consecutiveRestartAttempt); restartErr != nil {
+				if restartErr := e.restartServer(consecutiveRestartAttempt); restartErr != nil {
 					consecutiveRestartAttempt++
 					// If multiple tests in a row crash the server, we need to mark the remaining tests as failed
 					if consecutiveRestartAttempt >= MaxServerRestartAttempts {
@@ -422,9 +520,7 @@ func (e *Executor) RunBatchSequentialWithCrashHandling(batch []Test, hasMoreTest
 							}
 							results = append(results, failedResult)
 							// Invoke callback for these failed results
-							if e.OnTestCompleted != nil {
-								e.OnTestCompleted(failedResult, batch[j])
-							}
+							e.completeTest(failedResult, batch[j])
 						}
 						break
 					}
@@ -440,14 +536,37 @@ func (e *Executor) RunBatchSequentialWithCrashHandling(batch []Test, hasMoreTest
 		results = append(results, result)
 
 		// Invoke callback for this test result
-		if e.OnTestCompleted != nil {
-			e.OnTestCompleted(result, test)
-		}
+		e.completeTest(result, test)
 	}
 
 	return results
 }
 
+func (e *Executor) restartServer(attempt int) error {
+	if e.restartServerFn != nil {
+		return e.restartServerFn(attempt)
+	}
+	return e.RestartServerWithRetry(attempt)
+}
+
+// SetRepeat sets how many times each test is run. Results of repeated runs are
+// aggregated into one TestResult with a pass count.
+func (e *Executor) SetRepeat(n int) {
+	if n > 0 {
+		e.repeat = n
+	}
+}
+
+// SetSeed sets the seed for any randomized choices made by the mock matcher,
+// so a run can be reproduced exactly.
+func (e *Executor) SetSeed(seed int64) {
+	e.seed = seed
+}
+
+func (e *Executor) GetRepeat() int {
+	return max(e.repeat, 1)
+}
+
 // GetConcurrency returns the current concurrency setting
 func (e *Executor) GetConcurrency() int {
 	return e.parallel
@@ -465,8 +584,8 @@ func (e *Executor) GetSuiteSpans() []*core.Span {
 
 // WaitForSpanData blocks briefly until inbound or match events are recorded for a test
 func (e *Executor) WaitForSpanData(traceID string, timeout time.Duration) {
-	if e.server != nil {
-		e.server.WaitForSpanData(traceID, timeout)
+	if server := e.serverForTrace(traceID); server != nil {
+		server.WaitForSpanData(traceID, timeout)
 	}
 }
 
@@ -477,16 +596,78 @@ func (e *Executor) SetConcurrency(concurrency int) {
 	}
 }
 
+// SetEnvConcurrency sets how many environment groups ReplayTestsByEnvironment
+// may run at once.
+func (e *Executor) SetEnvConcurrency(concurrency int) {
+	if concurrency > 0 {
+		e.envConcurrency = concurrency
+	}
+}
+
+// GetEnvConcurrency returns the environment group concurrency (at least 1)
+func (e *Executor) GetEnvConcurrency() int {
+	return max(e.envConcurrency, 1)
+}
+
+// ExecutorForTrace returns the executor that replays traceID. During parallel
+// environment replay each group runs on its own executor and mock server, so
+// OnTestCompleted callbacks should read server state through this.
+func (e *Executor) ExecutorForTrace(traceID string) *Executor {
+	if envExecutor, ok := e.envExecutors.Load(traceID); ok {
+		return envExecutor.(*Executor)
+	}
+	return e
+}
+
+// serverForTrace returns the mock server that recorded traceID's replay state,
+// or nil if there is none. Safe to call on a nil executor.
+func (e *Executor) serverForTrace(traceID string) *Server {
+	if e == nil {
+		return nil
+	}
+	return e.ExecutorForTrace(traceID).server
+}
+
 func (e *Executor) SetTestTimeout(timeout time.Duration) {
 	if timeout > 0 {
 		e.testTimeout = timeout
 	}
 }
 
+// metadataTimeoutKey is the root span metadata key holding a per-test timeout
+// that overrides the global one, either a duration string ("2m") or a number
+// of seconds.
+const metadataTimeoutKey = "test_timeout"
+
+// timeoutForTest returns the timeout recorded in the test's metadata, or the
+// global test timeout when none (or an invalid one) is recorded.
+func (e *Executor) timeoutForTest(test Test) time.Duration {
+	var timeout time.Duration
+	switch v := test.Metadata[metadataTimeoutKey].(type) {
+	case nil:
+		return e.testTimeout
+	case string:
+		timeout, _ = time.ParseDuration(v)
+	case float64:
+		timeout = time.Duration(v * float64(time.Second))
+	}
+	if timeout <= 0 {
+		log.Debug("Ignoring invalid test timeout in trace metadata", "traceID", test.TraceID, "value", test.Metadata[metadataTimeoutKey])
+		return e.testTimeout
+	}
+	return timeout
+}
+
 func (e *Executor) SetOnTestCompleted(callback func(TestResult, Test)) {
 	e.OnTestCompleted = callback
 }
 
+// SetOnRepeatRunCompleted sets a callback invoked after every individual run of
+// a repeated test (run is 1-based), before the next run resets the trace state.
+func (e *Executor) SetOnRepeatRunCompleted(callback func(result TestResult, test Test, run int)) {
+	e.OnRepeatRunCompleted = callback
+}
+
 func (e *Executor) SetCoverageEnabled(enabled bool) {
 	e.coverageEnabled = enabled
 }
@@ -682,11 +863,26 @@ func (e *Executor) SetAllowSuiteWideMatching(enabled bool) {
 }
 
 func (e *Executor) CancelTests() {
+	e.envExecutors.Range(func(_, envExecutor any) bool {
+		if envExecutor != e {
+			envExecutor.(*Executor).CancelTests()
+		}
+		return true
+	})
+	e.testsCancelled.Store(true)
 	if e.cancelTests != nil {
 		e.cancelTests()
 	}
 }
 
+// ResetSpanUsage marks every span loaded on the mock server unused, for
+// re-running tests against a server that stays up (--watch).
+func (e *Executor) ResetSpanUsage() {
+	if e.server != nil {
+		e.server.ResetSpanUsage()
+	}
+}
+
 func (e *Executor) IsServiceLogsEnabled() bool {
 	return e.enableServiceLogs
 }
@@ -699,18 +895,16 @@ func (e *Executor) CheckServerHealth() bool {
 		return false
 	}
 
-	// Use readiness command if configured
-	if cfg.Service.Readiness.Command != "" {
-		cmd := createReadinessCommand(cfg.Service.Readiness.Command)
-		cmd.Env = e.buildCommandEnv()
-		if err := cmd.Run(); err == nil {
-			return true
+	// Use readiness probe if configured
+	if probe := e.newReadinessProbe(cfg); probe != nil {
+		if err := probe.check(); err != nil {
+			log.Debug("Readiness probe failed", "probe", probe.description, "error", err)
+			return false
 		}
-		log.Debug("Readiness command failed", "command", cfg.Service.Readiness.Command)
-		return false
+		return true
 	}
 
-	// Fallback to simple HTTP HEAD request if no readiness command configured
+	// Fallback to simple HTTP HEAD request if no readiness probe configured
 	client := &http.Client{Timeout: 2 * time.Second}
 
 	req, err := http.NewRequest("HEAD", e.serviceURL, nil)
@@ -773,15 +967,116 @@ func (e *Executor) DiscardStartupBuffer() {
 
 // GetStartupFailureHelpMessage returns a user-friendly help message when the service fails to start.
 func (e *Executor) GetStartupFailureHelpMessage() string {
+	var msg string
+	if e.failedReadinessProbe != "" {
+		msg += fmt.Sprintf("\n🩺 Readiness probe never succeeded (%s). Check service.readiness_check in .tusk/config.yaml points at your service, or raise service.readiness_check.timeout.\n", e.failedReadinessProbe)
+	}
+	if e.sdkAcknowledgementWait > 0 {
+		msg += fmt.Sprintf("\n⏱️  The SDK didn't connect within %v. Check the service initializes the Tusk Drift SDK, or raise replay.sdk_connect_retries in .tusk/config.yaml if it is slow to boot.\n", e.sdkAcknowledgementWait.Round(time.Second))
+		if lines := lastLines(e.GetStartupLogs(), startupFailureLogLines); lines != "" {
+			msg += fmt.Sprintf("Last service log lines:\n%s\n", lines)
+		}
+	}
 	if e.enableServiceLogs && e.serviceLogPath != "" {
-		return fmt.Sprintf("\n📄 Service logs are available at: %s\n", e.serviceLogPath)
+		msg += fmt.Sprintf("\n📄 Service logs are available at: %s\n", e.serviceLogPath)
 	}
-	return ""
+	return msg
 }
 
-// RunSingleTest replays a single trace on the service under test.
+// startupFailureLogLines is how many trailing service log lines
+// GetStartupFailureHelpMessage shows after an SDK acknowledgement timeout.
+const startupFailureLogLines = 10
+
+// lastLines returns the last n lines of s, ignoring trailing newlines.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RunSingleTest replays a single trace on the service under test, repeating it
+// when SetRepeat was called with n > 1.
 // NOTE: this does not invoke the OnTestCompleted callback. It is the responsibility of the caller to invoke it.
 func (e *Executor) RunSingleTest(test Test) (TestResult, error) {
+	return e.traceTest(test, e.runSingleTest)
+}
+
+func (e *Executor) runSingleTest(test Test) (TestResult, error) {
+	log.Event("test_started", "traceId", test.TraceID, "method", test.Method, "path", test.Path)
+	runs := e.GetRepeat()
+	if runs <= 1 {
+		return e.runSingleTestOnce(test)
+	}
+
+	var (
+		passed      *TestResult
+		failed      *TestResult
+		passedState traceReplayState
+		failedState traceReplayState
+		passCount   int
+		durationMs  int
+	)
+	for i := 0; i < runs; i++ {
+		if i > 0 && e.testsCancelled.Load() {
+			log.Debug("Repeated test runs interrupted", "testID", test.TraceID, "completedRuns", i)
+			return TestResult{
+				TestID:    test.TraceID,
+				Passed:    false,
+				Cancelled: true,
+				Error:     "Test execution interrupted",
+			}, nil
+		}
+
+		// Each run reloads the trace's spans, which resets mock usage and the
+		// events the SDK reported for the previous run
+		result, err := e.runSingleTestOnce(test)
+		if err != nil {
+			// Stop repeating so crash detection sees the error
+			log.Debug("Repeated test run failed", "testID", test.TraceID, "run", i+1, "error", err)
+			return result, err
+		}
+		if e.OnRepeatRunCompleted != nil {
+			e.OnRepeatRunCompleted(result, test, i+1)
+		}
+		durationMs += result.Duration
+		if result.Passed {
+			passCount++
+			if passed == nil {
+				passed = &result
+				if e.server != nil {
+					passedState = e.server.snapshotTraceReplayState(test.TraceID)
+				}
+			}
+		} else if failed == nil {
+			failed = &result
+			if e.server != nil {
+				failedState = e.server.snapshotTraceReplayState(test.TraceID)
+			}
+		}
+	}
+
+	// Report the first failing run so its deviations are shown, and keep the
+	// server's match events and inbound span in line with the reported run
+	aggregated, state := passed, passedState
+	if failed != nil {
+		aggregated, state = failed, failedState
+	}
+	if e.server != nil {
+		e.server.restoreTraceReplayState(test.TraceID, state)
+	}
+	aggregated.Passed = passCount == runs
+	aggregated.Duration = durationMs / runs
+	aggregated.Runs = runs
+	aggregated.PassCount = passCount
+	aggregated.Flaky = passCount > 0 && passCount < runs
+
+	log.Debug("Completed repeated test runs", "testID", test.TraceID, "runs", runs, "passed", passCount, "flaky", aggregated.Flaky)
+	return *aggregated, nil
+}
+
+func (e *Executor) runSingleTestOnce(test Test) (TestResult, error) {
 	// Load all spans for this trace into the server for sophisticated matching
 	if e.server != nil {
 		if len(test.Spans) > 0 {
@@ -843,10 +1138,11 @@ func (e *Executor) RunSingleTest(test Test) (TestResult, error) {
 		req.Header.Set(k, v)
 	}
 
-	client := &http.Client{Timeout: e.testTimeout}
+	client := &http.Client{Timeout: e.timeoutForTest(test)}
 
 	// Send time travel request to Python SDK before making HTTP request
 	// This ensures auth checks at the inbound request level use the recorded time
+	var timeTravelTo time.Time
 	if e.server != nil && e.server.GetSDKRuntime() == core.Runtime_RUNTIME_PYTHON {
 		timestamp, source := GetFirstSpanTimestamp(test.Spans)
 		if timestamp > 0 {
@@ -855,11 +1151,20 @@ func (e *Executor) RunSingleTest(test Test) (TestResult, error) {
 				log.Warn("Failed to set time travel", "error", err, "traceID", test.TraceID)
 			} else {
 				log.Debug("Time travel set", "timestamp", timestamp, "source", source, "traceID", test.TraceID)
+				timeTravelTo = time.Unix(0, int64(timestamp*float64(time.Second)))
 			}
 		}
 	}
 
 	startTime := time.Now()
+	if e.server != nil {
+		// A time-traveled SDK stamps spans on the recorded clock
+		replayStart := startTime
+		if !timeTravelTo.IsZero() {
+			replayStart = timeTravelTo
+		}
+		e.server.SetReplayStart(test.TraceID, replayStart)
+	}
 	resp, err := client.Do(req)
 	duration := int(time.Since(startTime).Milliseconds())
 
@@ -881,6 +1186,8 @@ func (e *Executor) RunSingleTest(test Test) (TestResult, error) {
 
 	result, _ := e.compareAndGenerateResult(test, resp, duration)
 	e.enforceInboundReplaySpanIfRequired(test.TraceID, &result)
+	e.enforceStrictTraceMatching(test.TraceID, &result)
+	classifyDeviations(result.Deviations)
 
 	return result, nil
 }
@@ -911,6 +1218,42 @@ func (e *Executor) enforceInboundReplaySpanIfRequired(traceID string, result *Te
 	})
 }
 
+// enforceStrictTraceMatching fails the result when any of the trace's
+// outbound calls, other than pre-app-start ones, were served by a span from
+// another trace. Such replays pass on a borrowed response and say little about
+// the trace itself.
+func (e *Executor) enforceStrictTraceMatching(traceID string, result *TestResult) {
+	if !e.strictTraceMatching || e.server == nil || result == nil {
+		return
+	}
+
+	var spans []string
+	for _, ev := range e.server.GetMatchEvents(traceID) {
+		if ev.MatchLevel.GetMatchScope() != core.MatchScope_MATCH_SCOPE_GLOBAL || ev.ReplaySpan.GetIsPreAppStart() {
+			continue
+		}
+		name := ev.ReplaySpan.GetName()
+		if name == "" {
+			name = ev.SpanID
+		}
+		spans = append(spans, name)
+	}
+	if len(spans) == 0 {
+		return
+	}
+
+	description := fmt.Sprintf("%d outbound call(s) matched a span from another trace (--strict-trace-matching): %s", len(spans), strings.Join(spans, ", "))
+	log.TestLog(traceID, description)
+
+	result.Passed = false
+	result.Deviations = append(result.Deviations, Deviation{
+		Field:       mockScopeDeviationField,
+		Expected:    "trace",
+		Actual:      "global",
+		Description: description,
+	})
+}
+
 func isTruthyEnv(value string) bool {
 	switch strings.ToLower(strings.TrimSpace(value)) {
 	case "1", "true", "yes", "y", "on":
@@ -924,6 +1267,10 @@ func OutputSingleResult(result TestResult, test Test, format string, quiet bool,
 	switch format {
 	case "json":
 		outputSingleJSON(result)
+	case "ndjson":
+		outputSingleNDJSON(os.Stdout, result)
+	case "junit":
+		// The JUnit document is written once by OutputResultsSummary
 	default:
 		outputSingleText(result, test, quiet, verbose)
 	}
@@ -935,6 +1282,12 @@ func outputSingleJSON(result TestResult) {
 	_ = encoder.Encode(result)
 }
 
+// outputSingleNDJSON writes result as one line of JSON, so consumers can
+// parse the stream line by line as tests complete.
+func outputSingleNDJSON(w io.Writer, result TestResult) {
+	_ = json.NewEncoder(w).Encode(result)
+}
+
 func outputSingleText(result TestResult, test Test, quiet bool, verbose bool) {
 	if result.Cancelled {
 		return
@@ -954,9 +1307,17 @@ func outputSingleText(result TestResult, test Test, quiet bool, verbose bool) {
 		return
 	}
 
+	runCount := ""
+	if label := result.RunCountLabel(); label != "" {
+		runCount = fmt.Sprintf(" [%s passed]", label)
+	}
+	if result.Environment != "" && result.Environment != "default" {
+		runCount += fmt.Sprintf(" [env: %s]", result.Environment)
+	}
+
 	if result.Passed {
 		if !quiet {
-			msg := fmt.Sprintf("NO DEVIATION - %s (%dms)", result.TestID, result.Duration)
+			msg := fmt.Sprintf("NO DEVIATION - %s (%dms)%s", result.TestID, result.Duration, runCount)
 			if result.RetriedAfterCrash {
 				log.UserSuccess(msg + " [retried after crash]")
 			} else {
@@ -964,7 +1325,10 @@ func outputSingleText(result TestResult, test Test, quiet bool, verbose bool) {
 			}
 		}
 	} else {
-		msg := fmt.Sprintf("DEVIATION - %s (%dms)", result.TestID, result.Duration)
+		msg := fmt.Sprintf("DEVIATION - %s (%dms)%s", result.TestID, result.Duration, runCount)
+		if result.Flaky {
+			msg = fmt.Sprintf("FLAKY - %s (%dms)%s", result.TestID, result.Duration, runCount)
+		}
 		if result.RetriedAfterCrash {
 			log.UserDeviation(msg + " [retried after crash]")
 		} else {
@@ -985,7 +1349,14 @@ func outputSingleText(result TestResult, test Test, quiet bool, verbose bool) {
 			log.Println("")
 
 			for _, dev := range result.Deviations {
-				log.UserWarn(fmt.Sprintf("  Deviation: %s", dev.Description))
+				log.UserWarn(fmt.Sprintf("  %s: %s", dev.Label(), dev.Description))
+				// Large text bodies are unreadable printed whole
+				if expected, actual, ok := utils.TextBodies(dev.Expected, dev.Actual); ok && dev.Field == "response.body" {
+					for _, line := range strings.Split(strings.TrimRight(utils.FormatTextDiffPlain(expected, actual), "\n"), "\n") {
+						log.Println("    " + line)
+					}
+					continue
+				}
 				log.Println(fmt.Sprintf("    Expected: %v", dev.Expected))
 				log.Println(fmt.Sprintf("    Actual: %v", dev.Actual))
 			}
@@ -997,13 +1368,40 @@ func outputSingleText(result TestResult, test Test, quiet bool, verbose bool) {
 	}
 }
 
-func OutputResultsSummary(results []TestResult, format string, quiet bool) error {
+// SummaryOptions configures OutputResultsSummary.
+type SummaryOptions struct {
+	// Format is the --output-format; json, ndjson and junit print the counts
+	// to stderr only.
+	Format string
+	Quiet  bool
+	// FailOnSeverity, when set, makes deviating tests only count towards the
+	// returned error if they have a deviation at or above that severity.
+	FailOnSeverity string
+	// MockNotFound (trace ID -> events) is only used by the junit format,
+	// which writes the whole report to stdout.
+	MockNotFound map[string][]MockNotFoundEvent
+	// MatchStats, when set, is printed as a breakdown of how mocks were matched.
+	MatchStats *MatchStatistics
+	// UnusedSpans, when set, is printed as a count of recorded spans no call
+	// matched.
+	UnusedSpans *UnusedSpansReport
+}
+
+// OutputResultsSummary prints the run summary and returns an error when any test
+// deviated or crashed.
+func OutputResultsSummary(results []TestResult, opts SummaryOptions) error {
 	passed := 0
 	failed := 0
 	cancelled := 0
 	crashed := 0
+	flaky := 0
+	// Deviating tests that fail the run under FailOnSeverity
+	failing := 0
 
 	for _, result := range results {
+		if result.Flaky {
+			flaky++
+		}
 		switch {
 		case result.Cancelled:
 			cancelled++
@@ -1013,10 +1411,19 @@ func OutputResultsSummary(results []TestResult, format string, quiet bool) error
 			passed++
 		default:
 			failed++
+			if failsAtSeverity(result, opts.FailOnSeverity) {
+				failing++
+			}
 		}
 	}
 
-	if format == "json" {
+	if opts.Format == "junit" {
+		if err := writeJUnitXML(os.Stdout, results, opts.MockNotFound); err != nil {
+			return err
+		}
+	}
+
+	if opts.Format == "json" || opts.Format == "ndjson" || opts.Format == "junit" {
 		if crashed > 0 {
 			fmt.Fprintf(os.Stderr, "\nTests: %d total, %d passed, %d failed, %d crashed server\n",
 				len(results), passed, failed, crashed)
@@ -1025,8 +1432,8 @@ func OutputResultsSummary(results []TestResult, format string, quiet bool) error
 				len(results), passed, failed)
 		}
 
-		if failed > 0 || crashed > 0 {
-			return fmt.Errorf("%d tests with deviations, %d crashed server", failed, crashed)
+		if failing > 0 || crashed > 0 {
+			return fmt.Errorf("%d tests with deviations, %d crashed server", failing, crashed)
 		}
 		return nil
 	}
@@ -1037,7 +1444,7 @@ func OutputResultsSummary(results []TestResult, format string, quiet bool) error
 	reset := ""
 	gray := ""
 
-	if utils.IsTerminal() && os.Getenv("NO_COLOR") == "" {
+	if utils.IsTerminal() && !styles.NoColor() {
 		green = "\033[32m"
 		orange = "\033[38;5;208m"
 		red = "\033[31m"
@@ -1059,26 +1466,85 @@ func OutputResultsSummary(results []TestResult, format string, quiet bool) error
 		summaryParts = append(summaryParts, fmt.Sprintf("%s%d crashed server%s", red, crashed, reset))
 	}
 
+	if flaky > 0 {
+		summaryParts = append(summaryParts, fmt.Sprintf("%s%d flaky%s", orange, flaky, reset))
+	}
+
 	if cancelled > 0 {
 		summaryParts = append(summaryParts, fmt.Sprintf("%s%d cancelled%s", gray, cancelled, reset))
 	}
 
-	fmt.Printf("\nTests: %s\n\n", strings.Join(summaryParts, ", "))
+	fmt.Printf("\nTests: %s\n", strings.Join(summaryParts, ", "))
 
-	if failed > 0 || crashed > 0 {
+	if counts := countDeviationsBySeverity(results); len(counts) > 0 {
+		var severityParts []string
+		for _, s := range []struct{ severity, color string }{
+			{SeverityError, red},
+			{SeverityWarn, orange},
+			{SeverityInfo, gray},
+		} {
+			if n := counts[s.severity]; n > 0 {
+				severityParts = append(severityParts, fmt.Sprintf("%s%d %s%s", s.color, n, s.severity, reset))
+			}
+		}
+		fmt.Printf("Deviations: %s\n", strings.Join(severityParts, ", "))
+	}
+	if envParts := environmentSummary(results); len(envParts) > 1 {
+		fmt.Printf("Environments: %s\n", strings.Join(envParts, ", "))
+	}
+	if opts.MatchStats != nil && opts.MatchStats.Total() > 0 {
+		fmt.Printf("Mock matches: %s\n", opts.MatchStats)
+	}
+	if spans, traces := opts.UnusedSpans.Counts(); spans > 0 {
+		fmt.Printf("Unused mocks: %s%d spans in %d traces%s\n", gray, spans, traces, reset)
+	}
+	fmt.Println()
+
+	if failing > 0 || crashed > 0 {
 		switch {
-		case crashed > 0 && failed > 0:
-			return fmt.Errorf("%d tests with deviations, %d crashed server", failed, crashed)
+		case crashed > 0 && failing > 0:
+			return fmt.Errorf("%d tests with deviations, %d crashed server", failing, crashed)
 		case crashed > 0:
 			return fmt.Errorf("%d tests crashed server", crashed)
 		default:
-			return fmt.Errorf("%d tests with deviations", failed)
+			return fmt.Errorf("%d tests with deviations", failing)
 		}
 	}
 
 	return nil
 }
 
+// environmentSummary returns "name (N tests, M deviations)" per environment
+// group in results, sorted by name.
+func environmentSummary(results []TestResult) []string {
+	total := make(map[string]int)
+	deviations := make(map[string]int)
+	for _, r := range results {
+		if r.Environment == "" {
+			continue
+		}
+		total[r.Environment]++
+		if !r.Passed && !r.Cancelled {
+			deviations[r.Environment]++
+		}
+	}
+	names := make([]string, 0, len(total))
+	for name := range total {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		part := fmt.Sprintf("%s (%d tests", name, total[name])
+		if n := deviations[name]; n > 0 {
+			part += fmt.Sprintf(", %d deviations", n)
+		}
+		parts = append(parts, part+")")
+	}
+	return parts
+}
+
 // GetFirstSpanTimestamp returns the timestamp to use for time travel.
 // Priority: server/root span > earliest non-server span.
 // Root span is preferred for inbound-level determinism (e.g., caching keys derived from time).
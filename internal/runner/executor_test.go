@@ -1,21 +1,31 @@
 package runner
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+	"github.com/Use-Tusk/tusk-cli/internal/log"
+	"github.com/Use-Tusk/tusk-cli/internal/tui/styles"
+	"github.com/Use-Tusk/tusk-cli/internal/utils"
 	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewExecutor(t *testing.T) {
@@ -355,6 +365,199 @@ func TestExecutor_RunSingleTest_WithMockServer(t *testing.T) {
 	// The test is successful if we get a result without HTTP errors, comparison details are tested elsewhere
 }
 
+func TestExecutor_RunSingleTest_MetadataTimeoutOverridesGlobal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	executor := NewExecutor()
+	executor.serviceURL = server.URL
+	executor.SetTestTimeout(100 * time.Millisecond)
+
+	newTest := func(traceID string, metadata map[string]any) Test {
+		return Test{
+			TraceID:  traceID,
+			Request:  Request{Method: "GET", Path: "/report"},
+			Response: Response{Status: 200},
+			Metadata: metadata,
+		}
+	}
+
+	// The global timeout still applies to tests without a hint
+	_, err := executor.RunSingleTest(newTest("fast-trace", map[string]any{}))
+	require.Error(t, err)
+
+	result, err := executor.RunSingleTest(newTest("slow-trace", map[string]any{"test_timeout": "2s"}))
+	require.NoError(t, err)
+	assert.Empty(t, result.Error)
+
+	result, err = executor.RunSingleTest(newTest("slow-trace-seconds", map[string]any{"test_timeout": float64(2)}))
+	require.NoError(t, err)
+	assert.Empty(t, result.Error)
+}
+
+func TestExecutor_TimeoutForTest(t *testing.T) {
+	executor := NewExecutor()
+	executor.SetTestTimeout(30 * time.Second)
+
+	tests := []struct {
+		name  string
+		value any
+		want  time.Duration
+	}{
+		{name: "absent", value: nil, want: 30 * time.Second},
+		{name: "duration string", value: "2m", want: 2 * time.Minute},
+		{name: "seconds", value: float64(90), want: 90 * time.Second},
+		{name: "fractional seconds", value: 1.5, want: 1500 * time.Millisecond},
+		{name: "invalid string", value: "soon", want: 30 * time.Second},
+		{name: "non-positive", value: float64(0), want: 30 * time.Second},
+		{name: "unsupported type", value: true, want: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := map[string]any{}
+			if tt.value != nil {
+				metadata["test_timeout"] = tt.value
+			}
+			assert.Equal(t, tt.want, executor.timeoutForTest(Test{Metadata: metadata}))
+		})
+	}
+}
+
+func TestExecutor_RunSingleTest_RepeatAggregatesRuns(t *testing.T) {
+	var calls int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		// Every other run returns a different status
+		if n%2 == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	executor := NewExecutor()
+	executor.serviceURL = server.URL
+	executor.SetRepeat(4)
+
+	test := Test{
+		TraceID:  "flaky-trace",
+		Request:  Request{Method: "GET", Path: "/api/flaky"},
+		Response: Response{Status: 200},
+	}
+
+	result, err := executor.RunSingleTest(test)
+	assert.NoError(t, err)
+	mu.Lock()
+	assert.Equal(t, 4, calls)
+	mu.Unlock()
+	assert.Equal(t, 4, result.Runs)
+	assert.Equal(t, 2, result.PassCount)
+	assert.True(t, result.Flaky)
+	assert.False(t, result.Passed)
+	assert.Equal(t, "2/4", result.RunCountLabel())
+	// The failing run is reported so its deviations are visible
+	if assert.Len(t, result.Deviations, 1) {
+		assert.Equal(t, "response.status", result.Deviations[0].Field)
+	}
+
+	executor.SetRepeat(1)
+	single, err := executor.RunSingleTest(test)
+	assert.NoError(t, err)
+	assert.Zero(t, single.Runs)
+	assert.Empty(t, single.RunCountLabel())
+}
+
+func TestExecutor_RunSingleTest_RepeatKeepsReportedRunServerState(t *testing.T) {
+	const traceID = "flaky-trace"
+	mockServer := &Server{
+		spans:                   make(map[string][]*core.Span),
+		matchEvents:             make(map[string][]MatchEvent),
+		spanUsage:               make(map[string]map[string]bool),
+		spansByPackage:          make(map[string]map[string][]*core.Span),
+		spansByReducedValueHash: make(map[string]map[string][]*core.Span),
+		spansByValueHash:        make(map[string]map[string][]*core.Span),
+		mockNotFoundEvents:      make(map[string][]MockNotFoundEvent),
+		replayInbound:           make(map[string]*core.Span),
+	}
+
+	var calls int
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		run := fmt.Sprintf("run%d", calls)
+		// Runs 1 and 3 pass but leave state behind; run 2 fails
+		switch calls {
+		case 2:
+			mockServer.recordMatchEvent(traceID, MatchEvent{SpanID: run})
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			mockServer.recordMockNotFoundEvent(traceID, MockNotFoundEvent{SpanName: run})
+			mockServer.mu.Lock()
+			mockServer.replayInbound[traceID] = &core.Span{SpanId: run}
+			mockServer.mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer httpServer.Close()
+
+	executor := NewExecutor()
+	executor.serviceURL = httpServer.URL
+	executor.server = mockServer
+	executor.SetRepeat(3)
+
+	test := Test{
+		TraceID:  traceID,
+		Request:  Request{Method: "GET", Path: "/api/flaky"},
+		Response: Response{Status: 200},
+		Spans:    []*core.Span{{TraceId: traceID, SpanId: "span1"}},
+	}
+
+	result, err := executor.RunSingleTest(test)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.False(t, result.Passed)
+	assert.Equal(t, 2, result.PassCount)
+
+	// Server state matches the failing run, not the last or first one
+	events := mockServer.GetMatchEvents(traceID)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "run2", events[0].SpanID)
+	}
+	assert.Nil(t, mockServer.GetInboundReplaySpan(traceID))
+	assert.Empty(t, mockServer.GetMockNotFoundEvents(traceID))
+}
+
+func TestExecutor_RunSingleTest_RepeatStopsWhenCancelled(t *testing.T) {
+	var calls int
+	executor := NewExecutor()
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		executor.CancelTests()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer httpServer.Close()
+
+	executor.serviceURL = httpServer.URL
+	executor.SetRepeat(5)
+
+	result, err := executor.RunSingleTest(Test{
+		TraceID:  "cancelled-trace",
+		Request:  Request{Method: "GET", Path: "/api/test"},
+		Response: Response{Status: 200},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.True(t, result.Cancelled)
+	assert.False(t, result.Passed)
+}
+
 func TestExecutor_RunSingleTest_WithRequestBody(t *testing.T) {
 	// Mock HTTP server that expects a POST with body
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -705,6 +908,78 @@ func TestOutputSingleResult_Text_WithFailures_Verbose(t *testing.T) {
 	assert.Contains(t, outputStr, "Actual: 404")
 }
 
+func TestOutputSingleResult_Text_Verbose_TextBodyDiff(t *testing.T) {
+	result := TestResult{
+		TestID: "test1",
+		Passed: false,
+		Deviations: []Deviation{{
+			Field:       "response.body",
+			Expected:    "Status: ok\nItems: 3\nUpdated: never\n",
+			Actual:      "Status: ok\nItems: 4\nUpdated: never\n",
+			Description: "Response body content mismatch",
+		}},
+	}
+	test := Test{TraceID: "test1", Request: Request{Method: "GET", Path: "/status.txt"}}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	OutputSingleResult(result, test, "text", false, true)
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	output, _ := io.ReadAll(r)
+	outputStr := string(output)
+
+	assert.Contains(t, outputStr, "    --- Expected\n    +++ Actual\n    @@ -1,3 +1,3 @@\n")
+	assert.Contains(t, outputStr, "    -Items: 3\n    +Items: 4\n")
+	assert.NotContains(t, outputStr, "Expected: Status")
+}
+
+func TestOutputSingleResult_NDJSONStreamsOneLinePerTest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("line one\nline two\n"))
+	}))
+	defer server.Close()
+
+	executor := NewExecutor()
+	executor.serviceURL = server.URL
+	executor.SetConcurrency(2)
+
+	var mu sync.Mutex
+	var buf bytes.Buffer
+	executor.SetOnTestCompleted(func(result TestResult, test Test) {
+		mu.Lock()
+		defer mu.Unlock()
+		outputSingleNDJSON(&buf, result)
+	})
+
+	var tests []Test
+	for i := range 4 {
+		tests = append(tests, Test{
+			TraceID:  fmt.Sprintf("ndjson-%d", i),
+			Request:  Request{Method: "GET", Path: "/"},
+			Response: Response{Status: 200, Body: "line one\nline 2\n"},
+		})
+	}
+	_, err := executor.RunTests(tests)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, len(tests))
+	seen := map[string]bool{}
+	for _, line := range lines {
+		var result TestResult
+		require.NoError(t, json.Unmarshal([]byte(line), &result), line)
+		assert.False(t, result.Passed)
+		seen[result.TestID] = true
+	}
+	assert.Len(t, seen, len(tests))
+}
+
 func TestOutputSingleResult_Text_WithPasses(t *testing.T) {
 	results := []TestResult{
 		{TestID: "test1", Passed: true, Duration: 100},
@@ -729,6 +1004,34 @@ func TestOutputSingleResult_Text_WithPasses(t *testing.T) {
 	assert.Contains(t, outputStr, "NO DEVIATION - test2")
 }
 
+func TestOutputSingleResult_Text_ShowsEnvironment(t *testing.T) {
+	results := []TestResult{
+		{TestID: "test1", Passed: true, Duration: 100, Environment: "staging"},
+		{TestID: "test2", Passed: false, Duration: 150, Environment: "production"},
+		{TestID: "test3", Passed: true, Duration: 50, Environment: "default"},
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	for _, result := range results {
+		OutputSingleResult(result, Test{TraceID: result.TestID}, "text", false, false)
+	}
+	_ = OutputResultsSummary(results, SummaryOptions{Format: "text"})
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	output, _ := io.ReadAll(r)
+	outputStr := string(output)
+
+	assert.Contains(t, outputStr, "NO DEVIATION - test1 (100ms) [env: staging]")
+	assert.Contains(t, outputStr, "DEVIATION - test2 (150ms) [env: production]")
+	assert.NotContains(t, outputStr, "[env: default]")
+	assert.Contains(t, outputStr, "Environments: default (1 tests), production (1 tests, 1 deviations), staging (1 tests)")
+}
+
 func TestOutputSingleResult_Text_Quiet_OnlyFailures(t *testing.T) {
 	results := []TestResult{
 		{TestID: "test1", Passed: true, Duration: 100},
@@ -960,6 +1263,144 @@ func TestExecutor_RunSingleTest_StrictInboundReplaySpanPresentPasses(t *testing.
 	}
 }
 
+func TestExecutor_RunSingleTest_StrictTraceMatchingFailsOnGlobalMatch(t *testing.T) {
+	cfg, _ := config.Get()
+	inputSchema := &core.JsonSchema{Properties: map[string]*core.JsonSchema{"method": {}, "path": {}}}
+	input := map[string]any{"method": "GET", "path": "/users/1"}
+
+	run := func(t *testing.T, strict bool) TestResult {
+		mockServer, err := NewServer("svc", &cfg.Service)
+		require.NoError(t, err)
+		// The only recorded span for the call belongs to another trace
+		other := makeSpan(t, "trace-other", "users-span", "http", input, inputSchema, 100)
+		mockServer.SetSuiteSpans([]*core.Span{other})
+		mockServer.SetGlobalSpans([]*core.Span{other})
+		mockServer.LoadSpansForTrace("trace-strict", []*core.Span{})
+
+		executor := NewExecutor()
+		executor.server = mockServer
+		executor.SetStrictTraceMatching(strict)
+
+		httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req := makeMockRequest(t, "http", input, inputSchema)
+			req.TestId = r.Header.Get("x-td-trace-id")
+			req.OutboundSpan.Name = "GET /users/1"
+			assert.True(t, mockServer.findMock(req, nil).Found)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		}))
+		defer httpServer.Close()
+		executor.serviceURL = httpServer.URL
+
+		result, err := executor.RunSingleTest(Test{
+			TraceID:  "trace-strict",
+			Request:  Request{Method: "GET", Path: "/api/test"},
+			Response: Response{Status: 200, Body: map[string]any{"ok": true}},
+		})
+		require.NoError(t, err)
+
+		events := mockServer.GetMatchEvents("trace-strict")
+		require.Len(t, events, 1)
+		require.Equal(t, core.MatchScope_MATCH_SCOPE_GLOBAL, events[0].MatchLevel.GetMatchScope())
+		return result
+	}
+
+	t.Run("passes without the flag", func(t *testing.T) {
+		result := run(t, false)
+		assert.True(t, result.Passed)
+		assert.Empty(t, result.Deviations)
+	})
+
+	t.Run("fails with the flag", func(t *testing.T) {
+		result := run(t, true)
+		assert.False(t, result.Passed)
+		require.Len(t, result.Deviations, 1)
+		assert.Equal(t, mockScopeDeviationField, result.Deviations[0].Field)
+		assert.Contains(t, result.Deviations[0].Description, "GET /users/1")
+	})
+}
+
+func TestExecutor_RunBatchSequentialWithCrashHandling_RetriesCrashedTest(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	})
+
+	// The stub service dies on its first request, like a crashing process
+	var crashing *httptest.Server
+	crashing = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = crashing.Listener.Close()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		_ = conn.Close()
+	}))
+	defer crashing.Close()
+
+	executor := NewExecutor()
+	executor.serviceURL = crashing.URL
+	executor.SetTestTimeout(2 * time.Second)
+	executor.SetCrashRetries(1)
+
+	restarts := 0
+	executor.restartServerFn = func(attempt int) error {
+		restarts++
+		restarted := httptest.NewServer(okHandler)
+		t.Cleanup(restarted.Close)
+		executor.serviceURL = restarted.URL
+		return nil
+	}
+
+	test := Test{
+		TraceID:  "trace-crash-once",
+		Request:  Request{Method: "GET", Path: "/api/test"},
+		Response: Response{Status: 200, Body: map[string]any{"ok": true}},
+	}
+	results := executor.RunBatchSequentialWithCrashHandling([]Test{test}, false)
+
+	require.Len(t, results, 1)
+	assert.Equal(t, 1, restarts)
+	assert.True(t, results[0].Passed, "retry after restart should pass: %s", results[0].Error)
+	assert.False(t, results[0].CrashedServer)
+	assert.True(t, results[0].RetriedAfterCrash)
+}
+
+func TestOutput_NoColorWritesNoEscapeSequences(t *testing.T) {
+	// Force a color profile, as on a terminal, so the test sees what --no-color strips
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+	var out bytes.Buffer
+	log.SetUserOutput(&out)
+	t.Cleanup(func() { log.SetUserOutput(nil) })
+
+	expected := map[string]any{"id": 1, "name": "a"}
+	actual := map[string]any{"id": 1, "name": "b"}
+	results := []TestResult{
+		{TestID: "trace-pass", Passed: true},
+		{TestID: "trace-deviation", Deviations: []Deviation{{Field: "response.body", Expected: expected, Actual: actual}}},
+	}
+	test := Test{Request: Request{Method: "GET", Path: "/users/1"}}
+	writeOutput := func() string {
+		out.Reset()
+		for _, result := range results {
+			OutputSingleResult(result, test, "text", false, true)
+		}
+		_ = OutputResultsSummary(results, SummaryOptions{Format: "text"})
+		out.WriteString(utils.FormatJSONDiff(expected, actual))
+		return out.String()
+	}
+
+	require.Contains(t, writeOutput(), "\x1b[", "output should be colored by default")
+
+	styles.SetNoColor(true)
+	t.Cleanup(func() { styles.SetNoColor(false) })
+	got := writeOutput()
+	assert.Contains(t, got, "DEVIATION - trace-deviation")
+	assert.NotContains(t, got, "\x1b[")
+}
+
 // Benchmark tests for performance validation
 func BenchmarkExecutor_RunTestsConcurrently(b *testing.B) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1015,3 +1456,107 @@ func BenchmarkCountPassedTests(b *testing.B) {
 		}
 	}
 }
+
+func TestExecutor_RunTests_MaxFailuresCancelsRemainingTests(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			requests.Add(1)
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	executor := NewExecutor()
+	executor.serviceURL = server.URL
+	executor.SetConcurrency(1)
+	executor.SetMaxFailures(2)
+
+	var completed []TestResult
+	executor.SetOnTestCompleted(func(result TestResult, test Test) {
+		completed = append(completed, result)
+	})
+
+	var tests []Test
+	for i := range 6 {
+		tests = append(tests, Test{
+			TraceID:  fmt.Sprintf("test-%d", i),
+			Request:  Request{Method: "GET", Path: "/fail"},
+			Response: Response{Status: 200},
+		})
+	}
+
+	results, err := executor.RunTests(tests)
+	require.NoError(t, err)
+	require.Len(t, results, 6)
+
+	assert.True(t, executor.FailureLimitReached())
+	assert.EqualValues(t, 2, requests.Load(), "no test starts after the limit is hit")
+	for i, result := range results {
+		if i < 2 {
+			assert.False(t, result.Passed)
+			assert.False(t, result.Cancelled, "test %d ran", i)
+		} else {
+			assert.True(t, result.Cancelled, "test %d was skipped", i)
+		}
+	}
+	assert.Len(t, completed, 6, "callbacks still fire for skipped tests")
+}
+
+func TestExecutor_RunTests_NoMaxFailuresRunsEverything(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	executor := NewExecutor()
+	executor.serviceURL = server.URL
+	executor.SetConcurrency(1)
+
+	tests := []Test{
+		{TraceID: "a", Request: Request{Method: "GET", Path: "/"}, Response: Response{Status: 200}},
+		{TraceID: "b", Request: Request{Method: "GET", Path: "/"}, Response: Response{Status: 200}},
+	}
+	results, err := executor.RunTests(tests)
+	require.NoError(t, err)
+	assert.False(t, executor.FailureLimitReached())
+	for _, result := range results {
+		assert.False(t, result.Cancelled)
+	}
+}
+
+func TestCompleteTestEmitsJSONEvent(t *testing.T) {
+	origLogger := slog.Default()
+	log.Setup(false, log.ModeHeadless, log.FormatJSON)
+	var logs bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() {
+		log.Setup(false, log.ModeHeadless, log.FormatText)
+		slog.SetDefault(origLogger)
+	})
+
+	executor := NewExecutor()
+	executor.completeTest(
+		TestResult{TestID: "trace-1", Passed: false, Duration: 42, Deviations: []Deviation{{Field: "response.status"}}},
+		Test{TraceID: "trace-1", Method: "GET", Path: "/users/1"},
+	)
+
+	var event map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &event), "event must be a single JSON line: %s", logs.String())
+	assert.Equal(t, "test_completed", event["msg"])
+	assert.Equal(t, "trace-1", event["traceId"])
+	assert.Equal(t, float64(42), event["durationMs"])
+	assert.Equal(t, false, event["passed"])
+	assert.Equal(t, float64(1), event["deviations"])
+	assert.Equal(t, "/users/1", event["path"])
+}
+
+func TestCompleteTestEmitsNoEventWithTextLogs(t *testing.T) {
+	origLogger := slog.Default()
+	var logs bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(origLogger) })
+
+	NewExecutor().completeTest(TestResult{TestID: "trace-1", Passed: true}, Test{TraceID: "trace-1"})
+	assert.Empty(t, logs.String())
+}
@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/Use-Tusk/tusk-cli/internal/log"
+)
+
+// failureLimit cancels the remaining tests once max tests have failed.
+type failureLimit struct {
+	max     int
+	failed  atomic.Int64
+	reached atomic.Bool
+	once    sync.Once
+	cancel  func()
+}
+
+func (l *failureLimit) record(result TestResult) {
+	if result.Passed || result.Cancelled {
+		return
+	}
+	if l.failed.Add(1) >= int64(l.max) {
+		l.once.Do(func() {
+			l.reached.Store(true)
+			l.cancel()
+		})
+	}
+}
+
+// SetMaxFailures cancels the remaining tests once n tests have failed. Tests
+// already running are allowed to finish. n <= 0 disables the limit.
+func (e *Executor) SetMaxFailures(n int) {
+	if n <= 0 {
+		e.failureLimit = nil
+		return
+	}
+	e.failureLimit = &failureLimit{max: n, cancel: e.CancelTests}
+}
+
+// FailureLimitReached reports whether the run was cut short by SetMaxFailures.
+func (e *Executor) FailureLimitReached() bool {
+	return e.failureLimit != nil && e.failureLimit.reached.Load()
+}
+
+// completeTest counts the result towards the failure limit and invokes the
+// OnTestCompleted callback.
+func (e *Executor) completeTest(result TestResult, test Test) {
+	if result.Environment == "" {
+		result.Environment = e.environment
+	}
+	logTestCompleted(result, test)
+	if e.failureLimit != nil {
+		e.failureLimit.record(result)
+	}
+	if e.OnTestCompleted != nil {
+		e.OnTestCompleted(result, test)
+	}
+}
+
+// logTestCompleted emits the structured test_completed event.
+func logTestCompleted(result TestResult, test Test) {
+	args := []any{
+		"traceId", test.TraceID,
+		"method", test.Method,
+		"path", test.Path,
+		"durationMs", result.Duration,
+		"passed", result.Passed,
+		"deviations", len(result.Deviations),
+	}
+	if result.Cancelled {
+		args = append(args, "cancelled", true)
+	}
+	if result.Error != "" {
+		args = append(args, "error", result.Error)
+	}
+	log.Event("test_completed", args...)
+}
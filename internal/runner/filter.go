@@ -3,13 +3,15 @@ package runner
 import (
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 )
 
 func FilterTests(tests []Test, pattern string) ([]Test, error) {
-	// Fielded filter syntax: key=regex[,key=regex...]
+	// Fielded filter syntax: key=regex[,key=regex...] (key:value is also accepted)
 	// AND semantics across keys. Values are Go regexes; quotes supported.
-	if !strings.Contains(pattern, "=") {
+	if !strings.ContainsAny(pattern, "=:") {
 		return nil, fmt.Errorf("non-fielded filters are no longer supported; use key=regex (e.g., type=GRAPHQL,op=^GetUser$)")
 	}
 
@@ -21,6 +23,20 @@ func FilterTests(tests []Test, pattern string) ([]Test, error) {
 	for _, t := range tests {
 		ok := true
 		for _, m := range matchers {
+			if m.matchStatusCode != nil {
+				if t.Response.Status == 0 || !m.matchStatusCode(t.Response.Status) {
+					ok = false
+					break
+				}
+				continue
+			}
+			if m.field == "tag" {
+				if !slices.ContainsFunc(testTags(t), m.re.MatchString) {
+					ok = false
+					break
+				}
+				continue
+			}
 			val := getFieldValueForFilter(t, m.field)
 			if !m.re.MatchString(val) {
 				ok = false
@@ -37,6 +53,9 @@ func FilterTests(tests []Test, pattern string) ([]Test, error) {
 type fieldMatcher struct {
 	field string
 	re    *regexp.Regexp
+	// Set for status code predicates (e.g. status:>=500); matched against the
+	// recorded response status instead of re.
+	matchStatusCode func(int) bool
 }
 
 func parseFieldedFilter(q string) ([]fieldMatcher, error) {
@@ -51,7 +70,8 @@ func parseFieldedFilter(q string) ([]fieldMatcher, error) {
 		if tok == "" {
 			continue
 		}
-		idx := strings.Index(tok, "=")
+		// The key ends at the first '=' or ':', so "status:>=500" splits after "status"
+		idx := strings.IndexAny(tok, "=:")
 		if idx <= 0 {
 			return nil, fmt.Errorf("invalid filter token: %q (expected key=value)", tok)
 		}
@@ -65,6 +85,17 @@ func parseFieldedFilter(q string) ([]fieldMatcher, error) {
 		if field == "" {
 			return nil, fmt.Errorf("unknown filter field: %s", key)
 		}
+		// status accepts either a status label regex or a response status code
+		// predicate; status_code only accepts the latter
+		if field == "status" || field == "status_code" {
+			if match, ok := parseStatusCodePredicate(val); ok {
+				out = append(out, fieldMatcher{field: "status_code", matchStatusCode: match})
+				continue
+			}
+			if field == "status_code" {
+				return nil, fmt.Errorf("invalid status code filter: %q (e.g., 404, >=500, 5xx, 400-499)", val)
+			}
+		}
 		// suite_status values are always lowercase; make matching case-insensitive
 		if field == "suite_status" {
 			val = "(?i)" + val
@@ -123,12 +154,16 @@ func normalizeFilterFieldKey(k string) string {
 		return "method"
 	case "status", "s":
 		return "status"
+	case "status_code", "code", "http_status":
+		return "status_code"
 	case "id", "trace", "trace_id":
 		return "id"
 	case "file", "filename", "f":
 		return "file"
 	case "suite_status", "suite":
 		return "suite_status"
+	case "tag", "tags":
+		return "tag"
 	default:
 		return ""
 	}
@@ -168,6 +203,104 @@ func getFieldValueForFilter(t Test, field string) string {
 	}
 }
 
+// metadataTagsKey is the root span metadata key holding a test's tags, either
+// a list of strings or a comma-separated string.
+const metadataTagsKey = "tags"
+
+// testTags returns the tags recorded in the test's root span metadata.
+func testTags(t Test) []string {
+	switch v := t.Metadata[metadataTagsKey].(type) {
+	case []any:
+		tags := make([]string, 0, len(v))
+		for _, tag := range v {
+			if s, ok := tag.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+		return tags
+	case []string:
+		return v
+	case string:
+		var tags []string
+		for tag := range strings.SplitSeq(v, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		return tags
+	default:
+		return nil
+	}
+}
+
+// parseStatusCodePredicate parses a response status code predicate: an exact
+// code (404), a comparison (>=500, <400, !=200), an inclusive range (400-499),
+// or a wildcard where x or * matches any digit (5xx). ok is false when s isn't
+// one of these forms, so it can fall back to being treated as a regex.
+func parseStatusCodePredicate(s string) (match func(int) bool, ok bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, false
+	}
+
+	for _, op := range []string{">=", "<=", "!=", ">", "<", "="} {
+		rest, found := strings.CutPrefix(s, op)
+		if !found {
+			continue
+		}
+		code, err := strconv.Atoi(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, false
+		}
+		switch op {
+		case ">=":
+			return func(c int) bool { return c >= code }, true
+		case "<=":
+			return func(c int) bool { return c <= code }, true
+		case "!=":
+			return func(c int) bool { return c != code }, true
+		case ">":
+			return func(c int) bool { return c > code }, true
+		case "<":
+			return func(c int) bool { return c < code }, true
+		default:
+			return func(c int) bool { return c == code }, true
+		}
+	}
+
+	if lo, hi, found := strings.Cut(s, "-"); found {
+		low, errLo := strconv.Atoi(strings.TrimSpace(lo))
+		high, errHi := strconv.Atoi(strings.TrimSpace(hi))
+		if errLo != nil || errHi != nil || low > high {
+			return nil, false
+		}
+		return func(c int) bool { return c >= low && c <= high }, true
+	}
+
+	if len(s) == 3 {
+		isWildcard := func(ch byte) bool { return ch == 'x' || ch == 'X' || ch == '*' }
+		for i := 0; i < 3; i++ {
+			if !isWildcard(s[i]) && (s[i] < '0' || s[i] > '9') {
+				return nil, false
+			}
+		}
+		return func(c int) bool {
+			code := strconv.Itoa(c)
+			if len(code) != 3 {
+				return false
+			}
+			for i := 0; i < 3; i++ {
+				if !isWildcard(s[i]) && s[i] != code[i] {
+					return false
+				}
+			}
+			return true
+		}, true
+	}
+
+	return nil, false
+}
+
 func extractGraphQLOperationName(displayName string) string {
 	// e.g. "query GetUser", "mutation UpdateUser", "subscription OnEvent"
 	parts := strings.Fields(displayName)
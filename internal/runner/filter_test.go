@@ -208,3 +208,88 @@ func TestExtractGraphQLOperationName(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterTestsByResponseStatusCode(t *testing.T) {
+	tests := []Test{
+		{TraceID: "ok", Status: "success", Response: Response{Status: 200}},
+		{TraceID: "not-found", Status: "success", Response: Response{Status: 404}},
+		{TraceID: "server-error", Status: "error", Response: Response{Status: 500}},
+		{TraceID: "bad-gateway", Status: "error", Response: Response{Status: 502}},
+		{TraceID: "no-response", Status: "error"},
+	}
+	ids := func(filtered []Test) []string {
+		var out []string
+		for _, tt := range filtered {
+			out = append(out, tt.TraceID)
+		}
+		return out
+	}
+
+	cases := []struct {
+		filter string
+		want   []string
+	}{
+		{"status:404", []string{"not-found"}},
+		{"status=404", []string{"not-found"}},
+		{"status:>=500", []string{"server-error", "bad-gateway"}},
+		{"status:<400", []string{"ok"}},
+		{"status:!=200", []string{"not-found", "server-error", "bad-gateway"}},
+		{"status_code=400-499", []string{"not-found"}},
+		{"status:5xx", []string{"server-error", "bad-gateway"}},
+		{"code=50*", []string{"server-error", "bad-gateway"}},
+		{"status:4XX,id=^not", []string{"not-found"}},
+		// Non-numeric values still match the status label
+		{"status=^error$", []string{"server-error", "bad-gateway", "no-response"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.filter, func(t *testing.T) {
+			filtered, err := FilterTests(tests, tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, ids(filtered))
+		})
+	}
+}
+
+func TestFilterTestsByTag(t *testing.T) {
+	tests := []Test{
+		{TraceID: "t-1", Method: "POST", Metadata: map[string]any{"tags": []any{"checkout", "payments"}}},
+		{TraceID: "t-2", Method: "GET", Metadata: map[string]any{"tags": []any{"checkout"}}},
+		{TraceID: "t-3", Method: "POST", Metadata: map[string]any{"tags": "search, beta"}},
+		{TraceID: "t-4", Method: "POST"},
+	}
+
+	ids := func(ts []Test) []string {
+		var out []string
+		for _, tt := range ts {
+			out = append(out, tt.TraceID)
+		}
+		return out
+	}
+
+	filtered, err := FilterTests(tests, "tag:^checkout$")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"t-1", "t-2"}, ids(filtered))
+
+	filtered, err = FilterTests(tests, "tag=checkout,method=POST")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"t-1"}, ids(filtered))
+
+	filtered, err = FilterTests(tests, "tag=checkout,tag=payments")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"t-1"}, ids(filtered))
+
+	filtered, err = FilterTests(tests, "tags=^beta$")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"t-3"}, ids(filtered))
+}
+
+func TestParseStatusCodePredicate(t *testing.T) {
+	for _, s := range []string{"", "abc", "5x", "50-", "599-500", ">=abc", "^5"} {
+		_, ok := parseStatusCodePredicate(s)
+		assert.False(t, ok, s)
+	}
+
+	_, err := FilterTests(nil, "status_code=server")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid status code filter")
+}
@@ -0,0 +1,183 @@
+package runner
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const junitSuiteName = "tusk-drift"
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Time      string         `xml:"time,attr"`
+	Failure   *junitProblem  `xml:"failure,omitempty"`
+	Errors    []junitProblem `xml:"error,omitempty"`
+	Skipped   *junitSkipped  `xml:"skipped,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// writeJUnitXML writes results as a JUnit XML document. Deviations become
+// <failure> elements; mock-not-found events, test errors, and server crashes
+// become <error> elements so CI systems can tell them apart.
+func writeJUnitXML(w io.Writer, results []TestResult, mockNotFound map[string][]MockNotFoundEvent) error {
+	suite := junitTestSuite{Name: junitSuiteName}
+	totalMs := 0
+
+	for _, result := range results {
+		totalMs += result.Duration
+		tc := junitTestCase{
+			Name:      result.TestID,
+			ClassName: junitSuiteName,
+			Time:      junitSeconds(result.Duration),
+		}
+
+		if result.Cancelled {
+			tc.Skipped = &junitSkipped{Message: "cancelled"}
+			suite.Skipped++
+			suite.TestCases = append(suite.TestCases, tc)
+			continue
+		}
+
+		if result.CrashedServer {
+			tc.Errors = append(tc.Errors, junitProblem{
+				Message: "test caused the service to crash",
+				Type:    "ServerCrash",
+				Body:    result.Error,
+			})
+		} else if result.Error != "" {
+			tc.Errors = append(tc.Errors, junitProblem{
+				Message: firstLine(result.Error),
+				Type:    "Error",
+				Body:    result.Error,
+			})
+		}
+
+		for _, ev := range mockNotFound[result.TestID] {
+			tc.Errors = append(tc.Errors, junitProblem{
+				Message: fmt.Sprintf("no mock found for %s", mockNotFoundOperationName(ev)),
+				Type:    "MockNotFound",
+				Body:    formatJUnitMockNotFound(ev),
+			})
+		}
+
+		if len(result.Deviations) > 0 {
+			tc.Failure = &junitProblem{
+				Message: fmt.Sprintf("%d deviation(s)", len(result.Deviations)),
+				Type:    "Deviation",
+				Body:    formatJUnitDeviations(result.Deviations),
+			}
+			suite.Failures++
+		} else if !result.Passed && len(tc.Errors) == 0 {
+			tc.Failure = &junitProblem{Message: "test failed", Type: "Deviation"}
+			suite.Failures++
+		}
+
+		if len(tc.Errors) > 0 {
+			suite.Errors++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	suite.Tests = len(results)
+	suite.Time = junitSeconds(totalMs)
+
+	doc := junitTestSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode JUnit XML: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSeconds(ms int) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
+
+func formatJUnitDeviations(deviations []Deviation) string {
+	var sb strings.Builder
+	for i, d := range deviations {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "%s: %s\n", d.Field, d.Description)
+		fmt.Fprintf(&sb, "  expected: %s\n", junitValue(d.Expected))
+		fmt.Fprintf(&sb, "  actual:   %s\n", junitValue(d.Actual))
+	}
+	return sb.String()
+}
+
+func formatJUnitMockNotFound(ev MockNotFoundEvent) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "package: %s\n", ev.PackageName)
+	if ev.Error != "" {
+		fmt.Fprintf(&sb, "error: %s\n", ev.Error)
+	}
+	if ev.StackTrace != "" {
+		fmt.Fprintf(&sb, "stack trace:\n%s\n", ev.StackTrace)
+	}
+	return sb.String()
+}
+
+func junitValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
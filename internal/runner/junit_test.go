@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJUnitXML(t *testing.T) {
+	results := []TestResult{
+		{TestID: "trace-pass", Passed: true, Duration: 120},
+		{
+			TestID:   "trace-deviation",
+			Passed:   false,
+			Duration: 1500,
+			Deviations: []Deviation{
+				{Field: "response.status", Expected: 200, Actual: 500, Description: "Status code mismatch"},
+			},
+		},
+		{TestID: "trace-mock-missing", Passed: false, Duration: 40},
+		{TestID: "trace-cancelled", Cancelled: true},
+	}
+	mockNotFound := map[string][]MockNotFoundEvent{
+		"trace-mock-missing": {
+			{PackageName: "pg", SpanName: "pg.query", Error: "no mock found for query"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeJUnitXML(&buf, results, mockNotFound))
+	assert.Contains(t, buf.String(), xml.Header)
+
+	var doc junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, 4, doc.Tests)
+	assert.Equal(t, 1, doc.Failures)
+	assert.Equal(t, 1, doc.Errors)
+	assert.Equal(t, 1, doc.Skipped)
+	assert.Equal(t, "1.660", doc.Time)
+
+	require.Len(t, doc.Suites, 1)
+	cases := doc.Suites[0].TestCases
+	require.Len(t, cases, 4)
+
+	assert.Equal(t, "trace-pass", cases[0].Name)
+	assert.Equal(t, "0.120", cases[0].Time)
+	assert.Nil(t, cases[0].Failure)
+	assert.Empty(t, cases[0].Errors)
+
+	require.NotNil(t, cases[1].Failure)
+	assert.Equal(t, "Deviation", cases[1].Failure.Type)
+	assert.Contains(t, cases[1].Failure.Body, "response.status: Status code mismatch")
+	assert.Contains(t, cases[1].Failure.Body, "expected: 200")
+	assert.Equal(t, "1.500", cases[1].Time)
+
+	assert.Nil(t, cases[2].Failure, "mock-not-found is reported as an error, not a failure")
+	require.Len(t, cases[2].Errors, 1)
+	assert.Equal(t, "MockNotFound", cases[2].Errors[0].Type)
+	assert.Equal(t, "no mock found for pg: pg.query", cases[2].Errors[0].Message)
+
+	require.NotNil(t, cases[3].Skipped)
+}
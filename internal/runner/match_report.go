@@ -0,0 +1,143 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// MatchReportCandidate is an alternative span considered during similarity scoring.
+type MatchReportCandidate struct {
+	SpanID string  `json:"spanId"`
+	Score  float32 `json:"score"`
+}
+
+// MatchReportEntry describes a single mock match decision.
+type MatchReportEntry struct {
+	SpanID           string                 `json:"spanId"`
+	PackageName      string                 `json:"packageName,omitempty"`
+	Name             string                 `json:"name,omitempty"`
+	MatchType        string                 `json:"matchType"`
+	MatchScope       string                 `json:"matchScope"`
+	MatchDescription string                 `json:"matchDescription,omitempty"`
+	SimilarityScore  *float32               `json:"similarityScore,omitempty"`
+	TopCandidates    []MatchReportCandidate `json:"topCandidates,omitempty"`
+	Ambiguous        bool                   `json:"ambiguous,omitempty"`
+}
+
+// MatchReportTrace groups match decisions made while replaying one trace.
+type MatchReportTrace struct {
+	TraceID string             `json:"traceId"`
+	Run     int                `json:"run,omitempty"` // 1-based run number when tests are repeated
+	Matches []MatchReportEntry `json:"matches"`
+}
+
+type matchReportKey struct {
+	traceID string
+	run     int
+}
+
+// MatchReport collects match events across a run so they can be written out
+// once all tests have completed. Events must be recorded before the server
+// cleans up the trace (see Server.CleanupTraceSpans).
+type MatchReport struct {
+	mu     sync.Mutex
+	traces map[matchReportKey][]MatchReportEntry
+}
+
+func NewMatchReport() *MatchReport {
+	return &MatchReport{traces: make(map[matchReportKey][]MatchReportEntry)}
+}
+
+// Record stores the match events for a trace, replacing anything recorded earlier.
+func (r *MatchReport) Record(traceID string, events []MatchEvent) {
+	r.RecordRun(traceID, 0, events)
+}
+
+// RecordRun stores the match events of one run of a repeated trace. Run 0 is
+// used for traces that ran once.
+func (r *MatchReport) RecordRun(traceID string, run int, events []MatchEvent) {
+	entries := make([]MatchReportEntry, 0, len(events))
+	for _, ev := range events {
+		entries = append(entries, matchEventToReportEntry(ev))
+	}
+
+	// Outbound requests can arrive in any order, so sort to keep the report diffable.
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].SpanID != entries[j].SpanID {
+			return entries[i].SpanID < entries[j].SpanID
+		}
+		return entries[i].Name < entries[j].Name
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.traces[matchReportKey{traceID: traceID, run: run}] = entries
+}
+
+// Traces returns the recorded traces sorted by trace ID, then run.
+func (r *MatchReport) Traces() []MatchReportTrace {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]MatchReportTrace, 0, len(r.traces))
+	for key, entries := range r.traces {
+		out = append(out, MatchReportTrace{TraceID: key.traceID, Run: key.run, Matches: entries})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].TraceID != out[j].TraceID {
+			return out[i].TraceID < out[j].TraceID
+		}
+		return out[i].Run < out[j].Run
+	})
+	return out
+}
+
+// WriteToFile writes the report as an indented JSON array to path.
+func (r *MatchReport) WriteToFile(path string) error {
+	data, err := json.MarshalIndent(r.Traces(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal match report: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to create match report directory: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write match report: %w", err)
+	}
+	return nil
+}
+
+func matchEventToReportEntry(ev MatchEvent) MatchReportEntry {
+	entry := MatchReportEntry{SpanID: ev.SpanID, Ambiguous: ev.Ambiguous}
+
+	if ev.ReplaySpan != nil {
+		entry.PackageName = ev.ReplaySpan.PackageName
+		entry.Name = ev.ReplaySpan.Name
+	}
+
+	if ml := ev.MatchLevel; ml != nil {
+		entry.MatchType = ml.MatchType.String()
+		entry.MatchScope = ml.MatchScope.String()
+		entry.MatchDescription = ml.MatchDescription
+		entry.SimilarityScore = ml.SimilarityScore
+		for _, c := range ml.TopCandidates {
+			if c == nil {
+				continue
+			}
+			entry.TopCandidates = append(entry.TopCandidates, MatchReportCandidate{
+				SpanID: c.SpanId,
+				Score:  c.Score,
+			})
+		}
+	}
+
+	return entry
+}
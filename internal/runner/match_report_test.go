@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchReport_WriteToFileIsDeterministic(t *testing.T) {
+	score := float32(0.8)
+	eventsB := []MatchEvent{
+		{
+			SpanID:    "span-2",
+			Timestamp: time.Now(),
+			MatchLevel: &core.MatchLevel{
+				MatchType:       core.MatchType_MATCH_TYPE_INPUT_SCHEMA_HASH,
+				MatchScope:      core.MatchScope_MATCH_SCOPE_TRACE,
+				SimilarityScore: &score,
+				TopCandidates: []*core.SimilarityCandidate{
+					{SpanId: "span-2", Score: 0.8},
+					{SpanId: "span-3", Score: 0.5},
+				},
+			},
+			ReplaySpan: &core.Span{PackageName: "http", Name: "GET /users"},
+		},
+		{
+			SpanID:     "span-1",
+			Timestamp:  time.Now(),
+			MatchLevel: &core.MatchLevel{MatchType: core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH},
+		},
+	}
+
+	report := NewMatchReport()
+	report.Record("trace-b", eventsB)
+	report.Record("trace-a", nil)
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "nested", "first.json")
+	require.NoError(t, report.WriteToFile(first))
+
+	// Recording the same events in a different order must produce identical output
+	report2 := NewMatchReport()
+	report2.Record("trace-a", nil)
+	report2.Record("trace-b", []MatchEvent{eventsB[1], eventsB[0]})
+	second := filepath.Join(dir, "second.json")
+	require.NoError(t, report2.WriteToFile(second))
+
+	firstData, err := os.ReadFile(first) // #nosec G304
+	require.NoError(t, err)
+	secondData, err := os.ReadFile(second) // #nosec G304
+	require.NoError(t, err)
+	assert.Equal(t, string(firstData), string(secondData))
+
+	var traces []MatchReportTrace
+	require.NoError(t, json.Unmarshal(firstData, &traces))
+	require.Len(t, traces, 2)
+	assert.Equal(t, "trace-a", traces[0].TraceID)
+	assert.Empty(t, traces[0].Matches)
+	assert.Equal(t, "trace-b", traces[1].TraceID)
+	require.Len(t, traces[1].Matches, 2)
+	assert.Equal(t, "span-1", traces[1].Matches[0].SpanID)
+
+	match := traces[1].Matches[1]
+	assert.Equal(t, "span-2", match.SpanID)
+	assert.Equal(t, "http", match.PackageName)
+	assert.Equal(t, "MATCH_TYPE_INPUT_SCHEMA_HASH", match.MatchType)
+	assert.Equal(t, "MATCH_SCOPE_TRACE", match.MatchScope)
+	require.NotNil(t, match.SimilarityScore)
+	assert.InDelta(t, 0.8, *match.SimilarityScore, 0.0001)
+	assert.Len(t, match.TopCandidates, 2)
+}
+
+func TestMatchReport_RecordRunKeepsEachRun(t *testing.T) {
+	report := NewMatchReport()
+	report.RecordRun("trace-a", 2, []MatchEvent{{SpanID: "span-2"}})
+	report.RecordRun("trace-a", 1, []MatchEvent{{SpanID: "span-1"}})
+
+	traces := report.Traces()
+	require.Len(t, traces, 2)
+	assert.Equal(t, 1, traces[0].Run)
+	assert.Equal(t, "span-1", traces[0].Matches[0].SpanID)
+	assert.Equal(t, 2, traces[1].Run)
+	assert.Equal(t, "span-2", traces[1].Matches[0].SpanID)
+}
@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+)
+
+// MatchStatistics counts mock matches by the matcher priority that found
+// them. Matches against suite-wide or global spans are counted as global
+// fallbacks whatever their match type, so the categories don't overlap.
+type MatchStatistics struct {
+	Pinned            int `json:"pinned"`
+	PrimaryKey        int `json:"primaryKey"`
+	ValueHash         int `json:"valueHash"`
+	ReducedValueHash  int `json:"reducedValueHash"`
+	SchemaHash        int `json:"schemaHash"`
+	ReducedSchemaHash int `json:"reducedSchemaHash"`
+	Fuzzy             int `json:"fuzzy"`
+	Fallback          int `json:"fallback"`
+	GlobalFallback    int `json:"globalFallback"`
+}
+
+// Add folds events into the statistics.
+func (s *MatchStatistics) Add(events []MatchEvent) {
+	for _, ev := range events {
+		ml := ev.MatchLevel
+		if ml == nil {
+			continue
+		}
+		if ml.MatchScope == core.MatchScope_MATCH_SCOPE_GLOBAL {
+			s.GlobalFallback++
+			continue
+		}
+		if ml.MatchDescription == pinnedMatchDescription {
+			s.Pinned++
+			continue
+		}
+		if strings.HasPrefix(ml.MatchDescription, primaryKeyMatchPrefix) {
+			s.PrimaryKey++
+			continue
+		}
+		switch ml.MatchType {
+		case core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH:
+			s.ValueHash++
+		case core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH_REDUCED_SCHEMA:
+			s.ReducedValueHash++
+		case core.MatchType_MATCH_TYPE_INPUT_SCHEMA_HASH:
+			s.SchemaHash++
+		case core.MatchType_MATCH_TYPE_INPUT_SCHEMA_HASH_REDUCED_SCHEMA:
+			s.ReducedSchemaHash++
+		case core.MatchType_MATCH_TYPE_FUZZY:
+			s.Fuzzy++
+		default:
+			s.Fallback++
+		}
+	}
+}
+
+// Total returns the number of matches counted.
+func (s MatchStatistics) Total() int {
+	return s.Pinned + s.PrimaryKey + s.ValueHash + s.ReducedValueHash + s.SchemaHash + s.ReducedSchemaHash +
+		s.Fuzzy + s.Fallback + s.GlobalFallback
+}
+
+// String formats the non-zero counts in matcher priority order, e.g.
+// "Value hash: 812, Reduced value hash: 40, Global fallback: 3".
+func (s MatchStatistics) String() string {
+	var parts []string
+	for _, c := range []struct {
+		label string
+		n     int
+	}{
+		{"Pinned", s.Pinned},
+		{"Primary key", s.PrimaryKey},
+		{"Value hash", s.ValueHash},
+		{"Reduced value hash", s.ReducedValueHash},
+		{"Schema hash", s.SchemaHash},
+		{"Reduced schema hash", s.ReducedSchemaHash},
+		{"Fuzzy", s.Fuzzy},
+		{"Fallback", s.Fallback},
+		{"Global fallback", s.GlobalFallback},
+	} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %d", c.label, c.n))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,41 @@
+package runner
+
+import (
+	"testing"
+
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func matchEventOf(matchType core.MatchType, scope core.MatchScope) MatchEvent {
+	return MatchEvent{MatchLevel: &core.MatchLevel{MatchType: matchType, MatchScope: scope}}
+}
+
+func TestMatchStatistics_Add(t *testing.T) {
+	trace := core.MatchScope_MATCH_SCOPE_TRACE
+	var stats MatchStatistics
+	stats.Add([]MatchEvent{
+		matchEventOf(core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH, trace),
+		matchEventOf(core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH, trace),
+		matchEventOf(core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH_REDUCED_SCHEMA, trace),
+		matchEventOf(core.MatchType_MATCH_TYPE_INPUT_SCHEMA_HASH, trace),
+		// Primary key and pinned matches share the value hash type but are counted apart
+		{MatchLevel: &core.MatchLevel{
+			MatchType:        core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH,
+			MatchScope:       trace,
+			MatchDescription: primaryKeyMatchPrefix + "$.body.id",
+		}},
+		{MatchLevel: &core.MatchLevel{
+			MatchType:        core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH,
+			MatchScope:       trace,
+			MatchDescription: pinnedMatchDescription,
+		}},
+		// Global scope wins over the match type
+		matchEventOf(core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH, core.MatchScope_MATCH_SCOPE_GLOBAL),
+		{SpanID: "no-level"},
+	})
+
+	assert.Equal(t, MatchStatistics{Pinned: 1, PrimaryKey: 1, ValueHash: 2, ReducedValueHash: 1, SchemaHash: 1, GlobalFallback: 1}, stats)
+	assert.Equal(t, 7, stats.Total())
+	assert.Equal(t, "Pinned: 1, Primary key: 1, Value hash: 2, Reduced value hash: 1, Schema hash: 1, Global fallback: 1", stats.String())
+}
@@ -0,0 +1,110 @@
+package runner
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+)
+
+// maxDiffSummaryParts caps how many differing fields a diff summary names.
+const maxDiffSummaryParts = 5
+
+// closestSpan returns the recorded span of the request's package that is most
+// similar to the request, used or not, for mock-not-found diagnostics. Spans
+// from testID's trace are preferred; suite spans are searched when the trace
+// has none for the package.
+func (mm *MockMatcher) closestSpan(req *core.GetMockRequest, testID string) (*core.Span, float64) {
+	pkg := req.OutboundSpan.GetPackageName()
+	candidates := mm.server.GetSpansByPackageForTrace(testID, pkg)
+	if len(candidates) == 0 {
+		candidates = mm.server.GetSuiteSpansByPackage(pkg)
+	}
+	if len(candidates) == 0 {
+		return nil, 0
+	}
+
+	requestData := mm.reqToRequestData(req)
+	// An empty trace ID keeps the diagnostic search out of the test log
+	best, bestScore, _ := mm.findBestMatchBySimilarity(requestData, candidates, true, "")
+	if used, usedScore, _ := mm.findBestMatchBySimilarity(requestData, candidates, false, ""); used != nil && (best == nil || usedScore > bestScore) {
+		best, bestScore = used, usedScore
+	}
+	return best, bestScore
+}
+
+// summarizeInputDiff names the fields where recorded differs from requested,
+// e.g. "path (recorded /users/1, requested /users/2), query key 'expand'". HTTP paths and
+// query keys are compared individually; other fields are compared whole.
+func summarizeInputDiff(requested, recorded any) string {
+	reqMap, ok1 := requested.(map[string]any)
+	recMap, ok2 := recorded.(map[string]any)
+	if !ok1 || !ok2 {
+		if reflect.DeepEqual(requested, recorded) {
+			return ""
+		}
+		return "input"
+	}
+
+	var parts []string
+	skip := map[string]bool{}
+	reqPath, reqQuery := extractPathAndQuery(reqMap)
+	recPath, recQuery := extractPathAndQuery(recMap)
+	if reqPath != "" || recPath != "" {
+		skip["path"], skip["url"], skip["target"] = true, true, true
+		if reqPath != recPath {
+			parts = append(parts, fmt.Sprintf("path (recorded %s, requested %s)", recPath, reqPath))
+		}
+		reqValues, _ := url.ParseQuery(reqQuery)
+		recValues, _ := url.ParseQuery(recQuery)
+		for _, key := range unionKeys(reqValues, recValues) {
+			if !slices.Equal(reqValues[key], recValues[key]) {
+				parts = append(parts, fmt.Sprintf("query key '%s'", key))
+			}
+		}
+	}
+
+	for _, key := range unionKeys(reqMap, recMap) {
+		if skip[key] {
+			continue
+		}
+		if !reflect.DeepEqual(reqMap[key], recMap[key]) {
+			parts = append(parts, fmt.Sprintf("field '%s'", key))
+		}
+	}
+
+	if len(parts) > maxDiffSummaryParts {
+		parts = append(parts[:maxDiffSummaryParts], fmt.Sprintf("%d more fields", len(parts)-maxDiffSummaryParts))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// unionKeys returns the keys of a and b, sorted.
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	return slices.Sorted(maps.Keys(keys))
+}
+
+// ClosestDescription describes the closest recorded span, e.g. "closest was
+// GET /users/42 with 0.91 similarity, differing on query key 'expand'".
+// Returns "" when no candidate span was found.
+func (ev MockNotFoundEvent) ClosestDescription() string {
+	if ev.ClosestSpanID == "" {
+		return ""
+	}
+	desc := fmt.Sprintf("closest was %s with %.2f similarity", ev.ClosestSpanName, ev.ClosestScore)
+	if ev.DiffSummary != "" {
+		desc += ", differing on " + ev.DiffSummary
+	}
+	return desc
+}
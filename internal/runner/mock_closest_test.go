@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+)
+
+func TestFindMockReportsClosestSpanWhenNotFound(t *testing.T) {
+	cfg, _ := config.Get()
+	server, err := NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+
+	recordedSchema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{"method": {}, "url": {}, "headers": {}},
+	}
+	traceID := "trace-closest"
+	closest := makeSpan(t, traceID, "get-user", "http", map[string]any{
+		"method": "GET", "url": "http://users/users/42?expand=team", "headers": map[string]any{"accept": "json"},
+	}, recordedSchema, 100)
+	closest.Name = "GET /users/42"
+	other := makeSpan(t, traceID, "create-order", "http", map[string]any{
+		"method": "POST", "url": "http://orders/orders", "headers": map[string]any{"accept": "xml"},
+	}, recordedSchema, 200)
+	other.Name = "POST /orders"
+	server.LoadSpansForTrace(traceID, []*core.Span{closest, other})
+
+	// The request differs in shape (no headers) and in its query, so nothing matches
+	req := makeMockRequest(t, "http", map[string]any{"method": "GET", "url": "http://users/users/42"},
+		&core.JsonSchema{Properties: map[string]*core.JsonSchema{"method": {}, "url": {}}})
+	req.TestId = traceID
+	resp := server.findMock(req, nil)
+	require.False(t, resp.Found)
+
+	events := server.GetMockNotFoundEvents(traceID)
+	require.Len(t, events, 1)
+	ev := events[0]
+	assert.Equal(t, "get-user", ev.ClosestSpanID)
+	assert.Equal(t, "GET /users/42", ev.ClosestSpanName)
+	assert.Greater(t, ev.ClosestScore, 0.0)
+	assert.Equal(t, "query key 'expand', field 'headers'", ev.DiffSummary)
+	assert.Contains(t, ev.ClosestDescription(), "closest was GET /users/42 with ")
+	assert.Contains(t, ev.ClosestDescription(), "differing on query key 'expand'")
+}
+
+func TestSummarizeInputDiff(t *testing.T) {
+	assert.Equal(t, "path (recorded /users/1, requested /users/2)",
+		summarizeInputDiff(map[string]any{"path": "/users/2"}, map[string]any{"path": "/users/1"}))
+	assert.Equal(t, "field 'key'",
+		summarizeInputDiff(map[string]any{"command": "GET", "key": "a"}, map[string]any{"command": "GET", "key": "b"}))
+	assert.Equal(t, "", summarizeInputDiff(map[string]any{"a": 1.0}, map[string]any{"a": 1.0}))
+	assert.Equal(t, "input", summarizeInputDiff("SELECT 1", "SELECT 2"))
+	assert.Equal(t, "", MockNotFoundEvent{}.ClosestDescription())
+}
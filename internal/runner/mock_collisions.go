@@ -0,0 +1,134 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Use-Tusk/tusk-cli/internal/log"
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+)
+
+// MockCollision is a pair of outbound spans in one trace that the mock
+// matcher could confuse: their inputs match by schema and HTTP shape and are
+// similar enough that a replayed call resembling one would be an ambiguous
+// match for the other.
+type MockCollision struct {
+	TraceID     string  `json:"traceId"`
+	Name        string  `json:"name,omitempty"`
+	PackageName string  `json:"packageName"`
+	SpanA       string  `json:"spanA"`
+	SpanAName   string  `json:"spanAName,omitempty"`
+	SpanB       string  `json:"spanB"`
+	SpanBName   string  `json:"spanBName,omitempty"`
+	Score       float64 `json:"score"`
+}
+
+// CompareMocks reports, without starting the service, every pair of outbound
+// spans within a trace that would collide during mock matching. A pair
+// collides when one span passes the other's schema and HTTP-shape check and
+// their similarity is above 1 - mock_matching.ambiguity_epsilon, the point
+// where a call exactly like one span is flagged as an ambiguous match.
+func (e *Executor) CompareMocks(tests []Test) ([]MockCollision, error) {
+	server, err := e.newConfiguredServer()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = server.Stop() }()
+	mm := NewMockMatcher(server)
+	threshold := 1 - server.AmbiguityEpsilon()
+
+	collisions := []MockCollision{}
+	for _, test := range tests {
+		spans := test.Spans
+		if len(spans) == 0 {
+			spans, err = e.LoadSpansForTrace(test.TraceID, test.FileName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load spans for trace %s: %w", test.TraceID, err)
+			}
+		}
+		collisions = append(collisions, mm.traceCollisions(test, spans, threshold)...)
+	}
+	return collisions, nil
+}
+
+func (mm *MockMatcher) traceCollisions(test Test, spans []*core.Span, threshold float64) []MockCollision {
+	outbound := make([]*core.Span, 0, len(spans))
+	for _, span := range spans {
+		if span.Kind == core.SpanKind_SPAN_KIND_CLIENT && !span.IsRootSpan {
+			outbound = append(outbound, span)
+		}
+	}
+	sort.SliceStable(outbound, func(i, j int) bool {
+		return outbound[i].GetTimestamp().AsTime().Before(outbound[j].GetTimestamp().AsTime())
+	})
+
+	compare := mm.stringSimilarity()
+	var collisions []MockCollision
+	for i, a := range outbound {
+		requestData := mm.reqToRequestData(&core.GetMockRequest{TestId: test.TraceID, OutboundSpan: a})
+		for _, b := range outbound[i+1:] {
+			if a.PackageName != b.PackageName || !mm.schemaMatchWithHttpShape(requestData, b) {
+				continue
+			}
+			var bValue any
+			if b.InputValue != nil {
+				bValue = b.InputValue.AsMap()
+			}
+			schema := requestData.InputSchema
+			if schema == nil {
+				schema = b.InputSchema
+			}
+			score := calculateSimilarityScore(requestData.InputValue, bValue, schema, 0, compare)
+			if score <= threshold {
+				continue
+			}
+			collisions = append(collisions, MockCollision{
+				TraceID:     test.TraceID,
+				Name:        test.DisplayName,
+				PackageName: a.PackageName,
+				SpanA:       a.SpanId,
+				SpanAName:   a.Name,
+				SpanB:       b.SpanId,
+				SpanBName:   b.Name,
+				Score:       score,
+			})
+		}
+	}
+	return collisions
+}
+
+// OutputMockCollisions prints the colliding span pairs and returns an error
+// when there are any.
+func OutputMockCollisions(collisions []MockCollision, tests int, format string) error {
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(collisions); err != nil {
+			return err
+		}
+	} else {
+		// Collisions of a trace are adjacent; print its header once
+		for i, c := range collisions {
+			if i == 0 || collisions[i-1].TraceID != c.TraceID {
+				label := c.TraceID
+				if c.Name != "" {
+					label = fmt.Sprintf("%s (%s)", c.Name, c.TraceID)
+				}
+				log.UserDeviation(fmt.Sprintf("COLLIDING MOCKS - %s", label))
+			}
+			log.Println(fmt.Sprintf("  %s spans %s (%s) and %s (%s): %.2f similarity", c.PackageName, c.SpanA, c.SpanAName, c.SpanB, c.SpanBName, c.Score))
+		}
+	}
+
+	traces := make(map[string]struct{})
+	for _, c := range collisions {
+		traces[c.TraceID] = struct{}{}
+	}
+	log.Stderrln(fmt.Sprintf("\nCompare mocks: %d tests, %d colliding span pairs in %d traces", tests, len(collisions), len(traces)))
+	if len(collisions) > 0 {
+		return fmt.Errorf("%d pairs of recorded spans could be confused by the mock matcher", len(collisions))
+	}
+	return nil
+}
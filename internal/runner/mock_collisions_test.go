@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"testing"
+
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareMocks_ReportsCollidingPair(t *testing.T) {
+	traceID := "collide-trace"
+	schema := &core.JsonSchema{Type: core.JsonSchemaType_JSON_SCHEMA_TYPE_OBJECT}
+	root := makeSpan(t, traceID, "root", "http", map[string]any{"method": "GET", "path": "/orders"}, schema, 100)
+	root.IsRootSpan = true
+	root.Kind = core.SpanKind_SPAN_KIND_SERVER
+
+	outbound := func(spanID string, input map[string]any, tsMs int64) *core.Span {
+		span := makeSpan(t, traceID, spanID, "http", input, schema, tsMs)
+		span.Kind = core.SpanKind_SPAN_KIND_CLIENT
+		span.Name = "GET /rates"
+		return span
+	}
+	first := outbound("rates-1", map[string]any{"method": "GET", "hostname": "rates.example.com", "path": "/rates?currency=usd", "headers": map[string]any{"x-request-id": "req-000000000001"}}, 200)
+	second := outbound("rates-2", map[string]any{"method": "GET", "hostname": "rates.example.com", "path": "/rates?currency=usd", "headers": map[string]any{"x-request-id": "req-000000000002"}}, 300)
+	distinct := outbound("users", map[string]any{"method": "GET", "hostname": "rates.example.com", "path": "/users/1"}, 400)
+
+	e := NewExecutor()
+	collisions, err := e.CompareMocks([]Test{{TraceID: traceID, DisplayName: "GET /orders", Spans: []*core.Span{distinct, second, root, first}}})
+	require.NoError(t, err)
+	require.Len(t, collisions, 1, "only the two near-identical rate lookups collide")
+	c := collisions[0]
+	assert.Equal(t, traceID, c.TraceID)
+	assert.Equal(t, "http", c.PackageName)
+	assert.Equal(t, "rates-1", c.SpanA, "pairs are listed in recorded order")
+	assert.Equal(t, "rates-2", c.SpanB)
+	assert.Greater(t, c.Score, 0.95)
+	assert.Less(t, c.Score, 1.0)
+
+	err = OutputMockCollisions(collisions, 1, "text")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 pairs of recorded spans")
+	require.NoError(t, OutputMockCollisions(nil, 1, "text"))
+}
+
+func TestCompareMocks_IgnoresSpansThatFailSchemaMatch(t *testing.T) {
+	traceID := "no-collide-trace"
+	schemaA := &core.JsonSchema{Type: core.JsonSchemaType_JSON_SCHEMA_TYPE_OBJECT}
+	schemaB := &core.JsonSchema{Type: core.JsonSchemaType_JSON_SCHEMA_TYPE_STRING}
+	a := makeSpan(t, traceID, "a", "pg", map[string]any{"query": "SELECT * FROM users WHERE id = $1"}, schemaA, 100)
+	b := makeSpan(t, traceID, "b", "pg", map[string]any{"query": "SELECT * FROM users WHERE id = $1"}, schemaB, 200)
+	a.Kind, b.Kind = core.SpanKind_SPAN_KIND_CLIENT, core.SpanKind_SPAN_KIND_CLIENT
+
+	collisions, err := NewExecutor().CompareMocks([]Test{{TraceID: traceID, Spans: []*core.Span{a, b}}})
+	require.NoError(t, err)
+	assert.Empty(t, collisions, "different schema hashes never compete in schema matching")
+}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"net/url"
 	"runtime"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
 
 	"github.com/Use-Tusk/tusk-cli/internal/log"
 	"github.com/Use-Tusk/tusk-cli/internal/utils"
@@ -20,17 +23,30 @@ type MockMatcherRequestData struct {
 	InputValueHash  string
 	InputSchema     *core.JsonSchema
 	InputSchemaHash string
+	Timestamp       time.Time // When the SDK issued the request; zero if unknown
 }
 
 type MockMatcher struct {
 	server *Server
+	search *mockSearch // nil when the search can't be abandoned
 }
 
-func reducedInputValueHash(span *core.Span) string {
-	if span == nil || span.InputValue == nil || span.InputSchema == nil {
+// Pinned and primary key matches carry MATCH_TYPE_INPUT_VALUE_HASH, as the
+// schema has no type of their own, so their description is what tells them
+// apart.
+const (
+	pinnedMatchDescription = "pinned by mock_matching.pins"
+	primaryKeyMatchPrefix  = "primary key "
+)
+
+// reducedInputValueHash hashes the span's input with 0-importance fields dropped.
+// ignorePaths (from mock_matching.ignore_fields) are treated as 0-importance too.
+func reducedInputValueHash(span *core.Span, ignorePaths []string) string {
+	if span == nil || span.InputValue == nil || (span.InputSchema == nil && len(ignorePaths) == 0) {
 		return ""
 	}
-	reduced := utils.ReduceByMatchImportance(span.InputValue.AsMap(), span.InputSchema)
+	schema := utils.IgnoreFieldsInSchema(span.InputSchema, ignorePaths)
+	reduced := utils.ReduceByMatchImportance(span.InputValue.AsMap(), schema)
 	return utils.GenerateDeterministicHash(reduced)
 }
 
@@ -43,12 +59,11 @@ func reducedInputSchemaHash(span *core.Span) string {
 	return utils.GenerateDeterministicHash(reduced)
 }
 
-func reducedRequestValueHash(req *core.GetMockRequest) string {
-	if req == nil || req.OutboundSpan == nil || req.OutboundSpan.InputValue == nil || req.OutboundSpan.InputSchema == nil {
+func (mm *MockMatcher) reducedRequestValueHash(req *core.GetMockRequest) string {
+	if req == nil || req.OutboundSpan == nil {
 		return ""
 	}
-	reduced := utils.ReduceByMatchImportance(req.OutboundSpan.InputValue.AsMap(), req.OutboundSpan.InputSchema)
-	return utils.GenerateDeterministicHash(reduced)
+	return reducedInputValueHash(req.OutboundSpan, mm.server.ignoredFieldsFor(req.OutboundSpan.PackageName))
 }
 
 func reducedRequestSchemaHash(req *core.GetMockRequest) string {
@@ -94,7 +109,8 @@ func shouldSkipSchemaFallbackMatching(req *core.GetMockRequest) bool {
 }
 
 // FindBestMatchWithTracePriority implements the priority matching algorithm.
-// It first searches the current trace (Priorities 1-4), then checks suite-wide by value hash
+// It first searches the current trace (Priority 0 for mock_matching.primary_keys,
+// then Priorities 1-4), then checks suite-wide by value hash
 // (Priorities 5-6), then falls back to schema-based matching in the current trace (Priorities 7-10).
 func (mm *MockMatcher) FindBestMatchWithTracePriority(req *core.GetMockRequest, traceID string) (*core.Span, *core.MatchLevel, error) {
 	filteredSpans := mm.server.GetSpansByPackageForTrace(traceID, req.OutboundSpan.PackageName)
@@ -115,7 +131,7 @@ func (mm *MockMatcher) FindBestMatchAcrossTraces(req *core.GetMockRequest, trace
 	// Priority 12: Input value hash across suite (use index)
 	// Note: This is duplicated in Priority 5 in runPriorityMatchingWithTraceSpans for all requests.
 	candidates := mm.server.GetSuiteSpansByValueHash(inputValueHash)
-	filteredCandidates := mm.filterByPreAppStart(candidates, requestIsPreAppStart)
+	filteredCandidates := mm.filterByFallbackHost(mm.filterByPreAppStart(candidates, requestIsPreAppStart))
 	if match := mm.findFirstUnused(filteredCandidates); match != nil {
 		return match, &core.MatchLevel{
 			MatchType:        core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH,
@@ -133,9 +149,9 @@ func (mm *MockMatcher) FindBestMatchAcrossTraces(req *core.GetMockRequest, trace
 
 	// Priority 13: Reduced input value hash across suite (use index)
 	// Note: This is duplicated in Priority 6 in runPriorityMatchingWithTraceSpans for all requests.
-	reducedHash := reducedRequestValueHash(req)
+	reducedHash := mm.reducedRequestValueHash(req)
 	reducedCandidates := mm.server.GetSuiteSpansByReducedValueHash(reducedHash)
-	filteredReducedCandidates := mm.filterByPreAppStart(reducedCandidates, requestIsPreAppStart)
+	filteredReducedCandidates := mm.filterByFallbackHost(mm.filterByPreAppStart(reducedCandidates, requestIsPreAppStart))
 
 	if match := mm.findFirstUnused(filteredReducedCandidates); match != nil {
 		return match, &core.MatchLevel{
@@ -159,12 +175,12 @@ func (mm *MockMatcher) FindBestMatchAcrossTraces(req *core.GetMockRequest, trace
 		return nil, nil, fmt.Errorf("no matching span found")
 	}
 
-	requestData := reqToRequestData(req)
+	requestData := mm.reqToRequestData(req)
 
 	// Priority 14: Input schema hash across suite (use index + similarity scoring)
 	inputSchemaHash := req.OutboundSpan.GetInputSchemaHash()
 	schemaCandidates := mm.server.GetSuiteSpansBySchemaHash(inputSchemaHash)
-	filteredSchemaCandidates := mm.filterByPreAppStart(schemaCandidates, true)
+	filteredSchemaCandidates := mm.filterByFallbackHost(mm.filterByPreAppStart(schemaCandidates, true))
 
 	if unusedSchema := mm.filterUnused(filteredSchemaCandidates); len(unusedSchema) > 0 {
 		best, score, _ := mm.findBestMatchBySimilarity(requestData, unusedSchema, true, "pre-app-start")
@@ -192,7 +208,7 @@ func (mm *MockMatcher) FindBestMatchAcrossTraces(req *core.GetMockRequest, trace
 	// Priority 15: Reduced input schema hash across suite (use index + similarity scoring)
 	reducedSchemaHash := reducedRequestSchemaHash(req)
 	reducedSchemaCandidates := mm.server.GetSuiteSpansByReducedSchemaHash(reducedSchemaHash)
-	filteredReducedSchemaCandidates := mm.filterByPreAppStart(reducedSchemaCandidates, true)
+	filteredReducedSchemaCandidates := mm.filterByFallbackHost(mm.filterByPreAppStart(reducedSchemaCandidates, true))
 
 	if unusedReduced := mm.filterUnused(filteredReducedSchemaCandidates); len(unusedReduced) > 0 {
 		best, score, _ := mm.findBestMatchBySimilarity(requestData, unusedReduced, true, "pre-app-start")
@@ -226,13 +242,13 @@ func (mm *MockMatcher) runPriorityMatchingWithTraceSpans(req *core.GetMockReques
 	var requestBody any
 	if req.OutboundSpan.InputValue != nil {
 		requestBody = req.OutboundSpan.InputValue.AsMap()
-		if !req.OutboundSpan.IsPreAppStart {
-			logStr := RedactSecrets(fmt.Sprintf("Finding best match for request: %v", requestBody))
+		if !req.OutboundSpan.IsPreAppStart && !mm.server.quietMocks.Load() {
+			logStr := RedactSecrets(fmt.Sprintf("Finding best match for request: %v", mm.server.redactFields(requestBody)))
 			log.TestLog(traceID, logStr)
 		}
 	}
 
-	schema := req.OutboundSpan.InputSchema
+	schema := utils.IgnoreFieldsInSchema(req.OutboundSpan.InputSchema, mm.server.ignoredFieldsFor(req.OutboundSpan.PackageName))
 	schemaHash := req.OutboundSpan.InputSchemaHash
 	valueHash := req.OutboundSpan.InputValueHash
 
@@ -241,23 +257,13 @@ func (mm *MockMatcher) runPriorityMatchingWithTraceSpans(req *core.GetMockReques
 		InputValueHash:  valueHash,
 		InputSchema:     schema,
 		InputSchemaHash: schemaHash,
+		Timestamp:       requestTimestamp(req),
 	}
 
 	sortedSpans := make([]*core.Span, len(spans))
 	copy(sortedSpans, spans)
 	sort.Slice(sortedSpans, func(i, j int) bool {
-		// Sort by timestamp (oldest first)
-		// Handle nil timestamps by treating them as oldest
-		if sortedSpans[i].Timestamp == nil && sortedSpans[j].Timestamp == nil {
-			return sortedSpans[i].SpanId < sortedSpans[j].SpanId // Fallback to span ID
-		}
-		if sortedSpans[i].Timestamp == nil {
-			return true // nil timestamps come first
-		}
-		if sortedSpans[j].Timestamp == nil {
-			return false
-		}
-		return sortedSpans[i].Timestamp.AsTime().Before(sortedSpans[j].Timestamp.AsTime())
+		return spanRecordedBefore(sortedSpans[i], sortedSpans[j])
 	})
 
 	log.Debug("Finding best match for request",
@@ -265,9 +271,52 @@ func (mm *MockMatcher) runPriorityMatchingWithTraceSpans(req *core.GetMockReques
 		"traceID", traceID,
 		"scope", scope)
 
+	// Pinned span (mock_matching.pins), ahead of every other priority
+	if match := mm.pinnedSpan(req, requestBody, sortedSpans); match != nil {
+		log.Debug("Found pinned span", "spanName", match.Name, "spanID", match.SpanId)
+		mm.markSpanAsUsed(match)
+		return match, &core.MatchLevel{
+			MatchType:        core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH,
+			MatchScope:       core.MatchScope_MATCH_SCOPE_TRACE,
+			MatchDescription: pinnedMatchDescription,
+		}, nil
+	}
+
+	// Priority 0: Span with the same primary key (mock_matching.primary_keys)
+	if keyPath := mm.server.primaryKeyFor(req.OutboundSpan.PackageName); keyPath != "" {
+		if key, ok := inputValueAtPath(requestBody, keyPath); ok {
+			log.Debug("Trying Priority 0: Span by primary key", "traceId", traceID, "path", keyPath)
+			keyCandidates := spansWithInputValueAt(sortedSpans, keyPath, key)
+			if match := mm.findFirstUnused(keyCandidates); match != nil {
+				log.Debug("Found unused span by primary key", "spanName", match.Name)
+				mm.markSpanAsUsed(match)
+				return match, &core.MatchLevel{
+					MatchType:        core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH,
+					MatchScope:       core.MatchScope_MATCH_SCOPE_TRACE,
+					MatchDescription: primaryKeyMatchPrefix + keyPath,
+				}, nil
+			}
+			if match := mm.findFirstUsed(keyCandidates); match != nil {
+				log.Debug("Found used span by primary key", "spanName", match.Name)
+				mm.markSpanAsUsed(match)
+				return match, &core.MatchLevel{
+					MatchType:        core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH,
+					MatchScope:       core.MatchScope_MATCH_SCOPE_TRACE,
+					MatchDescription: primaryKeyMatchPrefix + keyPath + " (used span)",
+				}, nil
+			}
+			log.Debug("Priority 0 failed: No span by primary key", "traceId", traceID)
+		}
+	}
+
 	// Priority 1: Unused span by input value hash (use index)
 	log.Debug("Trying Priority 1: Unused span by input value hash", "traceId", traceID)
 	candidates := mm.server.GetSpansByValueHashForTrace(traceID, requestData.InputValueHash)
+	if len(candidates) > 1 && mm.server.PoolIdenticalSpans() && mm.search.commit() {
+		// Identical calls may have been recorded on connections opened in a
+		// different order, so pick by recorded timeline rather than oldest-first
+		return mm.matchFromPool(requestData, traceID, "value:"+requestData.InputValueHash, candidates, core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH, "input value hash")
+	}
 	if match := mm.findFirstUnused(candidates); match != nil {
 		log.Debug("Found unused span by input value hash", "spanName", match.Name)
 		mm.markSpanAsUsed(match)
@@ -294,8 +343,11 @@ func (mm *MockMatcher) runPriorityMatchingWithTraceSpans(req *core.GetMockReques
 
 	// Priority 3: Unused span by reduced input value hash (use index)
 	log.Debug("Trying Priority 3: Unused span by input value hash with reduced schema", "traceId", traceID)
-	reducedHash := reducedRequestValueHash(req)
+	reducedHash := mm.reducedRequestValueHash(req)
 	reducedCandidates := mm.server.GetSpansByReducedValueHashForTrace(traceID, reducedHash)
+	if len(reducedCandidates) > 1 && mm.server.PoolIdenticalSpans() && mm.search.commit() {
+		return mm.matchFromPool(requestData, traceID, "reduced:"+reducedHash, reducedCandidates, core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH_REDUCED_SCHEMA, "input value hash with reduced schema")
+	}
 	if match := mm.findFirstUnused(reducedCandidates); match != nil {
 		log.Debug("Found unused span by input value hash with reduced schema", "spanName", match.Name)
 		mm.markSpanAsUsed(match)
@@ -327,7 +379,7 @@ func (mm *MockMatcher) runPriorityMatchingWithTraceSpans(req *core.GetMockReques
 		// Validation mode: search all suite spans
 		log.Debug("Trying Priority 5: Input value hash across suite (validation mode)", "traceId", traceID)
 		suiteValueHashCandidates := mm.server.GetSuiteSpansByValueHash(req.OutboundSpan.GetInputValueHash())
-		filteredSuiteValueHashCandidates := mm.filterByPreAppStart(suiteValueHashCandidates, req.OutboundSpan.IsPreAppStart)
+		filteredSuiteValueHashCandidates := mm.filterByFallbackHost(mm.filterByPreAppStart(suiteValueHashCandidates, req.OutboundSpan.IsPreAppStart))
 		if match := mm.findFirstUnused(filteredSuiteValueHashCandidates); match != nil {
 			log.Debug("Found suite unused span by input value hash", "spanName", match.Name)
 			mm.markSpanAsUsed(match)
@@ -349,8 +401,8 @@ func (mm *MockMatcher) runPriorityMatchingWithTraceSpans(req *core.GetMockReques
 		log.Debug("Priority 5 failed: No suite span by input value hash", "traceId", traceID)
 
 		log.Debug("Trying Priority 6: Reduced input value hash across suite (validation mode)", "traceId", traceID)
-		suiteReducedValueHashCandidates := mm.server.GetSuiteSpansByReducedValueHash(reducedRequestValueHash(req))
-		filteredSuiteReducedValueHashCandidates := mm.filterByPreAppStart(suiteReducedValueHashCandidates, req.OutboundSpan.IsPreAppStart)
+		suiteReducedValueHashCandidates := mm.server.GetSuiteSpansByReducedValueHash(mm.reducedRequestValueHash(req))
+		filteredSuiteReducedValueHashCandidates := mm.filterByFallbackHost(mm.filterByPreAppStart(suiteReducedValueHashCandidates, req.OutboundSpan.IsPreAppStart))
 		if match := mm.findFirstUnused(filteredSuiteReducedValueHashCandidates); match != nil {
 			log.Debug("Found suite unused span by reduced input value hash", "spanName", match.Name)
 			mm.markSpanAsUsed(match)
@@ -374,7 +426,7 @@ func (mm *MockMatcher) runPriorityMatchingWithTraceSpans(req *core.GetMockReques
 		// Regular replay mode: only search explicitly marked global spans
 		log.Debug("Trying Priority 5: Input value hash in global spans", "traceId", traceID)
 		globalValueHashCandidates := mm.server.GetGlobalSpansByValueHash(req.OutboundSpan.GetInputValueHash())
-		filteredGlobalValueHashCandidates := mm.filterByPreAppStart(globalValueHashCandidates, req.OutboundSpan.IsPreAppStart)
+		filteredGlobalValueHashCandidates := mm.filterByFallbackHost(mm.filterByPreAppStart(globalValueHashCandidates, req.OutboundSpan.IsPreAppStart))
 		if match := mm.findFirstUnused(filteredGlobalValueHashCandidates); match != nil {
 			log.Debug("Found global unused span by input value hash", "spanName", match.Name)
 			mm.markSpanAsUsed(match)
@@ -396,8 +448,8 @@ func (mm *MockMatcher) runPriorityMatchingWithTraceSpans(req *core.GetMockReques
 		log.Debug("Priority 5 failed: No global span by input value hash", "traceId", traceID)
 
 		log.Debug("Trying Priority 6: Reduced input value hash in global spans", "traceId", traceID)
-		globalReducedValueHashCandidates := mm.server.GetGlobalSpansByReducedValueHash(reducedRequestValueHash(req))
-		filteredGlobalReducedValueHashCandidates := mm.filterByPreAppStart(globalReducedValueHashCandidates, req.OutboundSpan.IsPreAppStart)
+		globalReducedValueHashCandidates := mm.server.GetGlobalSpansByReducedValueHash(mm.reducedRequestValueHash(req))
+		filteredGlobalReducedValueHashCandidates := mm.filterByFallbackHost(mm.filterByPreAppStart(globalReducedValueHashCandidates, req.OutboundSpan.IsPreAppStart))
 		if match := mm.findFirstUnused(filteredGlobalReducedValueHashCandidates); match != nil {
 			log.Debug("Found global unused span by reduced input value hash", "spanName", match.Name)
 			mm.markSpanAsUsed(match)
@@ -436,12 +488,16 @@ func (mm *MockMatcher) runPriorityMatchingWithTraceSpans(req *core.GetMockReques
 	// Priority 7: Unused span by input schema hash
 	log.Debug("Trying Priority 7: Unused span by input schema hash", "traceId", traceID)
 	if result := mm.findUnusedSpanByInputSchemaHash(requestData, sortedSpans, traceID); result.span != nil {
-		log.Debug("Found unused span by input schema hash", "spanName", result.span.Name)
+		log.Debug("Found unused span by input schema hash", "spanName", result.span.Name, "lenient", result.lenient)
 		mm.markSpanAsUsed(result.span)
+		description := "Unused span by input schema hash"
+		if result.lenient {
+			description = "Unused span by lenient input schema"
+		}
 		return result.span, buildMatchLevelWithSimilarity(
 			core.MatchType_MATCH_TYPE_INPUT_SCHEMA_HASH,
 			core.MatchScope_MATCH_SCOPE_TRACE,
-			"Unused span by input schema hash",
+			description,
 			result,
 		), nil
 	}
@@ -450,12 +506,16 @@ func (mm *MockMatcher) runPriorityMatchingWithTraceSpans(req *core.GetMockReques
 	// Priority 8: Used span by input schema hash
 	log.Debug("Trying Priority 8: Used span by input schema hash", "traceId", traceID)
 	if result := mm.findUsedSpanByInputSchemaHash(requestData, sortedSpans, traceID); result.span != nil {
-		log.Debug("Found used span by input schema hash", "spanName", result.span.Name)
+		log.Debug("Found used span by input schema hash", "spanName", result.span.Name, "lenient", result.lenient)
 		mm.markSpanAsUsed(result.span)
+		description := "Used span by input schema hash"
+		if result.lenient {
+			description = "Used span by lenient input schema"
+		}
 		return result.span, buildMatchLevelWithSimilarity(
 			core.MatchType_MATCH_TYPE_INPUT_SCHEMA_HASH,
 			core.MatchScope_MATCH_SCOPE_TRACE,
-			"Used span by input schema hash",
+			description,
 			result,
 		), nil
 	}
@@ -493,6 +553,10 @@ func (mm *MockMatcher) runPriorityMatchingWithTraceSpans(req *core.GetMockReques
 }
 
 func (mm *MockMatcher) markSpanAsUsed(span *core.Span) {
+	if !mm.search.commit() {
+		return
+	}
+
 	mm.server.mu.Lock()
 	defer mm.server.mu.Unlock()
 
@@ -577,6 +641,27 @@ func (mm *MockMatcher) filterByPreAppStart(spans []*core.Span, requestIsPreAppSt
 	return result
 }
 
+// filterByFallbackHost drops suite-wide and global candidates recorded against
+// a host outside mock_matching.global_fallback_hosts. Non-HTTP spans and HTTP
+// spans without a host are kept.
+func (mm *MockMatcher) filterByFallbackHost(spans []*core.Span) []*core.Span {
+	if len(spans) == 0 || !mm.server.hasGlobalFallbackHosts() {
+		return spans
+	}
+
+	var result []*core.Span
+	for _, span := range spans {
+		isHTTP := span.PackageName == "http" || span.PackageName == "https"
+		if isHTTP && span.InputValue != nil {
+			if host := extractHost(span.InputValue.AsMap()); host != "" && !mm.server.globalFallbackHostAllowed(host) {
+				continue
+			}
+		}
+		result = append(result, span)
+	}
+	return result
+}
+
 // buildMatchLevelWithSimilarity creates a MatchLevel with similarity scoring data
 func buildMatchLevelWithSimilarity(matchType core.MatchType, matchScope core.MatchScope, baseDescription string, result spanMatchResult) *core.MatchLevel {
 	level := &core.MatchLevel{
@@ -610,6 +695,15 @@ func buildMatchLevelWithSimilarity(matchType core.MatchType, matchScope core.Mat
 	return level
 }
 
+// isAmbiguousMatch reports whether the runner-up candidate of a similarity
+// match scored within epsilon of the chosen span.
+func isAmbiguousMatch(level *core.MatchLevel, epsilon float64) bool {
+	if level == nil || level.SimilarityScore == nil || len(level.TopCandidates) == 0 || epsilon <= 0 {
+		return false
+	}
+	return float64(*level.SimilarityScore-level.TopCandidates[0].GetScore()) < epsilon
+}
+
 // spanWithScore holds a span and its similarity score
 type spanWithScore struct {
 	span  *core.Span
@@ -617,15 +711,16 @@ type spanWithScore struct {
 }
 
 // calculateSimilarityScore computes a normalized similarity score between two values
-// by recursively comparing their structure using Levenshtein distance.
+// by recursively comparing their structure, comparing strings with compare.
+// If schema is provided, object keys are weighted by their matchImportance.
 // Returns a score between 0 and 1, where 1 is identical and 0 is completely different.
-func calculateSimilarityScore(a, b any, depth int) float64 {
+func calculateSimilarityScore(a, b any, schema *core.JsonSchema, depth int, compare stringSimilarity) float64 {
 	const maxDepth = 5
 	if depth > maxDepth {
 		// Beyond max depth, stringify and compare as strings
 		aStr := safeStringify(a)
 		bStr := safeStringify(b)
-		return compareStrings(aStr, bStr)
+		return compare(aStr, bStr)
 	}
 
 	// Handle nil cases
@@ -642,27 +737,27 @@ func calculateSimilarityScore(a, b any, depth int) float64 {
 		if !ok {
 			return 0.0
 		}
-		return compareMaps(aVal, bMap, depth)
+		return compareMaps(aVal, bMap, schema, depth, compare)
 
 	case []any:
 		bSlice, ok := b.([]any)
 		if !ok {
 			return 0.0
 		}
-		return compareSlices(aVal, bSlice, depth)
+		return compareSlices(aVal, bSlice, schema, depth, compare)
 
 	case string:
 		bStr, ok := b.(string)
 		if !ok {
 			return 0.0
 		}
-		return compareStrings(aVal, bStr)
+		return compare(aVal, bStr)
 
 	default:
 		// For numbers, bools, and other primitives, convert to string and compare
 		aStr := fmt.Sprintf("%v", a)
 		bStr := fmt.Sprintf("%v", b)
-		return compareStrings(aStr, bStr)
+		return compare(aStr, bStr)
 	}
 }
 
@@ -688,7 +783,9 @@ func safeStringify(v any) string {
 	}
 }
 
-func compareMaps(a, b map[string]any, depth int) float64 {
+// compareMaps averages per-key similarity, weighting each key by its matchImportance
+// (1.0 when absent). Keys with matchImportance 0 are skipped entirely.
+func compareMaps(a, b map[string]any, schema *core.JsonSchema, depth int, compare stringSimilarity) float64 {
 	if len(a) == 0 && len(b) == 0 {
 		return 1.0
 	}
@@ -706,20 +803,51 @@ func compareMaps(a, b map[string]any, depth int) float64 {
 	}
 
 	totalScore := 0.0
+	totalWeight := 0.0
 	for key := range allKeys {
+		fieldSchema := schemaProperty(schema, key)
+		weight := matchImportanceWeight(fieldSchema)
+		if weight == 0 {
+			continue
+		}
+		totalWeight += weight
+
 		aVal, aExists := a[key]
 		bVal, bExists := b[key]
 
 		if aExists && bExists {
-			totalScore += calculateSimilarityScore(aVal, bVal, depth+1)
+			totalScore += weight * calculateSimilarityScore(aVal, bVal, fieldSchema, depth+1, compare)
 		}
 		// If key doesn't exist in both, it contributes 0 to the score
 	}
 
-	return totalScore / float64(len(allKeys))
+	if totalWeight == 0 {
+		// Every key was excluded from matching, so nothing distinguishes the values
+		return 1.0
+	}
+	return totalScore / totalWeight
+}
+
+// schemaProperty returns the schema for a field of an object schema, or nil.
+func schemaProperty(schema *core.JsonSchema, key string) *core.JsonSchema {
+	if schema == nil {
+		return nil
+	}
+	return schema.Properties[key]
+}
+
+// matchImportanceWeight returns the field's matchImportance, defaulting to 1.0 when unset.
+func matchImportanceWeight(schema *core.JsonSchema) float64 {
+	if schema == nil || schema.MatchImportance == nil {
+		return 1.0
+	}
+	if w := *schema.MatchImportance; w > 0 {
+		return w
+	}
+	return 0
 }
 
-func compareSlices(a, b []any, depth int) float64 {
+func compareSlices(a, b []any, schema *core.JsonSchema, depth int, compare stringSimilarity) float64 {
 	if len(a) == 0 && len(b) == 0 {
 		return 1.0
 	}
@@ -732,18 +860,82 @@ func compareSlices(a, b []any, depth int) float64 {
 		maxLen = len(b)
 	}
 
+	var itemSchema *core.JsonSchema
+	if schema != nil {
+		itemSchema = schema.Items
+	}
+
 	totalScore := 0.0
 	for i := 0; i < maxLen; i++ {
 		if i >= len(a) || i >= len(b) {
 			// One slice is shorter, contributes 0
 			continue
 		}
-		totalScore += calculateSimilarityScore(a[i], b[i], depth+1)
+		totalScore += calculateSimilarityScore(a[i], b[i], itemSchema, depth+1, compare)
 	}
 
 	return totalScore / float64(maxLen)
 }
 
+// stringSimilarity scores two strings between 0 (completely different) and 1
+// (identical).
+type stringSimilarity func(a, b string) float64
+
+// Values of mock_matching.similarity_algorithm.
+const (
+	similarityAlgorithmLevenshtein = "levenshtein"
+	similarityAlgorithmToken       = "token"
+)
+
+// stringSimilarity returns the string comparison selected by
+// mock_matching.similarity_algorithm.
+func (mm *MockMatcher) stringSimilarity() stringSimilarity {
+	if mm.server.SimilarityAlgorithm() == similarityAlgorithmToken {
+		return compareTokens
+	}
+	return compareStrings
+}
+
+// compareTokens scores strings by the overlap of their word tokens (runs of
+// letters, digits and underscores): the multiset Jaccard index |A∩B|/|A∪B|.
+// Token order and punctuation are ignored, so reordered SQL clauses or JSON
+// keys still score high while a changed table or column name costs a whole
+// token. Strings of at most one token each fall back to compareStrings, which
+// handles near-miss values like "42" and "43" better.
+func compareTokens(a, b string) float64 {
+	if a == b {
+		return 1.0
+	}
+	tokensA, tokensB := tokenCounts(a), tokenCounts(b)
+	if len(tokensA) <= 1 && len(tokensB) <= 1 {
+		return compareStrings(a, b)
+	}
+
+	intersection, union := 0, 0
+	for token, countA := range tokensA {
+		countB := tokensB[token]
+		intersection += min(countA, countB)
+		union += max(countA, countB)
+	}
+	for token, countB := range tokensB {
+		if _, ok := tokensA[token]; !ok {
+			union += countB
+		}
+	}
+	return float64(intersection) / float64(union)
+}
+
+// tokenCounts counts the word tokens in s.
+func tokenCounts(s string) map[string]int {
+	counts := make(map[string]int)
+	for _, token := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		counts[token]++
+	}
+	return counts
+}
+
 func compareStrings(a, b string) float64 {
 	if a == b {
 		return 1.0
@@ -828,21 +1020,9 @@ func (mm *MockMatcher) findBestMatchBySimilarity(requestData MockMatcherRequestD
 		return nil, 0.0, nil
 	}
 
-	// Limit to first 50 spans for performance
-	maxSpansToScore := 50
-	spansToScore := spans
-	if len(spans) > maxSpansToScore {
-		spansToScore = spans[:maxSpansToScore]
-	}
-
-	// log to current test the number of spans we are scoring
-	if testID != "" {
-		log.TestLog(testID, fmt.Sprintf("Picking best match between %d spans based on similarity score", len(spansToScore)))
-	}
-
-	// Filter spans first (before parallelizing)
+	// Filter spans first so usage state doesn't eat into the scan limit
 	var spansToCompare []*core.Span
-	for _, span := range spansToScore {
+	for _, span := range spans {
 		if isUnused && !mm.isUnused(span) {
 			continue
 		}
@@ -856,6 +1036,21 @@ func (mm *MockMatcher) findBestMatchBySimilarity(requestData MockMatcherRequestD
 		return nil, 0.0, nil
 	}
 
+	// Limit the number of spans scored for performance, preferring the spans
+	// recorded closest in time to when this request was made
+	maxSpansToScore := mm.server.GetSimilarityScanLimit()
+	if len(spansToCompare) > maxSpansToScore {
+		if target := mm.recordedTimelineTarget(requestData, testID); !target.IsZero() {
+			spansToCompare = sortByTimestampProximity(spansToCompare, target)
+		}
+		spansToCompare = spansToCompare[:maxSpansToScore]
+	}
+
+	// log to current test the number of spans we are scoring
+	if testID != "" && !mm.server.quietMocks.Load() {
+		log.TestLog(testID, fmt.Sprintf("Picking best match between %d spans based on similarity score", len(spansToCompare)))
+	}
+
 	// Parallelize similarity scoring
 	scored := mm.calculateSimilarityScoresParallel(requestData, spansToCompare)
 
@@ -863,22 +1058,24 @@ func (mm *MockMatcher) findBestMatchBySimilarity(requestData MockMatcherRequestD
 		return nil, 0.0, nil
 	}
 
-	// Sort by score (highest first), then by timestamp (oldest first)
+	// Sort by score (highest first), then by timestamp (oldest first), then by
+	// SpanId. The order is total, so the same spans always produce the same
+	// match regardless of load order or scoring concurrency.
 	sort.Slice(scored, func(i, j int) bool {
 		if scored[i].score != scored[j].score {
 			return scored[i].score > scored[j].score
 		}
-		// Tiebreaker: oldest timestamp
-		if scored[i].span.Timestamp == nil && scored[j].span.Timestamp == nil {
-			return scored[i].span.SpanId < scored[j].span.SpanId
-		}
-		if scored[i].span.Timestamp == nil {
+		ti, tj := scored[i].span.Timestamp, scored[j].span.Timestamp
+		switch {
+		case ti == nil && tj == nil:
+		case ti == nil:
 			return true
-		}
-		if scored[j].span.Timestamp == nil {
+		case tj == nil:
 			return false
+		case !ti.AsTime().Equal(tj.AsTime()):
+			return ti.AsTime().Before(tj.AsTime())
 		}
-		return scored[i].span.Timestamp.AsTime().Before(scored[j].span.Timestamp.AsTime())
+		return scored[i].span.SpanId < scored[j].span.SpanId
 	})
 
 	bestScore := scored[0].score
@@ -893,6 +1090,66 @@ func (mm *MockMatcher) findBestMatchBySimilarity(requestData MockMatcherRequestD
 	return scored[0].span, bestScore, topCandidates
 }
 
+// matchFromPool serves a request from pool, spans that hash the same for the
+// request (mock_matching.pool_identical_spans). what describes the hash tier.
+func (mm *MockMatcher) matchFromPool(requestData MockMatcherRequestData, traceID, poolKey string, pool []*core.Span, matchType core.MatchType, what string) (*core.Span, *core.MatchLevel, error) {
+	target := mm.recordedTimelineTarget(requestData, traceID)
+	match, wasUnused := mm.server.nextPooledSpan(traceID, poolKey, pool, target)
+	description := fmt.Sprintf("Used span by %s (pooled)", what)
+	if wasUnused {
+		description = fmt.Sprintf("Unused span by %s (pooled)", what)
+	}
+	log.Debug("Found span from pool", "spanName", match.Name, "poolSize", len(pool), "wasUnused", wasUnused, "byTimeline", !target.IsZero())
+	return match, &core.MatchLevel{
+		MatchType:        matchType,
+		MatchScope:       core.MatchScope_MATCH_SCOPE_TRACE,
+		MatchDescription: description,
+	}, nil
+}
+
+// recordedTimelineTarget maps the request's timestamp onto the recorded trace's
+// timeline, using its offset from when the executor sent the inbound request
+// (SetReplayStart) applied to the recorded root span. Returns the zero time if
+// the request carries no timestamp or either start is unknown: the replay
+// clock alone says nothing about when spans were recorded.
+func (mm *MockMatcher) recordedTimelineTarget(requestData MockMatcherRequestData, traceID string) time.Time {
+	if requestData.Timestamp.IsZero() || traceID == "" {
+		return time.Time{}
+	}
+
+	replayStart := mm.server.getReplayStart(traceID)
+	recordedRoot := mm.server.GetRootSpan(traceID)
+	if replayStart.IsZero() || recordedRoot == nil || recordedRoot.Timestamp == nil {
+		return time.Time{}
+	}
+
+	offset := requestData.Timestamp.Sub(replayStart)
+	return recordedRoot.Timestamp.AsTime().Add(offset)
+}
+
+// sortByTimestampProximity returns a copy of spans ordered by how close their
+// timestamp is to target. Spans without a timestamp go last; ties keep their order.
+func sortByTimestampProximity(spans []*core.Span, target time.Time) []*core.Span {
+	sorted := make([]*core.Span, len(spans))
+	copy(sorted, spans)
+
+	distance := func(s *core.Span) time.Duration {
+		d := s.Timestamp.AsTime().Sub(target)
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Timestamp == nil || sorted[j].Timestamp == nil {
+			return sorted[i].Timestamp != nil && sorted[j].Timestamp == nil
+		}
+		return distance(sorted[i]) < distance(sorted[j])
+	})
+	return sorted
+}
+
 // calculateSimilarityScoresParallel computes similarity scores in parallel using a worker pool
 func (mm *MockMatcher) calculateSimilarityScoresParallel(requestData MockMatcherRequestData, spans []*core.Span) []spanWithScore {
 	numSpans := len(spans)
@@ -918,6 +1175,7 @@ func (mm *MockMatcher) calculateSimilarityScoresParallel(requestData MockMatcher
 
 	jobs := make(chan job, numSpans)
 	results := make(chan spanWithScore, numSpans)
+	compare := mm.stringSimilarity()
 
 	// Start worker goroutines
 	var wg sync.WaitGroup
@@ -931,7 +1189,11 @@ func (mm *MockMatcher) calculateSimilarityScoresParallel(requestData MockMatcher
 					spanValue = j.span.InputValue.AsMap()
 				}
 
-				score := calculateSimilarityScore(requestData.InputValue, spanValue, 0)
+				schema := requestData.InputSchema
+				if schema == nil {
+					schema = j.span.InputSchema
+				}
+				score := calculateSimilarityScore(requestData.InputValue, spanValue, schema, 0, compare)
 				results <- spanWithScore{span: j.span, score: score}
 			}
 		}()
@@ -981,6 +1243,7 @@ type spanMatchResult struct {
 	bestScore       float64
 	topCandidates   []spanWithScore // Top 5 candidates with scores (excluding the best match)
 	multipleMatches bool
+	lenient         bool // matched by mock_matching.lenient_schema, not by schema hash
 }
 
 func (mm *MockMatcher) findUnusedSpanByInputSchemaHash(requestData MockMatcherRequestData, spans []*core.Span, testID string) spanMatchResult {
@@ -995,11 +1258,22 @@ func (mm *MockMatcher) findUnusedSpanByInputSchemaHash(requestData MockMatcherRe
 		}
 	}
 
+	// Exact schema matches win; lenient ones are only a fallback
+	lenient := false
+	if len(candidates) == 0 && mm.server.LenientSchema() {
+		for i := range spans {
+			if mm.isUnused(spans[i]) && mm.lenientSchemaMatchWithHttpShape(requestData, spans[i]) {
+				candidates = append(candidates, spans[i])
+			}
+		}
+		lenient = true
+	}
+
 	if len(candidates) == 0 {
 		return spanMatchResult{}
 	}
 	if len(candidates) == 1 {
-		return spanMatchResult{span: candidates[0], multipleMatches: false}
+		return spanMatchResult{span: candidates[0], multipleMatches: false, lenient: lenient}
 	}
 
 	// Multiple matches - use similarity scoring
@@ -1009,6 +1283,7 @@ func (mm *MockMatcher) findUnusedSpanByInputSchemaHash(requestData MockMatcherRe
 		bestScore:       bestScore,
 		topCandidates:   topCandidates,
 		multipleMatches: true,
+		lenient:         lenient,
 	}
 }
 
@@ -1024,11 +1299,22 @@ func (mm *MockMatcher) findUsedSpanByInputSchemaHash(requestData MockMatcherRequ
 		}
 	}
 
+	// Exact schema matches win; lenient ones are only a fallback
+	lenient := false
+	if len(candidates) == 0 && mm.server.LenientSchema() {
+		for i := range spans {
+			if mm.isUsed(spans[i]) && mm.lenientSchemaMatchWithHttpShape(requestData, spans[i]) {
+				candidates = append(candidates, spans[i])
+			}
+		}
+		lenient = true
+	}
+
 	if len(candidates) == 0 {
 		return spanMatchResult{}
 	}
 	if len(candidates) == 1 {
-		return spanMatchResult{span: candidates[0], multipleMatches: false}
+		return spanMatchResult{span: candidates[0], multipleMatches: false, lenient: lenient}
 	}
 
 	// Multiple matches - use similarity scoring
@@ -1038,6 +1324,7 @@ func (mm *MockMatcher) findUsedSpanByInputSchemaHash(requestData MockMatcherRequ
 		bestScore:       bestScore,
 		topCandidates:   topCandidates,
 		multipleMatches: true,
+		lenient:         lenient,
 	}
 }
 
@@ -1047,7 +1334,7 @@ func (mm *MockMatcher) findUnusedSpanByReducedInputSchemaHash(req *core.GetMockR
 		return spanMatchResult{}
 	}
 
-	requestData := reqToRequestData(req)
+	requestData := mm.reqToRequestData(req)
 	var candidates []*core.Span
 	for i := range spans {
 		if !mm.isUnused(spans[i]) {
@@ -1081,7 +1368,7 @@ func (mm *MockMatcher) findUsedSpanByReducedInputSchemaHash(req *core.GetMockReq
 		return spanMatchResult{}
 	}
 
-	requestData := reqToRequestData(req)
+	requestData := mm.reqToRequestData(req)
 	var candidates []*core.Span
 	for i := range spans {
 		if !mm.isUsed(spans[i]) {
@@ -1109,9 +1396,35 @@ func (mm *MockMatcher) findUsedSpanByReducedInputSchemaHash(req *core.GetMockReq
 	}
 }
 
+// pinnedSpan returns the span pinned for req by mock_matching.pins, if it is
+// among spans. The method comes from the request input for HTTP calls, and is
+// the operation otherwise.
+func (mm *MockMatcher) pinnedSpan(req *core.GetMockRequest, requestBody any, spans []*core.Span) *core.Span {
+	method := req.Operation
+	var path string
+	if m, ok := requestBody.(map[string]any); ok {
+		if v, ok := m["method"].(string); ok && v != "" {
+			method = v
+		}
+		path, _ = extractPathAndQuery(m)
+	}
+	spanID := mm.server.pinnedSpanIDFor(req.OutboundSpan.PackageName, method, path)
+	if spanID == "" {
+		return nil
+	}
+	for _, span := range spans {
+		if span.SpanId == spanID {
+			return span
+		}
+	}
+	log.Debug("Pinned span not in trace, matching normally", "spanID", spanID)
+	return nil
+}
+
 // reqToRequestData converts a GetMockRequest to the MockMatcherRequestData
-// format used by the MockMatcher.
-func reqToRequestData(req *core.GetMockRequest) MockMatcherRequestData {
+// format used by the MockMatcher, with mock_matching.ignore_fields applied to
+// the schema so similarity scoring skips those fields.
+func (mm *MockMatcher) reqToRequestData(req *core.GetMockRequest) MockMatcherRequestData {
 	var body any
 	if req.OutboundSpan != nil && req.OutboundSpan.InputValue != nil {
 		body = req.OutboundSpan.InputValue.AsMap()
@@ -1119,9 +1432,17 @@ func reqToRequestData(req *core.GetMockRequest) MockMatcherRequestData {
 	return MockMatcherRequestData{
 		InputValue:      body,
 		InputValueHash:  req.OutboundSpan.GetInputValueHash(),
-		InputSchema:     req.OutboundSpan.InputSchema,
+		InputSchema:     utils.IgnoreFieldsInSchema(req.OutboundSpan.GetInputSchema(), mm.server.ignoredFieldsFor(req.OutboundSpan.GetPackageName())),
 		InputSchemaHash: req.OutboundSpan.GetInputSchemaHash(),
+		Timestamp:       requestTimestamp(req),
+	}
+}
+
+func requestTimestamp(req *core.GetMockRequest) time.Time {
+	if req == nil || req.OutboundSpan == nil || req.OutboundSpan.Timestamp == nil {
+		return time.Time{}
 	}
+	return req.OutboundSpan.Timestamp.AsTime()
 }
 
 func (mm *MockMatcher) schemaMatchWithHttpShape(requestData MockMatcherRequestData, span *core.Span) bool {
@@ -1129,7 +1450,61 @@ func (mm *MockMatcher) schemaMatchWithHttpShape(requestData MockMatcherRequestDa
 	if span.InputSchemaHash != requestData.InputSchemaHash {
 		return false
 	}
+	return mm.httpShapeMatch(requestData, span)
+}
 
+// lenientSchemaMatchWithHttpShape is schemaMatchWithHttpShape for
+// mock_matching.lenient_schema: instead of equal schema hashes, the schemas
+// only need to be compatible (see lenientSchemaCompatible).
+func (mm *MockMatcher) lenientSchemaMatchWithHttpShape(requestData MockMatcherRequestData, span *core.Span) bool {
+	spanSchema := utils.IgnoreFieldsInSchema(span.InputSchema, mm.server.ignoredFieldsFor(span.PackageName))
+	if !lenientSchemaCompatible(requestData.InputSchema, spanSchema) {
+		return false
+	}
+	return mm.httpShapeMatch(requestData, span)
+}
+
+// lenientSchemaCompatible reports whether two input schemas differ only in
+// low-importance fields. Fields present on both sides must have compatible
+// schemas; a field present on only one side is tolerated when its
+// matchImportance is below 1 (e.g. 0 from mock_matching.ignore_fields). An
+// unspecified type is compatible with any type.
+func lenientSchemaCompatible(a, b *core.JsonSchema) bool {
+	if a == nil || b == nil {
+		return true
+	}
+	unspecified := core.JsonSchemaType_JSON_SCHEMA_TYPE_UNSPECIFIED
+	if a.Type != unspecified && b.Type != unspecified && a.Type != b.Type {
+		return false
+	}
+	for key, aProp := range a.Properties {
+		bProp, ok := b.Properties[key]
+		if !ok {
+			if !lowMatchImportance(aProp) {
+				return false
+			}
+			continue
+		}
+		if !lenientSchemaCompatible(aProp, bProp) {
+			return false
+		}
+	}
+	for key, bProp := range b.Properties {
+		if _, ok := a.Properties[key]; !ok && !lowMatchImportance(bProp) {
+			return false
+		}
+	}
+	return lenientSchemaCompatible(a.Items, b.Items)
+}
+
+func lowMatchImportance(schema *core.JsonSchema) bool {
+	return schema != nil && schema.MatchImportance != nil && *schema.MatchImportance < 1
+}
+
+// httpShapeMatch checks the parts of an outbound call the schema hash can't
+// see: the GraphQL query, the gRPC method, and the HTTP method, host, path
+// (or path template, see mock_matching.path_templates) and query keys.
+func (mm *MockMatcher) httpShapeMatch(requestData MockMatcherRequestData, span *core.Span) bool {
 	// Build maps once
 	reqMap, ok := requestData.InputValue.(map[string]any)
 	if !ok {
@@ -1148,6 +1523,12 @@ func (mm *MockMatcher) schemaMatchWithHttpShape(requestData MockMatcherRequestDa
 		return false
 	}
 
+	// gRPC: fully-qualified method (/package.Service/Method) must match exactly.
+	// Message-body variance is left to similarity scoring.
+	if span.PackageName == "grpc" {
+		return grpcMethodEqualIfPresent(reqMap, spanMap)
+	}
+
 	// Only enforce HTTP-shape for HTTP/HTTPS
 	if span.PackageName != "http" && span.PackageName != "https" {
 		return true
@@ -1166,15 +1547,27 @@ func (mm *MockMatcher) schemaMatchWithHttpShape(requestData MockMatcherRequestDa
 	}
 
 	// Pathname must match (exclude query), and query key sets must be identical
-	reqPath, reqKeys := extractPathAndQueryKeys(reqMap)
-	spanPath, spanKeys := extractPathAndQueryKeys(spanMap)
-	if reqPath != "" && spanPath != "" && reqPath != spanPath {
+	reqPath, reqQuery := extractPathAndQuery(reqMap)
+	spanPath, spanQuery := extractPathAndQuery(spanMap)
+	if reqPath != "" && spanPath != "" && reqPath != spanPath && !mm.server.samePathTemplate(reqPath, spanPath) {
 		return false
 	}
-	if !stringSetEqual(reqKeys, spanKeys) {
+	if !stringSetEqual(parseQueryKeys(reqQuery), parseQueryKeys(spanQuery)) {
 		return false
 	}
 
+	// Values of significant query params (mock_matching.significant_query_params)
+	// must match too; other values are left to similarity scoring
+	if params := mm.server.significantQueryParamsFor(reqPath); len(params) > 0 {
+		reqValues, _ := url.ParseQuery(reqQuery)
+		spanValues, _ := url.ParseQuery(spanQuery)
+		for _, param := range params {
+			if !slices.Equal(reqValues[param], spanValues[param]) {
+				return false
+			}
+		}
+	}
+
 	return true
 }
 
@@ -1187,6 +1580,33 @@ func stringFieldEqualIfPresent(a, b map[string]any, key string) bool {
 	return true
 }
 
+// grpcMethodEqualIfPresent compares the fully-qualified gRPC method of both
+// inputs, read from "method" or "path". Returns true if either side lacks one.
+func grpcMethodEqualIfPresent(a, b map[string]any) bool {
+	ma := extractGrpcMethod(a)
+	mb := extractGrpcMethod(b)
+	if ma != "" && mb != "" {
+		return ma == mb
+	}
+	return true
+}
+
+func extractGrpcMethod(m map[string]any) string {
+	for _, key := range []string{"method", "path"} {
+		if v, ok := m[key].(string); ok && strings.TrimSpace(v) != "" {
+			return normalizeGrpcMethod(v)
+		}
+	}
+	return ""
+}
+
+// normalizeGrpcMethod trims whitespace and ensures a single leading slash,
+// so "pkg.Service/Method" and "/pkg.Service/Method" compare equal.
+func normalizeGrpcMethod(method string) string {
+	method = strings.TrimSpace(method)
+	return "/" + strings.TrimLeft(method, "/")
+}
+
 func extractHost(m map[string]any) string {
 	// Prefer explicit hostname
 	if hn, ok := m["hostname"].(string); ok && hn != "" {
@@ -1201,22 +1621,21 @@ func extractHost(m map[string]any) string {
 	return ""
 }
 
-func extractPathAndQueryKeys(m map[string]any) (string, map[string]struct{}) {
+// extractPathAndQuery returns the path and raw query string of an HTTP span input.
+func extractPathAndQuery(m map[string]any) (string, string) {
 	// Prefer 'path', else derive from 'url', else 'target'
 	var s string
 	if v, ok := m["path"].(string); ok && v != "" {
 		s = v
 	} else if v, ok := m["url"].(string); ok && v != "" {
 		if u, err := url.Parse(v); err == nil {
-			s = u.Path
-			return s, parseQueryKeys(u.RawQuery)
+			return u.Path, u.RawQuery
 		}
 	} else if v, ok := m["target"].(string); ok && v != "" {
 		s = v
 	}
 
-	base, rawQuery := splitPathQuery(s)
-	return base, parseQueryKeys(rawQuery)
+	return splitPathQuery(s)
 }
 
 func splitPathQuery(p string) (string, string) {
@@ -1,6 +1,9 @@
 package runner
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -74,6 +77,40 @@ func makeMockRequest(t *testing.T, pkg string, inputValueMap map[string]any, inp
 	}
 }
 
+func TestFindBestMatchWithTracePriority_PinOverridesValueHashMatch(t *testing.T) {
+	cfg, _ := config.Get()
+	server, err := NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	mm := NewMockMatcher(server)
+
+	traceID := "trace-pin"
+	requested := map[string]any{"method": "GET", "path": "/users/1"}
+	exact := makeSpan(t, traceID, "exact", "http", requested, nil, 1000)
+	pinned := makeSpan(t, traceID, "pinned", "http", map[string]any{"method": "GET", "path": "/users/2"}, nil, 2000)
+	server.LoadSpansForTrace(traceID, []*core.Span{exact, pinned})
+	req := makeMockRequest(t, "http", requested, nil)
+
+	// Without a pin the value hash match wins
+	match, _, err := mm.FindBestMatchWithTracePriority(req, traceID)
+	require.NoError(t, err)
+	assert.Equal(t, "exact", match.SpanId)
+
+	server.SetPins([]config.MockPin{
+		{Package: "http", Method: "post", Path: "/users/*", SpanID: "exact"},
+		{Package: "http", Method: "get", Path: "/users/*", SpanID: "pinned"},
+	})
+	match, level, err := mm.FindBestMatchWithTracePriority(req, traceID)
+	require.NoError(t, err)
+	assert.Equal(t, "pinned", match.SpanId)
+	assert.Equal(t, "pinned by mock_matching.pins", level.MatchDescription)
+
+	// A pin naming a span outside the trace falls through to normal matching
+	server.SetPins([]config.MockPin{{Package: "http", SpanID: "missing"}})
+	match, _, err = mm.FindBestMatchWithTracePriority(req, traceID)
+	require.NoError(t, err)
+	assert.Equal(t, "exact", match.SpanId)
+}
+
 func TestFindBestMatchWithTracePriority_InputValueHash_PrefersUnusedOldest(t *testing.T) {
 	cfg, _ := config.Get()
 	server, err := NewServer("svc", &cfg.Service)
@@ -124,6 +161,47 @@ func TestFindBestMatchWithTracePriority_InputValueHash_PrefersUnusedOldest(t *te
 	assert.Equal(t, core.MatchScope_MATCH_SCOPE_TRACE, level3.MatchScope)
 }
 
+func TestFindBestMatchWithTracePriority_InputValueHash_EqualTimestampsOrderBySpanID(t *testing.T) {
+	inputValueMap := map[string]any{"method": "GET", "path": "/users"}
+
+	// Whatever order the spans are loaded in, same-timestamp spans are handed
+	// out by span ID
+	for _, order := range [][]string{{"s-a", "s-b", "s-c"}, {"s-c", "s-b", "s-a"}, {"s-b", "s-c", "s-a"}} {
+		cfg, _ := config.Get()
+		server, err := NewServer("svc", &cfg.Service)
+		require.NoError(t, err)
+		mm := NewMockMatcher(server)
+
+		var spans []*core.Span
+		for _, id := range order {
+			spans = append(spans, makeSpan(t, "trace-1", id, "http", inputValueMap, nil, 1000))
+		}
+		server.LoadSpansForTrace("trace-1", spans)
+
+		req := makeMockRequest(t, "http", inputValueMap, nil)
+		var got []string
+		for range order {
+			match, _, err := mm.FindBestMatchWithTracePriority(req, "trace-1")
+			require.NoError(t, err)
+			require.NotNil(t, match)
+			got = append(got, match.SpanId)
+		}
+		assert.Equal(t, []string{"s-a", "s-b", "s-c"}, got, "load order %v", order)
+	}
+}
+
+func TestSpanRecordedBefore(t *testing.T) {
+	at := func(id string, tsMs int64) *core.Span {
+		return &core.Span{SpanId: id, Timestamp: unixMsToTimestamp(tsMs)}
+	}
+
+	assert.True(t, spanRecordedBefore(at("b", 1000), at("a", 2000)))
+	assert.True(t, spanRecordedBefore(at("a", 1000), at("b", 1000)))
+	assert.False(t, spanRecordedBefore(at("b", 1000), at("a", 1000)))
+	assert.True(t, spanRecordedBefore(&core.Span{SpanId: "z"}, at("a", 1000)))
+	assert.True(t, spanRecordedBefore(&core.Span{SpanId: "a"}, &core.Span{SpanId: "b"}))
+}
+
 func TestFindBestMatchWithTracePriority_ReducedInputValueHash_MatchesWhenDirectHashDiffers(t *testing.T) {
 	cfg, _ := config.Get()
 	server, err := NewServer("svc", &cfg.Service)
@@ -163,6 +241,139 @@ func TestFindBestMatchWithTracePriority_ReducedInputValueHash_MatchesWhenDirectH
 	assert.Equal(t, core.MatchScope_MATCH_SCOPE_TRACE, level.MatchScope)
 }
 
+func TestFindBestMatchWithTracePriority_IgnoreFieldsConfig_MatchesDespiteConnectionID(t *testing.T) {
+	cfg, _ := config.Get()
+	inputSchema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{
+			"command":      {},
+			"args":         {},
+			"connectionId": {},
+		},
+	}
+	recorded := map[string]any{"command": "GET", "args": []any{"user:1"}, "connectionId": "conn-41"}
+	replayed := map[string]any{"command": "GET", "args": []any{"user:1"}, "connectionId": "conn-97"}
+
+	// Without the override, the connection ID participates in the reduced hash
+	span := makeSpan(t, "trace-redis", "s1", "redis", recorded, inputSchema, 1000)
+	req := makeMockRequest(t, "redis", replayed, inputSchema)
+	assert.NotEqual(t, reducedInputValueHash(span, nil), reducedInputValueHash(req.OutboundSpan, nil))
+
+	server, err := NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	server.SetIgnoreFields(map[string][]string{"redis": {"$.connectionId"}})
+	mm := NewMockMatcher(server)
+	server.LoadSpansForTrace("trace-redis", []*core.Span{span})
+
+	match, level, err := mm.FindBestMatchWithTracePriority(req, "trace-redis")
+	require.NoError(t, err)
+	require.NotNil(t, match)
+	require.NotNil(t, level)
+	assert.Equal(t, "s1", match.SpanId)
+	assert.Equal(t, core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH_REDUCED_SCHEMA, level.MatchType)
+
+	// Overrides are per package
+	assert.Empty(t, server.ignoredFieldsFor("pg"))
+}
+
+func TestFindBestMatchWithTracePriority_PoolIdenticalSpans(t *testing.T) {
+	cfg, _ := config.Get()
+	query := map[string]any{"text": "SELECT 1", "values": []any{}}
+
+	pickAll := func(t *testing.T, pool bool) []string {
+		t.Helper()
+		server, err := NewServer("svc", &cfg.Service)
+		require.NoError(t, err)
+		server.SetPoolIdenticalSpans(pool)
+		mm := NewMockMatcher(server)
+		server.LoadSpansForTrace("trace-pool", []*core.Span{
+			makeSpan(t, "trace-pool", "s1", "pg", query, nil, 1000),
+			makeSpan(t, "trace-pool", "s2", "pg", query, nil, 2000),
+		})
+
+		var picked []string
+		for i := 0; i < 4; i++ {
+			match, level, err := mm.FindBestMatchWithTracePriority(makeMockRequest(t, "pg", query, nil), "trace-pool")
+			require.NoError(t, err)
+			require.NotNil(t, match)
+			assert.Equal(t, core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH, level.MatchType)
+			picked = append(picked, match.SpanId)
+		}
+		return picked
+	}
+
+	// Both spans are consumed before any is reused, then picks rotate
+	assert.Equal(t, []string{"s1", "s2", "s1", "s2"}, pickAll(t, true))
+	// Default behavior keeps falling back to the oldest used span
+	assert.Equal(t, []string{"s1", "s2", "s1", "s1"}, pickAll(t, false))
+}
+
+func TestFindBestMatchWithTracePriority_PoolIdenticalSpans_FollowsRecordedTimeline(t *testing.T) {
+	cfg, _ := config.Get()
+	traceID := "trace-pool-timeline"
+	query := map[string]any{"text": "SELECT 1", "values": []any{}, "connectionId": "c1"}
+	otherConn := map[string]any{"text": "SELECT 1", "values": []any{}, "connectionId": "c2"}
+	matchImportanceZero := 0.0
+	reducedSchema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{
+			"text":         {},
+			"values":       {},
+			"connectionId": {MatchImportance: &matchImportanceZero},
+		},
+	}
+
+	for _, tc := range []struct {
+		name      string
+		schema    *core.JsonSchema
+		second    map[string]any
+		matchType core.MatchType
+	}{
+		{"value hash", nil, query, core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH},
+		{"reduced value hash", reducedSchema, otherConn, core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH_REDUCED_SCHEMA},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pickAll := func(t *testing.T, pool bool) []string {
+				t.Helper()
+				server, err := NewServer("svc", &cfg.Service)
+				require.NoError(t, err)
+				server.SetPoolIdenticalSpans(pool)
+				recordedRoot := &core.Span{TraceId: traceID, SpanId: "root", PackageName: "http", IsRootSpan: true, Timestamp: unixMsToTimestamp(900)}
+				test := Test{
+					TraceID:  traceID,
+					Request:  Request{Method: "GET", Path: "/users"},
+					Response: Response{Status: http.StatusOK},
+					Spans: []*core.Span{
+						makeSpan(t, traceID, "s1", "pg", query, tc.schema, 1000),
+						makeSpan(t, traceID, "s2", "pg", tc.second, tc.schema, 2000),
+						recordedRoot,
+					},
+				}
+
+				// The call recorded second is replayed first this time
+				var reqs []*core.GetMockRequest
+				for range 2 {
+					req := makeMockRequest(t, "pg", map[string]any{"text": "SELECT 1", "values": []any{}, "connectionId": "c3"}, tc.schema)
+					if tc.schema == nil {
+						req = makeMockRequest(t, "pg", query, nil)
+					}
+					reqs = append(reqs, req)
+				}
+				matches, levels := replayWithMockRequests(t, server, test, []time.Duration{1100 * time.Millisecond, 100 * time.Millisecond}, reqs)
+
+				var picked []string
+				for i, match := range matches {
+					require.NotNil(t, match)
+					picked = append(picked, match.SpanId)
+					assert.Equal(t, tc.matchType, levels[i].MatchType)
+				}
+				return picked
+			}
+
+			assert.Equal(t, []string{"s2", "s1"}, pickAll(t, true))
+			assert.Equal(t, []string{"s1", "s2"}, pickAll(t, false))
+		})
+	}
+}
+
 func TestFindBestMatchWithTracePriority_InputSchemaHash_WithHTTPShape(t *testing.T) {
 	cfg, _ := config.Get()
 	server, err := NewServer("svc", &cfg.Service)
@@ -208,6 +419,89 @@ func TestFindBestMatchWithTracePriority_InputSchemaHash_WithHTTPShape(t *testing
 	assert.Equal(t, core.MatchScope_MATCH_SCOPE_TRACE, level.MatchScope)
 }
 
+func TestFindBestMatchWithTracePriority_LenientSchema_ToleratesExtraOptionalField(t *testing.T) {
+	cfg, _ := config.Get()
+	server, err := NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	mm := NewMockMatcher(server)
+
+	traceID := "trace-lenient"
+	pkg := "http"
+
+	spanSchema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{
+			"method": {},
+			"url":    {},
+		},
+	}
+	span := makeSpan(t, traceID, "sL", pkg, map[string]any{
+		"method": "GET",
+		"url":    "https://api.example.com/users?page=1",
+	}, spanSchema, 1000)
+	server.LoadSpansForTrace(traceID, []*core.Span{span})
+
+	// The request carries an optional field the recording didn't have
+	request := func(importance float64) *core.GetMockRequest {
+		return makeMockRequest(t, pkg, map[string]any{
+			"method":    "GET",
+			"url":       "https://api.example.com/users?page=2",
+			"requestId": "abc",
+		}, &core.JsonSchema{
+			Properties: map[string]*core.JsonSchema{
+				"method":    {},
+				"url":       {},
+				"requestId": {MatchImportance: &importance},
+			},
+		})
+	}
+	req := request(0.2)
+	require.NotEqual(t, span.InputSchemaHash, req.OutboundSpan.InputSchemaHash)
+
+	_, _, err = mm.FindBestMatchWithTracePriority(req, traceID)
+	require.Error(t, err, "strict schema matching should not match")
+
+	server.SetLenientSchema(true)
+	match, level, err := mm.FindBestMatchWithTracePriority(req, traceID)
+	require.NoError(t, err)
+	require.NotNil(t, match)
+	assert.Equal(t, "sL", match.SpanId)
+	assert.Equal(t, core.MatchType_MATCH_TYPE_INPUT_SCHEMA_HASH, level.MatchType)
+	assert.Equal(t, "Unused span by lenient input schema", level.MatchDescription)
+
+	// A request-only field that matters still prevents the match
+	_, _, err = mm.FindBestMatchWithTracePriority(request(1), traceID)
+	require.Error(t, err)
+}
+
+func TestLenientSchemaCompatible(t *testing.T) {
+	low := 0.0
+	str := core.JsonSchemaType_JSON_SCHEMA_TYPE_STRING
+	num := core.JsonSchemaType_JSON_SCHEMA_TYPE_NUMBER
+	object := func(props map[string]*core.JsonSchema) *core.JsonSchema {
+		return &core.JsonSchema{Type: core.JsonSchemaType_JSON_SCHEMA_TYPE_OBJECT, Properties: props}
+	}
+
+	base := object(map[string]*core.JsonSchema{"id": {Type: str}})
+	assert.True(t, lenientSchemaCompatible(base, object(map[string]*core.JsonSchema{
+		"id":    {Type: str},
+		"extra": {Type: str, MatchImportance: &low},
+	})))
+	assert.True(t, lenientSchemaCompatible(object(map[string]*core.JsonSchema{
+		"id":    {Type: str},
+		"extra": {Type: str, MatchImportance: &low},
+	}), base), "spans may have the extra field too")
+	assert.False(t, lenientSchemaCompatible(base, object(map[string]*core.JsonSchema{
+		"id":    {Type: str},
+		"extra": {Type: str},
+	})))
+	assert.False(t, lenientSchemaCompatible(base, object(map[string]*core.JsonSchema{"id": {Type: num}})))
+	assert.True(t, lenientSchemaCompatible(base, object(map[string]*core.JsonSchema{"id": {}})))
+	assert.False(t, lenientSchemaCompatible(
+		object(map[string]*core.JsonSchema{"user": object(map[string]*core.JsonSchema{"id": {Type: str}})}),
+		object(map[string]*core.JsonSchema{"user": object(map[string]*core.JsonSchema{"id": {Type: str}, "name": {Type: str}})}),
+	), "nested fields are compared too")
+}
+
 func TestSchemaMatchWithHttpShape_GraphQLNormalization(t *testing.T) {
 	cfg, _ := config.Get()
 	server, err := NewServer("svc", &cfg.Service)
@@ -252,6 +546,142 @@ func TestSchemaMatchWithHttpShape_GraphQLNormalization(t *testing.T) {
 	assert.False(t, mm.schemaMatchWithHttpShape(reqData2, span))
 }
 
+func TestSchemaMatchWithHttpShape_GrpcMethodNormalization(t *testing.T) {
+	cfg, _ := config.Get()
+	server, err := NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	mm := NewMockMatcher(server)
+
+	inputSchema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{
+			"method": {},
+			"body": {
+				Properties: map[string]*core.JsonSchema{
+					"id": {},
+				},
+			},
+		},
+	}
+	inputSchemaHash := utils.GenerateDeterministicHash(inputSchema)
+
+	span := makeSpan(t, "trace-grpc", "sg1", "grpc", map[string]any{
+		"method": "/users.v1.UserService/GetUser",
+		"body":   map[string]any{"id": "1"},
+	}, inputSchema, 0)
+
+	// Same method (missing leading slash), different message body -> should match
+	reqData1 := MockMatcherRequestData{
+		InputValue: map[string]any{
+			"method": "users.v1.UserService/GetUser",
+			"body":   map[string]any{"id": "2"},
+		},
+		InputSchemaHash: inputSchemaHash,
+	}
+	assert.True(t, mm.schemaMatchWithHttpShape(reqData1, span))
+
+	// Different RPC with identical message schema -> should fail
+	reqData2 := MockMatcherRequestData{
+		InputValue: map[string]any{
+			"method": "/users.v1.UserService/DeleteUser",
+			"body":   map[string]any{"id": "1"},
+		},
+		InputSchemaHash: inputSchemaHash,
+	}
+	assert.False(t, mm.schemaMatchWithHttpShape(reqData2, span))
+
+	// Method read from "path" when "method" is absent
+	reqData3 := MockMatcherRequestData{
+		InputValue: map[string]any{
+			"path": "/users.v1.UserService/DeleteUser",
+			"body": map[string]any{"id": "1"},
+		},
+		InputSchemaHash: inputSchemaHash,
+	}
+	assert.False(t, mm.schemaMatchWithHttpShape(reqData3, span))
+}
+
+func TestSchemaMatchWithHttpShape_SignificantQueryParams(t *testing.T) {
+	cfg, _ := config.Get()
+	server, err := NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	server.SetSignificantQueryParams([]config.SignificantQueryParamsRule{
+		{Path: "/reports/**", Params: []string{"view"}},
+	})
+	mm := NewMockMatcher(server)
+
+	inputSchema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{"method": {}, "url": {}},
+	}
+	input := func(rawURL string) map[string]any {
+		return map[string]any{"method": "GET", "url": rawURL}
+	}
+	// The spans differ only in the value of the significant param
+	summary := makeSpan(t, "trace-sqp", "summary", "http", input("https://api.example.com/reports/7?view=summary&page=1"), inputSchema, 100)
+	full := makeSpan(t, "trace-sqp", "full", "http", input("https://api.example.com/reports/7?view=full&page=1"), inputSchema, 200)
+	request := func(rawURL string) MockMatcherRequestData {
+		return MockMatcherRequestData{
+			InputValue:      input(rawURL),
+			InputSchemaHash: summary.InputSchemaHash,
+		}
+	}
+
+	// Other param values may still differ
+	req := request("https://api.example.com/reports/7?page=3&view=full")
+	assert.False(t, mm.schemaMatchWithHttpShape(req, summary))
+	assert.True(t, mm.schemaMatchWithHttpShape(req, full))
+
+	// Paths outside the rule only compare param names
+	other := makeSpan(t, "trace-sqp", "users", "http", input("https://api.example.com/users?view=summary"), inputSchema, 300)
+	assert.True(t, mm.schemaMatchWithHttpShape(request("https://api.example.com/users?view=full"), other))
+
+	// Without the rule both spans match
+	server, err = NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	mm = NewMockMatcher(server)
+	assert.True(t, mm.schemaMatchWithHttpShape(req, summary))
+	assert.True(t, mm.schemaMatchWithHttpShape(req, full))
+}
+
+func TestSchemaMatchWithHttpShape_PathTemplates(t *testing.T) {
+	cfg, _ := config.Get()
+	server, err := NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	server.SetPathTemplates([]string{"/users/:id", "/orgs/:org/repos/:repo"})
+	mm := NewMockMatcher(server)
+
+	inputSchema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{"method": {}, "url": {}},
+	}
+	input := func(rawURL string) map[string]any {
+		return map[string]any{"method": "GET", "url": rawURL}
+	}
+	user := makeSpan(t, "trace-tpl", "user", "http", input("https://api.example.com/users/123"), inputSchema, 100)
+	repo := makeSpan(t, "trace-tpl", "repo", "http", input("https://api.example.com/orgs/acme/repos/api"), inputSchema, 200)
+	request := func(rawURL string) MockMatcherRequestData {
+		return MockMatcherRequestData{
+			InputValue:      input(rawURL),
+			InputSchemaHash: user.InputSchemaHash,
+		}
+	}
+
+	// Different IDs under the same template match
+	assert.True(t, mm.schemaMatchWithHttpShape(request("https://api.example.com/users/456"), user))
+	assert.True(t, mm.schemaMatchWithHttpShape(request("https://api.example.com/orgs/tusk/repos/cli"), repo))
+
+	// Different routes still don't
+	assert.False(t, mm.schemaMatchWithHttpShape(request("https://api.example.com/users/456/posts"), user))
+	assert.False(t, mm.schemaMatchWithHttpShape(request("https://api.example.com/teams/456"), user))
+	assert.False(t, mm.schemaMatchWithHttpShape(request("https://api.example.com/orgs/acme/repos/api"), user))
+	assert.False(t, mm.schemaMatchWithHttpShape(request("https://api.example.com/users/"), user))
+
+	// Without templates the paths must be equal
+	server, err = NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	mm = NewMockMatcher(server)
+	assert.False(t, mm.schemaMatchWithHttpShape(request("https://api.example.com/users/456"), user))
+	assert.True(t, mm.schemaMatchWithHttpShape(request("https://api.example.com/users/123"), user))
+}
+
 func TestFindBestMatchAcrossTraces_GlobalValueHash(t *testing.T) {
 	cfg, _ := config.Get()
 	server, err := NewServer("svc", &cfg.Service)
@@ -325,6 +755,106 @@ func TestFindBestMatchAcrossTraces_GlobalSchemaHash(t *testing.T) {
 	assert.Equal(t, core.MatchScope_MATCH_SCOPE_GLOBAL, level.MatchScope)
 }
 
+func TestFindBestMatchAcrossTraces_IgnoreFieldsConfig_AppliesToSimilarity(t *testing.T) {
+	cfg, _ := config.Get()
+	inputSchema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{
+			"command":      {},
+			"key":          {},
+			"ts":           {},
+			"connectionId": {},
+		},
+	}
+	replayConn := "conn-bbbbbbbbbbbbbbbbbbbbbbbb"
+	sameKey := map[string]any{"command": "GET", "key": "user:1", "ts": "1", "connectionId": "conn-aaaaaaaaaaaaaaaaaaaaaaaa"}
+	sameConn := map[string]any{"command": "GET", "key": "user:9", "ts": "3", "connectionId": replayConn}
+	replayed := map[string]any{"command": "GET", "key": "user:1", "ts": "2", "connectionId": replayConn}
+
+	match := func(t *testing.T, ignore map[string][]string) string {
+		t.Helper()
+		server, err := NewServer("svc", &cfg.Service)
+		require.NoError(t, err)
+		server.SetIgnoreFields(ignore)
+		mm := NewMockMatcher(server)
+
+		spanA := makeSpan(t, "trace-A", "same-key", "redis", sameKey, inputSchema, 100)
+		spanB := makeSpan(t, "trace-B", "same-conn", "redis", sameConn, inputSchema, 200)
+		spanA.IsPreAppStart = true
+		spanB.IsPreAppStart = true
+		server.SetSuiteSpans([]*core.Span{spanA, spanB})
+
+		req := makeMockRequest(t, "redis", replayed, inputSchema)
+		req.OutboundSpan.IsPreAppStart = true
+		got, level, err := mm.FindBestMatchAcrossTraces(req, "irrelevant-trace", server.GetSuiteSpans())
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, core.MatchType_MATCH_TYPE_INPUT_SCHEMA_HASH, level.MatchType)
+		return got.SpanId
+	}
+
+	// The differing connection ID dominates similarity unless it is ignored
+	assert.Equal(t, "same-conn", match(t, nil))
+	assert.Equal(t, "same-key", match(t, map[string][]string{"redis": {"$.connectionId"}}))
+}
+
+func TestFindBestMatchWithTracePriority_GlobalFallbackHosts(t *testing.T) {
+	cfg, _ := config.Get()
+	matchImportanceZero := 0.0
+	inputSchema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{
+			"method":   {},
+			"path":     {},
+			"hostname": {MatchImportance: &matchImportanceZero},
+		},
+	}
+
+	match := func(t *testing.T, hosts []string) (string, error) {
+		t.Helper()
+		server, err := NewServer("svc", &cfg.Service)
+		require.NoError(t, err)
+		server.SetGlobalFallbackHosts(hosts)
+		mm := NewMockMatcher(server)
+
+		// Identical calls recorded against two hosts; the hostname is ignored
+		// by the reduced value hash, so both are fallback candidates
+		stripe := makeSpan(t, "trace-A", "stripe", "http", map[string]any{"method": "GET", "path": "/v1/rates", "hostname": "api.stripe.com"}, inputSchema, 100)
+		partner := makeSpan(t, "trace-B", "partner", "http", map[string]any{"method": "GET", "path": "/v1/rates", "hostname": "api.partner.com"}, inputSchema, 200)
+		server.SetGlobalSpans([]*core.Span{stripe, partner})
+
+		req := makeMockRequest(t, "http", map[string]any{"method": "GET", "path": "/v1/rates", "hostname": "rates.internal"}, inputSchema)
+		got, level, err := mm.FindBestMatchWithTracePriority(req, "trace-C")
+		if err != nil {
+			return "", err
+		}
+		require.NotNil(t, got)
+		assert.Equal(t, core.MatchScope_MATCH_SCOPE_GLOBAL, level.MatchScope)
+		return got.SpanId, nil
+	}
+
+	got, err := match(t, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "stripe", got, "without an allowlist the oldest global span matches")
+
+	got, err = match(t, []string{" API.partner.com "})
+	require.NoError(t, err)
+	assert.Equal(t, "partner", got)
+
+	_, err = match(t, []string{"api.example.com"})
+	assert.Error(t, err, "no global span is recorded against an allowlisted host")
+
+	// Non-HTTP spans are never filtered, even when their input names a host
+	server, err := NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	server.SetGlobalFallbackHosts([]string{"api.partner.com"})
+	query := map[string]any{"query": "SELECT 1", "hostname": "db.internal"}
+	server.SetGlobalSpans([]*core.Span{makeSpan(t, "trace-A", "pg-span", "pg", query, inputSchema, 100)})
+	got2, level, err := NewMockMatcher(server).FindBestMatchWithTracePriority(makeMockRequest(t, "pg", query, inputSchema), "trace-C")
+	require.NoError(t, err)
+	require.NotNil(t, got2)
+	assert.Equal(t, "pg-span", got2.SpanId)
+	assert.Equal(t, core.MatchScope_MATCH_SCOPE_GLOBAL, level.MatchScope)
+}
+
 func TestFindBestMatchAcrossTraces_GlobalReducedSchemaHash(t *testing.T) {
 	cfg, _ := config.Get()
 	server, err := NewServer("svc", &cfg.Service)
@@ -694,6 +1224,230 @@ func TestFindBestMatchWithTracePriority_SqlAlchemyQuery_DoesNotUseSchemaFallback
 	assert.Nil(t, match)
 }
 
+func TestFindBestMatchWithTracePriority_SimilarityScoring_WeightsByMatchImportance(t *testing.T) {
+	cfg, _ := config.Get()
+	server, err := NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	mm := NewMockMatcher(server)
+
+	traceID := "trace-importance"
+	pkg := "postgres"
+
+	lowImportance := 0.05
+	inputSchema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{
+			"query":     {},
+			"timestamp": {MatchImportance: &lowImportance},
+		},
+	}
+
+	requestValueMap := map[string]any{
+		"query":     "SELECT * FROM users WHERE id = 1",
+		"timestamp": "aaaaaaaaaa",
+	}
+
+	// Same query, completely different (low-importance) timestamp
+	queryMatchValueMap := map[string]any{
+		"query":     "SELECT * FROM users WHERE id = 1",
+		"timestamp": "bbbbbbbbbb",
+	}
+
+	// Different query, identical timestamp - wins on unweighted similarity
+	timestampMatchValueMap := map[string]any{
+		"query":     "SELECT * FROM orders WHERE id = 2",
+		"timestamp": "aaaaaaaaaa",
+	}
+
+	// Sanity check: without the schema, the timestamp match scores higher
+	require.Greater(t,
+		calculateSimilarityScore(requestValueMap, timestampMatchValueMap, nil, 0, compareStrings),
+		calculateSimilarityScore(requestValueMap, queryMatchValueMap, nil, 0, compareStrings),
+	)
+
+	spanTimestamp := makeSpan(t, traceID, "span-timestamp-match", pkg, timestampMatchValueMap, inputSchema, 1000)
+	spanQuery := makeSpan(t, traceID, "span-query-match", pkg, queryMatchValueMap, inputSchema, 2000)
+	server.LoadSpansForTrace(traceID, []*core.Span{spanTimestamp, spanQuery})
+
+	req := makeMockRequest(t, pkg, requestValueMap, inputSchema)
+
+	match, level, err := mm.FindBestMatchWithTracePriority(req, traceID)
+	require.NoError(t, err)
+	require.NotNil(t, match)
+	require.NotNil(t, level)
+	assert.Equal(t, "span-query-match", match.SpanId)
+}
+
+func TestCalculateSimilarityScore_SkipsZeroImportanceFields(t *testing.T) {
+	zero := 0.0
+	schema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{
+			"id":         {},
+			"request_id": {MatchImportance: &zero},
+		},
+	}
+
+	a := map[string]any{"id": "1", "request_id": "abc"}
+	b := map[string]any{"id": "1", "request_id": "xyz"}
+	assert.InDelta(t, 1.0, calculateSimilarityScore(a, b, schema, 0, compareStrings), 0.0001)
+	assert.Less(t, calculateSimilarityScore(a, b, nil, 0, compareStrings), 1.0)
+}
+
+func TestCompareTokens_RanksReorderedQueryHigherThanLevenshtein(t *testing.T) {
+	request := "SELECT name, email FROM users WHERE id = 1 AND active = true"
+	reordered := "SELECT email, name FROM users WHERE active = true AND id = 1"
+	otherTable := "SELECT name, email FROM orders WHERE id = 1 AND active = true"
+
+	assert.InDelta(t, 1.0, compareTokens(request, reordered), 0.0001)
+	assert.Greater(t, compareTokens(request, reordered), compareStrings(request, reordered))
+
+	// Levenshtein prefers the query against another table; tokens don't
+	assert.Greater(t, compareStrings(request, otherTable), compareStrings(request, reordered))
+	assert.Greater(t, compareTokens(request, reordered), compareTokens(request, otherTable))
+}
+
+func TestCompareTokens_SingleTokenFallsBackToLevenshtein(t *testing.T) {
+	assert.Equal(t, compareStrings("42", "43"), compareTokens("42", "43"))
+	assert.InDelta(t, 1.0, compareTokens("", ""), 0.0001)
+	// Repeated tokens count once per occurrence
+	assert.InDelta(t, 2.0/3.0, compareTokens("a a b", "a b"), 0.0001)
+}
+
+func TestFindBestMatchWithTracePriority_SimilarityAlgorithm(t *testing.T) {
+	pkg := "postgres"
+	inputSchema := &core.JsonSchema{Properties: map[string]*core.JsonSchema{"query": {}}}
+	requestValueMap := map[string]any{"query": "SELECT name, email FROM users WHERE id = 1 AND active = true"}
+	reorderedValueMap := map[string]any{"query": "SELECT email, name FROM users WHERE active = true AND id = 1"}
+	otherTableValueMap := map[string]any{"query": "SELECT name, email FROM orders WHERE id = 1 AND active = true"}
+
+	for _, tc := range []struct {
+		algorithm string
+		want      string
+	}{
+		{algorithm: "", want: "span-other-table"},
+		{algorithm: "levenshtein", want: "span-other-table"},
+		{algorithm: "token", want: "span-reordered"},
+	} {
+		t.Run("algorithm="+tc.algorithm, func(t *testing.T) {
+			cfg, _ := config.Get()
+			server, err := NewServer("svc", &cfg.Service)
+			require.NoError(t, err)
+			server.SetSimilarityAlgorithm(tc.algorithm)
+			mm := NewMockMatcher(server)
+
+			traceID := "trace-similarity-algorithm"
+			server.LoadSpansForTrace(traceID, []*core.Span{
+				makeSpan(t, traceID, "span-other-table", pkg, otherTableValueMap, inputSchema, 1000),
+				makeSpan(t, traceID, "span-reordered", pkg, reorderedValueMap, inputSchema, 2000),
+			})
+
+			match, level, err := mm.FindBestMatchWithTracePriority(makeMockRequest(t, pkg, requestValueMap, inputSchema), traceID)
+			require.NoError(t, err)
+			require.NotNil(t, match)
+			require.NotNil(t, level)
+			assert.Equal(t, tc.want, match.SpanId)
+		})
+	}
+}
+
+func TestFindBestMatchWithTracePriority_SimilarityScoring_BeyondScanLimit(t *testing.T) {
+	cfg, _ := config.Get()
+	server, err := NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	require.Equal(t, defaultSimilarityScanLimit, server.GetSimilarityScanLimit())
+
+	traceID := "trace-scan-limit"
+	pkg := "pg"
+
+	inputSchema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{
+			"query":      {},
+			"parameters": {},
+		},
+	}
+
+	// 80 identical-schema queries 100ms apart; the true match is at index 70,
+	// 7.1s after the recorded root
+	var spans []*core.Span
+	for i := 0; i < 80; i++ {
+		spans = append(spans, makeSpan(t, traceID, fmt.Sprintf("span-%02d", i), pkg, map[string]any{
+			"query":      "select * from users where id = $1",
+			"parameters": []any{fmt.Sprintf("value-%02d", i)},
+		}, inputSchema, int64(1000+i*100)))
+	}
+	recordedRoot := &core.Span{TraceId: traceID, SpanId: "root", PackageName: "http", IsRootSpan: true, Timestamp: unixMsToTimestamp(900)}
+	test := Test{
+		TraceID:  traceID,
+		Request:  Request{Method: "GET", Path: "/users"},
+		Response: Response{Status: http.StatusOK},
+		Spans:    append(spans, recordedRoot),
+	}
+
+	req := makeMockRequest(t, pkg, map[string]any{
+		"query":      "select * from users where id = $1",
+		"parameters": []any{"value-70x"},
+	}, inputSchema)
+
+	// Before the inbound request is sent the replay clock can't be mapped onto
+	// the recording, so candidates keep their oldest-first order
+	server.LoadSpansForTrace(traceID, test.Spans)
+	req.OutboundSpan.Timestamp = timestamppb.Now()
+	mm := NewMockMatcher(server)
+	assert.True(t, mm.recordedTimelineTarget(mm.reqToRequestData(req), traceID).IsZero())
+
+	// Replay happens much later than the recording; the query's offset into
+	// the replayed request places it next to span-70 on the recorded timeline
+	matches, _ := replayWithMockRequests(t, server, test, []time.Duration{7100 * time.Millisecond}, []*core.GetMockRequest{req})
+	require.Len(t, matches, 1)
+	assert.Equal(t, "span-70", matches[0].SpanId)
+}
+
+// replayWithMockRequests replays test through RunSingleTest against a stub
+// service that, as the SDK would while handling the request, asks server for
+// a mock for each of reqs, stamped at its offset into the request. It returns
+// the matched spans and their match levels.
+func replayWithMockRequests(t *testing.T, server *Server, test Test, offsets []time.Duration, reqs []*core.GetMockRequest) ([]*core.Span, []*core.MatchLevel) {
+	t.Helper()
+	var matches []*core.Span
+	var levels []*core.MatchLevel
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received := time.Now()
+		mm := NewMockMatcher(server)
+		for i, req := range reqs {
+			req.OutboundSpan.Timestamp = timestamppb.New(received.Add(offsets[i]))
+			match, level, err := mm.FindBestMatchWithTracePriority(req, test.TraceID)
+			assert.NoError(t, err)
+			matches = append(matches, match)
+			levels = append(levels, level)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer service.Close()
+
+	executor := NewExecutor()
+	executor.serviceURL = service.URL
+	executor.server = server
+	_, err := executor.RunSingleTest(test)
+	require.NoError(t, err)
+	return matches, levels
+}
+
+func TestSortByTimestampProximity(t *testing.T) {
+	spans := []*core.Span{
+		{SpanId: "a", Timestamp: unixMsToTimestamp(1000)},
+		{SpanId: "no-ts"},
+		{SpanId: "b", Timestamp: unixMsToTimestamp(5000)},
+		{SpanId: "c", Timestamp: unixMsToTimestamp(3500)},
+	}
+
+	sorted := sortByTimestampProximity(spans, unixMsToTimestamp(4000).AsTime())
+	var ids []string
+	for _, s := range sorted {
+		ids = append(ids, s.SpanId)
+	}
+	assert.Equal(t, []string{"c", "b", "a", "no-ts"}, ids)
+	assert.Equal(t, "a", spans[0].SpanId, "input slice should not be reordered")
+}
+
 // TestFindBestMatchWithTracePriority_SimilarityScoring_TiebreakByTimestamp tests that when similarity
 // scores are identical, the oldest span is picked
 func TestFindBestMatchWithTracePriority_SimilarityScoring_TiebreakByTimestamp(t *testing.T) {
@@ -1368,3 +2122,83 @@ func TestFindBestMatchWithTracePriority_RegularReplayMode_GlobalReducedValueHash
 	assert.Equal(t, core.MatchType_MATCH_TYPE_INPUT_VALUE_HASH_REDUCED_SCHEMA, level.MatchType)
 	assert.Equal(t, core.MatchScope_MATCH_SCOPE_GLOBAL, level.MatchScope)
 }
+
+func TestFindBestMatchWithTracePriority_PrimaryKeySelectsSpan(t *testing.T) {
+	cfg, _ := config.Get()
+	inputSchema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{"method": {}, "body": {}},
+	}
+	input := func(key, requestID string) map[string]any {
+		return map[string]any{
+			"method": "POST",
+			"body": map[string]any{
+				"amount":  10.0,
+				"payment": map[string]any{"idempotencyKey": key},
+			},
+			"requestId": requestID,
+		}
+	}
+
+	traceID := "trace-primary-key"
+	newMatcher := func(t *testing.T, primaryKeys map[string]string) *MockMatcher {
+		t.Helper()
+		server, err := NewServer("svc", &cfg.Service)
+		require.NoError(t, err)
+		server.SetPrimaryKeys(primaryKeys)
+		// The spans differ only in the idempotency key
+		server.LoadSpansForTrace(traceID, []*core.Span{
+			makeSpan(t, traceID, "charge-k1", "payments", input("k1", "rec"), inputSchema, 100),
+			makeSpan(t, traceID, "charge-k2", "payments", input("k2", "rec"), inputSchema, 200),
+		})
+		return NewMockMatcher(server)
+	}
+	request := func(key string) *core.GetMockRequest {
+		return makeMockRequest(t, "payments", input(key, "replay"), inputSchema)
+	}
+
+	mm := newMatcher(t, map[string]string{"payments": "$.body.payment.idempotencyKey"})
+	got, level, err := mm.FindBestMatchWithTracePriority(request("k2"), traceID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "charge-k2", got.SpanId)
+	assert.Equal(t, "primary key $.body.payment.idempotencyKey", level.MatchDescription)
+
+	// A retried call reuses the span with its key, even though the other span is unused
+	got, level, err = mm.FindBestMatchWithTracePriority(request("k2"), traceID)
+	require.NoError(t, err)
+	assert.Equal(t, "charge-k2", got.SpanId)
+	assert.Equal(t, "primary key $.body.payment.idempotencyKey (used span)", level.MatchDescription)
+
+	// Without the primary key, similarity prefers the unused span
+	mm = newMatcher(t, nil)
+	got, _, err = mm.FindBestMatchWithTracePriority(request("k2"), traceID)
+	require.NoError(t, err)
+	require.Equal(t, "charge-k2", got.SpanId)
+	got, _, err = mm.FindBestMatchWithTracePriority(request("k2"), traceID)
+	require.NoError(t, err)
+	assert.Equal(t, "charge-k1", got.SpanId)
+
+	// Unknown keys fall through to the rest of the ladder
+	mm = newMatcher(t, map[string]string{"payments": "$.body.payment.idempotencyKey"})
+	got, level, err = mm.FindBestMatchWithTracePriority(request("k3"), traceID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.NotContains(t, level.MatchDescription, "primary key")
+}
+
+func TestInputValueAtPath(t *testing.T) {
+	input := map[string]any{"headers": map[string]any{"idempotency-key": "abc", "empty": nil}, "n": 1.0}
+
+	v, ok := inputValueAtPath(input, "$.headers.idempotency-key")
+	assert.True(t, ok)
+	assert.Equal(t, "abc", v)
+
+	v, ok = inputValueAtPath(input, "n")
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, v)
+
+	for _, path := range []string{"$.headers.empty", "$.headers.missing", "$.n.deeper"} {
+		_, ok = inputValueAtPath(input, path)
+		assert.False(t, ok, path)
+	}
+}
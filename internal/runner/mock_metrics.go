@@ -0,0 +1,114 @@
+package runner
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// mockLatencyBuckets are the upper bounds of the mock search time histogram.
+var mockLatencyBuckets = [...]time.Duration{
+	100 * time.Microsecond,
+	250 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// mockMetrics counts mock requests and how long finding each mock took. It
+// only uses atomics, so concurrent mock requests never wait on each other.
+type mockMetrics struct {
+	requests atomic.Int64
+	found    atomic.Int64
+	totalNs  atomic.Int64
+	maxNs    atomic.Int64
+	// buckets[i] counts searches up to mockLatencyBuckets[i]; the last one
+	// counts slower searches
+	buckets [len(mockLatencyBuckets) + 1]atomic.Int64
+}
+
+func (m *mockMetrics) record(d time.Duration, found bool) {
+	m.requests.Add(1)
+	if found {
+		m.found.Add(1)
+	}
+	ns := d.Nanoseconds()
+	m.totalNs.Add(ns)
+	for {
+		cur := m.maxNs.Load()
+		if ns <= cur || m.maxNs.CompareAndSwap(cur, ns) {
+			break
+		}
+	}
+
+	i := 0
+	for i < len(mockLatencyBuckets) && d > mockLatencyBuckets[i] {
+		i++
+	}
+	m.buckets[i].Add(1)
+}
+
+// MockMetrics is a snapshot of the mock server's request counters.
+type MockMetrics struct {
+	Requests int64 `json:"requests"`
+	Found    int64 `json:"found"`
+	NotFound int64 `json:"notFound"`
+	// AvgLatency is the mean time to answer a mock request
+	AvgLatency time.Duration `json:"avgLatencyNs"`
+	// P95Latency is the upper bound of the histogram bucket holding the 95th
+	// percentile, capped at MaxLatency
+	P95Latency time.Duration `json:"p95LatencyNs"`
+	MaxLatency time.Duration `json:"maxLatencyNs"`
+}
+
+func (m *mockMetrics) snapshot() MockMetrics {
+	if m == nil {
+		return MockMetrics{}
+	}
+	// Counters are read one at a time, so a snapshot taken while requests are
+	// in flight may be off by those requests
+	requests := m.requests.Load()
+	found := m.found.Load()
+	out := MockMetrics{
+		Requests:   requests,
+		Found:      found,
+		NotFound:   requests - found,
+		MaxLatency: time.Duration(m.maxNs.Load()),
+	}
+	if requests == 0 {
+		return out
+	}
+	out.AvgLatency = time.Duration(m.totalNs.Load() / requests)
+
+	rank := int64(math.Ceil(0.95 * float64(requests)))
+	var seen int64
+	out.P95Latency = out.MaxLatency
+	for i := range mockLatencyBuckets {
+		seen += m.buckets[i].Load()
+		if seen >= rank {
+			out.P95Latency = min(mockLatencyBuckets[i], out.MaxLatency)
+			break
+		}
+	}
+	return out
+}
+
+// String formats the metrics on one line, e.g. "Mock requests: 120 (118
+// found, 2 not found), avg 1.2ms, p95 <= 5ms, max 40ms".
+func (m MockMetrics) String() string {
+	return fmt.Sprintf("Mock requests: %d (%d found, %d not found), avg %s, p95 <= %s, max %s",
+		m.Requests, m.Found, m.NotFound,
+		m.AvgLatency.Round(time.Microsecond), m.P95Latency.Round(time.Microsecond), m.MaxLatency.Round(time.Microsecond))
+}
@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+)
+
+func TestMockMetricsSnapshot(t *testing.T) {
+	var m mockMetrics
+	assert.Equal(t, MockMetrics{}, m.snapshot())
+
+	// 19 fast searches and one slow one: p95 falls in the fast bucket
+	for range 19 {
+		m.record(200*time.Microsecond, true)
+	}
+	m.record(3*time.Second, false)
+
+	s := m.snapshot()
+	assert.Equal(t, int64(20), s.Requests)
+	assert.Equal(t, int64(19), s.Found)
+	assert.Equal(t, int64(1), s.NotFound)
+	assert.Equal(t, (19*200*time.Microsecond+3*time.Second)/20, s.AvgLatency)
+	assert.Equal(t, 250*time.Microsecond, s.P95Latency)
+	assert.Equal(t, 3*time.Second, s.MaxLatency)
+	assert.Contains(t, s.String(), "Mock requests: 20 (19 found, 1 not found)")
+
+	// Past the last bucket, p95 is the slowest search
+	m.record(20*time.Second, false)
+	m.record(30*time.Second, false)
+	assert.Equal(t, 30*time.Second, m.snapshot().P95Latency)
+}
+
+func TestMockMetricsConcurrentRecords(t *testing.T) {
+	var m mockMetrics
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				m.record(time.Duration(i)*time.Millisecond, i%2 == 0)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s := m.snapshot()
+	assert.Equal(t, int64(5000), s.Requests)
+	assert.Equal(t, int64(2500), s.Found)
+	assert.Equal(t, 49*time.Millisecond, s.MaxLatency)
+}
+
+func TestFindMockRecordsMetrics(t *testing.T) {
+	cfg, _ := config.Get()
+	server, err := NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = server.Stop() })
+
+	traceID := "trace-metrics"
+	server.LoadSpansForTrace(traceID, []*core.Span{
+		makeSpan(t, traceID, "get-user", "redis", map[string]any{"command": "GET", "key": "user:1"}, nil, 100),
+	})
+
+	found := makeMockRequest(t, "redis", map[string]any{"command": "GET", "key": "user:1"}, nil)
+	found.TestId = traceID
+	missing := makeMockRequest(t, "pg", map[string]any{"query": "SELECT 1"}, nil)
+	missing.TestId = traceID
+	require.True(t, server.findMockWithTimeout(found).Found)
+	require.False(t, server.findMockWithTimeout(missing).Found)
+
+	metrics := server.GetMetrics()
+	assert.Equal(t, int64(2), metrics.Requests)
+	assert.Equal(t, int64(1), metrics.Found)
+	assert.Positive(t, metrics.MaxLatency)
+}
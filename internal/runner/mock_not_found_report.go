@@ -0,0 +1,108 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// MockNotFoundReportEntry describes an outbound call that found no mock.
+type MockNotFoundReportEntry struct {
+	PackageName string `json:"packageName"`
+	Operation   string `json:"operation,omitempty"`
+	SpanName    string `json:"spanName,omitempty"`
+	StackTrace  string `json:"stackTrace,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Closest     string `json:"closest,omitempty"`
+}
+
+// MockNotFoundReportTrace groups the missing mocks of one trace.
+type MockNotFoundReportTrace struct {
+	TraceID      string                    `json:"traceId"`
+	MockNotFound []MockNotFoundReportEntry `json:"mockNotFound"`
+}
+
+// MockNotFoundReport collects mock-not-found events across a run. Like
+// MatchReport, events must be recorded before the server cleans up the trace.
+type MockNotFoundReport struct {
+	mu     sync.Mutex
+	traces map[string][]MockNotFoundEvent
+}
+
+func NewMockNotFoundReport() *MockNotFoundReport {
+	return &MockNotFoundReport{traces: make(map[string][]MockNotFoundEvent)}
+}
+
+// Record stores the mock-not-found events of a trace, replacing anything
+// recorded earlier. Traces without events are not stored.
+func (r *MockNotFoundReport) Record(traceID string, events []MockNotFoundEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(events) == 0 {
+		delete(r.traces, traceID)
+		return
+	}
+	r.traces[traceID] = events
+}
+
+// Events returns the recorded events by trace ID.
+func (r *MockNotFoundReport) Events() map[string][]MockNotFoundEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return maps.Clone(r.traces)
+}
+
+// Traces returns the traces with missing mocks sorted by trace ID. Entries
+// are sorted too, since outbound calls can arrive in any order.
+func (r *MockNotFoundReport) Traces() []MockNotFoundReportTrace {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]MockNotFoundReportTrace, 0, len(r.traces))
+	for _, traceID := range slices.Sorted(maps.Keys(r.traces)) {
+		events := r.traces[traceID]
+		entries := make([]MockNotFoundReportEntry, 0, len(events))
+		for _, ev := range events {
+			entries = append(entries, MockNotFoundReportEntry{
+				PackageName: ev.PackageName,
+				Operation:   ev.Operation,
+				SpanName:    ev.SpanName,
+				StackTrace:  ev.StackTrace,
+				Error:       ev.Error,
+				Closest:     ev.ClosestDescription(),
+			})
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].PackageName != entries[j].PackageName {
+				return entries[i].PackageName < entries[j].PackageName
+			}
+			return entries[i].SpanName < entries[j].SpanName
+		})
+		out = append(out, MockNotFoundReportTrace{TraceID: traceID, MockNotFound: entries})
+	}
+	return out
+}
+
+// WriteToFile writes the report as an indented JSON array to path.
+func (r *MockNotFoundReport) WriteToFile(path string) error {
+	data, err := json.MarshalIndent(r.Traces(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal mock-not-found report: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to create mock-not-found report directory: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write mock-not-found report: %w", err)
+	}
+	return nil
+}
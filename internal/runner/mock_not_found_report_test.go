@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockNotFoundReport_WriteToFile(t *testing.T) {
+	report := NewMockNotFoundReport()
+	report.Record("trace-b", []MockNotFoundEvent{
+		{PackageName: "pg", Operation: "query", SpanName: "pg.query", StackTrace: "at db.js:10", Error: "no mock found"},
+		{PackageName: "http", Operation: "GET", SpanName: "GET /users"},
+	})
+	report.Record("trace-a", []MockNotFoundEvent{{PackageName: "redis", SpanName: "GET"}})
+	report.Record("trace-c", nil)
+
+	path := filepath.Join(t.TempDir(), "nested", "mock-not-found.json")
+	require.NoError(t, report.WriteToFile(path))
+
+	data, err := os.ReadFile(path) // #nosec G304
+	require.NoError(t, err)
+	var traces []MockNotFoundReportTrace
+	require.NoError(t, json.Unmarshal(data, &traces))
+
+	require.Len(t, traces, 2, "traces without missing mocks are left out")
+	assert.Equal(t, "trace-a", traces[0].TraceID)
+	assert.Equal(t, "trace-b", traces[1].TraceID)
+	require.Len(t, traces[1].MockNotFound, 2)
+	assert.Equal(t, "http", traces[1].MockNotFound[0].PackageName)
+	assert.Equal(t, MockNotFoundReportEntry{
+		PackageName: "pg",
+		Operation:   "query",
+		SpanName:    "pg.query",
+		StackTrace:  "at db.js:10",
+		Error:       "no mock found",
+	}, traces[1].MockNotFound[1])
+
+	assert.Len(t, report.Events(), 2)
+}
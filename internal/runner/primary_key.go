@@ -0,0 +1,42 @@
+package runner
+
+import (
+	"reflect"
+	"strings"
+
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+)
+
+// inputValueAtPath returns the value at a dot-separated path (optionally
+// prefixed with "$.") in a span input, e.g. "$.headers.idempotency-key".
+// Missing and null values are reported as not found.
+func inputValueAtPath(input any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	path = strings.TrimPrefix(path, ".")
+	v := input
+	for field := range strings.SplitSeq(path, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[field]; !ok {
+			return nil, false
+		}
+	}
+	return v, v != nil
+}
+
+// spansWithInputValueAt returns the spans whose input has value at path,
+// keeping their order.
+func spansWithInputValueAt(spans []*core.Span, path string, value any) []*core.Span {
+	var out []*core.Span
+	for _, span := range spans {
+		if span.InputValue == nil {
+			continue
+		}
+		if v, ok := inputValueAtPath(span.InputValue.AsMap(), path); ok && reflect.DeepEqual(v, value) {
+			out = append(out, span)
+		}
+	}
+	return out
+}
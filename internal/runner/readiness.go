@@ -0,0 +1,108 @@
+package runner
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+)
+
+// readinessProbeTimeout bounds a single HTTP or TCP readiness attempt.
+const readinessProbeTimeout = 2 * time.Second
+
+// readinessProbe checks once whether the service is ready to accept the first
+// test, as configured under service.readiness_check.
+type readinessProbe struct {
+	// description names the probe in startup failure messages
+	description string
+	check       func() error
+}
+
+// newReadinessProbe returns the configured readiness probe, or nil when none
+// is configured.
+func (e *Executor) newReadinessProbe(cfg *config.Config) *readinessProbe {
+	readiness := cfg.Service.Readiness
+	switch {
+	case readiness.Command != "":
+		return &readinessProbe{
+			description: fmt.Sprintf("command %q", readiness.Command),
+			check: func() error {
+				cmd := createReadinessCommand(readiness.Command)
+				cmd.Env = e.buildCommandEnv()
+				return cmd.Run()
+			},
+		}
+	case readiness.HTTPURL != "":
+		url := e.groupReadinessURL(readiness.HTTPURL)
+		return &readinessProbe{
+			description: "GET " + url,
+			check: func() error {
+				return checkHTTPReady(url)
+			},
+		}
+	case readiness.TCPPort != 0:
+		// Offset like service.port so parallel environment groups probe their own service
+		addr := net.JoinHostPort("localhost", strconv.Itoa(e.groupServicePort(readiness.TCPPort)))
+		return &readinessProbe{
+			description: "TCP connect to " + addr,
+			check: func() error {
+				conn, err := net.DialTimeout("tcp", addr, readinessProbeTimeout)
+				if err != nil {
+					return err
+				}
+				return conn.Close()
+			},
+		}
+	}
+	return nil
+}
+
+// groupReadinessURL offsets the port of a readiness URL like service.port,
+// so parallel environment groups probe their own service. A URL without a
+// port is offset from its scheme's default port.
+func (e *Executor) groupReadinessURL(rawURL string) string {
+	if e.envGroupIndex <= 1 {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		switch u.Scheme {
+		case "http":
+			port = 80
+		case "https":
+			port = 443
+		default:
+			return rawURL
+		}
+	}
+	u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(e.groupServicePort(port)))
+	return u.String()
+}
+
+func checkHTTPReady(url string) error {
+	client := &http.Client{
+		Timeout: readinessProbeTimeout,
+		// A redirect already shows the service is answering requests
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(url) // #nosec G107
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("unhealthy status %d", resp.StatusCode)
+	}
+	return nil
+}
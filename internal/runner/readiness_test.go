@@ -0,0 +1,158 @@
+package runner
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readyAfter returns a handler that answers 503 until delay has passed.
+func readyAfter(delay time.Duration) (http.Handler, *atomic.Int32) {
+	readyAt := time.Now().Add(delay)
+	var calls atomic.Int32
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if time.Now().Before(readyAt) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}), &calls
+}
+
+func readinessConfig(readiness config.ReadinessConfig) *config.Config {
+	return &config.Config{Service: config.ServiceConfig{Readiness: readiness}}
+}
+
+func TestWaitForReadinessHTTPProbe(t *testing.T) {
+	handler, calls := readyAfter(300 * time.Millisecond)
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	e := NewExecutor()
+	err := e.waitForReadiness(readinessConfig(config.ReadinessConfig{
+		HTTPURL:  srv.URL + "/health",
+		Timeout:  "5s",
+		Interval: "50ms",
+	}))
+	require.NoError(t, err)
+	assert.Greater(t, calls.Load(), int32(1), "probe should have been retried until healthy")
+	assert.Empty(t, e.GetStartupFailureHelpMessage())
+}
+
+func TestWaitForReadinessHTTPProbeTimesOut(t *testing.T) {
+	handler, _ := readyAfter(time.Hour)
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	e := NewExecutor()
+	err := e.waitForReadiness(readinessConfig(config.ReadinessConfig{
+		HTTPURL:  srv.URL + "/health",
+		Timeout:  "300ms",
+		Interval: "50ms",
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service failed to become ready")
+	assert.Contains(t, err.Error(), "unhealthy status 503")
+
+	help := e.GetStartupFailureHelpMessage()
+	assert.Contains(t, help, "Readiness probe never succeeded")
+	assert.Contains(t, help, "GET "+srv.URL+"/health")
+}
+
+func TestWaitForReadinessTCPProbe(t *testing.T) {
+	// Reserve a free port, then start listening on it after a delay
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	listening := make(chan net.Listener, 1)
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+		if err != nil {
+			listening <- nil
+			return
+		}
+		listening <- ln
+	}()
+	t.Cleanup(func() {
+		if ln := <-listening; ln != nil {
+			_ = ln.Close()
+		}
+	})
+
+	e := NewExecutor()
+	err = e.waitForReadiness(readinessConfig(config.ReadinessConfig{
+		TCPPort:  port,
+		Timeout:  "5s",
+		Interval: "50ms",
+	}))
+	require.NoError(t, err)
+}
+
+func TestCheckServerHealthUsesHTTPProbe(t *testing.T) {
+	config.Invalidate()
+	t.Cleanup(config.Invalidate)
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	cfgPath := writeTempConfig(t, "service:\n  port: 3000\n  start:\n    command: \"true\"\n  readiness_check:\n    http_url: "+healthy.URL+"\n")
+	require.NoError(t, config.Load(cfgPath))
+
+	e := NewExecutor()
+	assert.True(t, e.CheckServerHealth())
+}
+
+func TestReadinessHTTPProbeOffsetsPortForEnvironmentGroups(t *testing.T) {
+	// Find two consecutive free ports, for the services of groups 1 and 2
+	var group1, group2 net.Listener
+	for range 20 {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		next, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(l.Addr().(*net.TCPAddr).Port+1)))
+		if err == nil {
+			group1, group2 = l, next
+			break
+		}
+		_ = l.Close()
+	}
+	require.NotNil(t, group1, "no two consecutive free ports")
+
+	// Group 1's service is still starting; group 2's is ready
+	starting := &httptest.Server{Listener: group1, Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})}}
+	starting.Start()
+	defer starting.Close()
+	ready := &httptest.Server{Listener: group2, Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})}}
+	ready.Start()
+	defer ready.Close()
+
+	cfg := readinessConfig(config.ReadinessConfig{HTTPURL: starting.URL + "/health"})
+	e := NewExecutor()
+
+	first := e.newEnvironmentExecutor("production", 1).newReadinessProbe(cfg)
+	assert.Equal(t, "GET "+starting.URL+"/health", first.description)
+	assert.Error(t, first.check())
+
+	second := e.newEnvironmentExecutor("staging", 2).newReadinessProbe(cfg)
+	assert.Equal(t, "GET "+ready.URL+"/health", second.description)
+	assert.NoError(t, second.check(), "group 2 must probe its own service")
+
+	assert.Equal(t, "https://api.local:444/health", e.newEnvironmentExecutor("staging", 2).groupReadinessURL("https://api.local/health"))
+}
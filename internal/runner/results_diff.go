@@ -0,0 +1,185 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ResultsFile is the subset of a results file written by WriteRunResultsToFile
+// (a JSON-encoded UploadTraceTestResultsRequest plus environment labels)
+// needed to compare runs.
+// Span payloads are left out because the proto Struct values they contain
+// cannot be decoded with encoding/json.
+type ResultsFile struct {
+	CliVersion       string             `json:"cli_version,omitempty"`
+	SdkVersion       string             `json:"sdk_version,omitempty"`
+	TraceTestResults []ResultsFileEntry `json:"trace_test_results,omitempty"`
+	// Trace test ID -> environment group, for runs replayed by environment
+	Environments map[string]string `json:"environments,omitempty"`
+}
+
+type ResultsFileEntry struct {
+	TraceTestID        string                  `json:"trace_test_id,omitempty"`
+	TestSuccess        bool                    `json:"test_success,omitempty"`
+	TestFailureMessage *string                 `json:"test_failure_message,omitempty"`
+	SpanResults        []ResultsFileSpanResult `json:"span_results,omitempty"`
+}
+
+type ResultsFileSpanResult struct {
+	Deviations []ResultsFileDeviation `json:"deviations,omitempty"`
+}
+
+type ResultsFileDeviation struct {
+	Field       string `json:"field,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// LoadResultsFile reads a results file produced by --save-results.
+func LoadResultsFile(path string) (*ResultsFile, error) {
+	data, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results file: %w", err)
+	}
+
+	var rf ResultsFile
+	if err := json.Unmarshal(data, &rf); err != nil {
+		return nil, fmt.Errorf("failed to parse results file %s: %w", path, err)
+	}
+	return &rf, nil
+}
+
+// deviationFields returns the sorted, de-duplicated deviation fields of a test.
+func (e ResultsFileEntry) deviationFields() []string {
+	seen := make(map[string]bool)
+	fields := []string{}
+	for _, sr := range e.SpanResults {
+		for _, d := range sr.Deviations {
+			if !seen[d.Field] {
+				seen[d.Field] = true
+				fields = append(fields, d.Field)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// ResultsDiff describes how test outcomes changed between two runs.
+type ResultsDiff struct {
+	NewlyFailing   []ResultsDiffEntry `json:"newlyFailing"`
+	NewlyPassing   []ResultsDiffEntry `json:"newlyPassing"`
+	ChangedFields  []ResultsDiffEntry `json:"changedDeviations"`
+	OnlyInBase     []string           `json:"onlyInBase"`
+	OnlyInHead     []string           `json:"onlyInHead"`
+	UnchangedCount int                `json:"unchangedCount"`
+}
+
+type ResultsDiffEntry struct {
+	TraceTestID string `json:"traceTestId"`
+	// Deviation fields in each run; for changed deviations, the fields that
+	// appeared or disappeared are listed in Added/Removed.
+	BaseFields []string `json:"baseFields,omitempty"`
+	HeadFields []string `json:"headFields,omitempty"`
+	Added      []string `json:"addedFields,omitempty"`
+	Removed    []string `json:"removedFields,omitempty"`
+	Message    string   `json:"message,omitempty"`
+}
+
+// HasRegressions reports whether any test newly fails in head.
+func (d *ResultsDiff) HasRegressions() bool {
+	return len(d.NewlyFailing) > 0
+}
+
+// DiffResults compares base and head results by trace test ID. All lists are
+// sorted by ID so output is stable.
+func DiffResults(base, head *ResultsFile) *ResultsDiff {
+	baseByID := make(map[string]ResultsFileEntry, len(base.TraceTestResults))
+	for _, e := range base.TraceTestResults {
+		baseByID[e.TraceTestID] = e
+	}
+	headByID := make(map[string]ResultsFileEntry, len(head.TraceTestResults))
+	for _, e := range head.TraceTestResults {
+		headByID[e.TraceTestID] = e
+	}
+
+	diff := &ResultsDiff{
+		NewlyFailing:  []ResultsDiffEntry{},
+		NewlyPassing:  []ResultsDiffEntry{},
+		ChangedFields: []ResultsDiffEntry{},
+		OnlyInBase:    []string{},
+		OnlyInHead:    []string{},
+	}
+
+	for id := range baseByID {
+		if _, ok := headByID[id]; !ok {
+			diff.OnlyInBase = append(diff.OnlyInBase, id)
+		}
+	}
+
+	for id, h := range headByID {
+		b, ok := baseByID[id]
+		if !ok {
+			diff.OnlyInHead = append(diff.OnlyInHead, id)
+			continue
+		}
+
+		entry := ResultsDiffEntry{
+			TraceTestID: id,
+			BaseFields:  b.deviationFields(),
+			HeadFields:  h.deviationFields(),
+		}
+		switch {
+		case b.TestSuccess && !h.TestSuccess:
+			if h.TestFailureMessage != nil {
+				entry.Message = *h.TestFailureMessage
+			}
+			diff.NewlyFailing = append(diff.NewlyFailing, entry)
+		case !b.TestSuccess && h.TestSuccess:
+			diff.NewlyPassing = append(diff.NewlyPassing, entry)
+		case !b.TestSuccess && !h.TestSuccess:
+			entry.Added, entry.Removed = stringSetDiff(entry.BaseFields, entry.HeadFields)
+			if len(entry.Added) > 0 || len(entry.Removed) > 0 {
+				diff.ChangedFields = append(diff.ChangedFields, entry)
+			} else {
+				diff.UnchangedCount++
+			}
+		default:
+			diff.UnchangedCount++
+		}
+	}
+
+	byID := func(entries []ResultsDiffEntry) {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].TraceTestID < entries[j].TraceTestID })
+	}
+	byID(diff.NewlyFailing)
+	byID(diff.NewlyPassing)
+	byID(diff.ChangedFields)
+	sort.Strings(diff.OnlyInBase)
+	sort.Strings(diff.OnlyInHead)
+
+	return diff
+}
+
+// stringSetDiff returns the elements only in head (added) and only in base (removed).
+// Both inputs must be sorted; outputs are sorted.
+func stringSetDiff(base, head []string) (added, removed []string) {
+	inBase := make(map[string]bool, len(base))
+	for _, s := range base {
+		inBase[s] = true
+	}
+	inHead := make(map[string]bool, len(head))
+	for _, s := range head {
+		inHead[s] = true
+		if !inBase[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range base {
+		if !inHead[s] {
+			removed = append(removed, s)
+		}
+	}
+	return added, removed
+}
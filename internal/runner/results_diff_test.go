@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	backend "github.com/Use-Tusk/tusk-drift-schemas/generated/go/backend"
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// writeResultsFile encodes req the same way WriteRunResultsToFile does.
+func writeResultsFile(t *testing.T, dir, name string, results ...*backend.TraceTestResult) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	data, err := json.Marshal(&backend.UploadTraceTestResultsRequest{CliVersion: "test", TraceTestResults: results})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func traceResult(id string, success bool, fields ...string) *backend.TraceTestResult {
+	value, _ := structpb.NewStruct(map[string]any{"statusCode": 200})
+	tr := &backend.TraceTestResult{TraceTestId: id, TestSuccess: success}
+	spanResult := &backend.TraceTestSpanResult{ReplaySpan: &core.Span{SpanId: "root", OutputValue: value}}
+	for _, f := range fields {
+		spanResult.Deviations = append(spanResult.Deviations, &backend.Deviation{Field: f, Description: f + " differs"})
+	}
+	tr.SpanResults = append(tr.SpanResults, spanResult)
+	if !success {
+		msg := "response mismatch"
+		tr.TestFailureMessage = &msg
+	}
+	return tr
+}
+
+func TestDiffResults(t *testing.T) {
+	dir := t.TempDir()
+	basePath := writeResultsFile(t, dir, "base.json",
+		traceResult("a", true),
+		traceResult("b", false, "response.body"),
+		traceResult("c", false, "response.body"),
+		traceResult("d", false, "response.status"),
+		traceResult("gone", true),
+	)
+	headPath := writeResultsFile(t, dir, "head.json",
+		traceResult("a", false, "response.body", "response.headers"),
+		traceResult("b", true),
+		traceResult("c", false, "response.body"),
+		traceResult("d", false, "response.body"),
+		traceResult("new", true),
+	)
+
+	base, err := LoadResultsFile(basePath)
+	require.NoError(t, err)
+	head, err := LoadResultsFile(headPath)
+	require.NoError(t, err)
+	require.Len(t, base.TraceTestResults, 5)
+
+	diff := DiffResults(base, head)
+	assert.True(t, diff.HasRegressions())
+
+	require.Len(t, diff.NewlyFailing, 1)
+	assert.Equal(t, "a", diff.NewlyFailing[0].TraceTestID)
+	assert.Equal(t, []string{"response.body", "response.headers"}, diff.NewlyFailing[0].HeadFields)
+	assert.Equal(t, "response mismatch", diff.NewlyFailing[0].Message)
+
+	require.Len(t, diff.NewlyPassing, 1)
+	assert.Equal(t, "b", diff.NewlyPassing[0].TraceTestID)
+
+	require.Len(t, diff.ChangedFields, 1)
+	assert.Equal(t, "d", diff.ChangedFields[0].TraceTestID)
+	assert.Equal(t, []string{"response.body"}, diff.ChangedFields[0].Added)
+	assert.Equal(t, []string{"response.status"}, diff.ChangedFields[0].Removed)
+
+	assert.Equal(t, []string{"gone"}, diff.OnlyInBase)
+	assert.Equal(t, []string{"new"}, diff.OnlyInHead)
+	assert.Equal(t, 1, diff.UnchangedCount)
+}
+
+func TestLoadResultsFile_InvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o600))
+
+	_, err := LoadResultsFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse results file")
+}
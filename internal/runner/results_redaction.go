@@ -0,0 +1,88 @@
+package runner
+
+import (
+	backend "github.com/Use-Tusk/tusk-drift-schemas/generated/go/backend"
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// SetResultsRedaction configures results.redact. When enabled, the default
+// redacted fields plus fields (logging.redact_fields) are redacted from the
+// request and response data in saved results files. It is enabled by default.
+func (e *Executor) SetResultsRedaction(enabled bool, fields []string) error {
+	if !enabled {
+		e.resultsRedactor = nil
+		return nil
+	}
+	r, err := newFieldRedactor(fields)
+	if err != nil {
+		return err
+	}
+	e.resultsRedactor = r
+	return nil
+}
+
+// RedactForResults returns copies of test and result with request and
+// response bodies and deviation values redacted for writing to a results
+// file. They are returned unchanged when results redaction is disabled.
+func (e *Executor) RedactForResults(test Test, result TestResult) (Test, TestResult) {
+	r := e.resultsRedactor
+	if r == nil {
+		return test, result
+	}
+	test.Request.Body = r.RedactPayload(test.Request.Body)
+	test.Response.Body = r.RedactPayload(test.Response.Body)
+	if len(result.Deviations) > 0 {
+		deviations := make([]Deviation, len(result.Deviations))
+		for i, d := range result.Deviations {
+			d.Expected = r.RedactPayload(d.Expected)
+			d.Actual = r.RedactPayload(d.Actual)
+			deviations[i] = d
+		}
+		result.Deviations = deviations
+	}
+	return test, result
+}
+
+// redactTraceTestResults redacts the input and output values of the replay
+// spans in results, in place. The spans are cloned first since they are
+// shared with the mock server.
+func (e *Executor) redactTraceTestResults(results []*backend.TraceTestResult) {
+	r := e.resultsRedactor
+	if r == nil {
+		return
+	}
+	for _, tr := range results {
+		for _, spanRes := range tr.SpanResults {
+			if spanRes.ReplaySpan != nil {
+				spanRes.ReplaySpan = redactSpanValues(r, spanRes.ReplaySpan)
+			}
+		}
+	}
+}
+
+func redactSpanValues(r *fieldRedactor, span *core.Span) *core.Span {
+	clone, ok := proto.Clone(span).(*core.Span)
+	if !ok {
+		return span
+	}
+	clone.InputValue = redactStruct(r, clone.InputValue)
+	clone.OutputValue = redactStruct(r, clone.OutputValue)
+	return clone
+}
+
+func redactStruct(r *fieldRedactor, s *structpb.Struct) *structpb.Struct {
+	if s == nil {
+		return nil
+	}
+	redacted, ok := r.RedactPayload(s.AsMap()).(map[string]any)
+	if !ok {
+		return s
+	}
+	out, err := structpb.NewStruct(redacted)
+	if err != nil {
+		return s
+	}
+	return out
+}
@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func compileResultsFileSchema(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+	// Round-trip through JSON, as `tusk schema results` prints it
+	data, err := json.Marshal(ResultsFileSchema())
+	require.NoError(t, err)
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	compiler := jsonschema.NewCompiler()
+	require.NoError(t, compiler.AddResource("results.schema.json", doc))
+	schema, err := compiler.Compile("results.schema.json")
+	require.NoError(t, err)
+	return schema
+}
+
+func TestResultsFileSchemaValidatesResultsFile(t *testing.T) {
+	cfg, _ := config.Get()
+	server, err := NewServer("test-service", &cfg.Service)
+	require.NoError(t, err)
+	defer func() { _ = server.Stop() }()
+
+	input, err := structpb.NewStruct(map[string]any{"method": "GET", "target": "/users", "tags": []any{"a", 1}})
+	require.NoError(t, err)
+	output, err := structpb.NewStruct(map[string]any{"statusCode": 200, "body": map[string]any{"id": 7}})
+	require.NoError(t, err)
+	server.replayInbound = map[string]*core.Span{"trace-1": {
+		TraceId:     "trace-1",
+		SpanId:      "root",
+		Kind:        core.SpanKind_SPAN_KIND_SERVER,
+		InputValue:  input,
+		OutputValue: output,
+	}}
+
+	resultsDir := t.TempDir()
+	executor := &Executor{
+		server:      server,
+		resultsDir:  resultsDir,
+		ResultsFile: filepath.Join(resultsDir, "results.json"),
+	}
+	tests := []Test{{TraceID: "trace-1", TraceTestID: "tt-1"}, {TraceID: "trace-2"}}
+	results := []TestResult{
+		{
+			TestID:      "trace-1",
+			Passed:      false,
+			Environment: "staging",
+			Deviations:  []Deviation{{Field: "response.body.id", Description: "expected 7, got 8"}},
+		},
+		{TestID: "trace-2", Passed: true},
+	}
+	path, err := executor.WriteRunResultsToFile(tests, results)
+	require.NoError(t, err)
+
+	f, err := os.Open(path) // #nosec G304
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	file, err := jsonschema.UnmarshalJSON(f)
+	require.NoError(t, err)
+
+	assert.NoError(t, compileResultsFileSchema(t).Validate(file))
+}
+
+func TestResultsFileSchemaRejectsUnknownFields(t *testing.T) {
+	schema := compileResultsFileSchema(t)
+
+	valid, err := jsonschema.UnmarshalJSON(bytes.NewReader([]byte(`{"cli_version":"1.0.0","trace_test_results":[{"trace_test_id":"tt-1","test_success":true}]}`)))
+	require.NoError(t, err)
+	assert.NoError(t, schema.Validate(valid))
+
+	invalid, err := jsonschema.UnmarshalJSON(bytes.NewReader([]byte(`{"cli_version":"1.0.0","trace_test_results":[{"trace_test_id":"tt-1","passed":true}]}`)))
+	require.NoError(t, err)
+	assert.Error(t, schema.Validate(invalid))
+}
@@ -17,6 +17,63 @@ import (
 	backend "github.com/Use-Tusk/tusk-drift-schemas/generated/go/backend"
 )
 
+// runResultsFile is the saved results file: the upload request plus what the
+// backend schema has no field for.
+type runResultsFile struct {
+	*backend.UploadTraceTestResultsRequest
+	// Environments maps trace test IDs to the environment group they ran in
+	Environments map[string]string `json:"environments,omitempty"`
+	// MatchEvents are the mock match decisions of each test, when
+	// results.include_match_events is set
+	MatchEvents []MatchReportTrace `json:"match_events,omitempty"`
+}
+
+// SetResultsMatchEvents embeds the match events recorded in report in the
+// results file written by WriteRunResultsToFile (results.include_match_events).
+// The server drops a trace's match events once its test completes, so they
+// must be recorded into report as tests finish.
+func (e *Executor) SetResultsMatchEvents(report *MatchReport) {
+	e.resultsMatchEvents = report
+}
+
+// resultMatchEvents returns the recorded match events of the traces in results.
+func (e *Executor) resultMatchEvents(results []TestResult) []MatchReportTrace {
+	if e.resultsMatchEvents == nil {
+		return nil
+	}
+	inRun := make(map[string]bool, len(results))
+	for _, r := range results {
+		inRun[r.TestID] = true
+	}
+	var traces []MatchReportTrace
+	for _, trace := range e.resultsMatchEvents.Traces() {
+		if inRun[trace.TraceID] {
+			traces = append(traces, trace)
+		}
+	}
+	return traces
+}
+
+// resultEnvironments keys each result's environment by the same trace test ID
+// BuildTraceTestResultsProto uses.
+func resultEnvironments(results []TestResult, testByID map[string]Test) map[string]string {
+	var envs map[string]string
+	for _, r := range results {
+		if r.Environment == "" {
+			continue
+		}
+		if envs == nil {
+			envs = make(map[string]string)
+		}
+		id := r.TestID
+		if t, ok := testByID[r.TestID]; ok && t.TraceTestID != "" {
+			id = t.TraceTestID
+		}
+		envs[id] = r.Environment
+	}
+	return envs
+}
+
 func (e *Executor) WriteRunResultsToFile(tests []Test, results []TestResult) (string, error) {
 	// Resolve output path
 	if err := config.Load(""); err != nil {
@@ -41,16 +98,24 @@ func (e *Executor) WriteRunResultsToFile(tests []Test, results []TestResult) (st
 	}
 
 	sdkVersion := ""
-	if e.server != nil {
-		sdkVersion = e.server.GetSDKVersion()
+	for _, r := range results {
+		if server := e.serverForTrace(r.TestID); server != nil {
+			sdkVersion = server.GetSDKVersion()
+			break
+		}
 	}
 
-	req := &backend.UploadTraceTestResultsRequest{
-		DriftRunId:       "", // Optional/unknown in local runs
-		CliVersion:       version.Version,
-		SdkVersion:       sdkVersion,
-		TraceTestResults: BuildTraceTestResultsProto(e, results, tests),
+	req := runResultsFile{
+		UploadTraceTestResultsRequest: &backend.UploadTraceTestResultsRequest{
+			DriftRunId:       "", // Optional/unknown in local runs
+			CliVersion:       version.Version,
+			SdkVersion:       sdkVersion,
+			TraceTestResults: BuildTraceTestResultsProto(e, results, tests),
+		},
+		Environments: resultEnvironments(results, testByID),
+		MatchEvents:  e.resultMatchEvents(results),
 	}
+	e.redactTraceTestResults(req.TraceTestResults)
 
 	f, err := os.Create(outPath) // #nosec G304
 	if err != nil {
@@ -82,13 +147,14 @@ func UploadSingleTestResult(
 		e.WaitForSpanData(test.TraceID, waitForSpanDataTimeout)
 	}
 
-	if e != nil && e.server != nil {
-		e.server.WaitForInboundSpan(test.TraceID, waitForSpanDataTimeout)
+	server := e.serverForTrace(test.TraceID)
+	if server != nil {
+		server.WaitForInboundSpan(test.TraceID, waitForSpanDataTimeout)
 	}
 
 	sdkVersion := "unknown"
-	if e != nil && e.server != nil {
-		if v := e.server.GetSDKVersion(); v != "" {
+	if server != nil {
+		if v := server.GetSDKVersion(); v != "" {
 			sdkVersion = v
 		}
 	}
@@ -214,6 +280,9 @@ func BuildTraceTestResultsProto(e *Executor, results []TestResult, tests []Test)
 	}
 
 	for _, r := range results {
+		// Parallel environment groups each record into their own server
+		server := e.serverForTrace(r.TestID)
+
 		traceTestID := r.TestID
 		if t, ok := testByTrace[r.TestID]; ok && t.TraceTestID != "" {
 			traceTestID = t.TraceTestID
@@ -238,7 +307,7 @@ func BuildTraceTestResultsProto(e *Executor, results []TestResult, tests []Test)
 					Field:       "response",
 					Description: fmt.Sprintf("No response received: %s", msg),
 				})
-			case e != nil && e.server != nil && e.server.HasMockNotFoundEvents(r.TestID):
+			case server != nil && server.HasMockNotFoundEvents(r.TestID):
 				// Check if there were any mock-not-found events during replay
 				reason := backend.TraceTestFailureReason_TRACE_TEST_FAILURE_REASON_MOCK_NOT_FOUND
 				tr.TestFailureReason = &reason
@@ -246,7 +315,7 @@ func BuildTraceTestResultsProto(e *Executor, results []TestResult, tests []Test)
 				tr.TestFailureMessage = &msg
 
 				// Build deviation message with details about which calls failed
-				mockEvents := e.server.GetMockNotFoundEvents(r.TestID)
+				mockEvents := server.GetMockNotFoundEvents(r.TestID)
 				var failedCalls []string
 				for _, ev := range mockEvents {
 					failedCalls = append(failedCalls, fmt.Sprintf("%s %s", ev.PackageName, ev.SpanName))
@@ -272,9 +341,9 @@ func BuildTraceTestResultsProto(e *Executor, results []TestResult, tests []Test)
 		}
 
 		// Inbound replay + root span id + deviations
-		if e != nil && e.server != nil {
-			inbound := e.server.GetInboundReplaySpan(r.TestID)
-			rootID := e.server.GetRootSpanID(r.TestID)
+		if server != nil {
+			inbound := server.GetInboundReplaySpan(r.TestID)
+			rootID := server.GetRootSpanID(r.TestID)
 			if inbound != nil || len(r.Deviations) > 0 || rootID != "" {
 				inboundRes := &backend.TraceTestSpanResult{}
 				if inbound != nil {
@@ -293,7 +362,7 @@ func BuildTraceTestResultsProto(e *Executor, results []TestResult, tests []Test)
 			}
 
 			// Outbound match events
-			events := e.server.GetMatchEvents(r.TestID)
+			events := server.GetMatchEvents(r.TestID)
 			for i := range events {
 				ev := events[i]
 				spanRes := &backend.TraceTestSpanResult{
@@ -310,7 +379,7 @@ func BuildTraceTestResultsProto(e *Executor, results []TestResult, tests []Test)
 			}
 
 			// Mock-not-found events (outbound requests that had no matching recording)
-			mockNotFoundEvents := e.server.GetMockNotFoundEvents(r.TestID)
+			mockNotFoundEvents := server.GetMockNotFoundEvents(r.TestID)
 			for i := range mockNotFoundEvents {
 				ev := mockNotFoundEvents[i]
 				spanRes := &backend.TraceTestSpanResult{
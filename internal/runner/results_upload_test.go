@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -16,6 +17,7 @@ import (
 	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 func TestWriteRunResultsToFile(t *testing.T) {
@@ -356,6 +358,160 @@ func TestWriteRunResultsToFile_JSONStructure(t *testing.T) {
 	}
 }
 
+func TestWriteRunResultsToFile_IncludesEnvironments(t *testing.T) {
+	t.Parallel()
+
+	resultsDir := t.TempDir()
+	executor := &Executor{
+		resultsDir:  resultsDir,
+		ResultsFile: filepath.Join(resultsDir, "results.json"),
+	}
+	tests := []Test{{TraceID: "trace-1", TraceTestID: "tt-1"}, {TraceID: "trace-2"}}
+	results := []TestResult{
+		{TestID: "trace-1", Passed: true, Environment: "production"},
+		{TestID: "trace-2", Passed: false, Environment: "staging"},
+	}
+
+	path, err := executor.WriteRunResultsToFile(tests, results)
+	require.NoError(t, err)
+
+	file, err := LoadResultsFile(path)
+	require.NoError(t, err)
+	assert.Len(t, file.TraceTestResults, 2)
+	assert.Equal(t, map[string]string{"tt-1": "production", "trace-2": "staging"}, file.Environments)
+}
+
+func TestWriteRunResultsToFile_IncludesMatchEvents(t *testing.T) {
+	t.Parallel()
+
+	score := float32(0.92)
+	report := NewMatchReport()
+	report.Record("trace-1", []MatchEvent{{
+		SpanID: "span-users",
+		MatchLevel: &core.MatchLevel{
+			MatchType:        core.MatchType_MATCH_TYPE_INPUT_SCHEMA_HASH,
+			MatchScope:       core.MatchScope_MATCH_SCOPE_TRACE,
+			MatchDescription: "Unused span by input schema hash",
+			SimilarityScore:  &score,
+		},
+		InputData:  map[string]any{"password": "hunter2"},
+		ReplaySpan: &core.Span{PackageName: "http", Name: "GET /users"},
+	}})
+	// Not part of this run
+	report.Record("trace-other", []MatchEvent{{SpanID: "span-other"}})
+
+	write := func(t *testing.T, report *MatchReport) []byte {
+		resultsDir := t.TempDir()
+		executor := &Executor{
+			resultsDir:  resultsDir,
+			ResultsFile: filepath.Join(resultsDir, "results.json"),
+		}
+		executor.SetResultsMatchEvents(report)
+		path, err := executor.WriteRunResultsToFile([]Test{{TraceID: "trace-1"}}, []TestResult{{TestID: "trace-1", Passed: true}})
+		require.NoError(t, err)
+		data, err := os.ReadFile(path) // #nosec G304
+		require.NoError(t, err)
+		return data
+	}
+
+	t.Run("embedded when enabled", func(t *testing.T) {
+		t.Parallel()
+
+		data := write(t, report)
+		var file runResultsFile
+		require.NoError(t, json.Unmarshal(data, &file))
+		require.Len(t, file.MatchEvents, 1)
+		trace := file.MatchEvents[0]
+		assert.Equal(t, "trace-1", trace.TraceID)
+		require.Len(t, trace.Matches, 1)
+		match := trace.Matches[0]
+		assert.Equal(t, "span-users", match.SpanID)
+		assert.Equal(t, "GET /users", match.Name)
+		assert.Equal(t, "MATCH_TYPE_INPUT_SCHEMA_HASH", match.MatchType)
+		assert.Equal(t, "MATCH_SCOPE_TRACE", match.MatchScope)
+		require.NotNil(t, match.SimilarityScore)
+		assert.InDelta(t, 0.92, *match.SimilarityScore, 0.001)
+		assert.NotContains(t, string(data), "hunter2")
+	})
+
+	t.Run("omitted by default", func(t *testing.T) {
+		t.Parallel()
+
+		assert.NotContains(t, string(write(t, nil)), "match_events")
+	})
+}
+
+func TestWriteRunResultsToFile_RedactsSecrets(t *testing.T) {
+	t.Parallel()
+
+	cfg, _ := config.Get()
+	server, err := NewServer("test-service", &cfg.Service)
+	require.NoError(t, err)
+	defer func() { _ = server.Stop() }()
+
+	body := base64.StdEncoding.EncodeToString([]byte(`{"token":"s3cr3t-value","user":{"id":7,"name":"alice"}}`))
+	input, err := structpb.NewStruct(map[string]any{"method": "POST", "target": "/login"})
+	require.NoError(t, err)
+	output, err := structpb.NewStruct(map[string]any{"statusCode": 200, "body": body})
+	require.NoError(t, err)
+	inbound := &core.Span{TraceId: "trace-1", SpanId: "root", InputValue: input, OutputValue: output}
+	server.replayInbound = map[string]*core.Span{"trace-1": inbound}
+
+	resultsDir := t.TempDir()
+	executor := &Executor{
+		server:      server,
+		resultsDir:  resultsDir,
+		ResultsFile: filepath.Join(resultsDir, "results.json"),
+	}
+	require.NoError(t, executor.SetResultsRedaction(true, nil))
+
+	tests := []Test{{TraceID: "trace-1", TraceTestID: "tt-1"}}
+	results := []TestResult{{TestID: "trace-1", Passed: true}}
+	path, err := executor.WriteRunResultsToFile(tests, results)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path) // #nosec G304
+	require.NoError(t, err)
+	var file backend.UploadTraceTestResultsRequest
+	require.NoError(t, json.Unmarshal(data, &file))
+	require.Len(t, file.TraceTestResults, 1)
+	require.Len(t, file.TraceTestResults[0].SpanResults, 1)
+	written := file.TraceTestResults[0].SpanResults[0].ReplaySpan
+	require.NotNil(t, written)
+
+	assert.Equal(t, "/login", written.InputValue.AsMap()["target"])
+	outputMap := written.OutputValue.AsMap()
+	assert.Equal(t, float64(200), outputMap["statusCode"])
+	decoded, err := base64.StdEncoding.DecodeString(outputMap["body"].(string))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"token":"TUSK_REDACTED_FIELD","user":{"id":7,"name":"alice"}}`, string(decoded))
+
+	assert.Equal(t, body, server.GetInboundReplaySpan("trace-1").OutputValue.AsMap()["body"], "the server's span must not be modified")
+
+	require.NoError(t, executor.SetResultsRedaction(false, nil))
+	path, err = executor.WriteRunResultsToFile(tests, results)
+	require.NoError(t, err)
+	data, err = os.ReadFile(path) // #nosec G304
+	require.NoError(t, err)
+	assert.Contains(t, string(data), body, "results.redact: false writes bodies as replayed")
+}
+
+func TestRedactForResults(t *testing.T) {
+	executor := NewExecutor()
+	test := Test{Request: Request{Body: map[string]any{"password": "p", "authorization": "Bearer x"}}}
+	result := TestResult{Deviations: []Deviation{{
+		Field:    "response.body",
+		Expected: map[string]any{"token": "old", "id": float64(1)},
+		Actual:   map[string]any{"token": "new", "id": float64(2)},
+	}}}
+
+	redactedTest, redactedResult := executor.RedactForResults(test, result)
+	assert.Equal(t, map[string]any{"password": "p", "authorization": "TUSK_REDACTED_FIELD"}, redactedTest.Request.Body)
+	assert.Equal(t, map[string]any{"token": "TUSK_REDACTED_FIELD", "id": float64(1)}, redactedResult.Deviations[0].Expected)
+	assert.Equal(t, map[string]any{"token": "TUSK_REDACTED_FIELD", "id": float64(2)}, redactedResult.Deviations[0].Actual)
+	assert.Equal(t, "old", result.Deviations[0].Expected.(map[string]any)["token"], "the original result is not modified")
+}
+
 func TestBuildTraceTestResultsProto_EdgeCases(t *testing.T) {
 	t.Parallel()
 
@@ -598,6 +754,34 @@ func TestBuildTraceTestResultsProto_WithMockNotFound(t *testing.T) {
 	})
 }
 
+func TestBuildTraceTestResultsProto_ParallelEnvironmentGroups(t *testing.T) {
+	t.Parallel()
+
+	cfg, _ := config.Get()
+	server, err := NewServer("test-service", &cfg.Service)
+	require.NoError(t, err)
+	defer func() { _ = server.Stop() }()
+
+	server.recordMockNotFoundEvent("trace-1", MockNotFoundEvent{
+		PackageName: "pg",
+		SpanName:    "pg.query",
+		Timestamp:   time.Now(),
+	})
+
+	// The parent executor has no server of its own; the group executor does
+	executor := NewExecutor()
+	envExecutor := executor.newEnvironmentExecutor("staging", 1)
+	envExecutor.server = server
+	executor.envExecutors.Store("trace-1", envExecutor)
+
+	protoResults := BuildTraceTestResultsProto(executor, []TestResult{{TestID: "trace-1"}}, []Test{{TraceID: "trace-1"}})
+
+	require.Len(t, protoResults, 1)
+	require.NotNil(t, protoResults[0].TestFailureReason)
+	assert.Equal(t, backend.TraceTestFailureReason_TRACE_TEST_FAILURE_REASON_MOCK_NOT_FOUND, *protoResults[0].TestFailureReason)
+	assert.NotEmpty(t, protoResults[0].SpanResults)
+}
+
 func TestBuildTraceTestResultsProto_WithNoResponse(t *testing.T) {
 	t.Parallel()
 
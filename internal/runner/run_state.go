@@ -0,0 +1,137 @@
+package runner
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// RunState persists the result of each completed test to a JSONL file as the
+// run goes (--state-file), so an interrupted run can be resumed without
+// replaying those tests again.
+type RunState struct {
+	path string
+
+	mu        sync.Mutex
+	completed map[string]TestResult
+	order     []string // trace IDs in completion order
+}
+
+// LoadRunState reads the results recorded in the state file at path. A
+// missing file is an empty state. A line torn by an interruption mid-write
+// is cut off the file, so its test runs again and later results start on a
+// line of their own.
+func LoadRunState(path string) (*RunState, error) {
+	s := &RunState{path: path, completed: make(map[string]TestResult)}
+
+	f, err := os.Open(path) // #nosec G304
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	reader := bufio.NewReader(f)
+	var complete int64 // bytes up to the end of the last complete line
+	torn := false
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			torn = len(line) > 0
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read state file: %w", err)
+		}
+		complete += int64(len(line))
+
+		var result TestResult
+		if err := json.Unmarshal(line, &result); err != nil || result.TestID == "" {
+			continue
+		}
+		s.add(result)
+	}
+
+	if torn {
+		if err := os.Truncate(path, complete); err != nil {
+			return nil, fmt.Errorf("failed to repair state file: %w", err)
+		}
+	}
+	return s, nil
+}
+
+func (s *RunState) add(result TestResult) {
+	if _, ok := s.completed[result.TestID]; !ok {
+		s.order = append(s.order, result.TestID)
+	}
+	s.completed[result.TestID] = result
+}
+
+// Record appends result to the state file. Cancelled tests didn't complete
+// and are left to run on resume.
+func (s *RunState) Record(result TestResult) error {
+	if result.Cancelled {
+		return nil
+	}
+	line, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) // #nosec G304
+	if err != nil {
+		return fmt.Errorf("failed to open state file: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	s.add(result)
+	return nil
+}
+
+// Results returns the results recorded when the state was loaded or since,
+// in completion order.
+func (s *RunState) Results() []TestResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	results := make([]TestResult, 0, len(s.order))
+	for _, id := range s.order {
+		results = append(results, s.completed[id])
+	}
+	return results
+}
+
+// Resume splits tests into those still to run and the recorded results of
+// the others, in test order.
+func (s *RunState) Resume(tests []Test) (pending []Test, done []TestResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range tests {
+		if result, ok := s.completed[t.TraceID]; ok {
+			done = append(done, result)
+		} else {
+			pending = append(pending, t)
+		}
+	}
+	return pending, done
+}
+
+// Clear deletes the state file once the run is complete.
+func (s *RunState) Clear() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunState_RecordsEachResultAsItCompletes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.jsonl")
+	state, err := LoadRunState(path)
+	require.NoError(t, err)
+	assert.Empty(t, state.Results())
+
+	require.NoError(t, state.Record(TestResult{TestID: "trace-1", Passed: true, Duration: 12}))
+	// Each result is on disk before the next test completes
+	reloaded, err := LoadRunState(path)
+	require.NoError(t, err)
+	require.Len(t, reloaded.Results(), 1)
+
+	require.NoError(t, state.Record(TestResult{TestID: "trace-2", Deviations: []Deviation{{Field: "response.status", Expected: 200.0, Actual: 500.0}}}))
+	require.NoError(t, state.Record(TestResult{TestID: "trace-3", Cancelled: true}))
+
+	reloaded, err = LoadRunState(path)
+	require.NoError(t, err)
+	results := reloaded.Results()
+	require.Len(t, results, 2, "cancelled tests aren't completed")
+	assert.Equal(t, TestResult{TestID: "trace-1", Passed: true, Duration: 12}, results[0])
+	assert.Equal(t, "trace-2", results[1].TestID)
+	assert.False(t, results[1].Passed)
+	require.Len(t, results[1].Deviations, 1)
+	assert.Equal(t, "response.status", results[1].Deviations[0].Field)
+
+	require.NoError(t, state.Clear())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	require.NoError(t, state.Clear(), "clearing twice is fine")
+}
+
+func TestRunState_ResumesPartiallyCompleteRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.jsonl")
+	tests := []Test{{TraceID: "trace-1"}, {TraceID: "trace-2"}, {TraceID: "trace-3"}}
+
+	// The first run completes trace-1 and is killed while writing trace-2
+	state, err := LoadRunState(path)
+	require.NoError(t, err)
+	require.NoError(t, state.Record(TestResult{TestID: "trace-1", Passed: true}))
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"test_id":"trace-2","pas`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	resumed, err := LoadRunState(path)
+	require.NoError(t, err)
+	pending, done := resumed.Resume(tests)
+	assert.Equal(t, []Test{{TraceID: "trace-2"}, {TraceID: "trace-3"}}, pending)
+	assert.Equal(t, []TestResult{{TestID: "trace-1", Passed: true}}, done)
+
+	// Results of tests outside this run, e.g. after changing --filter, are ignored
+	_, done = resumed.Resume(tests[1:])
+	assert.Empty(t, done)
+
+	// The resumed run records trace-2 and is interrupted again
+	require.NoError(t, resumed.Record(TestResult{TestID: "trace-2", Passed: true}))
+	resumed, err = LoadRunState(path)
+	require.NoError(t, err)
+	pending, _ = resumed.Resume(tests)
+	assert.Equal(t, []Test{{TraceID: "trace-3"}}, pending, "results recorded after a torn line must survive")
+
+	require.NoError(t, resumed.Record(TestResult{TestID: "trace-3", Passed: true}))
+	resumed, err = LoadRunState(path)
+	require.NoError(t, err)
+	pending, _ = resumed.Resume(tests)
+	assert.Empty(t, pending)
+
+	var ids []string
+	for _, r := range resumed.Results() {
+		ids = append(ids, r.TestID)
+	}
+	assert.Equal(t, []string{"trace-1", "trace-2", "trace-3"}, ids)
+}
@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Use-Tusk/tusk-cli/internal/utils"
+)
+
+// SchemaHashMismatch is a span whose stored inputSchemaHash differs from the
+// hash of its inputSchema.
+type SchemaHashMismatch struct {
+	FilePath     string `json:"filePath"`
+	Line         int    `json:"line"`
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	Name         string `json:"name,omitempty"`
+	StoredHash   string `json:"storedHash"`
+	ComputedHash string `json:"computedHash"`
+}
+
+// SchemaHashReport summarizes a ValidateSchemaHashes run.
+type SchemaHashReport struct {
+	Files      int                  `json:"files"`
+	Spans      int                  `json:"spans"`
+	Mismatches []SchemaHashMismatch `json:"mismatches"`
+}
+
+// ValidateSchemaHashes recomputes the inputSchemaHash of every span with an
+// inputSchema in the .jsonl files under tracesDir and reports the spans whose
+// stored hash is missing or differs. Schema-based matching relies on these
+// hashes, so a mismatch silently stops the span from matching by schema.
+// Files are only read; lines that are not valid JSON are skipped.
+func ValidateSchemaHashes(tracesDir string) (SchemaHashReport, error) {
+	report := SchemaHashReport{Mismatches: []SchemaHashMismatch{}}
+	err := filepath.WalkDir(tracesDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".jsonl") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path) // #nosec G304
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		report.Files++
+		for i, line := range strings.Split(string(data), "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			var span map[string]any
+			if err := json.Unmarshal([]byte(line), &span); err != nil {
+				continue
+			}
+			schema, ok := span["inputSchema"].(map[string]any)
+			if !ok {
+				continue
+			}
+			report.Spans++
+
+			stored, _ := span["inputSchemaHash"].(string)
+			computed := utils.GenerateDeterministicHash(schema)
+			if stored == computed {
+				continue
+			}
+			traceID, _ := span["traceId"].(string)
+			spanID, _ := span["spanId"].(string)
+			name, _ := span["name"].(string)
+			report.Mismatches = append(report.Mismatches, SchemaHashMismatch{
+				FilePath:     path,
+				Line:         i + 1,
+				TraceID:      traceID,
+				SpanID:       spanID,
+				Name:         name,
+				StoredHash:   stored,
+				ComputedHash: computed,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return SchemaHashReport{}, fmt.Errorf("traces folder not found: %s", tracesDir)
+		}
+		return SchemaHashReport{}, err
+	}
+
+	sort.SliceStable(report.Mismatches, func(i, j int) bool {
+		a, b := report.Mismatches[i], report.Mismatches[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.Line < b.Line
+	})
+	return report, nil
+}
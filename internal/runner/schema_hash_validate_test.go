@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Use-Tusk/tusk-cli/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSchemaHashes(t *testing.T) {
+	dir := t.TempDir()
+	schema := map[string]any{"properties": map[string]any{"query": map[string]any{"type": 6.0}}}
+	span := func(spanID, schemaHash string) map[string]any {
+		return map[string]any{
+			"traceId":         "trace-1",
+			"spanId":          spanID,
+			"name":            "pg.query",
+			"packageName":     "pg",
+			"inputSchema":     schema,
+			"inputSchemaHash": schemaHash,
+		}
+	}
+	path := writeTraceFile(t, dir, "trace-1.jsonl",
+		httpRootSpan("trace-1", "GET", "/users"),
+		span("consistent", utils.GenerateDeterministicHash(schema)),
+		span("inconsistent", "stale-hash"),
+	)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.jsonl"), []byte("{not json\n"), 0o600))
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	report, err := ValidateSchemaHashes(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Files)
+	assert.Equal(t, 2, report.Spans, "only spans with an input schema are checked")
+	assert.Equal(t, []SchemaHashMismatch{{
+		FilePath:     path,
+		Line:         3,
+		TraceID:      "trace-1",
+		SpanID:       "inconsistent",
+		Name:         "pg.query",
+		StoredHash:   "stale-hash",
+		ComputedHash: utils.GenerateDeterministicHash(schema),
+	}}, report.Mismatches)
+
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, before, after, "validation should not modify trace files")
+
+	_, err = ValidateSchemaHashes(filepath.Join(dir, "missing"))
+	require.ErrorContains(t, err, "traces folder not found")
+}
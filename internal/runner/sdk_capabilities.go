@@ -0,0 +1,62 @@
+package runner
+
+import (
+	"maps"
+	"math"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// sdkCapabilitiesMetadataKey is the ConnectRequest metadata key under which
+// the SDK lists what it supports. ConnectRequest has no dedicated field, so
+// capabilities travel in its free-form metadata.
+const sdkCapabilitiesMetadataKey = "capabilities"
+
+// SDKCapabilities is what the connected SDK reported it supports in the
+// connect handshake. The zero value means the SDK reported nothing, and
+// callers should keep the behavior they had before capabilities existed.
+type SDKCapabilities struct {
+	// Websocket is supports_websocket: the SDK can talk to the CLI over websocket
+	Websocket bool
+	// Compression is supports_compression: the SDK accepts compressed messages
+	Compression bool
+	// MaxMessageBytes is max_message_size: the largest message the SDK
+	// accepts, or 0 when unreported
+	MaxMessageBytes int
+	// Raw holds every reported capability, including ones this CLI doesn't
+	// know, so newer SDK features can be detected by name
+	Raw map[string]any
+}
+
+// parseSDKCapabilities reads the capabilities object from connect metadata.
+// Values of the wrong type are ignored.
+func parseSDKCapabilities(metadata *structpb.Struct) SDKCapabilities {
+	raw, _ := metadata.AsMap()[sdkCapabilitiesMetadataKey].(map[string]any)
+	if len(raw) == 0 {
+		return SDKCapabilities{}
+	}
+
+	caps := SDKCapabilities{Raw: raw}
+	caps.Websocket, _ = raw["supports_websocket"].(bool)
+	caps.Compression, _ = raw["supports_compression"].(bool)
+	if size, ok := raw["max_message_size"].(float64); ok && size > 0 && size <= math.MaxInt32 {
+		caps.MaxMessageBytes = int(size)
+	}
+	return caps
+}
+
+// Has reports whether the SDK reported capability name as true.
+func (c SDKCapabilities) Has(name string) bool {
+	v, _ := c.Raw[name].(bool)
+	return v
+}
+
+// GetSDKCapabilities returns the capabilities the connected SDK reported in
+// its connect request.
+func (ms *Server) GetSDKCapabilities() SDKCapabilities {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	caps := ms.sdkCapabilities
+	caps.Raw = maps.Clone(caps.Raw)
+	return caps
+}
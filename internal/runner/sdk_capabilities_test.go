@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+	"github.com/Use-Tusk/tusk-cli/internal/version"
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestParseSDKCapabilities(t *testing.T) {
+	assert.Equal(t, SDKCapabilities{}, parseSDKCapabilities(nil))
+	assert.Equal(t, SDKCapabilities{}, parseSDKCapabilities(toStruct(t, map[string]any{"env": "ci"})))
+
+	caps := parseSDKCapabilities(toStruct(t, map[string]any{
+		"capabilities": map[string]any{
+			"supports_websocket":   true,
+			"supports_compression": "yes",
+			"max_message_size":     float64(1 << 20),
+			"supports_streaming":   true,
+		},
+	}))
+	assert.True(t, caps.Websocket)
+	assert.False(t, caps.Compression, "non-boolean values are ignored")
+	assert.Equal(t, 1<<20, caps.MaxMessageBytes)
+	assert.True(t, caps.Has("supports_streaming"), "unknown capabilities are kept by name")
+	assert.False(t, caps.Has("supports_compression"))
+}
+
+func TestGetSDKCapabilities_StoredOnConnect(t *testing.T) {
+	config.Invalidate()
+	server, err := NewServer("test-capabilities", &config.ServiceConfig{
+		ID:            "test-capabilities",
+		Communication: config.CommunicationConfig{Type: "tcp", TCPPort: 0},
+	})
+	require.NoError(t, err)
+	defer func() { _ = server.Stop() }()
+	require.NoError(t, server.Start())
+	port := server.listener.Addr().(*net.TCPAddr).Port
+
+	assert.Equal(t, SDKCapabilities{}, server.GetSDKCapabilities(), "nothing before the SDK connects")
+
+	metadata, err := structpb.NewStruct(map[string]any{
+		"capabilities": map[string]any{"supports_compression": true, "max_message_size": 4096},
+	})
+	require.NoError(t, err)
+	conn, resp := sendSDKConnect(t, port, &core.ConnectRequest{ServiceId: "svc", SdkVersion: version.MinSDKVersion, Metadata: metadata})
+	defer func() { _ = conn.Close() }()
+	require.True(t, resp.GetSuccess())
+	require.NoError(t, server.WaitForSDKConnection(time.Second))
+
+	caps := server.GetSDKCapabilities()
+	assert.True(t, caps.Compression)
+	assert.False(t, caps.Websocket)
+	assert.Equal(t, 4096, caps.MaxMessageBytes)
+
+	caps.Raw["supports_websocket"] = true
+	assert.False(t, server.GetSDKCapabilities().Has("supports_websocket"), "callers get a copy")
+}
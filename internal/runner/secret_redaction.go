@@ -1,6 +1,12 @@
 package runner
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -64,3 +70,136 @@ func RedactSecrets(content string) string {
 
 	return redacted
 }
+
+// redactedFieldPlaceholder replaces the value of a field whose name is in the
+// redact list.
+const redactedFieldPlaceholder = "TUSK_REDACTED_FIELD"
+
+// defaultRedactFields are always redacted; logging.redact_fields adds to them.
+var defaultRedactFields = []string{"token", "authorization", "secret", "secretorpublickey"}
+
+// fieldRedactor redacts values of fields by name. Plain names match keys
+// exactly, ignoring case; any other entry is a regex matched against the
+// whole key, also ignoring case.
+type fieldRedactor struct {
+	exact    map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+// newFieldRedactor compiles the default field list plus extra.
+func newFieldRedactor(extra []string) (*fieldRedactor, error) {
+	r := &fieldRedactor{exact: make(map[string]struct{})}
+	for _, field := range append(slices.Clone(defaultRedactFields), extra...) {
+		if regexp.QuoteMeta(field) == field {
+			r.exact[strings.ToLower(field)] = struct{}{}
+			continue
+		}
+		re, err := regexp.Compile("(?i)^(?:" + field + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact field pattern %q: %w", field, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+func (r *fieldRedactor) matches(key string) bool {
+	if _, ok := r.exact[strings.ToLower(key)]; ok {
+		return true
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns a copy of v with the values of matching map keys replaced,
+// descending into nested maps and slices. v itself is not modified.
+func (r *fieldRedactor) Redact(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			if r.matches(k) {
+				out[k] = redactedFieldPlaceholder
+				continue
+			}
+			out[k] = r.Redact(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			out[i] = r.Redact(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// RedactPayload is Redact, but it also redacts JSON objects and arrays held in
+// string values, such as recorded bodies, which may be base64-encoded. Such a
+// string is re-encoded only when something in it was redacted.
+func (r *fieldRedactor) RedactPayload(v any) any {
+	switch val := v.(type) {
+	case string:
+		return r.redactEncodedJSON(val)
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			if r.matches(k) {
+				out[k] = redactedFieldPlaceholder
+				continue
+			}
+			out[k] = r.RedactPayload(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			out[i] = r.RedactPayload(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func (r *fieldRedactor) redactEncodedJSON(s string) string {
+	if redacted, ok := r.redactJSONString(s); ok {
+		return redacted
+	}
+	decoded, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return s
+	}
+	if redacted, ok := r.redactJSONString(string(decoded)); ok {
+		return base64.StdEncoding.EncodeToString([]byte(redacted))
+	}
+	return s
+}
+
+// redactJSONString reports whether s is a JSON object or array in which a
+// field was redacted, and if so returns it re-marshaled.
+func (r *fieldRedactor) redactJSONString(s string) (string, bool) {
+	trimmed := strings.TrimSpace(s)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return "", false
+	}
+	var parsed any
+	if err := json.Unmarshal([]byte(trimmed), &parsed); err != nil {
+		return "", false
+	}
+	redacted := r.RedactPayload(parsed)
+	if reflect.DeepEqual(parsed, redacted) {
+		return "", false
+	}
+	out, err := json.Marshal(redacted)
+	if err != nil {
+		return "", false
+	}
+	return string(out), true
+}
@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"encoding/base64"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -64,3 +65,56 @@ Status: 200 (OK)
 	assert.NotContains(t, result, testJWT)
 	assert.Contains(t, result, "TUSK_REDACTED_JWT")
 }
+
+func TestFieldRedactor_DefaultsAndPatterns(t *testing.T) {
+	r, err := newFieldRedactor([]string{".*password.*", "X-Api-Key"})
+	assert.NoError(t, err)
+
+	input := map[string]any{
+		"username":    "alice",
+		"db_password": "hunter2",
+		"x-api-key":   "abc",
+		"Token":       "t",
+		"nested": []any{
+			map[string]any{"secretOrPublicKey": "k", "id": float64(1)},
+		},
+	}
+	got := r.Redact(input).(map[string]any)
+
+	assert.Equal(t, "alice", got["username"])
+	assert.Equal(t, redactedFieldPlaceholder, got["db_password"])
+	assert.Equal(t, redactedFieldPlaceholder, got["x-api-key"])
+	assert.Equal(t, redactedFieldPlaceholder, got["Token"])
+	nested := got["nested"].([]any)[0].(map[string]any)
+	assert.Equal(t, redactedFieldPlaceholder, nested["secretOrPublicKey"])
+	assert.Equal(t, float64(1), nested["id"])
+
+	// The input is left untouched
+	assert.Equal(t, "hunter2", input["db_password"])
+}
+
+func TestFieldRedactor_InvalidPattern(t *testing.T) {
+	_, err := newFieldRedactor([]string{"(unclosed"})
+	assert.Error(t, err)
+}
+
+func TestFieldRedactor_RedactPayloadEncodedBodies(t *testing.T) {
+	r, err := newFieldRedactor(nil)
+	assert.NoError(t, err)
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(`{"token":"abc","user":{"id":1}}`))
+	untouched := base64.StdEncoding.EncodeToString([]byte(`{"id":1}`))
+	got := r.RedactPayload(map[string]any{
+		"body":      encoded,
+		"plainBody": `{"items":[{"secret":"s","name":"n"}]}`,
+		"other":     untouched,
+		"text":      "not json",
+	}).(map[string]any)
+
+	decoded, err := base64.StdEncoding.DecodeString(got["body"].(string))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"token":"TUSK_REDACTED_FIELD","user":{"id":1}}`, string(decoded))
+	assert.JSONEq(t, `{"items":[{"secret":"TUSK_REDACTED_FIELD","name":"n"}]}`, got["plainBody"].(string))
+	assert.Equal(t, untouched, got["other"], "bodies without redacted fields are left byte-for-byte")
+	assert.Equal(t, "not json", got["text"])
+}
@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/Use-Tusk/tusk-cli/internal/log"
+)
+
+// SelfCheckResult compares a trace's recorded response with a replay of the
+// trace and with the same request sent to the service live.
+type SelfCheckResult struct {
+	TraceID string `json:"trace_id"`
+	// Replay is the response with outbound calls mocked, diffed against the
+	// recorded response
+	Replay TestResult `json:"replay"`
+	// Live is the response with the SDK disabled, so outbound calls reach the
+	// service's real dependencies, diffed against the recorded response
+	Live TestResult `json:"live"`
+}
+
+// LiveOnlyFields returns the fields that deviate live but not in replay. With
+// mocks in place these fields reproduce, so the live differences come from
+// the service's dependencies or from the service itself (timestamps, random
+// IDs, changed data) rather than from the code under test.
+func (r *SelfCheckResult) LiveOnlyFields() []string {
+	replayed := make(map[string]struct{}, len(r.Replay.Deviations))
+	for _, d := range r.Replay.Deviations {
+		replayed[d.Field] = struct{}{}
+	}
+	var fields []string
+	for _, d := range r.Live.Deviations {
+		if _, ok := replayed[d.Field]; !ok {
+			fields = append(fields, d.Field)
+		}
+	}
+	return fields
+}
+
+// SelfCheck replays test with mocks, then restarts the service with the SDK
+// disabled and sends the recorded request again live. Both responses are
+// diffed against the recorded one. The executor is left with no environment
+// running.
+func (e *Executor) SelfCheck(test Test) (*SelfCheckResult, error) {
+	if len(test.Spans) == 0 {
+		spans, err := e.LoadSpansForTrace(test.TraceID, test.FileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load spans for trace %s: %w", test.TraceID, err)
+		}
+		// The live run has no mock server to load them, but decodes the
+		// request and response bodies with their schemas
+		test.Spans = spans
+	}
+	result := &SelfCheckResult{TraceID: test.TraceID}
+
+	if err := e.StartEnvironment(); err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	replay, err := e.RunSingleTest(test)
+	if err != nil {
+		log.Debug("Self-check replay request failed", "traceID", test.TraceID, "error", err)
+	}
+	result.Replay = replay
+	if err := e.StopEnvironment(); err != nil {
+		log.Debug("Failed to stop replay environment", "error", err)
+	}
+
+	// Live requests go straight to the service, without a mock server
+	server := e.server
+	e.server = nil
+	e.liveMode = true
+	defer func() {
+		e.server = server
+		e.liveMode = false
+	}()
+
+	log.ServiceLog("Starting service live (SDK disabled)...")
+	if err := e.StartService(); err != nil {
+		return nil, fmt.Errorf("live: %w", err)
+	}
+	live, err := e.runSingleTestOnce(test)
+	if err != nil {
+		log.Debug("Self-check live request failed", "traceID", test.TraceID, "error", err)
+	}
+	result.Live = live
+	if err := e.StopService(); err != nil {
+		log.Debug("Failed to stop live service", "error", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,21 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelfCheckResultLiveOnlyFields(t *testing.T) {
+	result := &SelfCheckResult{
+		Replay: TestResult{Deviations: []Deviation{{Field: "response.body.user.name"}}},
+		Live: TestResult{Deviations: []Deviation{
+			{Field: "response.body.user.name"},
+			{Field: "response.body.createdAt"},
+			{Field: "response.body.requestId"},
+		}},
+	}
+	assert.Equal(t, []string{"response.body.createdAt", "response.body.requestId"}, result.LiveOnlyFields())
+
+	assert.Empty(t, (&SelfCheckResult{}).LiveOnlyFields())
+}
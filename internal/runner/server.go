@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -11,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -25,7 +27,9 @@ import (
 	"github.com/Use-Tusk/tusk-cli/internal/utils"
 	"github.com/Use-Tusk/tusk-cli/internal/version"
 	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/bmatcuk/doublestar/v4"
 
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 )
@@ -33,17 +37,41 @@ import (
 type CommunicationType string
 
 const (
-	CommunicationUnix  CommunicationType = "unix"
-	CommunicationTCP   CommunicationType = "tcp"
-	unixSocketDirName  string            = ".tusk"
-	unixSocketName     string            = ".s"
-	fallbackSocketName string            = ".t.sock"
+	CommunicationUnix      CommunicationType = "unix"
+	CommunicationTCP       CommunicationType = "tcp"
+	CommunicationWebSocket CommunicationType = "websocket"
+	unixSocketDirName      string            = ".tusk"
+	unixSocketName         string            = ".s"
+	fallbackSocketName     string            = ".t.sock"
+)
+
+const (
+	// defaultMockSearchTimeout bounds a single mock search when
+	// test_execution.mock_search_timeout is not configured.
+	defaultMockSearchTimeout = 15 * time.Second
+	// defaultSimilarityScanLimit caps how many candidates are similarity-scored
+	// per match when replay.similarity_scan_limit is not configured.
+	defaultSimilarityScanLimit = 50
+	// defaultAmbiguityEpsilon is how close the runner-up similarity score must
+	// be to the best one for a match to be flagged as ambiguous when
+	// mock_matching.ambiguity_epsilon is not configured.
+	defaultAmbiguityEpsilon = 0.05
+	// defaultMaxMessageBytes caps a single SDK message when
+	// service.communication.max_message_bytes is not configured.
+	defaultMaxMessageBytes = 10 * 1024 * 1024
+	// oversizedMessagePrefixBytes is how much of an oversized message is
+	// buffered to recover its request ID; the rest is discarded unread.
+	oversizedMessagePrefixBytes = 4 * 1024
+	// SDKMessage field numbers read by parseMessageHeader
+	sdkMessageTypeField      protowire.Number = 1
+	sdkMessageRequestIDField protowire.Number = 2
 )
 
 // Server handles Unix socket communication with the SDK
 type Server struct {
-	socketPath string
-	listener   net.Listener
+	socketPath    string
+	instanceLabel string // keeps the socket apart from other servers running at once
+	listener      net.Listener
 
 	// Hashes for fast lookup
 	spans                         map[string][]*core.Span
@@ -72,21 +100,67 @@ type Server struct {
 	activeConnsMu          sync.Mutex
 	sdkVersion             string
 	sdkConnected           bool
-	sdkConnectedChan       chan struct{}
+	sdkConnectedChan       chan struct{} // replaced (under mu) when the SDK disconnects
+	sdkGeneration          uint64        // incremented on every accepted SDK connect
+	reconnectAfterGen      uint64        // WaitForSDKReconnection waits for a generation above this
 	sdkRuntime             core.Runtime
+	sdkCapabilities        SDKCapabilities // from the latest connect request's metadata
 	sdkConnection          net.Conn
 	pendingRequests        map[string]chan *core.SDKMessage
 	pendingMu              sync.Mutex
 	suiteSpans             []*core.Span
 	matchEvents            map[string][]MatchEvent
 	replayInbound          map[string]*core.Span
+	replayStarted          map[string]time.Time // traceId -> when the inbound request was sent, on the service's clock
 	mockNotFoundEvents     map[string][]MockNotFoundEvent
-	allowSuiteWideMatching bool // When true, allows cross-trace matching from any suite span
+	allowSuiteWideMatching bool         // When true, allows cross-trace matching from any suite span
+	mockSearchTimeout      atomic.Int64 // time.Duration; read on every mock request without taking mu
+	similarityScanLimit    atomic.Int64
+	quietMocks             atomic.Bool // --quiet-mocks; read on every mock request without taking mu
+	// packageName -> JSON paths treated as matchImportance 0. Set before spans
+	// are loaded and read-only afterwards, so reads don't take mu.
+	ignoreFields map[string][]string
+	// packageName -> JSON path of the primary key. Read-only like ignoreFields.
+	primaryKeys map[string]string
+	// Delay before found mocks are sent back, by package name
+	// (mock_matching.inject_latency)
+	injectLatency map[string]time.Duration
+	// HTTP query params whose values gate schema matching. Read-only like
+	// ignoreFields.
+	significantQueryParams []config.SignificantQueryParamsRule
+	pathTemplates          []string // mock_matching.path_templates
+	pins                   []config.MockPin
+	// When set, spans sharing an input value hash are handed out round-robin.
+	poolIdenticalSpans  bool
+	lenientSchema       bool                      // mock_matching.lenient_schema
+	valueHashPoolCursor map[string]map[string]int // traceId -> pool key -> next pool index
+	// Traces already warned about spans with an input value hash but no
+	// input value
+	warnedHashWithoutInput map[string]bool
+	// Seed for randomized matcher choices (--seed). Matching is currently
+	// deterministic, so it only needs to be threaded through.
+	seed int64
+	// Lowercased hosts that suite-wide and global fallback matches may come
+	// from. Empty allows any host.
+	globalFallbackHosts map[string]struct{}
+	ambiguityEpsilon    float64
+	similarityAlgorithm string // mock_matching.similarity_algorithm
+	// allowSDKVersionMismatch lets SDKs older than version.MinSDKVersion connect
+	allowSDKVersionMismatch bool
+	// Field names whose values are hidden when request payloads are logged
+	fieldRedactor *fieldRedactor
 
 	// For TCP communication (docker environments)
 	communicationType CommunicationType
 	tcpListener       net.Listener
 	tcpPort           int
+	maxMessageBytes   uint32
+
+	// For WebSocket communication (shares tcpPort)
+	wsURL string
+
+	// Counts and times mock requests; may be shared by an executor's servers
+	metrics atomic.Pointer[mockMetrics]
 
 	// Analytics
 	analyticsClient *analytics.Client
@@ -107,6 +181,9 @@ type MatchEvent struct {
 	InputData  map[string]any   `json:"inputData,omitempty"`
 	Timestamp  time.Time        `json:"timestamp"`
 	ReplaySpan *core.Span       `json:"replaySpan,omitempty"`
+	// Ambiguous is set when the runner-up candidate scored almost as well as
+	// the chosen span, so the match was close to a coin flip.
+	Ambiguous bool `json:"ambiguous,omitempty"`
 }
 
 type MockNotFoundEvent struct {
@@ -117,6 +194,12 @@ type MockNotFoundEvent struct {
 	Timestamp   time.Time  `json:"timestamp"`
 	Error       string     `json:"error"`      // Full error message
 	ReplaySpan  *core.Span `json:"replaySpan"` // The outbound span that failed to find a mock
+	// The recorded span of the same package most similar to the request, if
+	// any, and the input fields where it differs (see ClosestDescription)
+	ClosestSpanID   string  `json:"closestSpanId,omitempty"`
+	ClosestSpanName string  `json:"closestSpanName,omitempty"`
+	ClosestScore    float64 `json:"closestScore,omitempty"`
+	DiffSummary     string  `json:"diffSummary,omitempty"`
 }
 
 // serviceDelegatesToHostDaemon reports whether the configured service start
@@ -157,6 +240,9 @@ func determineCommunicationType(cfg *config.ServiceConfig) CommunicationType {
 	if commType == "tcp" {
 		return CommunicationTCP
 	}
+	if commType == "websocket" {
+		return CommunicationWebSocket
+	}
 	return CommunicationUnix
 }
 
@@ -180,6 +266,7 @@ func NewServer(serviceID string, cfg *config.ServiceConfig) (*Server, error) {
 		suiteSpansByReducedSchemaHash: make(map[string][]*core.Span),
 		globalSpansByValueHash:        make(map[string][]*core.Span),
 		globalSpansByReducedValueHash: make(map[string][]*core.Span),
+		valueHashPoolCursor:           make(map[string]map[string]int),
 
 		ctx:                ctx,
 		cancel:             cancel,
@@ -193,14 +280,27 @@ func NewServer(serviceID string, cfg *config.ServiceConfig) (*Server, error) {
 		pendingRequests:    make(map[string]chan *core.SDKMessage),
 		activeConns:        make(map[net.Conn]struct{}),
 	}
+	server.mockSearchTimeout.Store(int64(defaultMockSearchTimeout))
+	server.similarityScanLimit.Store(defaultSimilarityScanLimit)
+	server.ambiguityEpsilon = defaultAmbiguityEpsilon
+	server.fieldRedactor, _ = newFieldRedactor(nil)
+	server.metrics.Store(&mockMetrics{})
+
+	server.maxMessageBytes = defaultMaxMessageBytes
+	if limit := cfg.Communication.MaxMessageBytes; limit > 0 && limit <= math.MaxUint32 {
+		server.maxMessageBytes = uint32(limit) // #nosec G115 -- bounds checked above
+	}
 
 	return server, nil
 }
 
 // Start begins listening (Unix socket or TCP)
 func (ms *Server) Start() error {
-	if ms.communicationType == CommunicationTCP {
+	switch ms.communicationType {
+	case CommunicationTCP:
 		return ms.startTCP()
+	case CommunicationWebSocket:
+		return ms.startWebSocket()
 	}
 	return ms.startUnix()
 }
@@ -220,7 +320,7 @@ func (ms *Server) startUnix() error {
 	if err != nil {
 		return fmt.Errorf("failed to determine working directory for Unix socket: %w", err)
 	}
-	candidates := unixSocketCandidates(cwd)
+	candidates := unixSocketCandidates(cwd, ms.instanceLabel)
 
 	var listenErrs []string
 	for _, candidate := range candidates {
@@ -263,13 +363,19 @@ func (ms *Server) startUnix() error {
 	return nil
 }
 
-func unixSocketCandidates(cwd string) []string {
+// unixSocketCandidates lists socket paths to try in order. A non-empty label is
+// appended to every name so servers running side by side never share a socket.
+func unixSocketCandidates(cwd, label string) []string {
+	suffix := ""
+	if label != "" {
+		suffix = "-" + label
+	}
 	candidates := []string{
-		filepath.Join(cwd, unixSocketDirName, unixSocketName),
-		filepath.Join(cwd, fallbackSocketName),
+		filepath.Join(cwd, unixSocketDirName, unixSocketName+suffix),
+		filepath.Join(cwd, fallbackSocketName+suffix),
 	}
 
-	shortFallbackName := unixSocketShortFallbackName(cwd)
+	shortFallbackName := unixSocketShortFallbackName(cwd) + suffix
 	for dir := filepath.Dir(cwd); ; dir = filepath.Dir(dir) {
 		candidates = append(candidates, filepath.Join(dir, shortFallbackName))
 		parent := filepath.Dir(dir)
@@ -306,6 +412,8 @@ func (ms *Server) startTCP() error {
 		return fmt.Errorf("failed to create TCP listener: %w", err)
 	}
 
+	// Port 0 asks the OS for a free port; record the one actually bound
+	ms.tcpPort = listener.Addr().(*net.TCPAddr).Port
 	ms.tcpListener = listener
 	ms.listener = listener
 	log.Debug("Mock server started with TCP", "address", addr, "port", ms.tcpPort)
@@ -317,6 +425,28 @@ func (ms *Server) startTCP() error {
 	return nil
 }
 
+// startWebSocket listens for WebSocket upgrades on the TCP port. Upgraded
+// connections carry the same length-prefixed protobuf stream as Unix/TCP.
+func (ms *Server) startWebSocket() error {
+	addr := fmt.Sprintf("0.0.0.0:%d", ms.tcpPort)
+	tcpListener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to create WebSocket listener: %w", err)
+	}
+
+	port := tcpListener.Addr().(*net.TCPAddr).Port
+	ms.tcpListener = tcpListener
+	ms.listener = newWebSocketListener(tcpListener, ms.maxMessageBytes)
+	ms.wsURL = fmt.Sprintf("ws://localhost:%d/", port)
+	log.Debug("Mock server started with WebSocket", "address", addr, "url", ms.wsURL)
+
+	ms.wg.Add(1)
+	go ms.acceptConnections()
+	time.Sleep(100 * time.Millisecond)
+
+	return nil
+}
+
 // Stop shuts down the mock server
 func (ms *Server) Stop() error {
 	ms.cancel()
@@ -350,9 +480,30 @@ func (ms *Server) Stop() error {
 	return nil
 }
 
+// SetInstanceLabel distinguishes this server's Unix socket from those of other
+// servers started from the same directory. Must be called before Start.
+func (ms *Server) SetInstanceLabel(label string) {
+	ms.instanceLabel = label
+}
+
+// SetTCPPort overrides the configured TCP/WebSocket port; 0 lets the OS pick a
+// free one. Must be called before Start.
+func (ms *Server) SetTCPPort(port int) {
+	ms.tcpPort = port
+}
+
+// GetConnectionInfo returns the socket path (Unix), the port (TCP), or the
+// WebSocket URL and port (WebSocket).
 func (ms *Server) GetConnectionInfo() (string, int) {
-	if ms.communicationType == CommunicationTCP {
+	switch ms.communicationType {
+	case CommunicationTCP:
 		return "", ms.tcpPort
+	case CommunicationWebSocket:
+		port := ms.tcpPort
+		if ms.tcpListener != nil {
+			port = ms.tcpListener.Addr().(*net.TCPAddr).Port
+		}
+		return ms.wsURL, port
 	}
 	return ms.socketPath, 0
 }
@@ -375,20 +526,78 @@ func (ms *Server) SetCurrentTestID(id string) {
 	ms.currentTestID.Store(id)
 }
 
+// errSDKAcknowledgementTimeout is returned by WaitForSDKConnection when no SDK
+// connected in time, as opposed to the server shutting down.
+var errSDKAcknowledgementTimeout = errors.New("timeout waiting for SDK acknowledgement")
+
 func (ms *Server) WaitForSDKConnection(timeout time.Duration) error {
 	log.Debug("Waiting for SDK to connect and acknowledge...", "timeout", timeout)
 
+	ms.mu.RLock()
+	connectedChan := ms.sdkConnectedChan
+	ms.mu.RUnlock()
+
 	select {
-	case <-ms.sdkConnectedChan:
+	case <-connectedChan:
 		log.Debug("SDK connection acknowledged")
 		return nil
 	case <-time.After(timeout):
-		return fmt.Errorf("timeout waiting for SDK acknowledgement after %v", timeout)
+		return fmt.Errorf("%w after %v", errSDKAcknowledgementTimeout, timeout)
 	case <-ms.ctx.Done():
 		return fmt.Errorf("server context cancelled while waiting for SDK acknowledgement")
 	}
 }
 
+// ResetSDKConnection forgets the current SDK connection so that
+// WaitForSDKReconnection only returns once a newer connect arrives. It is called
+// when the SDK's connection closes and before restarting the service.
+func (ms *Server) ResetSDKConnection() {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.resetSDKConnectionLocked()
+}
+
+func (ms *Server) resetSDKConnectionLocked() {
+	ms.reconnectAfterGen = ms.sdkGeneration
+	ms.sdkConnection = nil
+	if ms.sdkConnected {
+		ms.sdkConnected = false
+		ms.sdkConnectedChan = make(chan struct{})
+	}
+}
+
+// WaitForSDKReconnection blocks until an SDK connects after the last
+// ResetSDKConnection (or SDK disconnect). Unlike WaitForSDKConnection, it does
+// not return early because of a connection from the previous service process.
+func (ms *Server) WaitForSDKReconnection(timeout time.Duration) error {
+	log.Debug("Waiting for SDK to reconnect...", "timeout", timeout)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		ms.mu.RLock()
+		reconnected := ms.sdkConnected && ms.sdkGeneration > ms.reconnectAfterGen
+		connectedChan := ms.sdkConnectedChan
+		generation := ms.sdkGeneration
+		ms.mu.RUnlock()
+
+		if reconnected {
+			log.Debug("SDK reconnected", "generation", generation)
+			return nil
+		}
+
+		select {
+		case <-connectedChan:
+			// Re-check: the connect may belong to an older generation
+		case <-timer.C:
+			return fmt.Errorf("timeout waiting for SDK to reconnect after %v", timeout)
+		case <-ms.ctx.Done():
+			return fmt.Errorf("server context cancelled while waiting for SDK to reconnect")
+		}
+	}
+}
+
 // LoadSpansForTrace loads all spans for matching
 func (ms *Server) LoadSpansForTrace(traceID string, spans []*core.Span) {
 	ms.mu.Lock()
@@ -406,24 +615,36 @@ func (ms *Server) LoadSpansForTrace(traceID string, spans []*core.Span) {
 
 	ms.spans[traceID] = spans
 	ms.matchEvents[traceID] = nil
+	delete(ms.replayInbound, traceID)
+	delete(ms.replayStarted, traceID)
+	delete(ms.mockNotFoundEvents, traceID)
+	delete(ms.valueHashPoolCursor, traceID)
 
 	// Build package name index
 	ms.spansByPackage[traceID] = make(map[string][]*core.Span)
 	ms.spansByReducedValueHash[traceID] = make(map[string][]*core.Span)
 	ms.spansByValueHash[traceID] = make(map[string][]*core.Span)
 
+	var hashedWithoutInput []string
 	for _, span := range spans {
 		// Package index
 		pkgName := span.PackageName
 		ms.spansByPackage[traceID][pkgName] = append(ms.spansByPackage[traceID][pkgName], span)
 
+		// A hash without the value it was computed from is a malformed
+		// recording: hash matching still finds the span, but similarity
+		// scoring compares against nothing
+		if span.InputValueHash != "" && span.InputValue == nil {
+			hashedWithoutInput = append(hashedWithoutInput, span.SpanId)
+		}
+
 		// Value hash index (already computed by SDK)
 		if span.InputValueHash != "" {
 			ms.spansByValueHash[traceID][span.InputValueHash] = append(ms.spansByValueHash[traceID][span.InputValueHash], span)
 		}
 
 		// Reduced value hash index (compute once here)
-		reducedHash := reducedInputValueHash(span)
+		reducedHash := reducedInputValueHash(span, ms.ignoredFieldsFor(span.PackageName))
 		if reducedHash != "" {
 			ms.spansByReducedValueHash[traceID][reducedHash] = append(ms.spansByReducedValueHash[traceID][reducedHash], span)
 		}
@@ -432,16 +653,7 @@ func (ms *Server) LoadSpansForTrace(traceID string, spans []*core.Span) {
 	// Sort all indexed spans by timestamp (oldest first)
 	sortSpansByTimestamp := func(spans []*core.Span) {
 		sort.Slice(spans, func(i, j int) bool {
-			if spans[i].Timestamp == nil && spans[j].Timestamp == nil {
-				return spans[i].SpanId < spans[j].SpanId
-			}
-			if spans[i].Timestamp == nil {
-				return true
-			}
-			if spans[j].Timestamp == nil {
-				return false
-			}
-			return spans[i].Timestamp.AsTime().Before(spans[j].Timestamp.AsTime())
+			return spanRecordedBefore(spans[i], spans[j])
 		})
 	}
 
@@ -457,6 +669,15 @@ func (ms *Server) LoadSpansForTrace(traceID string, spans []*core.Span) {
 		sortSpansByTimestamp(ms.spansByReducedValueHash[traceID][hash])
 	}
 
+	if len(hashedWithoutInput) > 0 && !ms.warnedHashWithoutInput[traceID] {
+		if ms.warnedHashWithoutInput == nil {
+			ms.warnedHashWithoutInput = make(map[string]bool)
+		}
+		ms.warnedHashWithoutInput[traceID] = true
+		log.Warn("Spans have an input value hash but no input value; the recording may be malformed and they can only match by hash",
+			"traceID", traceID, "spanIDs", hashedWithoutInput)
+	}
+
 	log.Debug("Loaded spans for trace", "traceID", traceID, "count", len(spans))
 }
 
@@ -483,7 +704,7 @@ func (ms *Server) SetSuiteSpans(spans []*core.Span) {
 		}
 
 		// Reduced value hash index (compute once here)
-		reducedHash := reducedInputValueHash(span)
+		reducedHash := reducedInputValueHash(span, ms.ignoredFieldsFor(span.PackageName))
 		if reducedHash != "" {
 			ms.suiteSpansByReducedValueHash[reducedHash] = append(ms.suiteSpansByReducedValueHash[reducedHash], span)
 		}
@@ -507,6 +728,412 @@ func (ms *Server) GetSuiteSpans() []*core.Span {
 	return ms.suiteSpans
 }
 
+// SetMockSearchTimeout sets how long a mock search may run before the SDK
+// receives a not-found response. Non-positive values restore the default.
+func (ms *Server) SetMockSearchTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultMockSearchTimeout
+	}
+	ms.mockSearchTimeout.Store(int64(timeout))
+}
+
+func (ms *Server) GetMockSearchTimeout() time.Duration {
+	return time.Duration(ms.mockSearchTimeout.Load())
+}
+
+// SetSimilarityScanLimit sets the maximum number of candidate spans scored by
+// similarity for a single match. Non-positive values restore the default.
+func (ms *Server) SetSimilarityScanLimit(limit int) {
+	if limit <= 0 {
+		limit = defaultSimilarityScanLimit
+	}
+	ms.similarityScanLimit.Store(int64(limit))
+}
+
+func (ms *Server) GetSimilarityScanLimit() int {
+	return int(ms.similarityScanLimit.Load())
+}
+
+// SetIgnoreFields configures per-package JSON paths that are ignored when
+// matching (mock_matching.ignore_fields). Must be called before spans are loaded.
+func (ms *Server) SetIgnoreFields(ignoreFields map[string][]string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.ignoreFields = ignoreFields
+}
+
+func (ms *Server) ignoredFieldsFor(packageName string) []string {
+	if ms == nil {
+		return nil
+	}
+	return ms.ignoreFields[packageName]
+}
+
+// SetPrimaryKeys configures per-package JSON paths of keys that spans are
+// matched on first (mock_matching.primary_keys). Must be called before spans
+// are loaded.
+func (ms *Server) SetPrimaryKeys(primaryKeys map[string]string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.primaryKeys = primaryKeys
+}
+
+func (ms *Server) primaryKeyFor(packageName string) string {
+	if ms == nil {
+		return ""
+	}
+	return ms.primaryKeys[packageName]
+}
+
+// SetSignificantQueryParams configures the HTTP query params whose values must
+// match for schema-based matching (mock_matching.significant_query_params).
+// Must be called before spans are loaded.
+func (ms *Server) SetSignificantQueryParams(rules []config.SignificantQueryParamsRule) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.significantQueryParams = rules
+}
+
+// significantQueryParamsFor returns the significant query params of every
+// rule whose path glob matches path.
+func (ms *Server) significantQueryParamsFor(path string) []string {
+	if ms == nil {
+		return nil
+	}
+	var params []string
+	for _, rule := range ms.significantQueryParams {
+		if rule.Path != "" {
+			if matched, _ := doublestar.Match(rule.Path, path); !matched {
+				continue
+			}
+		}
+		params = append(params, rule.Params...)
+	}
+	return params
+}
+
+// SetPathTemplates configures the HTTP routes under which outbound call paths
+// compare equal (mock_matching.path_templates). Must be called before spans
+// are loaded.
+func (ms *Server) SetPathTemplates(templates []string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.pathTemplates = templates
+}
+
+// samePathTemplate reports whether a and b both fall under the first path
+// template either of them matches, e.g. /users/123 and /users/456 under
+// /users/:id.
+func (ms *Server) samePathTemplate(a, b string) bool {
+	if ms == nil {
+		return false
+	}
+	for _, template := range ms.pathTemplates {
+		matchA, matchB := pathMatchesTemplate(template, a), pathMatchesTemplate(template, b)
+		if matchA || matchB {
+			return matchA && matchB
+		}
+	}
+	return false
+}
+
+// pathMatchesTemplate reports whether path has the segments of template, where
+// a ":name" segment stands for any non-empty segment.
+func pathMatchesTemplate(template, path string) bool {
+	templateSegments := strings.Split(strings.TrimSuffix(template, "/"), "/")
+	pathSegments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	if len(templateSegments) != len(pathSegments) {
+		return false
+	}
+	for i, segment := range templateSegments {
+		if strings.HasPrefix(segment, ":") {
+			if pathSegments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// SetInjectLatency configures per-package delays applied to found mocks
+// before they are sent back (mock_matching.inject_latency).
+func (ms *Server) SetInjectLatency(latency map[string]time.Duration) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.injectLatency = latency
+}
+
+func (ms *Server) injectedLatency(packageName string) time.Duration {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return ms.injectLatency[packageName]
+}
+
+// SetPins configures the spans forced for matching outbound calls
+// (mock_matching.pins).
+func (ms *Server) SetPins(pins []config.MockPin) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.pins = pins
+}
+
+// pinnedSpanIDFor returns the span ID of the first pin matching the package,
+// method and path of an outbound call, or "" when none does.
+func (ms *Server) pinnedSpanIDFor(packageName, method, path string) string {
+	if ms == nil {
+		return ""
+	}
+	for _, pin := range ms.pins {
+		if pin.Package != packageName {
+			continue
+		}
+		if pin.Method != "" && !strings.EqualFold(pin.Method, method) {
+			continue
+		}
+		if pin.Path != "" {
+			if matched, _ := doublestar.Match(pin.Path, path); !matched {
+				continue
+			}
+		}
+		return pin.SpanID
+	}
+	return ""
+}
+
+// SetPoolIdenticalSpans enables round-robin selection among spans with the same
+// input value hash (mock_matching.pool_identical_spans). This helps when
+// identical queries run on connections opened in a nondeterministic order.
+func (ms *Server) SetPoolIdenticalSpans(enabled bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.poolIdenticalSpans = enabled
+}
+
+func (ms *Server) PoolIdenticalSpans() bool {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return ms.poolIdenticalSpans
+}
+
+// SetLenientSchema lets schema-based matching fall back to spans whose input
+// schema differs from the request's only in low-importance fields
+// (mock_matching.lenient_schema).
+func (ms *Server) SetLenientSchema(enabled bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.lenientSchema = enabled
+}
+
+func (ms *Server) LenientSchema() bool {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return ms.lenientSchema
+}
+
+// SetSeed sets the seed for randomized matcher choices. None are randomized
+// yet: similarity ties go to the oldest span, then the smallest SpanId, and
+// identical-span pools are handed out in load order.
+func (ms *Server) SetSeed(seed int64) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.seed = seed
+}
+
+func (ms *Server) Seed() int64 {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return ms.seed
+}
+
+// GetMetrics returns a snapshot of the mock request counters.
+func (ms *Server) GetMetrics() MockMetrics {
+	return ms.metrics.Load().snapshot()
+}
+
+// shareMetrics makes the server count into m, so the servers of environment
+// groups replayed in parallel add up to one set of metrics.
+func (ms *Server) shareMetrics(m *mockMetrics) {
+	ms.metrics.Store(m)
+}
+
+// SetGlobalFallbackHosts restricts suite-wide and global fallback matching of
+// HTTP spans to the given hosts (mock_matching.global_fallback_hosts).
+func (ms *Server) SetGlobalFallbackHosts(hosts []string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if len(hosts) == 0 {
+		ms.globalFallbackHosts = nil
+		return
+	}
+	ms.globalFallbackHosts = make(map[string]struct{}, len(hosts))
+	for _, host := range hosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			ms.globalFallbackHosts[host] = struct{}{}
+		}
+	}
+}
+
+// globalFallbackHostAllowed reports whether a fallback candidate recorded
+// against host may be used. Everything is allowed when no hosts are configured.
+func (ms *Server) globalFallbackHostAllowed(host string) bool {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	if len(ms.globalFallbackHosts) == 0 {
+		return true
+	}
+	_, ok := ms.globalFallbackHosts[strings.ToLower(host)]
+	return ok
+}
+
+func (ms *Server) hasGlobalFallbackHosts() bool {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return len(ms.globalFallbackHosts) > 0
+}
+
+// SetAmbiguityEpsilon sets how close the two best similarity scores may be
+// before a match is flagged as ambiguous (mock_matching.ambiguity_epsilon).
+// Zero disables the check.
+func (ms *Server) SetAmbiguityEpsilon(epsilon float64) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.ambiguityEpsilon = epsilon
+}
+
+func (ms *Server) AmbiguityEpsilon() float64 {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return ms.ambiguityEpsilon
+}
+
+// SetSimilarityAlgorithm selects how strings are compared when ranking spans
+// by similarity (mock_matching.similarity_algorithm): "levenshtein" (the
+// default) or "token".
+func (ms *Server) SetSimilarityAlgorithm(algorithm string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.similarityAlgorithm = algorithm
+}
+
+func (ms *Server) SimilarityAlgorithm() string {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return ms.similarityAlgorithm
+}
+
+// SetAllowSDKVersionMismatch lets an SDK older than version.MinSDKVersion
+// connect with a warning instead of being refused
+// (replay.allow_sdk_version_mismatch, --allow-sdk-version-mismatch).
+func (ms *Server) SetAllowSDKVersionMismatch(allow bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.allowSDKVersionMismatch = allow
+}
+
+// SetQuietMocks stops per-request mock matching messages ("Finding best
+// match for request", "Found best match") from being logged to the test
+// (--quiet-mocks). Mock-not-found and ambiguous-match messages are kept.
+func (ms *Server) SetQuietMocks(quiet bool) {
+	ms.quietMocks.Store(quiet)
+}
+
+// SetRedactFields adds field names or regexes (logging.redact_fields) to the
+// fields redacted from logged request payloads.
+func (ms *Server) SetRedactFields(fields []string) error {
+	r, err := newFieldRedactor(fields)
+	if err != nil {
+		return err
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.fieldRedactor = r
+	return nil
+}
+
+// redactFields returns a copy of v with the values of sensitive fields hidden.
+func (ms *Server) redactFields(v any) any {
+	ms.mu.RLock()
+	r := ms.fieldRedactor
+	ms.mu.RUnlock()
+	return r.Redact(v)
+}
+
+// SetReplayStart records when the executor sent traceID's inbound request, on
+// the clock the service's SDK stamps outbound spans with. Mock requests are
+// placed on the recorded timeline relative to it, since the replayed inbound
+// span only arrives once the response is complete.
+func (ms *Server) SetReplayStart(traceID string, t time.Time) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.replayStarted == nil {
+		ms.replayStarted = make(map[string]time.Time)
+	}
+	ms.replayStarted[traceID] = t
+}
+
+func (ms *Server) getReplayStart(traceID string) time.Time {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return ms.replayStarted[traceID]
+}
+
+// nextPooledSpan picks a span from pool, a set of interchangeable spans in
+// traceID identified by poolKey. Unused spans are preferred. When target (the
+// request's position on the recorded timeline) is known, the pick is the span
+// recorded nearest to it, so calls replayed in a different order than recorded
+// still get the matching recording. Otherwise picks rotate through the pool,
+// starting where the previous pick left off. The returned span is marked as
+// used, and wasUnused reports its prior state.
+func (ms *Server) nextPooledSpan(traceID, poolKey string, pool []*core.Span, target time.Time) (span *core.Span, wasUnused bool) {
+	if len(pool) == 0 {
+		return nil, false
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if ms.valueHashPoolCursor[traceID] == nil {
+		ms.valueHashPoolCursor[traceID] = make(map[string]int)
+	}
+	if ms.spanUsage[traceID] == nil {
+		ms.spanUsage[traceID] = make(map[string]bool)
+	}
+	used := ms.spanUsage[traceID]
+
+	if !target.IsZero() {
+		ordered := sortByTimestampProximity(pool, target)
+		span = ordered[0]
+		for _, candidate := range ordered {
+			if !used[candidate.SpanId] {
+				span = candidate
+				wasUnused = true
+				break
+			}
+		}
+		used[span.SpanId] = true
+		return span, wasUnused
+	}
+
+	start := ms.valueHashPoolCursor[traceID][poolKey] % len(pool)
+	idx := start
+	for i := 0; i < len(pool); i++ {
+		candidate := (start + i) % len(pool)
+		if !used[pool[candidate].SpanId] {
+			idx = candidate
+			wasUnused = true
+			break
+		}
+	}
+
+	ms.valueHashPoolCursor[traceID][poolKey] = idx + 1
+	used[pool[idx].SpanId] = true
+	return pool[idx], wasUnused
+}
+
 func (ms *Server) SetAllowSuiteWideMatching(enabled bool) {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
@@ -536,7 +1163,7 @@ func (ms *Server) SetGlobalSpans(spans []*core.Span) {
 		}
 
 		// Reduced value hash index
-		reducedHash := reducedInputValueHash(span)
+		reducedHash := reducedInputValueHash(span, ms.ignoredFieldsFor(span.PackageName))
 		if reducedHash != "" {
 			ms.globalSpansByReducedValueHash[reducedHash] = append(ms.globalSpansByReducedValueHash[reducedHash], span)
 		}
@@ -606,12 +1233,63 @@ func (ms *Server) GetSuiteSpansBySchemaHash(schemaHash string) []*core.Span {
 	return ms.suiteSpansBySchemaHash[schemaHash]
 }
 
+// spanRecordedBefore orders spans oldest first, with nil timestamps first.
+// Spans recorded at the same instant (common at millisecond resolution) are
+// ordered by span ID so mock selection doesn't depend on sort stability.
+func spanRecordedBefore(a, b *core.Span) bool {
+	switch {
+	case a.Timestamp == nil && b.Timestamp == nil:
+		return a.SpanId < b.SpanId
+	case a.Timestamp == nil:
+		return true
+	case b.Timestamp == nil:
+		return false
+	}
+	ta, tb := a.Timestamp.AsTime(), b.Timestamp.AsTime()
+	if !ta.Equal(tb) {
+		return ta.Before(tb)
+	}
+	return a.SpanId < b.SpanId
+}
+
 func (ms *Server) GetSuiteSpansByReducedSchemaHash(reducedSchemaHash string) []*core.Span {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 	return ms.suiteSpansByReducedSchemaHash[reducedSchemaHash]
 }
 
+// ResetSpanUsage marks every loaded span unused again, so a re-run of the same
+// traces matches mocks as if from a fresh start.
+func (ms *Server) ResetSpanUsage() {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for _, usage := range ms.spanUsage {
+		for spanID := range usage {
+			usage[spanID] = false
+		}
+	}
+	clear(ms.valueHashPoolCursor)
+}
+
+// GetUnusedSpans returns the recorded outbound spans of a trace that no
+// outbound call was matched to, in load order. Root spans are the replayed
+// requests themselves and are never included.
+func (ms *Server) GetUnusedSpans(traceID string) []*core.Span {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	usage := ms.spanUsage[traceID]
+	var unused []*core.Span
+	for _, span := range ms.spans[traceID] {
+		if span.IsRootSpan || usage[span.SpanId] {
+			continue
+		}
+		unused = append(unused, span)
+	}
+	return unused
+}
+
 func (ms *Server) CleanupTraceSpans(traceID string) {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
@@ -623,6 +1301,7 @@ func (ms *Server) CleanupTraceSpans(traceID string) {
 	delete(ms.spansByPackage, traceID)
 	delete(ms.spansByValueHash, traceID)
 	delete(ms.spansByReducedValueHash, traceID)
+	delete(ms.valueHashPoolCursor, traceID)
 
 	log.Debug("Cleaned up spans for trace", "traceID", traceID)
 }
@@ -664,6 +1343,13 @@ func (ms *Server) handleConnection(conn net.Conn) {
 		delete(ms.activeConns, conn)
 		ms.activeConnsMu.Unlock()
 		_ = conn.Close()
+
+		ms.mu.Lock()
+		if ms.sdkConnection == conn {
+			log.Debug("SDK disconnected", "generation", ms.sdkGeneration)
+			ms.resetSDKConnectionLocked()
+		}
+		ms.mu.Unlock()
 	}()
 
 	for {
@@ -687,13 +1373,20 @@ func (ms *Server) handleConnection(conn net.Conn) {
 
 		// Parse message length
 		messageLength := binary.BigEndian.Uint32(lengthBytes)
-		if messageLength > 10*1024*1024 { // 10MB limit
-			log.Warn("Message too large, skipping", "length", messageLength)
-			discardBuf := make([]byte, messageLength)
-			if _, err := io.ReadFull(conn, discardBuf); err != nil {
+		if messageLength > ms.maxMessageBytes {
+			log.Warn("Message too large, skipping", "length", messageLength, "limit", ms.maxMessageBytes)
+			// Only the leading fields are needed to answer the request, so
+			// buffer a bounded prefix and stream the rest to io.Discard.
+			prefix := make([]byte, min(messageLength, oversizedMessagePrefixBytes))
+			if _, err := io.ReadFull(conn, prefix); err != nil {
 				log.Error("Failed to discard oversized message", "error", err)
 				return
 			}
+			if _, err := io.CopyN(io.Discard, conn, int64(messageLength)-int64(len(prefix))); err != nil {
+				log.Error("Failed to discard oversized message", "error", err)
+				return
+			}
+			ms.rejectOversizedMessage(prefix, messageLength, conn)
 			continue // Skip this message but keep connection alive
 		}
 
@@ -738,6 +1431,73 @@ func (ms *Server) handleConnection(conn net.Conn) {
 	}
 }
 
+// rejectOversizedMessage answers an oversized mock request with an error so
+// the SDK fails fast instead of waiting for a response that never comes.
+// prefix holds the first bytes of a message of messageLength bytes.
+func (ms *Server) rejectOversizedMessage(prefix []byte, messageLength uint32, conn net.Conn) {
+	msgType, requestID := parseMessageHeader(prefix)
+	if msgType != core.MessageType_MESSAGE_TYPE_MOCK_REQUEST || requestID == "" {
+		log.Debug("Oversized message is not an answerable mock request", "type", msgType)
+		return
+	}
+
+	cliMsg := &core.CLIMessage{
+		Type:      core.MessageType_MESSAGE_TYPE_MOCK_REQUEST,
+		RequestId: requestID,
+		Payload: &core.CLIMessage_GetMockResponse{
+			GetMockResponse: &core.GetMockResponse{
+				RequestId: requestID,
+				Found:     false,
+				Error: fmt.Sprintf(
+					"mock request of %d bytes exceeds the %d byte limit (increase service.communication.max_message_bytes in .tusk/config.yaml)",
+					messageLength, ms.maxMessageBytes,
+				),
+			},
+		},
+	}
+	if err := ms.sendProtobufResponse(conn, cliMsg); err != nil {
+		log.Debug("Failed to send oversized message response", "error", err)
+	}
+}
+
+// parseMessageHeader extracts the type and request ID of an SDKMessage from a
+// possibly truncated encoding. Both are leading scalar fields, so they are
+// normally present in any prefix that covers them.
+func parseMessageHeader(data []byte) (core.MessageType, string) {
+	var (
+		msgType   core.MessageType
+		requestID string
+	)
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			break
+		}
+		data = data[n:]
+		switch {
+		case num == sdkMessageTypeField && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(data)
+			if n >= 0 {
+				msgType = core.MessageType(v) // #nosec G115 -- enum values fit in int32
+			}
+		case num == sdkMessageRequestIDField && typ == protowire.BytesType:
+			var v []byte
+			v, n = protowire.ConsumeBytes(data)
+			if n >= 0 {
+				requestID = string(v)
+			}
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			break // truncated field
+		}
+		data = data[n:]
+	}
+	return msgType, requestID
+}
+
 // Helper function to send protobuf response
 func (ms *Server) sendProtobufResponse(conn net.Conn, msg proto.Message) error {
 	data, err := proto.Marshal(msg)
@@ -884,7 +1644,16 @@ func (ms *Server) handleSDKConnectProtobuf(msg *core.SDKMessage, conn net.Conn)
 	}
 
 	// Check if SDK version meets CLI's minimum requirement
-	if connectReq.SdkVersion != "" && !isVersionCompatible(connectReq.SdkVersion, version.MinSDKVersion) {
+	// unless mismatches are allowed, in which case it only warns
+	sdkTooOld := connectReq.SdkVersion != "" && !isVersionCompatible(connectReq.SdkVersion, version.MinSDKVersion)
+	ms.mu.RLock()
+	allowSDKVersionMismatch := ms.allowSDKVersionMismatch
+	ms.mu.RUnlock()
+	if sdkTooOld && allowSDKVersionMismatch {
+		warning := fmt.Sprintf("⚠️  SDK version %s is below the minimum %s required by CLI %s; continuing because SDK version mismatches are allowed", connectReq.SdkVersion, version.MinSDKVersion, cliVersion)
+		log.ServiceLog(warning)
+		log.Stderrln(warning)
+	} else if sdkTooOld {
 		log.ServiceLog(fmt.Sprintf("SDK version %s is incompatible. CLI (%s) requires SDK version %s or higher", connectReq.SdkVersion, cliVersion, version.MinSDKVersion))
 
 		response := &core.CLIMessage{
@@ -914,11 +1683,17 @@ func (ms *Server) handleSDKConnectProtobuf(msg *core.SDKMessage, conn net.Conn)
 	log.ServiceLog(fmt.Sprintf("  - SDK version: %s", connectReq.SdkVersion))
 	log.ServiceLog(fmt.Sprintf("  - CLI version: %s", cliVersion))
 	log.ServiceLog(fmt.Sprintf("  - Min CLI version: %s", connectReq.MinCliVersion))
+	capabilities := parseSDKCapabilities(connectReq.Metadata)
+	if len(capabilities.Raw) > 0 {
+		log.ServiceLog(fmt.Sprintf("  - Capabilities: %v", capabilities.Raw))
+	}
 
 	ms.mu.Lock()
 	ms.sdkVersion = connectReq.SdkVersion
 	ms.sdkRuntime = connectReq.Runtime
+	ms.sdkCapabilities = capabilities
 	ms.sdkConnection = conn
+	ms.sdkGeneration++
 	if !ms.sdkConnected {
 		ms.sdkConnected = true
 		close(ms.sdkConnectedChan)
@@ -1111,9 +1886,20 @@ func (ms *Server) handleMockRequestProtobuf(msg *core.SDKMessage, conn net.Conn)
 		return
 	}
 
-	response := ms.findMock(mockReq)
+	response := ms.findMockWithTimeout(mockReq)
 	response.RequestId = msg.RequestId
 
+	if response.Found {
+		if delay := ms.injectedLatency(mockReq.GetOutboundSpan().GetPackageName()); delay > 0 {
+			// Simulate a slow upstream
+			select {
+			case <-time.After(delay):
+			case <-ms.ctx.Done():
+				return
+			}
+		}
+	}
+
 	cliMsg := &core.CLIMessage{
 		Type:      core.MessageType_MESSAGE_TYPE_MOCK_REQUEST,
 		RequestId: msg.RequestId,
@@ -1207,8 +1993,81 @@ func (ms *Server) handleUnpatchedDependencyAlert(alert *core.UnpatchedDependency
 	}
 }
 
-// findMock searches for a matching mock for the given request
-func (ms *Server) findMock(req *core.GetMockRequest) *core.GetMockResponse {
+// mockSearch guards the side effects of a single mock search (marking spans
+// used, recording match and mock-not-found events). A search abandoned by
+// findMockWithTimeout must not change server state, since the SDK has already
+// been told no mock was found.
+type mockSearch struct {
+	mu        sync.Mutex
+	abandoned bool
+	committed bool
+}
+
+// commit reports whether the search may still change server state. Once it
+// returns true, the search can no longer be abandoned.
+func (s *mockSearch) commit() bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.abandoned {
+		return false
+	}
+	s.committed = true
+	return true
+}
+
+// abandon stops the search from changing server state. It returns false if the
+// search has already committed to a result, in which case the caller should
+// wait for it.
+func (s *mockSearch) abandon() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.committed {
+		return false
+	}
+	s.abandoned = true
+	return true
+}
+
+// findMockWithTimeout runs findMock, returning a not-found response if the
+// search exceeds the configured mock search timeout.
+func (ms *Server) findMockWithTimeout(req *core.GetMockRequest) *core.GetMockResponse {
+	start := time.Now()
+	response := ms.searchMockWithTimeout(req)
+	ms.metrics.Load().record(time.Since(start), response.Found)
+	return response
+}
+
+func (ms *Server) searchMockWithTimeout(req *core.GetMockRequest) *core.GetMockResponse {
+	timeout := ms.GetMockSearchTimeout()
+	search := &mockSearch{}
+
+	resultCh := make(chan *core.GetMockResponse, 1)
+	go func() {
+		resultCh <- ms.findMock(req, search)
+	}()
+
+	select {
+	case response := <-resultCh:
+		return response
+	case <-time.After(timeout):
+		if !search.abandon() {
+			// A match was already claimed; its result is moments away
+			return <-resultCh
+		}
+		log.Warn("Mock search timed out", "testID", req.TestId, "timeout", timeout)
+		return &core.GetMockResponse{
+			Found: false,
+			Error: fmt.Sprintf("mock search timed out after %s (increase test_execution.mock_search_timeout in .tusk/config.yaml)", timeout),
+		}
+	}
+}
+
+// findMock searches for a matching mock for the given request. Server state is
+// only changed while search allows it (see mockSearch).
+func (ms *Server) findMock(req *core.GetMockRequest, search *mockSearch) *core.GetMockResponse {
 	testID := req.TestId
 	if testID == "" {
 		if stored := ms.currentTestID.Load(); stored != nil {
@@ -1216,7 +2075,7 @@ func (ms *Server) findMock(req *core.GetMockRequest) *core.GetMockResponse {
 		}
 	}
 
-	matcher := NewMockMatcher(ms)
+	matcher := &MockMatcher{server: ms, search: search}
 	var span *core.Span
 	var matchLevel *core.MatchLevel
 	var err error
@@ -1277,10 +2136,10 @@ func (ms *Server) findMock(req *core.GetMockRequest) *core.GetMockResponse {
 			"operation", req.Operation,
 			"error", err)
 
-		if testID != "" {
+		if testID != "" && search.commit() {
 			log.TestLog(testID, "🔴 No mock found for request\n")
 			// Record that a mock was not found for this test
-			ms.recordMockNotFoundEvent(testID, MockNotFoundEvent{
+			event := MockNotFoundEvent{
 				PackageName: req.OutboundSpan.PackageName,
 				SpanName:    req.OutboundSpan.Name,
 				Operation:   req.Operation,
@@ -1288,7 +2147,14 @@ func (ms *Server) findMock(req *core.GetMockRequest) *core.GetMockResponse {
 				Timestamp:   time.Now(),
 				Error:       fmt.Sprintf("no mock found for %s %s: %v", req.Operation, req.OutboundSpan.Name, err),
 				ReplaySpan:  req.OutboundSpan,
-			})
+			}
+			if closest, score := matcher.closestSpan(req, testID); closest != nil {
+				event.ClosestSpanID = closest.SpanId
+				event.ClosestSpanName = closest.Name
+				event.ClosestScore = score
+				event.DiffSummary = summarizeInputDiff(req.OutboundSpan.GetInputValue().AsMap(), closest.GetInputValue().AsMap())
+			}
+			ms.recordMockNotFoundEvent(testID, event)
 		}
 
 		return &core.GetMockResponse{
@@ -1300,11 +2166,11 @@ func (ms *Server) findMock(req *core.GetMockRequest) *core.GetMockResponse {
 	// Log based on actual match scope from MatchLevel
 	switch matchLevel.MatchScope {
 	case core.MatchScope_MATCH_SCOPE_TRACE:
-		if testID != "" {
+		if testID != "" && !ms.quietMocks.Load() {
 			log.TestLog(testID, "🟢 Found best match for request in trace\n")
 		}
 	case core.MatchScope_MATCH_SCOPE_GLOBAL:
-		if testID != "" {
+		if testID != "" && !ms.quietMocks.Load() {
 			msg := "🟢 Found best match for request across traces\n"
 			if span.IsPreAppStart {
 				msg = "🟢 Found best match for request across traces (pre-app-start)\n"
@@ -1313,6 +2179,11 @@ func (ms *Server) findMock(req *core.GetMockRequest) *core.GetMockResponse {
 		}
 	}
 
+	ambiguous := isAmbiguousMatch(matchLevel, ms.AmbiguityEpsilon())
+	if ambiguous && testID != "" {
+		log.TestLog(testID, fmt.Sprintf("⚠ ambiguous match: %s\n", matchLevel.MatchDescription))
+	}
+
 	// Record match event metadata for results output
 	var inputMap map[string]any
 	if req.OutboundSpan.InputValue != nil {
@@ -1322,6 +2193,9 @@ func (ms *Server) findMock(req *core.GetMockRequest) *core.GetMockResponse {
 	if span.Timestamp != nil {
 		timestamp = span.Timestamp.AsTime()
 	}
+	if !search.commit() {
+		return &core.GetMockResponse{Found: false, Error: "mock search abandoned"}
+	}
 	ms.recordMatchEvent(testID, MatchEvent{
 		SpanID:     span.SpanId,
 		MatchLevel: matchLevel,
@@ -1329,6 +2203,7 @@ func (ms *Server) findMock(req *core.GetMockRequest) *core.GetMockResponse {
 		InputData:  inputMap,
 		Timestamp:  timestamp,
 		ReplaySpan: req.OutboundSpan,
+		Ambiguous:  ambiguous,
 	})
 
 	// Convert span to mock response
@@ -1450,8 +2325,17 @@ func (ms *Server) spanToMockInteraction(span *core.Span) api.MockInteraction {
 	}
 }
 
-// loadSpansForTraceID attempts to load spans for a given trace ID from disk
+// loadSpansForTraceID attempts to load spans for a given trace ID from disk.
+// A recording can split a trace across several files, so spans are merged
+// from every matching file.
 func (ms *Server) loadSpansForTraceID(traceID string) error {
+	filter := func(span *core.Span) bool {
+		return span.TraceId == traceID
+	}
+
+	var spans []*core.Span
+	var files []string
+	seen := make(map[string]struct{})
 	// Scan for trace files that contain this trace ID
 	for _, dir := range utils.GetPossibleTraceDirs() {
 		matches, err := filepath.Glob(filepath.Join(dir, "*trace*"+traceID+"*.jsonl"))
@@ -1460,24 +2344,34 @@ func (ms *Server) loadSpansForTraceID(traceID string) error {
 		}
 
 		for _, traceFile := range matches {
-			filter := func(span *core.Span) bool {
-				return span.TraceId == traceID
-			}
-			spans, err := utils.ParseSpansFromFile(traceFile, filter)
+			fileSpans, err := utils.ParseSpansFromFile(traceFile, filter)
 			if err != nil {
 				log.Warn("Failed to load spans from file", "file", traceFile, "error", err)
 				continue
 			}
 
-			if len(spans) > 0 {
-				ms.LoadSpansForTrace(traceID, spans)
-				log.Info("Successfully loaded spans for trace", "traceID", traceID, "count", len(spans), "file", traceFile)
-				return nil
+			added := 0
+			for _, span := range fileSpans {
+				// The same file can be reached through more than one traces directory
+				if _, ok := seen[span.SpanId]; ok {
+					continue
+				}
+				seen[span.SpanId] = struct{}{}
+				spans = append(spans, span)
+				added++
+			}
+			if added > 0 {
+				files = append(files, traceFile)
 			}
 		}
 	}
 
-	return fmt.Errorf("no trace file found for trace ID %s", traceID)
+	if len(spans) == 0 {
+		return fmt.Errorf("no trace file found for trace ID %s", traceID)
+	}
+	ms.LoadSpansForTrace(traceID, spans)
+	log.Info("Successfully loaded spans for trace", "traceID", traceID, "count", len(spans), "files", files)
+	return nil
 }
 
 func (ms *Server) recordMatchEvent(traceID string, ev MatchEvent) {
@@ -1501,6 +2395,45 @@ func (ms *Server) GetInboundReplaySpan(traceID string) *core.Span {
 	return ms.replayInbound[traceID]
 }
 
+// traceReplayState is what the SDK reported for one replay of a trace.
+type traceReplayState struct {
+	matchEvents        []MatchEvent
+	inbound            *core.Span
+	mockNotFoundEvents []MockNotFoundEvent
+}
+
+func (ms *Server) snapshotTraceReplayState(traceID string) traceReplayState {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return traceReplayState{
+		matchEvents:        slices.Clone(ms.matchEvents[traceID]),
+		inbound:            ms.replayInbound[traceID],
+		mockNotFoundEvents: slices.Clone(ms.mockNotFoundEvents[traceID]),
+	}
+}
+
+// restoreTraceReplayState replaces the trace's replay state, so results are
+// built from an earlier run rather than the most recent one.
+func (ms *Server) restoreTraceReplayState(traceID string, state traceReplayState) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.matchEvents[traceID] = state.matchEvents
+	delete(ms.replayInbound, traceID)
+	delete(ms.mockNotFoundEvents, traceID)
+	if state.inbound != nil {
+		if ms.replayInbound == nil {
+			ms.replayInbound = make(map[string]*core.Span)
+		}
+		ms.replayInbound[traceID] = state.inbound
+	}
+	if len(state.mockNotFoundEvents) > 0 {
+		if ms.mockNotFoundEvents == nil {
+			ms.mockNotFoundEvents = make(map[string][]MockNotFoundEvent)
+		}
+		ms.mockNotFoundEvents[traceID] = state.mockNotFoundEvents
+	}
+}
+
 func (ms *Server) recordMockNotFoundEvent(traceID string, ev MockNotFoundEvent) {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
@@ -1526,14 +2459,22 @@ func (ms *Server) HasMockNotFoundEvents(traceID string) bool {
 }
 
 func (ms *Server) GetRootSpanID(traceID string) string {
+	if root := ms.GetRootSpan(traceID); root != nil {
+		return root.SpanId
+	}
+	return ""
+}
+
+// GetRootSpan returns the recorded root (inbound) span for a trace, or nil.
+func (ms *Server) GetRootSpan(traceID string) *core.Span {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 	for _, s := range ms.spans[traceID] {
 		if s.IsRootSpan {
-			return s.SpanId
+			return s
 		}
 	}
-	return ""
+	return nil
 }
 
 func (ms *Server) WaitForInboundSpan(traceID string, timeout time.Duration) {
@@ -1,18 +1,28 @@
 package runner
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
+	"io"
+	"log/slog"
 	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/Use-Tusk/tusk-cli/internal/config"
+	"github.com/Use-Tusk/tusk-cli/internal/log"
+	"github.com/Use-Tusk/tusk-cli/internal/utils"
+	"github.com/Use-Tusk/tusk-cli/internal/version"
 	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -229,7 +239,7 @@ func TestServerUnixMode_FallsBackForLongPaths(t *testing.T) {
 	assert.Equal(t, 0, tcpPort, "Unix mode should have zero TCP port")
 	assert.NotEqual(t, filepath.Join(workingDir, unixSocketDirName, unixSocketName), socketPath)
 	assert.NotEqual(t, filepath.Join(workingDir, fallbackSocketName), socketPath)
-	assert.Contains(t, unixSocketCandidates(workingDir), socketPath)
+	assert.Contains(t, unixSocketCandidates(workingDir, ""), socketPath)
 	assert.True(t, strings.HasPrefix(filepath.Base(socketPath), ".t-"), "expected fallback to use the short ancestor socket name: %s", socketPath)
 	assert.Less(t, len(socketPath), len(filepath.Join(workingDir, fallbackSocketName)), "expected fallback to shorten the socket path: %s", socketPath)
 }
@@ -443,6 +453,56 @@ func TestRecordMatchEventReturnsCopy(t *testing.T) {
 	assert.Equal(t, "span-1", fresh[0].SpanID)
 }
 
+func TestFindMockFlagsAmbiguousSimilarityMatch(t *testing.T) {
+	cfg, _ := config.Get()
+	schema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{"command": {}, "key": {}},
+	}
+
+	findEvent := func(t *testing.T, epsilon float64) MatchEvent {
+		t.Helper()
+		server, err := NewServer("svc", &cfg.Service)
+		require.NoError(t, err)
+		server.SetAmbiguityEpsilon(epsilon)
+
+		traceID := "trace-ambiguous"
+		// Neither recorded key equals the replayed one, and both are equally close
+		server.LoadSpansForTrace(traceID, []*core.Span{
+			makeSpan(t, traceID, "user-10", "redis", map[string]any{"command": "GET", "key": "user:10"}, schema, 100),
+			makeSpan(t, traceID, "user-11", "redis", map[string]any{"command": "GET", "key": "user:11"}, schema, 200),
+		})
+
+		req := makeMockRequest(t, "redis", map[string]any{"command": "GET", "key": "user:12"}, schema)
+		req.TestId = traceID
+		resp := server.findMockWithTimeout(req)
+		require.True(t, resp.Found, resp.Error)
+
+		events := server.GetMatchEvents(traceID)
+		require.Len(t, events, 1)
+		require.NotNil(t, events[0].MatchLevel.SimilarityScore)
+		return events[0]
+	}
+
+	assert.True(t, findEvent(t, defaultAmbiguityEpsilon).Ambiguous)
+	assert.False(t, findEvent(t, 0).Ambiguous, "an epsilon of 0 disables the check")
+}
+
+func TestIsAmbiguousMatch(t *testing.T) {
+	level := func(best float32, next ...float32) *core.MatchLevel {
+		l := &core.MatchLevel{SimilarityScore: &best}
+		for _, score := range next {
+			l.TopCandidates = append(l.TopCandidates, &core.SimilarityCandidate{Score: score})
+		}
+		return l
+	}
+
+	assert.True(t, isAmbiguousMatch(level(0.98, 0.97), 0.05))
+	assert.False(t, isAmbiguousMatch(level(0.98, 0.5), 0.05))
+	assert.False(t, isAmbiguousMatch(level(0.98), 0.05), "no runner-up")
+	assert.False(t, isAmbiguousMatch(&core.MatchLevel{}, 0.05), "not a similarity match")
+	assert.False(t, isAmbiguousMatch(nil, 0.05))
+}
+
 func TestWaitForSpanDataReturnsOnceDataAvailable(t *testing.T) {
 	cfg, _ := config.Get()
 	server, err := NewServer("svc", &cfg.Service)
@@ -592,3 +652,603 @@ func TestMockNotFoundEvents(t *testing.T) {
 	// Different trace should have no events
 	assert.False(t, server.HasMockNotFoundEvents("other-trace"))
 }
+
+func TestFindMockWithTimeout_ReturnsNotFoundOnTimeout(t *testing.T) {
+	server, err := NewServer("test-mock-timeout", &config.ServiceConfig{ID: "test-mock-timeout"})
+	require.NoError(t, err)
+	assert.Equal(t, defaultMockSearchTimeout, server.GetMockSearchTimeout())
+
+	server.SetMockSearchTimeout(time.Millisecond)
+
+	// Hold the write lock so the search blocks until the timeout fires.
+	server.mu.Lock()
+	done := make(chan *core.GetMockResponse, 1)
+	go func() {
+		done <- server.findMockWithTimeout(&core.GetMockRequest{
+			TestId:       "trace-1",
+			OutboundSpan: &core.Span{PackageName: "http"},
+		})
+	}()
+
+	var resp *core.GetMockResponse
+	select {
+	case resp = <-done:
+	case <-time.After(5 * time.Second):
+		server.mu.Unlock()
+		t.Fatal("findMockWithTimeout did not return after timeout")
+	}
+	server.mu.Unlock()
+
+	require.NotNil(t, resp)
+	assert.False(t, resp.Found)
+	assert.Contains(t, resp.Error, "timed out after 1ms")
+	assert.Contains(t, resp.Error, "mock_search_timeout")
+}
+
+func TestFindMock_AbandonedSearchLeavesNoSideEffects(t *testing.T) {
+	server, err := NewServer("test-mock-abandoned", &config.ServiceConfig{ID: "test-mock-abandoned"})
+	require.NoError(t, err)
+
+	input := map[string]any{"method": "GET", "url": "http://api.example.com/users"}
+	span := makeSpan(t, "trace-1", "s1", "http", input, nil, 1000)
+	server.LoadSpansForTrace("trace-1", []*core.Span{span})
+	req := makeMockRequest(t, "http", input, nil)
+	req.TestId = "trace-1"
+
+	// The timeout fired before the search claimed a match
+	search := &mockSearch{}
+	require.True(t, search.abandon())
+
+	resp := server.findMock(req, search)
+	assert.False(t, resp.Found)
+	assert.True(t, NewMockMatcher(server).isUnused(span), "abandoned search must not mark spans used")
+	assert.Empty(t, server.GetMatchEvents("trace-1"))
+	assert.False(t, server.HasMockNotFoundEvents("trace-1"))
+
+	// Once a search commits, it can no longer be abandoned
+	committed := &mockSearch{}
+	resp = server.findMock(req, committed)
+	assert.True(t, resp.Found)
+	assert.False(t, committed.abandon())
+	assert.Len(t, server.GetMatchEvents("trace-1"), 1)
+}
+
+func TestResetSpanUsage(t *testing.T) {
+	server, err := NewServer("test-reset-usage", &config.ServiceConfig{ID: "test-reset-usage"})
+	require.NoError(t, err)
+
+	input := map[string]any{"method": "GET", "url": "http://api.example.com/users"}
+	span := makeSpan(t, "trace-1", "s1", "http", input, nil, 1000)
+	server.LoadSpansForTrace("trace-1", []*core.Span{span})
+	req := makeMockRequest(t, "http", input, nil)
+	req.TestId = "trace-1"
+
+	mm := NewMockMatcher(server)
+	require.True(t, server.findMock(req, nil).Found)
+	assert.False(t, mm.isUnused(span))
+
+	server.ResetSpanUsage()
+	assert.True(t, mm.isUnused(span), "a re-run should see the span unused")
+}
+
+func TestGetUnusedSpans(t *testing.T) {
+	server, err := NewServer("test-unused-spans", &config.ServiceConfig{ID: "test-unused-spans"})
+	require.NoError(t, err)
+
+	root := makeSpan(t, "trace-1", "root", "http", map[string]any{"method": "GET", "url": "/orders"}, nil, 0)
+	root.IsRootSpan = true
+	users := map[string]any{"method": "GET", "url": "http://api.example.com/users"}
+	used := makeSpan(t, "trace-1", "used", "http", users, nil, 1000)
+	unused := makeSpan(t, "trace-1", "unused", "pg", map[string]any{"query": "SELECT 1"}, nil, 2000)
+	unused.Name = "pg.query"
+	server.LoadSpansForTrace("trace-1", []*core.Span{root, used, unused})
+
+	spanIDs := func(spans []*core.Span) []string {
+		ids := make([]string, 0, len(spans))
+		for _, s := range spans {
+			ids = append(ids, s.SpanId)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"used", "unused"}, spanIDs(server.GetUnusedSpans("trace-1")), "root spans are never reported")
+
+	req := makeMockRequest(t, "http", users, nil)
+	req.TestId = "trace-1"
+	require.True(t, server.findMock(req, nil).Found)
+	assert.Equal(t, []string{"unused"}, spanIDs(server.GetUnusedSpans("trace-1")))
+	assert.Empty(t, server.GetUnusedSpans("other-trace"))
+
+	report := NewUnusedSpansReport()
+	report.Record("trace-1", server.GetUnusedSpans("trace-1"))
+	report.Record("trace-2", nil)
+	spans, traces := report.Counts()
+	assert.Equal(t, 1, spans)
+	assert.Equal(t, 1, traces)
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteText(&out))
+	assert.Equal(t, "trace-1 (1 unused)\n  - [pg] pg.query  span=unused\n", out.String())
+}
+
+func TestLoadSpansForTrace_WarnsOnceAboutHashWithoutInputValue(t *testing.T) {
+	var logs bytes.Buffer
+	origLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(origLogger) })
+
+	server, err := NewServer("test-hash-without-input", &config.ServiceConfig{ID: "test-hash-without-input"})
+	require.NoError(t, err)
+
+	input := map[string]any{"query": "SELECT 1"}
+	wellFormed := makeSpan(t, "trace-1", "well-formed", "pg", input, nil, 1000)
+	malformed := makeSpan(t, "trace-1", "malformed", "pg", input, nil, 2000)
+	malformed.InputValue = nil
+	require.NotEmpty(t, malformed.InputValueHash)
+
+	server.LoadSpansForTrace("trace-1", []*core.Span{wellFormed, malformed})
+	server.LoadSpansForTrace("trace-1", []*core.Span{wellFormed, malformed})
+	assert.Equal(t, 1, strings.Count(logs.String(), "input value hash but no input value"), "warned once per trace")
+	assert.Contains(t, logs.String(), "traceID=trace-1")
+	assert.Contains(t, logs.String(), "spanIDs=[malformed]")
+
+	logs.Reset()
+	server.LoadSpansForTrace("trace-2", []*core.Span{makeSpan(t, "trace-2", "ok", "pg", input, nil, 1000)})
+	assert.NotContains(t, logs.String(), "input value hash but no input value")
+
+	// The span still matches by hash
+	req := makeMockRequest(t, "pg", input, nil)
+	req.TestId = "trace-1"
+	resp := server.findMock(req, nil)
+	require.True(t, resp.Found)
+}
+
+func TestLoadSpansForTraceID_MergesSplitTraceFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTraceFile(t, dir, "trace_abc_part1.jsonl",
+		map[string]any{"traceId": "abc", "spanId": "root", "isRootSpan": true},
+		map[string]any{"traceId": "abc", "spanId": "pg-1", "packageName": "pg"},
+	)
+	writeTraceFile(t, dir, "trace_abc_part2.jsonl",
+		map[string]any{"traceId": "abc", "spanId": "pg-2", "packageName": "pg"},
+		map[string]any{"traceId": "other", "spanId": "other-1"},
+	)
+	utils.SetTracesDirOverride(dir)
+	t.Cleanup(func() { utils.SetTracesDirOverride("") })
+
+	server, err := NewServer("test-split-trace", &config.ServiceConfig{ID: "test-split-trace"})
+	require.NoError(t, err)
+	require.NoError(t, server.loadSpansForTraceID("abc"))
+
+	var spanIDs []string
+	for _, s := range server.spans["abc"] {
+		spanIDs = append(spanIDs, s.SpanId)
+	}
+	assert.ElementsMatch(t, []string{"root", "pg-1", "pg-2"}, spanIDs)
+
+	assert.Error(t, server.loadSpansForTraceID("missing"))
+}
+
+func TestSetMockSearchTimeout_NonPositiveRestoresDefault(t *testing.T) {
+	server, err := NewServer("test-mock-timeout-default", &config.ServiceConfig{ID: "test-mock-timeout-default"})
+	require.NoError(t, err)
+
+	server.SetMockSearchTimeout(3 * time.Second)
+	assert.Equal(t, 3*time.Second, server.GetMockSearchTimeout())
+
+	server.SetMockSearchTimeout(0)
+	assert.Equal(t, defaultMockSearchTimeout, server.GetMockSearchTimeout())
+}
+
+func TestHandleConnection_OversizedMockRequestReturnsError(t *testing.T) {
+	config.Invalidate()
+
+	server, err := NewServer("test-oversized", &config.ServiceConfig{
+		ID: "test-oversized",
+		Communication: config.CommunicationConfig{
+			Type:    "tcp",
+			TCPPort: 0, // let OS pick a port
+		},
+	})
+	require.NoError(t, err)
+	defer func() { _ = server.Stop() }()
+	require.NoError(t, server.Start())
+
+	actualPort := server.listener.Addr().(*net.TCPAddr).Port
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", actualPort))
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(10*time.Second)))
+
+	// ~11MB mock request, over the 10MB default limit
+	data, err := proto.Marshal(&core.SDKMessage{
+		Type:      core.MessageType_MESSAGE_TYPE_MOCK_REQUEST,
+		RequestId: "req-big",
+		Payload: &core.SDKMessage_GetMockRequest{
+			GetMockRequest: &core.GetMockRequest{
+				RequestId:    "req-big",
+				OutboundSpan: &core.Span{PackageName: "http", Name: strings.Repeat("x", 11*1024*1024)},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Greater(t, len(data), defaultMaxMessageBytes)
+
+	_, err = conn.Write(binary.BigEndian.AppendUint32(nil, uint32(len(data)))) // #nosec G115
+	require.NoError(t, err)
+	_, err = conn.Write(data)
+	require.NoError(t, err)
+
+	lengthBytes := make([]byte, 4)
+	_, err = io.ReadFull(conn, lengthBytes)
+	require.NoError(t, err, "expected an error response rather than silent discard")
+	respData := make([]byte, binary.BigEndian.Uint32(lengthBytes))
+	_, err = io.ReadFull(conn, respData)
+	require.NoError(t, err)
+
+	var cliMsg core.CLIMessage
+	require.NoError(t, proto.Unmarshal(respData, &cliMsg))
+	assert.Equal(t, "req-big", cliMsg.RequestId)
+	resp := cliMsg.GetGetMockResponse()
+	require.NotNil(t, resp)
+	assert.False(t, resp.Found)
+	assert.Contains(t, resp.Error, fmt.Sprintf("%d bytes", len(data)))
+	assert.Contains(t, resp.Error, "max_message_bytes")
+}
+
+func TestParseMessageHeader_TruncatedPrefix(t *testing.T) {
+	data, err := proto.Marshal(&core.SDKMessage{
+		Type:      core.MessageType_MESSAGE_TYPE_MOCK_REQUEST,
+		RequestId: "req-prefix",
+		Payload: &core.SDKMessage_GetMockRequest{
+			GetMockRequest: &core.GetMockRequest{
+				RequestId:    "req-prefix",
+				OutboundSpan: &core.Span{Name: strings.Repeat("x", 2*oversizedMessagePrefixBytes)},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Greater(t, len(data), oversizedMessagePrefixBytes)
+
+	msgType, requestID := parseMessageHeader(data[:oversizedMessagePrefixBytes])
+	assert.Equal(t, core.MessageType_MESSAGE_TYPE_MOCK_REQUEST, msgType)
+	assert.Equal(t, "req-prefix", requestID)
+
+	// A prefix that cuts the request ID short yields no ID
+	msgType, requestID = parseMessageHeader(data[:4])
+	assert.Equal(t, core.MessageType_MESSAGE_TYPE_MOCK_REQUEST, msgType)
+	assert.Empty(t, requestID)
+}
+
+func TestNewServer_MaxMessageBytes(t *testing.T) {
+	server, err := NewServer("test-max-default", &config.ServiceConfig{ID: "test-max-default"})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(defaultMaxMessageBytes), server.maxMessageBytes)
+
+	server, err = NewServer("test-max-custom", &config.ServiceConfig{
+		ID:            "test-max-custom",
+		Communication: config.CommunicationConfig{MaxMessageBytes: 1024},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1024), server.maxMessageBytes)
+}
+
+func TestHandleMockRequestInjectsLatencyForPackage(t *testing.T) {
+	cfg, _ := config.Get()
+	server, err := NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	defer func() { _ = server.Stop() }()
+
+	const latency = 300 * time.Millisecond
+	server.SetInjectLatency(map[string]time.Duration{"pg": latency})
+
+	traceID := "trace-latency"
+	input := map[string]any{"query": "SELECT 1"}
+	server.LoadSpansForTrace(traceID, []*core.Span{
+		makeSpan(t, traceID, "pg-span", "pg", input, nil, 1000),
+		makeSpan(t, traceID, "http-span", "http", input, nil, 2000),
+	})
+
+	requestMock := func(pkg string) (*core.GetMockResponse, time.Duration) {
+		t.Helper()
+		req := makeMockRequest(t, pkg, input, nil)
+		req.TestId = traceID
+		clientConn, serverConn := net.Pipe()
+		defer func() { _ = clientConn.Close() }()
+		defer func() { _ = serverConn.Close() }()
+
+		start := time.Now()
+		go server.handleMockRequestProtobuf(&core.SDKMessage{
+			Type:      core.MessageType_MESSAGE_TYPE_MOCK_REQUEST,
+			RequestId: "req-" + pkg,
+			Payload:   &core.SDKMessage_GetMockRequest{GetMockRequest: req},
+		}, serverConn)
+
+		require.NoError(t, clientConn.SetDeadline(time.Now().Add(5*time.Second)))
+		lengthBytes := make([]byte, 4)
+		_, err := io.ReadFull(clientConn, lengthBytes)
+		require.NoError(t, err)
+		elapsed := time.Since(start)
+		respData := make([]byte, binary.BigEndian.Uint32(lengthBytes))
+		_, err = io.ReadFull(clientConn, respData)
+		require.NoError(t, err)
+
+		var cliMsg core.CLIMessage
+		require.NoError(t, proto.Unmarshal(respData, &cliMsg))
+		return cliMsg.GetGetMockResponse(), elapsed
+	}
+
+	resp, elapsed := requestMock("pg")
+	require.True(t, resp.GetFound())
+	assert.GreaterOrEqual(t, elapsed, latency)
+	assert.Less(t, elapsed, latency+time.Second)
+
+	resp, elapsed = requestMock("http")
+	require.True(t, resp.GetFound())
+	assert.Less(t, elapsed, latency, "other packages are not delayed")
+}
+
+// connectSDK dials the server and completes the SDK connect handshake.
+func connectSDK(t *testing.T, port int) net.Conn {
+	t.Helper()
+	conn, resp := sendSDKConnect(t, port, &core.ConnectRequest{ServiceId: "svc", SdkVersion: version.MinSDKVersion})
+	require.True(t, resp.GetSuccess())
+	return conn
+}
+
+// sendSDKConnect dials the server and sends connectReq, returning the
+// connection and the CLI's response.
+func sendSDKConnect(t *testing.T, port int, connectReq *core.ConnectRequest) (net.Conn, *core.ConnectResponse) {
+	t.Helper()
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err)
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	data, err := proto.Marshal(&core.SDKMessage{
+		Type:      core.MessageType_MESSAGE_TYPE_SDK_CONNECT,
+		RequestId: "connect",
+		Payload: &core.SDKMessage_ConnectRequest{
+			ConnectRequest: connectReq,
+		},
+	})
+	require.NoError(t, err)
+	_, err = conn.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(data))), data...)) // #nosec G115
+	require.NoError(t, err)
+
+	lengthBytes := make([]byte, 4)
+	_, err = io.ReadFull(conn, lengthBytes)
+	require.NoError(t, err)
+	respData := make([]byte, binary.BigEndian.Uint32(lengthBytes))
+	_, err = io.ReadFull(conn, respData)
+	require.NoError(t, err)
+
+	var cliMsg core.CLIMessage
+	require.NoError(t, proto.Unmarshal(respData, &cliMsg))
+	return conn, cliMsg.GetConnectResponse()
+}
+
+// logRecorder captures service and test log messages in place of the TUI.
+type logRecorder struct {
+	mu       sync.Mutex
+	messages []string
+	// Only test messages for this test are kept, so log messages still
+	// queued by earlier tests don't leak in
+	testID string
+}
+
+func (r *logRecorder) LogToCurrentTest(testID, message string) {
+	if testID != r.testID {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, message)
+}
+
+func (r *logRecorder) LogToService(message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, message)
+}
+
+func (r *logRecorder) count(substr string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, m := range r.messages {
+		if strings.Contains(m, substr) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestQuietMocksKeepsMockNotFoundLogs(t *testing.T) {
+	users := map[string]any{"method": "GET", "url": "http://api.example.com/users"}
+	findMocks := func(t *testing.T, quiet bool) *logRecorder {
+		t.Helper()
+		const traceID = "trace-quiet-mocks"
+		recorder := &logRecorder{testID: traceID}
+		log.SetTUILogger(recorder)
+		t.Cleanup(func() { log.SetTUILogger(nil) })
+
+		server, err := NewServer("test-quiet-mocks", &config.ServiceConfig{ID: "test-quiet-mocks"})
+		require.NoError(t, err)
+		server.SetQuietMocks(quiet)
+		server.LoadSpansForTrace(traceID, []*core.Span{makeSpan(t, traceID, "s1", "http", users, nil, 1000)})
+
+		req := makeMockRequest(t, "http", users, nil)
+		req.TestId = traceID
+		require.True(t, server.findMock(req, nil).Found)
+		missing := makeMockRequest(t, "redis", map[string]any{"command": "GET"}, nil)
+		missing.TestId = traceID
+		require.False(t, server.findMock(missing, nil).Found)
+
+		// Messages are delivered in order, so once mock-not-found has arrived
+		// every match message has too
+		require.Eventually(t, func() bool { return recorder.count("No mock found") > 0 }, 2*time.Second, 10*time.Millisecond)
+		return recorder
+	}
+
+	t.Run("default logs every match", func(t *testing.T) {
+		recorder := findMocks(t, false)
+		assert.Equal(t, 2, recorder.count("Finding best match for request"))
+		assert.Equal(t, 1, recorder.count("Found best match"))
+	})
+
+	t.Run("quiet mocks logs only mock not found", func(t *testing.T) {
+		recorder := findMocks(t, true)
+		assert.Zero(t, recorder.count("Finding best match for request"))
+		assert.Zero(t, recorder.count("Found best match"))
+		assert.Equal(t, 1, recorder.count("No mock found"))
+	})
+}
+
+func TestSDKVersionMismatch(t *testing.T) {
+	const oldSDKVersion = "0.0.1"
+	require.False(t, isVersionCompatible(oldSDKVersion, version.MinSDKVersion), "test needs an SDK version below the minimum")
+
+	newStartedServer := func(t *testing.T, allowMismatch bool) (*Server, int) {
+		t.Helper()
+		config.Invalidate()
+		server, err := NewServer("test-sdk-version", &config.ServiceConfig{
+			ID:            "test-sdk-version",
+			Communication: config.CommunicationConfig{Type: "tcp", TCPPort: 0},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = server.Stop() })
+		server.SetAllowSDKVersionMismatch(allowMismatch)
+		require.NoError(t, server.Start())
+		return server, server.listener.Addr().(*net.TCPAddr).Port
+	}
+
+	t.Run("strict by default refuses the connection", func(t *testing.T) {
+		server, port := newStartedServer(t, false)
+		conn, resp := sendSDKConnect(t, port, &core.ConnectRequest{ServiceId: "svc", SdkVersion: oldSDKVersion})
+		defer func() { _ = conn.Close() }()
+
+		assert.False(t, resp.GetSuccess())
+		assert.Contains(t, resp.GetError(), "SDK version 0.0.1 is incompatible")
+		err := server.WaitForSDKConnection(2 * time.Second)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server context cancelled")
+	})
+
+	t.Run("allowed mismatch connects with a warning", func(t *testing.T) {
+		recorder := &logRecorder{}
+		log.SetTUILogger(recorder)
+		defer log.SetTUILogger(nil)
+
+		server, port := newStartedServer(t, true)
+		conn, resp := sendSDKConnect(t, port, &core.ConnectRequest{ServiceId: "svc", SdkVersion: oldSDKVersion})
+		defer func() { _ = conn.Close() }()
+
+		assert.True(t, resp.GetSuccess())
+		require.NoError(t, server.WaitForSDKConnection(time.Second))
+		assert.Equal(t, oldSDKVersion, server.GetSDKVersion())
+		assert.Eventually(t, func() bool {
+			return recorder.count("SDK version 0.0.1 is below the minimum") > 0
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestWaitForSDKReconnection_AfterDisconnect(t *testing.T) {
+	config.Invalidate()
+
+	server, err := NewServer("test-reconnect", &config.ServiceConfig{
+		ID:            "test-reconnect",
+		Communication: config.CommunicationConfig{Type: "tcp", TCPPort: 0},
+	})
+	require.NoError(t, err)
+	defer func() { _ = server.Stop() }()
+	require.NoError(t, server.Start())
+	port := server.listener.Addr().(*net.TCPAddr).Port
+
+	first := connectSDK(t, port)
+	require.NoError(t, server.WaitForSDKConnection(time.Second))
+	require.NoError(t, server.WaitForSDKReconnection(time.Second), "first connect counts as fresh")
+
+	// Simulate the service crashing: the old connection must not satisfy the wait
+	require.NoError(t, first.Close())
+	require.Eventually(t, func() bool {
+		server.mu.RLock()
+		defer server.mu.RUnlock()
+		return !server.sdkConnected
+	}, 2*time.Second, 10*time.Millisecond)
+
+	err = server.WaitForSDKReconnection(50 * time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timeout")
+
+	done := make(chan error, 1)
+	go func() { done <- server.WaitForSDKReconnection(2 * time.Second) }()
+
+	second := connectSDK(t, port)
+	defer func() { _ = second.Close() }()
+	require.NoError(t, <-done)
+
+	server.mu.RLock()
+	assert.Equal(t, uint64(2), server.sdkGeneration)
+	server.mu.RUnlock()
+}
+
+func TestResetSDKConnection_RequiresNewConnect(t *testing.T) {
+	cfg, _ := config.Get()
+	server, err := NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = server.Stop() })
+
+	server.mu.Lock()
+	server.sdkGeneration = 1
+	server.sdkConnected = true
+	close(server.sdkConnectedChan)
+	server.mu.Unlock()
+
+	server.ResetSDKConnection()
+	err = server.WaitForSDKReconnection(50 * time.Millisecond)
+	require.Error(t, err)
+}
+
+func TestFindMockSameSeedGivesSameMatches(t *testing.T) {
+	cfg, _ := config.Get()
+	schema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{"command": {}, "key": {}},
+	}
+
+	matchSequence := func(t *testing.T, seed int64, reverseLoad bool) []string {
+		t.Helper()
+		server, err := NewServer("svc", &cfg.Service)
+		require.NoError(t, err)
+		server.SetSeed(seed)
+
+		traceID := "trace-seeded"
+		// Every recorded key is equally close to the replayed one and all share
+		// a timestamp, so only the SpanId tiebreak separates them
+		spans := []*core.Span{
+			makeSpan(t, traceID, "span-c", "redis", map[string]any{"command": "GET", "key": "user:13"}, schema, 100),
+			makeSpan(t, traceID, "span-a", "redis", map[string]any{"command": "GET", "key": "user:10"}, schema, 100),
+			makeSpan(t, traceID, "span-b", "redis", map[string]any{"command": "GET", "key": "user:11"}, schema, 100),
+		}
+		if reverseLoad {
+			slices.Reverse(spans)
+		}
+		server.LoadSpansForTrace(traceID, spans)
+
+		for range spans {
+			req := makeMockRequest(t, "redis", map[string]any{"command": "GET", "key": "user:12"}, schema)
+			req.TestId = traceID
+			resp := server.findMockWithTimeout(req)
+			require.True(t, resp.Found, resp.Error)
+		}
+
+		var ids []string
+		for _, event := range server.GetMatchEvents(traceID) {
+			ids = append(ids, event.SpanID)
+		}
+		return ids
+	}
+
+	first := matchSequence(t, 42, false)
+	assert.Equal(t, []string{"span-a", "span-b", "span-c"}, first)
+	assert.Equal(t, first, matchSequence(t, 42, false))
+	assert.Equal(t, first, matchSequence(t, 42, true), "load order must not change the matches")
+}
@@ -7,7 +7,9 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Use-Tusk/tusk-cli/internal/config"
@@ -27,21 +29,27 @@ func (e *Executor) StartService() error {
 		return fmt.Errorf("failed to get config: %w", err)
 	}
 
-	e.servicePort = cfg.Service.Port
-	e.serviceURL = fmt.Sprintf("http://localhost:%d", cfg.Service.Port)
+	e.servicePort = e.groupServicePort(cfg.Service.Port)
+	if e.servicePort > 65535 {
+		return fmt.Errorf("service port %d for parallel environment group %d is out of range; lower test_execution.env_concurrency or service.port", e.servicePort, e.envGroupIndex)
+	}
+	e.serviceURL = fmt.Sprintf("http://localhost:%d", e.servicePort)
 
 	if cfg.Service.Start.Command == "" {
 		return fmt.Errorf("no start command defined in config")
 	}
 
-	processExists, err := e.checkProcessOnPort(cfg.Service.Port)
+	processExists, err := e.checkProcessOnPort(e.servicePort)
 	if err != nil {
-		log.Debug("Failed to check for existing processes on port", "port", cfg.Service.Port, "error", err)
+		log.Debug("Failed to check for existing processes on port", "port", e.servicePort, "error", err)
 	} else if processExists {
-		return fmt.Errorf("port %d is already in use, if your service is already running you should stop it first", cfg.Service.Port)
+		return fmt.Errorf("port %d is already in use, if your service is already running you should stop it first", e.servicePort)
 	}
 
 	log.Debug("Starting service", "command", cfg.Service.Start.Command)
+	if cfg.Service.Stop.Command == "" && serviceDelegatesToHostDaemon(cfg.Service.Start.Command) {
+		log.ServiceLog("💡 Docker start command without service.stop.command: containers may keep running after tests. Set service.stop.command (e.g. docker compose down) to tear them down.")
+	}
 
 	command := cfg.Service.Start.Command
 
@@ -62,7 +70,10 @@ func (e *Executor) StartService() error {
 		}
 	}
 	effectiveSandboxMode := e.GetEffectiveSandboxMode()
-	if effectiveSandboxMode == SandboxModeOff || e.sandboxBypass {
+	if e.liveMode {
+		// Live requests must reach the service's real dependencies
+		effectiveSandboxMode = SandboxModeOff
+	} else if effectiveSandboxMode == SandboxModeOff || e.sandboxBypass {
 		log.ServiceLog("⚠️  Replay sandbox disabled (real outbound connections allowed)")
 	}
 
@@ -105,7 +116,7 @@ func (e *Executor) StartService() error {
 			sbx, sbxErr := newReplaySandboxManager(replaySandboxOptions{
 				UserConfigPath:   sandboxConfigPath,
 				Debug:            e.debug,
-				ExposedPort:      cfg.Service.Port,
+				ExposedPort:      e.servicePort,
 				BindsOnHost:      serviceDelegatesToHostDaemon(cfg.Service.Start.Command),
 				ExposedHostPaths: exposedHostPaths,
 			})
@@ -153,7 +164,8 @@ func (e *Executor) StartService() error {
 	if e.server != nil {
 		socketPath, tcpPort := e.server.GetConnectionInfo()
 
-		if e.server.GetCommunicationType() == CommunicationTCP {
+		switch e.server.GetCommunicationType() {
+		case CommunicationTCP:
 			// TCP mode - set host and port
 			env = append(env, fmt.Sprintf("TUSK_MOCK_PORT=%d", tcpPort))
 			env = append(env, "TUSK_MOCK_HOST=host.docker.internal") // Mac/Windows
@@ -161,7 +173,12 @@ func (e *Executor) StartService() error {
 			log.Debug("Setting TCP environment variables",
 				"TUSK_MOCK_PORT", tcpPort,
 				"TUSK_MOCK_HOST", "host.docker.internal")
-		} else {
+		case CommunicationWebSocket:
+			// WebSocket mode - GetConnectionInfo returns the ws URL in place of a socket path
+			env = append(env, fmt.Sprintf("TUSK_MOCK_WS_URL=%s", socketPath))
+			env = append(env, fmt.Sprintf("TUSK_MOCK_PORT=%d", tcpPort))
+			log.Debug("Setting WebSocket environment variables", "TUSK_MOCK_WS_URL", socketPath)
+		default:
 			// Unix socket mode
 			env = append(env, fmt.Sprintf("TUSK_MOCK_SOCKET=%s", socketPath))
 			log.Debug("Setting socket environment variable", "TUSK_MOCK_SOCKET", socketPath)
@@ -172,7 +189,11 @@ func (e *Executor) StartService() error {
 		}
 	}
 
-	env = append(env, "TUSK_DRIFT_MODE=REPLAY")
+	if e.liveMode {
+		env = append(env, "TUSK_DRIFT_MODE=DISABLED")
+	} else {
+		env = append(env, "TUSK_DRIFT_MODE=REPLAY")
+	}
 
 	// Coverage: inject env vars that SDK coverage servers listen for.
 	// NODE_V8_COVERAGE is required by the Node SDK to enable V8 coverage collection.
@@ -223,9 +244,12 @@ func (e *Executor) StartService() error {
 	// this goroutine still sends to the original one (not the new one).
 	e.processExitCh = make(chan error, 1)
 	exitCh := e.processExitCh
+	exited := make(chan struct{})
+	e.serviceExited = exited
 	cmd := e.serviceCmd
 	go func() {
 		exitCh <- cmd.Wait()
+		close(exited)
 	}()
 
 	if err := e.waitForReadiness(cfg); err != nil {
@@ -255,32 +279,150 @@ func (e *Executor) StopService() error {
 		log.ServiceLog("Service stopped")
 	}()
 
-	// Use custom stop command if provided
+	// Run the custom stop command first, e.g. to tear down containers that
+	// killing the start command would leave running
 	if cfg != nil && cfg.Service.Stop.Command != "" {
-		log.Debug("Using custom stop command", "command", cfg.Service.Stop.Command)
-
-		stopCmd := createServiceCommand(context.Background(), cfg.Service.Stop.Command)
-		stopCmd.Env = e.buildCommandEnv()
-		if err := stopCmd.Run(); err != nil {
-			log.Warn("Stop command failed", "error", err)
-			// Continue to fallback method
-		} else {
-			return nil
+		if err := e.runStopCommand(cfg); err != nil {
+			// The process group is still killed below, so this is not fatal
+			log.Warn("Stop command failed", "command", cfg.Service.Stop.Command, "error", err)
+			log.ServiceLog(fmt.Sprintf("⚠️  Stop command failed: %v", err))
 		}
 	}
 
-	// Default: kill process group
+	// Kill whatever the stop command left of the process group
 	if e.serviceCmd != nil && e.serviceCmd.Process != nil {
-		// Use platform-specific process group killing with 3 second timeout
-		if err := killProcessGroup(e.serviceCmd, 3*time.Second); err != nil {
+		// Use platform-specific process group killing
+		if err := killProcessGroup(e.serviceCmd, e.serviceExited, stopGracePeriod(cfg)); err != nil {
 			log.Debug("Process group kill completed with error", "error", err)
 		}
 		e.serviceCmd = nil
+		e.serviceExited = nil
+	}
+
+	return nil
+}
+
+// runStopCommand runs service.stop.command, giving up after service.stop.timeout.
+func (e *Executor) runStopCommand(cfg *config.Config) error {
+	timeout := 60 * time.Second
+	if cfg.Service.Stop.Timeout != "" {
+		if d, err := time.ParseDuration(cfg.Service.Stop.Timeout); err == nil {
+			timeout = d
+		}
+	}
+	log.Debug("Using custom stop command", "command", cfg.Service.Stop.Command, "timeout", timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	stopCmd := createServiceCommand(ctx, cfg.Service.Stop.Command)
+	stopCmd.Env = e.buildCommandEnv()
+	if err := stopCmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("timed out after %v; you can increase service.stop.timeout in .tusk/config.yaml", timeout)
+		}
+		return err
+	}
+	return nil
+}
+
+// preRunState tracks service.pre_run.command across the executors of a run,
+// so it runs once however many environments start.
+type preRunState struct {
+	mu   sync.Mutex
+	done bool
+}
+
+// runPreRunCommand runs service.pre_run.command unless it already succeeded
+// in this run. Environment groups replayed in parallel wait for it.
+func (e *Executor) runPreRunCommand() error {
+	cfg, err := config.Get()
+	if err != nil || cfg.Service.PreRun.Command == "" {
+		return nil
+	}
+	if e.preRun == nil {
+		e.preRun = &preRunState{}
+	}
+	e.preRun.mu.Lock()
+	defer e.preRun.mu.Unlock()
+	if e.preRun.done {
+		return nil
+	}
+
+	log.ServiceLog("Running pre-run command...")
+	if err := e.runHookCommand(cfg, cfg.Service.PreRun.Command, e.buildCommandEnv()); err != nil {
+		log.ServiceLog(fmt.Sprintf("❌ Pre-run command failed: %v", err))
+		return fmt.Errorf("pre-run command: %w", err)
+	}
+	log.ServiceLog("✅ Pre-run command completed")
+	e.preRun.done = true
+	return nil
+}
+
+// runGroupPreRunCommand runs service.pre_run.group_command before the
+// service of environment group groupName starts.
+func (e *Executor) runGroupPreRunCommand(groupName string) error {
+	cfg, err := config.Get()
+	if err != nil || cfg.Service.PreRun.GroupCommand == "" {
+		return nil
+	}
+
+	log.ServiceLog(fmt.Sprintf("Running pre-run command for environment: %s", groupName))
+	env := mergeEnvVars(e.buildCommandEnv(), map[string]string{"TUSK_ENVIRONMENT": groupName})
+	if err := e.runHookCommand(cfg, cfg.Service.PreRun.GroupCommand, env); err != nil {
+		log.ServiceLog(fmt.Sprintf("❌ Pre-run command for %s failed: %v", groupName, err))
+		return fmt.Errorf("pre-run command for %s: %w", groupName, err)
+	}
+	return nil
+}
+
+// runHookCommand runs a pre-run command to completion, giving up after
+// service.pre_run.timeout. Its output goes to the service logs, so it is
+// shown with the startup logs when the run fails to start.
+func (e *Executor) runHookCommand(cfg *config.Config, command string, env []string) error {
+	timeout := 5 * time.Minute
+	if cfg.Service.PreRun.Timeout != "" {
+		if d, err := time.ParseDuration(cfg.Service.PreRun.Timeout); err == nil {
+			timeout = d
+		}
+	}
+	log.Debug("Running pre-run command", "command", command, "timeout", timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	hookCmd := createServiceCommand(ctx, command)
+	hookCmd.Env = env
+	if err := e.setupServiceLogging(); err != nil {
+		log.Debug("Failed to setup service logging, suppressing output", "error", err)
+	} else if e.enableServiceLogs {
+		hookCmd.Stdout = e.serviceLogFile
+		hookCmd.Stderr = e.serviceLogFile
+		// StartService reopens the log file
+		defer e.cleanupLogFiles()
+	} else {
+		hookCmd.Stdout = e.startupLogBuffer
+		hookCmd.Stderr = e.startupLogBuffer
 	}
 
+	if err := hookCmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("timed out after %v; you can increase service.pre_run.timeout in .tusk/config.yaml", timeout)
+		}
+		return err
+	}
 	return nil
 }
 
+// stopGracePeriod is how long StopService waits for the service's processes
+// to exit after SIGTERM before sending SIGKILL.
+func stopGracePeriod(cfg *config.Config) time.Duration {
+	if cfg != nil && cfg.Service.Stop.GracePeriod != "" {
+		if d, err := time.ParseDuration(cfg.Service.Stop.GracePeriod); err == nil {
+			return d
+		}
+	}
+	return 3 * time.Second
+}
+
 func mergeEnvVars(base []string, overrides map[string]string) []string {
 	if len(overrides) == 0 {
 		return base
@@ -312,7 +454,26 @@ func mergeEnvVars(base []string, overrides map[string]string) []string {
 }
 
 func (e *Executor) buildCommandEnv() []string {
-	return mergeEnvVars(os.Environ(), e.getReplayEnvVars())
+	env := mergeEnvVars(os.Environ(), e.getReplayEnvVars())
+	env = mergeEnvVars(env, e.envFileVars)
+	if e.envGroupIndex > 0 && e.servicePort > 0 {
+		// Tell each parallel environment group's service which port to listen on
+		port := strconv.Itoa(e.servicePort)
+		env = mergeEnvVars(env, map[string]string{
+			"PORT":              port,
+			"TUSK_SERVICE_PORT": port,
+		})
+	}
+	return env
+}
+
+// groupServicePort returns the port the service listens on. Environment groups
+// replayed in parallel use consecutive ports starting at basePort.
+func (e *Executor) groupServicePort(basePort int) int {
+	if e.envGroupIndex <= 1 {
+		return basePort
+	}
+	return basePort + e.envGroupIndex - 1
 }
 
 func (e *Executor) GetServiceLogPath() string {
@@ -344,11 +505,14 @@ func (e *Executor) checkProcessOnPort(port int) (bool, error) {
 	return false, nil
 }
 
-// waitForReadiness polls the specified readiness check command until it succeeds or the timeout is reached.
+// waitForReadiness polls the configured readiness probe (command, HTTP URL or TCP port) until it succeeds or the timeout is reached.
 // This is necessary because replaying traces requires the service to be properly instrumented and ready to handle requests.
-// If no readiness check command is configured, it will simply wait for 10 seconds.
+// If no readiness probe is configured, it will simply wait for 10 seconds.
 func (e *Executor) waitForReadiness(cfg *config.Config) error {
-	if cfg.Service.Readiness.Command == "" {
+	e.failedReadinessProbe = ""
+
+	probe := e.newReadinessProbe(cfg)
+	if probe == nil {
 		// Allow tests to override the default wait time
 		waitTime := 10 * time.Second
 		if testWait := os.Getenv("TUSK_TEST_DEFAULT_WAIT"); testWait != "" {
@@ -381,6 +545,7 @@ func (e *Executor) waitForReadiness(cfg *config.Config) error {
 
 	deadline := time.Now().Add(timeout)
 
+	var lastErr error
 	for time.Now().Before(deadline) {
 		// Check for early process exit before each readiness attempt
 		select {
@@ -389,11 +554,10 @@ func (e *Executor) waitForReadiness(cfg *config.Config) error {
 		default:
 		}
 
-		cmd := createReadinessCommand(cfg.Service.Readiness.Command)
-		cmd.Env = e.buildCommandEnv()
-		if err := cmd.Run(); err == nil {
+		if lastErr = probe.check(); lastErr == nil {
 			return nil
 		}
+		log.Debug("Readiness probe failed", "probe", probe.description, "error", lastErr)
 
 		// Wait for the interval, but fail fast if the process exits
 		select {
@@ -403,7 +567,11 @@ func (e *Executor) waitForReadiness(cfg *config.Config) error {
 		}
 	}
 
-	return fmt.Errorf("service failed to become ready within %v. You can increase the timeout in .tusk/config.yaml under service.readiness.timeout", timeout)
+	e.failedReadinessProbe = probe.description
+	if lastErr != nil {
+		return fmt.Errorf("service failed to become ready within %v (%s: %v). You can increase the timeout in .tusk/config.yaml under service.readiness_check.timeout", timeout, probe.description, lastErr)
+	}
+	return fmt.Errorf("service failed to become ready within %v. You can increase the timeout in .tusk/config.yaml under service.readiness_check.timeout", timeout)
 }
 
 // SetDisableServiceLogs sets whether service logging should be disabled
@@ -436,7 +604,11 @@ func (e *Executor) setupServiceLogging() error {
 			}
 
 			timestamp := time.Now().Format("20060102-150405")
-			logPath := filepath.Join(logsDir, fmt.Sprintf("tusk-replay-%s.log", timestamp))
+			logName := fmt.Sprintf("tusk-replay-%s.log", timestamp)
+			if e.serviceLogLabel != "" {
+				logName = fmt.Sprintf("tusk-replay-%s-%s.log", timestamp, sanitizePathComponent(e.serviceLogLabel))
+			}
+			logPath := filepath.Join(logsDir, logName)
 			logFile, err := os.Create(logPath) //nolint:gosec // logsDir is configured by the user
 			if err != nil {
 				return fmt.Errorf("failed to create service log file: %w", err)
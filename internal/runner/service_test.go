@@ -1,8 +1,10 @@
 package runner
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"log/slog"
 	"net"
 	"os"
 	"path/filepath"
@@ -10,6 +12,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/Use-Tusk/tusk-cli/internal/config"
 	"github.com/stretchr/testify/assert"
@@ -503,6 +506,158 @@ service:
 	require.NoError(t, err, "Custom stop command should create marker when replay env is present")
 }
 
+func TestStartServiceLiveModeDisablesSDK(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("start command uses a POSIX shell")
+	}
+	config.Invalidate()
+	origWait := os.Getenv("TUSK_TEST_DEFAULT_WAIT")
+	_ = os.Setenv("TUSK_TEST_DEFAULT_WAIT", "100ms")
+	defer func() {
+		if origWait != "" {
+			_ = os.Setenv("TUSK_TEST_DEFAULT_WAIT", origWait)
+		} else {
+			_ = os.Unsetenv("TUSK_TEST_DEFAULT_WAIT")
+		}
+	}()
+
+	tempDir := t.TempDir()
+	markerFile := filepath.Join(tempDir, "live-mode-ok")
+	startCmd := createMarkerIfEnvMatchesCommand(filepath.ToSlash(markerFile), "TUSK_DRIFT_MODE", "DISABLED") + "; " + getSimpleSleepCommand()
+
+	configContent := fmt.Sprintf(`
+service:
+  port: 13015
+  start:
+    command: %s
+`, yamlSingleQuoted(startCmd))
+
+	configPath := filepath.Join(tempDir, "tusk.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
+	require.NoError(t, config.Load(configPath))
+
+	e := NewExecutor()
+	e.liveMode = true
+	require.NoError(t, e.StartService())
+	assert.False(t, e.lastServiceSandboxed, "live mode should never sandbox the service")
+	assert.NoError(t, e.StopService())
+
+	_, err := os.Stat(markerFile)
+	require.NoError(t, err, "live mode should start the service with TUSK_DRIFT_MODE=DISABLED")
+}
+
+func TestCustomStopCommandFailureIsWarning(t *testing.T) {
+	tests := []struct {
+		name        string
+		stopConfig  string
+		wantWarning string
+	}{
+		{
+			name:        "command_fails",
+			stopConfig:  "command: \"exit 3\"",
+			wantWarning: "exit status 3",
+		},
+		{
+			name:        "command_times_out",
+			stopConfig:  "command: \"sleep 10\"\n    timeout: 200ms",
+			wantWarning: "timed out after 200ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Invalidate()
+			t.Cleanup(config.Invalidate)
+
+			var logs bytes.Buffer
+			origLogger := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+			t.Cleanup(func() { slog.SetDefault(origLogger) })
+
+			cfgPath := writeTempConfig(t, "service:\n  port: 13014\n  start:\n    command: \"sleep 10\"\n  stop:\n    "+tt.stopConfig+"\n")
+			require.NoError(t, config.Load(cfgPath))
+
+			// The service keeps running after the stop command fails, so it
+			// must still be killed
+			e := NewExecutor()
+			cmd := createTestCommand(context.Background(), "10")
+			require.NoError(t, cmd.Start())
+			e.serviceCmd = cmd
+
+			start := time.Now()
+			err := e.StopService()
+			require.NoError(t, err)
+			assert.Less(t, time.Since(start), 5*time.Second)
+			assert.Nil(t, e.serviceCmd)
+			assert.Contains(t, logs.String(), "Stop command failed")
+			assert.Contains(t, logs.String(), tt.wantWarning)
+		})
+	}
+}
+
+func TestPreRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pre-run commands use a POSIX shell")
+	}
+
+	t.Run("runs once before the first environment and each group", func(t *testing.T) {
+		config.Invalidate()
+		t.Cleanup(config.Invalidate)
+		dir := t.TempDir()
+		migrations := filepath.ToSlash(filepath.Join(dir, "migrations"))
+		groups := filepath.ToSlash(filepath.Join(dir, "groups"))
+		cfgPath := writeTempConfig(t, fmt.Sprintf(`
+service:
+  port: 13016
+  start:
+    command: "%s"
+  pre_run:
+    command: %s
+    group_command: %s
+`, getSimpleSleepCommand(), yamlSingleQuoted("echo migrated >> "+migrations), yamlSingleQuoted("echo $TUSK_ENVIRONMENT >> "+groups)))
+		require.NoError(t, config.Load(cfgPath))
+
+		e := NewExecutor()
+		require.NoError(t, e.runPreRunCommand())
+		require.NoError(t, e.runPreRunCommand())
+		staging := e.newEnvironmentExecutor("staging", 1)
+		require.NoError(t, staging.runPreRunCommand())
+		require.NoError(t, staging.runGroupPreRunCommand("staging"))
+		require.NoError(t, e.newEnvironmentExecutor("production", 2).runGroupPreRunCommand("production"))
+
+		data, err := os.ReadFile(migrations)
+		require.NoError(t, err)
+		assert.Equal(t, "migrated\n", string(data))
+		data, err = os.ReadFile(groups)
+		require.NoError(t, err)
+		assert.Equal(t, "staging\nproduction\n", string(data))
+	})
+
+	t.Run("failure prevents startup", func(t *testing.T) {
+		config.Invalidate()
+		t.Cleanup(config.Invalidate)
+		started := filepath.ToSlash(filepath.Join(t.TempDir(), "started"))
+		cfgPath := writeTempConfig(t, fmt.Sprintf(`
+service:
+  port: 13017
+  start:
+    command: %s
+  pre_run:
+    command: "echo migration failed; exit 3"
+`, yamlSingleQuoted("touch "+started+"; "+getSimpleSleepCommand())))
+		require.NoError(t, config.Load(cfgPath))
+
+		e := newExecutorForServiceLifecycleTests()
+		err := e.StartEnvironment()
+		require.ErrorContains(t, err, "pre-run command: exit status 3")
+		assert.Nil(t, e.server, "the mock server must not start")
+		assert.Nil(t, e.serviceCmd)
+		_, statErr := os.Stat(started)
+		assert.True(t, os.IsNotExist(statErr), "the service must not start")
+		assert.Contains(t, e.GetStartupLogs(), "migration failed")
+	})
+}
+
 func TestGetServiceLogPath(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -3,9 +3,15 @@
 package runner
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -29,58 +35,115 @@ func setupProcessGroup(cmd *exec.Cmd) {
 	}
 }
 
-// killProcessGroup attempts to kill the process group gracefully, then forcefully
-func killProcessGroup(cmd *exec.Cmd, timeout time.Duration) error {
+// processGroupPollInterval is how often killProcessGroup checks whether the
+// process group has exited.
+const processGroupPollInterval = 50 * time.Millisecond
+
+// killProcessGroup sends SIGTERM to the service's process group, waits up to
+// gracePeriod for every process in it to exit, then sends SIGKILL to whatever
+// is left. Waiting on the whole group rather than the shell ensures children of
+// wrappers such as npm or docker are stopped too. exited is closed once
+// cmd.Wait has returned; when nil, killProcessGroup waits on cmd itself.
+func killProcessGroup(cmd *exec.Cmd, exited <-chan struct{}, gracePeriod time.Duration) error {
 	if cmd == nil || cmd.Process == nil {
 		return nil
 	}
 
+	// setupProcessGroup made the service its own group leader, so its pid is
+	// the pgid even after it has exited
 	pid := cmd.Process.Pid
 	log.Debug("Stopping service", "pid", pid)
 
-	// Try to get the process group ID
-	pgid, err := syscall.Getpgid(pid)
-	if err == nil {
-		// Send SIGTERM to the entire process group
-		log.Debug("Killing process group", "pgid", pgid)
-		if err := syscall.Kill(-pgid, syscall.SIGTERM); err != nil {
-			log.Debug("Failed to send SIGTERM to process group", "pgid", pgid, "error", err)
-			// Fallback to interrupting the main process
-			if err := cmd.Process.Signal(syscall.Signal(syscall.SIGINT)); err != nil {
-				log.Debug("Failed to send interrupt signal", "pid", pid, "error", err)
-			}
+	if exited == nil {
+		// Reap the group leader as soon as it exits so it does not linger as
+		// a zombie member of the group
+		waited := make(chan struct{})
+		go func() {
+			_ = cmd.Wait()
+			close(waited)
+		}()
+		exited = waited
+	}
+
+	log.Debug("Sending SIGTERM to process group", "pgid", pid)
+	if err := syscall.Kill(-pid, syscall.SIGTERM); err != nil {
+		if errors.Is(err, syscall.ESRCH) {
+			return nil
 		}
-	} else {
-		// If we can't get the process group, just interrupt the main process
-		log.Debug("Failed to get process group", "pid", pid, "error", err)
-		if err := cmd.Process.Signal(syscall.Signal(syscall.SIGINT)); err != nil {
+		log.Debug("Failed to send SIGTERM to process group", "pgid", pid, "error", err)
+		// Fallback to interrupting the main process
+		if err := cmd.Process.Signal(syscall.SIGINT); err != nil {
 			log.Debug("Failed to send interrupt signal", "pid", pid, "error", err)
 		}
 	}
 
-	// Wait for the process to exit gracefully
-	done := make(chan error, 1)
-	go func() { done <- cmd.Wait() }()
-
-	select {
-	case <-done:
+	if waitForProcessGroupExit(pid, exited, gracePeriod) {
 		log.Debug("Service stopped gracefully")
 		return nil
-	case <-time.After(timeout):
-		log.Debug("Service didn't stop gracefully, force killing")
-		// Force kill the process group
-		if pgid, err := syscall.Getpgid(pid); err == nil {
-			log.Debug("Force killing process group", "pgid", pgid)
-			if err := syscall.Kill(-pgid, syscall.SIGKILL); err != nil {
-				log.Debug("Failed to SIGKILL process group", "pgid", pgid, "error", err)
-				// Last resort: kill the main process
-				_ = cmd.Process.Kill()
+	}
+
+	log.Debug("Service didn't stop gracefully, force killing process group", "pgid", pid)
+	if err := syscall.Kill(-pid, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+		log.Debug("Failed to SIGKILL process group", "pgid", pid, "error", err)
+		// Last resort: kill the main process
+		_ = cmd.Process.Kill()
+	}
+	waitForProcessGroupExit(pid, exited, gracePeriod)
+	return fmt.Errorf("service was force killed after %v", gracePeriod)
+}
+
+// waitForProcessGroupExit reports whether every process in the group exited
+// within timeout.
+func waitForProcessGroupExit(pgid int, leaderDone <-chan struct{}, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		select {
+		case <-leaderDone:
+			if !processGroupAlive(pgid) {
+				return true
 			}
-		} else {
-			log.Debug("Final fallback: killing main process", "pid", pid)
-			_ = cmd.Process.Kill()
+		default:
+		}
+		if !time.Now().Before(deadline) {
+			return false
+		}
+		time.Sleep(processGroupPollInterval)
+	}
+}
+
+// processGroupAlive reports whether any process in the group is still running.
+// Children orphaned to an init that does not reap them (common in containers)
+// stay in the group as zombies after exiting, so on Linux those are ignored.
+func processGroupAlive(pgid int) bool {
+	// Signal 0 only checks whether any process in the group remains
+	if err := syscall.Kill(-pgid, 0); errors.Is(err, syscall.ESRCH) {
+		return false
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return true
+	}
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "stat")) // #nosec G304
+		if err != nil {
+			continue
+		}
+		// Format: pid (comm) state ppid pgrp ...; comm may contain spaces
+		end := bytes.LastIndexByte(data, ')')
+		if end < 0 {
+			continue
+		}
+		fields := strings.Fields(string(data[end+1:]))
+		if len(fields) < 3 || fields[2] != strconv.Itoa(pgid) {
+			continue
+		}
+		if state := fields[0]; state != "Z" && state != "X" {
+			return true
 		}
-		_ = cmd.Wait()
-		return fmt.Errorf("service was force killed after timeout")
 	}
+	return false
 }
@@ -0,0 +1,49 @@
+//go:build darwin || linux || freebsd
+
+package runner
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startProcessTree starts a shell in its own process group that spawns
+// children and waits on them, like npm wrapping node.
+func startProcessTree(t *testing.T, script string) *exec.Cmd {
+	t.Helper()
+	cmd := createServiceCommand(context.Background(), script)
+	setupProcessGroup(cmd)
+	require.NoError(t, cmd.Start())
+	pid := cmd.Process.Pid
+	t.Cleanup(func() { _ = syscall.Kill(-pid, syscall.SIGKILL) })
+
+	// Give the shell time to spawn its children
+	time.Sleep(200 * time.Millisecond)
+	require.True(t, processGroupAlive(pid), "process group should be running")
+	return cmd
+}
+
+func TestKillProcessGroupStopsChildren(t *testing.T) {
+	cmd := startProcessTree(t, "sleep 30 & sleep 30 & wait")
+
+	err := killProcessGroup(cmd, nil, 5*time.Second)
+	require.NoError(t, err)
+	assert.False(t, processGroupAlive(cmd.Process.Pid), "all processes in the group should have exited")
+}
+
+func TestKillProcessGroupForceKillsChildrenIgnoringSIGTERM(t *testing.T) {
+	cmd := startProcessTree(t, `sh -c 'trap "" TERM; sleep 30' & wait`)
+
+	start := time.Now()
+	err := killProcessGroup(cmd, nil, 500*time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "force killed")
+	assert.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond, "SIGKILL should only follow the grace period")
+	assert.False(t, processGroupAlive(cmd.Process.Pid), "all processes in the group should have exited")
+}
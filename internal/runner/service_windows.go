@@ -43,8 +43,10 @@ func setupProcessGroup(cmd *exec.Cmd) {
 	cmd.SysProcAttr.CreationFlags = syscall.CREATE_NEW_PROCESS_GROUP
 }
 
-// killProcessGroup attempts to kill the process gracefully, then forcefully
-func killProcessGroup(cmd *exec.Cmd, timeout time.Duration) error {
+// killProcessGroup attempts to kill the process gracefully, then forcefully.
+// exited is closed once cmd.Wait has returned; when nil, killProcessGroup
+// waits on cmd itself.
+func killProcessGroup(cmd *exec.Cmd, exited <-chan struct{}, timeout time.Duration) error {
 	if cmd == nil || cmd.Process == nil {
 		return nil
 	}
@@ -63,11 +65,17 @@ func killProcessGroup(cmd *exec.Cmd, timeout time.Duration) error {
 	}
 
 	// Wait for the process to exit gracefully
-	done := make(chan error, 1)
-	go func() { done <- cmd.Wait() }()
+	if exited == nil {
+		waited := make(chan struct{})
+		go func() {
+			_ = cmd.Wait()
+			close(waited)
+		}()
+		exited = waited
+	}
 
 	select {
-	case <-done:
+	case <-exited:
 		log.Debug("Service stopped gracefully")
 		return nil
 	case <-time.After(timeout):
@@ -79,7 +87,10 @@ func killProcessGroup(cmd *exec.Cmd, timeout time.Duration) error {
 			// Last resort: use Process.Kill()
 			_ = cmd.Process.Kill()
 		}
-		_ = cmd.Wait()
+		select {
+		case <-exited:
+		case <-time.After(timeout):
+		}
 		return fmt.Errorf("service was force killed after timeout")
 	}
 }
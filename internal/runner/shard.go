@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Shard selects one of several disjoint subsets of a test suite.
+type Shard struct {
+	Index int // 1-based
+	Total int
+}
+
+// ParseShard parses "i/n" with 1 <= i <= n.
+func ParseShard(s string) (Shard, error) {
+	idx, total, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Shard{}, fmt.Errorf("invalid shard %q (expected i/n, e.g. 2/5)", s)
+	}
+	i, errI := strconv.Atoi(strings.TrimSpace(idx))
+	n, errN := strconv.Atoi(strings.TrimSpace(total))
+	if errI != nil || errN != nil {
+		return Shard{}, fmt.Errorf("invalid shard %q (expected i/n, e.g. 2/5)", s)
+	}
+	if n < 1 || i < 1 || i > n {
+		return Shard{}, fmt.Errorf("invalid shard %q: i must be between 1 and n", s)
+	}
+	return Shard{Index: i, Total: n}, nil
+}
+
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Total)
+}
+
+// ShardTests returns the tests in shard s. Tests are assigned by a hash of
+// their trace ID, so the assignment doesn't depend on load order and every
+// test lands in exactly one shard.
+func ShardTests(tests []Test, s Shard) []Test {
+	var out []Test
+	for _, t := range tests {
+		if shardForTrace(t.TraceID, s.Total) == s.Index {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// shardForTrace returns the 1-based shard of traceID among total shards.
+func shardForTrace(traceID string, total int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(traceID))
+	return int(h.Sum32()%uint32(total)) + 1 // #nosec G115 -- total is a positive shard count
+}
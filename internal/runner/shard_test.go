@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShard(t *testing.T) {
+	s, err := ParseShard("2/5")
+	require.NoError(t, err)
+	assert.Equal(t, Shard{Index: 2, Total: 5}, s)
+
+	for _, invalid := range []string{"", "2", "0/5", "6/5", "1/0", "-1/3", "a/b", "1/2/3"} {
+		_, err := ParseShard(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestShardTests_CoverSuiteWithoutOverlap(t *testing.T) {
+	var tests []Test
+	for i := range 200 {
+		tests = append(tests, Test{TraceID: fmt.Sprintf("trace-%03d", i)})
+	}
+
+	const total = 5
+	seen := make(map[string]int)
+	for i := 1; i <= total; i++ {
+		shard := ShardTests(tests, Shard{Index: i, Total: total})
+		assert.NotEmpty(t, shard)
+		for _, test := range shard {
+			seen[test.TraceID]++
+		}
+	}
+
+	assert.Len(t, seen, len(tests), "every test runs in some shard")
+	for id, count := range seen {
+		assert.Equal(t, 1, count, "%s ran in %d shards", id, count)
+	}
+}
+
+func TestShardTests_SingleShardKeepsAll(t *testing.T) {
+	tests := []Test{{TraceID: "a"}, {TraceID: "b"}}
+	assert.Equal(t, tests, ShardTests(tests, Shard{Index: 1, Total: 1}))
+}
@@ -3,6 +3,7 @@ package runner
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"time"
 
@@ -33,8 +34,13 @@ type SuiteSpanOptions struct {
 	// PreloadedPreAppStartSpans allows passing pre-fetched pre-app-start spans to avoid fetching again
 	PreloadedPreAppStartSpans []*core.Span
 
-	// PreloadedGlobalSpans allows passing pre-fetched global spans to avoid fetching again
+	// PreloadedGlobalSpans allows passing pre-fetched global spans to avoid fetching again.
+	// Outside cloud mode these come from --global-spans-file.
 	PreloadedGlobalSpans []*core.Span
+
+	// SkipPreAppStart leaves out pre-app-start spans from other traces instead
+	// of fetching them (--skip-preappstart). Those of currentTests are kept.
+	SkipPreAppStart bool
 }
 
 // BuildSuiteSpansResult contains the result of building suite spans
@@ -56,18 +62,17 @@ func BuildSuiteSpansForRun(
 	var globalSpans []*core.Span
 
 	// Fetch global spans (use preloaded if available)
-	if opts.IsCloudMode && opts.Client != nil {
-		var global []*core.Span
-		if len(opts.PreloadedGlobalSpans) > 0 {
-			global = opts.PreloadedGlobalSpans
-		} else {
-			var err error
-			global, err = FetchGlobalSpansFromCloudWithCache(ctx, opts.Client, opts.AuthOptions, opts.ServiceID, opts.Interactive, opts.Quiet)
-			if err != nil {
-				log.Warn("Failed to fetch global spans", "error", err)
-			}
+	var global []*core.Span
+	if len(opts.PreloadedGlobalSpans) > 0 {
+		global = opts.PreloadedGlobalSpans
+	} else if opts.IsCloudMode && opts.Client != nil {
+		var err error
+		global, err = FetchGlobalSpansFromCloudWithCache(ctx, opts.Client, opts.AuthOptions, opts.ServiceID, opts.Interactive, opts.Quiet)
+		if err != nil {
+			log.Warn("Failed to fetch global spans", "error", err)
 		}
-
+	}
+	if len(global) > 0 {
 		if opts.AllowSuiteWideMatching {
 			// Validation mode: add global spans directly to suite spans for matching
 			suiteSpans = append(suiteSpans, global...)
@@ -82,9 +87,11 @@ func BuildSuiteSpansForRun(
 		suiteSpans = append(suiteSpans, t.Spans...)
 	}
 
-	// Pre-app-start spans are always included (both modes)
+	// Pre-app-start spans are always included (both modes) unless skipped
 	// Prepend these spans so they get considered first
-	if opts.IsCloudMode && opts.Client != nil {
+	if opts.SkipPreAppStart {
+		log.Debug("Skipping pre-app-start span fetch")
+	} else if opts.IsCloudMode && opts.Client != nil {
 		var preAppStartSpans []*core.Span
 		if len(opts.PreloadedPreAppStartSpans) > 0 {
 			preAppStartSpans = opts.PreloadedPreAppStartSpans
@@ -165,8 +172,8 @@ func PrepareAndSetSuiteSpans(
 
 	// Enable suite-wide matching when:
 	// - Explicitly requested (validation mode or other use cases)
-	// - Local (non-cloud) runs since there are no explicit global spans
-	if opts.AllowSuiteWideMatching || !opts.IsCloudMode {
+	// - Local (non-cloud) runs without a global spans file, since there are no explicit global spans
+	if opts.AllowSuiteWideMatching || (!opts.IsCloudMode && len(result.GlobalSpans) == 0) {
 		exec.SetAllowSuiteWideMatching(true)
 	}
 	return nil
@@ -247,6 +254,19 @@ func FetchGlobalSpansFromCloudWithCache(
 	return api.FetchGlobalSpansWithCache(ctx, client, auth, serviceID, interactive, quiet)
 }
 
+// LoadGlobalSpansFile reads global spans from a JSONL file of spans, such as
+// spans exported from Tusk Drift Cloud, for cross-trace matching offline.
+func LoadGlobalSpansFile(path string) ([]*core.Span, error) {
+	spans, err := utils.ParseSpansFromFile(path, nil)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("global spans file not found: %s", path)
+		}
+		return nil, fmt.Errorf("failed to load global spans: %w", err)
+	}
+	return spans, nil
+}
+
 // FetchGlobalSpansFromCloud fetches only spans marked as global (is_global=true) from cloud
 func FetchGlobalSpansFromCloud(
 	ctx context.Context,
@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+	"github.com/Use-Tusk/tusk-cli/internal/utils"
 	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -305,6 +307,42 @@ func TestBuildSuiteSpansForRun_PreAppStartSpans(t *testing.T) {
 	}
 }
 
+func TestBuildSuiteSpansForRun_SkipPreAppStart(t *testing.T) {
+	dir := t.TempDir()
+	writeTraceFile(t, dir, "other-trace.jsonl", map[string]any{
+		"traceId":       "other-trace",
+		"spanId":        "other-preapp",
+		"name":          "preapp-other",
+		"isPreAppStart": true,
+	})
+	utils.SetTracesDirOverride(dir)
+	t.Cleanup(func() { utils.SetTracesDirOverride("") })
+
+	ownPreApp := &core.Span{TraceId: "trace1", SpanId: "own-preapp", IsPreAppStart: true}
+	currentTests := []Test{{
+		TraceID: "trace1",
+		Spans:   []*core.Span{{TraceId: "trace1", SpanId: "span1"}, ownPreApp},
+	}}
+	hasSpan := func(spans []*core.Span, spanID string) bool {
+		for _, s := range spans {
+			if s.SpanId == spanID {
+				return true
+			}
+		}
+		return false
+	}
+
+	result, err := BuildSuiteSpansForRun(context.Background(), SuiteSpanOptions{}, currentTests)
+	require.NoError(t, err)
+	assert.True(t, hasSpan(result.SuiteSpans, "other-preapp"), "pre-app-start spans from other traces are loaded by default")
+
+	result, err = BuildSuiteSpansForRun(context.Background(), SuiteSpanOptions{SkipPreAppStart: true}, currentTests)
+	require.NoError(t, err)
+	assert.False(t, hasSpan(result.SuiteSpans, "other-preapp"), "trace files should not be read for pre-app-start spans")
+	assert.True(t, hasSpan(result.SuiteSpans, "own-preapp"))
+	assert.Equal(t, 1, result.PreAppStartCount)
+}
+
 func TestBuildSuiteSpansForRun_UniqueTraceCount(t *testing.T) {
 	t.Parallel()
 
@@ -434,6 +472,49 @@ func TestPrepareAndSetSuiteSpans(t *testing.T) {
 	}
 }
 
+func TestGlobalSpansFile_EnablesCrossTraceMatchInRegularReplayMode(t *testing.T) {
+	config.Invalidate()
+	t.Cleanup(config.Invalidate)
+	cfg, _ := config.Get()
+
+	pkg := "http"
+	sharedInput := map[string]any{"method": "GET", "url": "https://auth.example.com/token"}
+	path := writeTraceFile(t, t.TempDir(), "global.jsonl", map[string]any{
+		"traceId":        "recorded-elsewhere",
+		"spanId":         "global-token",
+		"packageName":    pkg,
+		"inputValue":     sharedInput,
+		"inputValueHash": utils.GenerateDeterministicHash(sharedInput),
+	})
+
+	globalSpans, err := LoadGlobalSpansFile(path)
+	require.NoError(t, err)
+	require.Len(t, globalSpans, 1)
+
+	executor := NewExecutor()
+	require.NoError(t, PrepareAndSetSuiteSpans(context.Background(), executor, SuiteSpanOptions{
+		PreloadedGlobalSpans: globalSpans,
+	}, nil))
+	assert.False(t, executor.allowSuiteWideMatching, "global spans should keep local runs in regular replay mode")
+
+	server, err := NewServer("svc", &cfg.Service)
+	require.NoError(t, err)
+	executor.applySuiteSpans(server)
+	server.LoadSpansForTrace("trace-current", []*core.Span{
+		makeSpan(t, "trace-current", "current-span", pkg, map[string]any{"method": "GET", "url": "https://api.example.com/users"}, nil, 1000),
+	})
+
+	match, level, err := NewMockMatcher(server).FindBestMatchWithTracePriority(makeMockRequest(t, pkg, sharedInput, nil), "trace-current")
+	require.NoError(t, err)
+	require.NotNil(t, match)
+	assert.Equal(t, "global-token", match.SpanId)
+	assert.Equal(t, core.MatchScope_MATCH_SCOPE_GLOBAL, level.MatchScope)
+	assert.Equal(t, "Global unused span by input value hash", level.MatchDescription)
+
+	_, err = LoadGlobalSpansFile(path + ".missing")
+	assert.ErrorContains(t, err, "global spans file not found")
+}
+
 func TestDedupeSpans_PreservesFirstOccurrence(t *testing.T) {
 	t.Parallel()
 
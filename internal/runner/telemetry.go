@@ -0,0 +1,160 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Use-Tusk/tusk-cli/internal/version"
+)
+
+const telemetryTracerName = "github.com/Use-Tusk/tusk-cli/internal/runner"
+
+// SetupTelemetry exports spans of the CLI's own replay execution over OTLP/HTTP
+// when OTEL_EXPORTER_OTLP_ENDPOINT (or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT) is
+// set. The exporter reads the standard OTEL_EXPORTER_OTLP_* variables. When
+// neither is set nothing is installed and spans are no-ops. The returned
+// shutdown flushes pending spans and must be called before exit.
+func SetupTelemetry(ctx context.Context) (shutdown func(context.Context) error, err error) {
+	noop := func(context.Context) error { return nil }
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return noop, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return noop, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName("tusk-cli"),
+		semconv.ServiceVersion(version.Version),
+	))
+	if err != nil {
+		return noop, fmt.Errorf("failed to create telemetry resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// runTelemetry holds the span of the whole run, which test spans are
+// children of. It is shared with environment executors.
+type runTelemetry struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	runSpan trace.Span
+}
+
+func (t *runTelemetry) context() context.Context {
+	if t == nil {
+		return context.Background()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
+// StartRunSpan starts the parent span of a replay run. Test spans started
+// afterwards, by this executor or its environment executors, are its children.
+func (e *Executor) StartRunSpan() {
+	if e.telemetry == nil {
+		return
+	}
+	ctx, span := otel.Tracer(telemetryTracerName).Start(context.Background(), "tusk.drift.run")
+	e.telemetry.mu.Lock()
+	defer e.telemetry.mu.Unlock()
+	e.telemetry.ctx, e.telemetry.runSpan = ctx, span
+}
+
+// EndRunSpan ends the run span with the run's test counts.
+func (e *Executor) EndRunSpan(results []TestResult) {
+	if e.telemetry == nil {
+		return
+	}
+	e.telemetry.mu.Lock()
+	span := e.telemetry.runSpan
+	e.telemetry.ctx, e.telemetry.runSpan = nil, nil
+	e.telemetry.mu.Unlock()
+	if span == nil {
+		return
+	}
+
+	failed := 0
+	for _, r := range results {
+		if !r.Passed {
+			failed++
+		}
+	}
+	span.SetAttributes(
+		attribute.Int("tusk.tests.total", len(results)),
+		attribute.Int("tusk.tests.passed", len(results)-failed),
+		attribute.Int("tusk.tests.failed", failed),
+	)
+	if failed > 0 {
+		span.SetStatus(codes.Error, fmt.Sprintf("%d tests failed", failed))
+	}
+	span.End()
+}
+
+// traceTest runs a test inside a span recording its outcome and how its
+// mocks were matched.
+func (e *Executor) traceTest(test Test, run func(Test) (TestResult, error)) (TestResult, error) {
+	_, span := otel.Tracer(telemetryTracerName).Start(e.telemetry.context(), "tusk.drift.test",
+		trace.WithAttributes(
+			attribute.String("tusk.trace_id", test.TraceID),
+			attribute.String("tusk.test.name", test.DisplayName),
+		))
+	defer span.End()
+
+	result, err := run(test)
+	if !span.IsRecording() {
+		return result, err
+	}
+
+	span.SetAttributes(
+		attribute.Bool("tusk.test.passed", result.Passed),
+		attribute.Int("tusk.test.duration_ms", result.Duration),
+		attribute.Int("tusk.test.deviations", len(result.Deviations)),
+	)
+	if e.server != nil {
+		var stats MatchStatistics
+		stats.Add(e.server.GetMatchEvents(test.TraceID))
+		span.SetAttributes(
+			attribute.Int("tusk.match.pinned", stats.Pinned),
+			attribute.Int("tusk.match.primary_key", stats.PrimaryKey),
+			attribute.Int("tusk.match.value_hash", stats.ValueHash),
+			attribute.Int("tusk.match.reduced_value_hash", stats.ReducedValueHash),
+			attribute.Int("tusk.match.schema_hash", stats.SchemaHash),
+			attribute.Int("tusk.match.reduced_schema_hash", stats.ReducedSchemaHash),
+			attribute.Int("tusk.match.fuzzy", stats.Fuzzy),
+			attribute.Int("tusk.match.fallback", stats.Fallback),
+			attribute.Int("tusk.match.global_fallback", stats.GlobalFallback),
+		)
+	}
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case result.Error != "":
+		span.SetStatus(codes.Error, result.Error)
+	case !result.Passed:
+		span.SetStatus(codes.Error, "deviations detected")
+	}
+	return result, err
+}
@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTraceTestExportsSpanPerTest(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	executor := NewExecutor()
+	executor.StartRunSpan()
+
+	// Environment executors share the run span
+	envExecutor := executor.newEnvironmentExecutor("staging", 1)
+	run := func(test Test) (TestResult, error) {
+		if test.TraceID == "broken" {
+			return TestResult{TestID: test.TraceID}, errors.New("server crashed")
+		}
+		return TestResult{TestID: test.TraceID, Passed: test.TraceID == "ok", Duration: 12}, nil
+	}
+	var results []TestResult
+	for i, id := range []string{"ok", "deviating", "broken"} {
+		exec := executor
+		if i == 2 {
+			exec = envExecutor
+		}
+		result, _ := exec.traceTest(Test{TraceID: id}, run)
+		results = append(results, result)
+	}
+	executor.EndRunSpan(results)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 4, "one span per test plus the run span")
+	runSpan := spans[3]
+	assert.Equal(t, "tusk.drift.run", runSpan.Name)
+	assert.Contains(t, runSpan.Attributes, attribute.Int("tusk.tests.total", 3))
+	assert.Contains(t, runSpan.Attributes, attribute.Int("tusk.tests.failed", 2))
+
+	for i, id := range []string{"ok", "deviating", "broken"} {
+		span := spans[i]
+		assert.Equal(t, "tusk.drift.test", span.Name)
+		assert.Equal(t, runSpan.SpanContext.SpanID(), span.Parent.SpanID(), "test spans are children of the run span")
+		assert.Contains(t, span.Attributes, attribute.String("tusk.trace_id", id))
+	}
+	assert.Contains(t, spans[0].Attributes, attribute.Bool("tusk.test.passed", true))
+	assert.Contains(t, spans[0].Attributes, attribute.Int("tusk.test.duration_ms", 12))
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Equal(t, "server crashed", spans[2].Status.Description)
+}
+
+func TestSetupTelemetryIsNoopWithoutEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	previous := otel.GetTracerProvider()
+
+	shutdown, err := SetupTelemetry(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, shutdown(t.Context()))
+	assert.Equal(t, previous, otel.GetTracerProvider(), "no tracer provider should be installed")
+}
@@ -1,17 +1,60 @@
 package runner
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/Use-Tusk/tusk-cli/internal/log"
 	"github.com/Use-Tusk/tusk-cli/internal/utils"
 	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
-func (e *Executor) LoadTestsFromFolder(folder string) ([]Test, error) {
+// LoadTestsFromFolder loads tests from every .jsonl trace file under each
+// folder. A folder may also be a .tar.gz/.tgz or .zip archive of trace files.
+// A trace found in several folders is loaded from the first one only.
+func (e *Executor) LoadTestsFromFolder(folders ...string) ([]Test, error) {
+	if len(folders) == 1 {
+		return e.loadTestsFromFolder(folders[0])
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("no traces folder given")
+	}
+
+	var tests []Test
+	loadedFrom := make(map[string]string) // trace ID -> folder
+	for i, folder := range folders {
+		if slices.Contains(folders[:i], folder) {
+			continue
+		}
+		folderTests, err := e.loadTestsFromFolder(folder)
+		if err != nil {
+			return nil, err
+		}
+		for _, test := range folderTests {
+			if first, ok := loadedFrom[test.TraceID]; ok && first != folder {
+				log.Debug("Skipping trace already loaded from another traces folder", "traceId", test.TraceID, "folder", folder, "loadedFrom", first)
+				continue
+			}
+			loadedFrom[test.TraceID] = folder
+			tests = append(tests, test)
+		}
+	}
+	return tests, nil
+}
+
+func (e *Executor) loadTestsFromFolder(folder string) ([]Test, error) {
+	if IsTraceArchive(folder) {
+		return e.LoadTestsFromArchive(folder)
+	}
+
 	var tests []Test
 
 	err := filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
@@ -48,8 +91,81 @@ func (e *Executor) LoadTestFromTraceFile(path string) (*Test, error) {
 		return nil, err
 	}
 
-	filename := filepath.Base(path)
+	return testFromSpans(spans, filepath.Base(path)), nil
+}
+
+// LoadTestsByTraceIDs loads the test of each trace ID, finding its file with
+// utils.FindTraceFile. IDs without a trace file are returned as missing, and a
+// warning is printed for each, rather than failing the load.
+func (e *Executor) LoadTestsByTraceIDs(traceIDs []string) (tests []Test, missing []string, err error) {
+	for _, traceID := range traceIDs {
+		path, err := utils.FindTraceFile(traceID, "")
+		if errors.Is(err, utils.ErrTraceFileNotFound) {
+			log.UserWarn(fmt.Sprintf("⚠️  No trace file found for trace ID %s", traceID))
+			missing = append(missing, traceID)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		test, err := e.LoadTestFromTraceFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if test == nil {
+			log.UserWarn(fmt.Sprintf("⚠️  Trace %s has no root span to replay (%s)", traceID, path))
+			missing = append(missing, traceID)
+			continue
+		}
+		tests = append(tests, *test)
+	}
+	return tests, missing, nil
+}
+
+// ReadTraceIDFile reads newline-separated trace IDs. Blank lines and lines
+// starting with # are skipped, as are repeated IDs.
+func ReadTraceIDFile(path string) ([]string, error) {
+	f, err := os.Open(path) // #nosec G304 -- user-provided --trace-id-file
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace ID file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var traceIDs []string
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, ok := seen[line]; ok {
+			continue
+		}
+		seen[line] = struct{}{}
+		traceIDs = append(traceIDs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trace ID file: %w", err)
+	}
+	return traceIDs, nil
+}
+
+// LoadTestFromReader loads a single test from JSONL spans read from r, e.g.
+// a trace piped on stdin. name stands in for the trace file name.
+func (e *Executor) LoadTestFromReader(r io.Reader, name string) (*Test, error) {
+	spans, err := utils.ParseSpansFromReader(r, name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return testFromSpans(spans, name), nil
+}
 
+// testFromSpans builds a test from the spans of one trace file, or returns nil
+// when the file has no root span.
+func testFromSpans(spans []*core.Span, filename string) *Test {
 	// Find the root span
 	var rootSpan *core.Span
 	for _, span := range spans {
@@ -61,13 +177,13 @@ func (e *Executor) LoadTestFromTraceFile(path string) (*Test, error) {
 
 	// No root span means no test
 	if rootSpan == nil {
-		return nil, nil
+		return nil
 	}
 
 	test := spanToTest(rootSpan, filename)
 	test.Spans = spans // All spans belong to the same trace
 
-	return &test, nil
+	return &test
 }
 
 func (e *Executor) LoadSpansForTrace(traceID string, filename string) ([]*core.Span, error) {
@@ -195,17 +311,27 @@ func spanToTest(span *core.Span, filename string) Test {
 			Body:    extractBody(span),
 		},
 		Response: Response{
-			Status: httpStatus,
-			Body:   responseBody,
+			Status:  httpStatus,
+			Headers: extractResponseHeaders(span),
+			Body:    responseBody,
 		},
 	}
 }
 
 // extractHeaders extracts HTTP headers from span input data
 func extractHeaders(span *core.Span) map[string]string {
+	return headersFromValue(span, span.InputValue)
+}
+
+// extractResponseHeaders extracts HTTP headers from span output data
+func extractResponseHeaders(span *core.Span) map[string]string {
+	return headersFromValue(span, span.OutputValue)
+}
+
+func headersFromValue(span *core.Span, value *structpb.Struct) map[string]string {
 	headers := make(map[string]string)
-	if (span.GetPackageType() == core.PackageType_PACKAGE_TYPE_HTTP || span.GetPackageType() == core.PackageType_PACKAGE_TYPE_GRAPHQL) && span.InputValue != nil {
-		if headersField, exists := span.InputValue.Fields["headers"]; exists {
+	if (span.GetPackageType() == core.PackageType_PACKAGE_TYPE_HTTP || span.GetPackageType() == core.PackageType_PACKAGE_TYPE_GRAPHQL) && value != nil {
+		if headersField, exists := value.Fields["headers"]; exists {
 			if headersStruct := headersField.GetStructValue(); headersStruct != nil {
 				for key, value := range headersStruct.Fields {
 					if strValue := value.GetStringValue(); strValue != "" {
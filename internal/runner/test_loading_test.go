@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Use-Tusk/tusk-cli/internal/log"
 	"github.com/Use-Tusk/tusk-cli/internal/utils"
 	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
 	"github.com/stretchr/testify/assert"
@@ -179,6 +181,66 @@ func TestExecutorLoadTestsFromFolderReadsJSONLTraces(t *testing.T) {
 	require.Len(t, traceTwoTest.Spans, 1)
 }
 
+func TestExecutorLoadTestsFromFolderUnionsFolders(t *testing.T) {
+	executor := &Executor{}
+	root := func(traceID string) map[string]any {
+		return map[string]any{"traceId": traceID, "spanId": "root-" + traceID, "name": "GET /" + traceID, "isRootSpan": true}
+	}
+
+	first := t.TempDir()
+	second := t.TempDir()
+	writeTraceFile(t, first, "trace-shared.jsonl", root("shared"))
+	writeTraceFile(t, first, "trace-a.jsonl", root("a"))
+	writeTraceFile(t, second, "trace-shared.jsonl", root("shared"))
+	writeTraceFile(t, second, "trace-b.jsonl", root("b"))
+
+	tests, err := executor.LoadTestsFromFolder(first, second)
+	require.NoError(t, err)
+
+	var traceIDs []string
+	for _, test := range tests {
+		traceIDs = append(traceIDs, test.TraceID)
+	}
+	assert.ElementsMatch(t, []string{"shared", "a", "b"}, traceIDs, "the overlapping trace is loaded once")
+
+	_, err = executor.LoadTestsFromFolder(first, filepath.Join(second, "missing"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "traces folder not found")
+}
+
+func TestExecutorLoadTestsByTraceIDFile(t *testing.T) {
+	executor := &Executor{}
+	root := func(traceID string) map[string]any {
+		return map[string]any{"traceId": traceID, "spanId": "root-" + traceID, "name": "GET /" + traceID, "isRootSpan": true}
+	}
+
+	dir := t.TempDir()
+	utils.SetTracesDirOverride(dir)
+	t.Cleanup(func() { utils.SetTracesDirOverride("") })
+	writeTraceFile(t, dir, "trace-aaa.jsonl", root("aaa"))
+	writeTraceFile(t, dir, "trace-bbb.jsonl", root("bbb"))
+	writeTraceFile(t, dir, "trace-unlisted.jsonl", root("unlisted"))
+
+	idFile := filepath.Join(t.TempDir(), "important-traces.txt")
+	require.NoError(t, os.WriteFile(idFile, []byte("# curated by QA\naaa\n\nmissing\nbbb\naaa\n"), 0o600))
+	traceIDs, err := ReadTraceIDFile(idFile)
+	require.NoError(t, err)
+	require.Equal(t, []string{"aaa", "missing", "bbb"}, traceIDs)
+
+	var out bytes.Buffer
+	log.SetUserOutput(&out)
+	t.Cleanup(func() { log.SetUserOutput(nil) })
+
+	tests, missing, err := executor.LoadTestsByTraceIDs(traceIDs)
+	require.NoError(t, err)
+	require.Len(t, tests, 2)
+	assert.Equal(t, "aaa", tests[0].TraceID)
+	assert.Equal(t, "bbb", tests[1].TraceID)
+	assert.Equal(t, []string{"missing"}, missing)
+	assert.Equal(t, 1, bytes.Count(out.Bytes(), []byte("No trace file found")), "one warning per unresolved ID")
+	assert.Contains(t, out.String(), "missing")
+}
+
 func TestExecutorLoadTestsFromFolderMissing(t *testing.T) {
 	executor := &Executor{}
 	missing := filepath.Join(t.TempDir(), "does-not-exist")
@@ -269,3 +331,27 @@ func TestPackageTypeToString(t *testing.T) {
 		assert.Equal(t, expected, packageTypeToString(pkgType))
 	}
 }
+
+func TestExecutorLoadTestFromReader(t *testing.T) {
+	var input bytes.Buffer
+	for _, span := range []map[string]any{
+		httpRootSpan("piped", "GET", "/users/1"),
+		{"traceId": "piped", "spanId": "db", "name": "pg.query", "packageName": "pg"},
+	} {
+		line, err := json.Marshal(span)
+		require.NoError(t, err)
+		input.Write(append(line, '\n'))
+	}
+
+	executor := &Executor{}
+	test, err := executor.LoadTestFromReader(&input, "stdin")
+	require.NoError(t, err)
+	require.NotNil(t, test)
+	assert.Equal(t, "piped", test.TraceID)
+	assert.Equal(t, "stdin", test.FileName)
+	assert.Equal(t, "/users/1", test.Path)
+	assert.Len(t, test.Spans, 2)
+
+	_, err = executor.LoadTestFromReader(bytes.NewBufferString("not json\n"), "stdin")
+	require.ErrorContains(t, err, "malformed span in stdin at line 1")
+}
@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// PlannedTest is a test a run would execute, as printed by --list.
+type PlannedTest struct {
+	TraceID     string `json:"traceId"`
+	Name        string `json:"name,omitempty"`
+	Method      string `json:"method,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Environment string `json:"environment"`
+}
+
+// PlanTests flattens environment groups into the tests they would run,
+// ordered by environment name and then by test order within each group.
+func PlanTests(groups []*EnvironmentGroup) []PlannedTest {
+	sorted := make([]*EnvironmentGroup, len(groups))
+	copy(sorted, groups)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var plan []PlannedTest
+	for _, group := range sorted {
+		for _, t := range group.Tests {
+			plan = append(plan, PlannedTest{
+				TraceID:     t.TraceID,
+				Name:        t.DisplayName,
+				Method:      t.Method,
+				Path:        t.Path,
+				Environment: group.Name,
+			})
+		}
+	}
+	return plan
+}
+
+// OutputTestPlan writes plan to w as a table, or as JSON when format is "json".
+func OutputTestPlan(w io.Writer, plan []PlannedTest, format string) error {
+	if format == "json" {
+		output := struct {
+			Count int           `json:"count"`
+			Tests []PlannedTest `json:"tests"`
+		}{
+			Count: len(plan),
+			Tests: plan,
+		}
+		if output.Tests == nil {
+			output.Tests = []PlannedTest{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(output)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TRACE ID\tMETHOD\tPATH\tENVIRONMENT")
+	for _, t := range plan {
+		method, path := t.Method, t.Path
+		if method == "" {
+			method = "-"
+		}
+		if path == "" {
+			// Non-HTTP tests have no path; show their name instead
+			path = t.Name
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", t.TraceID, method, path, t.Environment)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d tests\n", len(plan))
+	return err
+}
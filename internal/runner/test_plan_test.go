@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanTestsListsFilteredTestsByEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	withEnv := func(span map[string]any, env string) map[string]any {
+		span["environment"] = env
+		return span
+	}
+	writeTraceFile(t, dir, "get-user.jsonl", withEnv(httpRootSpan("get-user", "GET", "/users/1"), "staging"))
+	writeTraceFile(t, dir, "create-user.jsonl", httpRootSpan("create-user", "POST", "/users"))
+	writeTraceFile(t, dir, "health.jsonl", httpRootSpan("health", "GET", "/health"))
+
+	executor := NewExecutor()
+	tests, err := executor.LoadTestsFromFolder(dir)
+	require.NoError(t, err)
+	tests, err = FilterTests(tests, "path=^/users")
+	require.NoError(t, err)
+	groups, err := GroupTestsByEnvironment(tests, nil)
+	require.NoError(t, err)
+
+	plan := PlanTests(groups.Groups)
+	assert.Equal(t, []PlannedTest{
+		{TraceID: "create-user", Name: "POST /users", Method: "POST", Path: "/users", Environment: "default"},
+		{TraceID: "get-user", Name: "GET /users/1", Method: "GET", Path: "/users/1", Environment: "staging"},
+	}, plan)
+
+	var out bytes.Buffer
+	require.NoError(t, OutputTestPlan(&out, plan, "json"))
+	var decoded struct {
+		Count int           `json:"count"`
+		Tests []PlannedTest `json:"tests"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, 2, decoded.Count)
+	assert.Equal(t, plan, decoded.Tests)
+
+	out.Reset()
+	require.NoError(t, OutputTestPlan(&out, plan, "text"))
+	assert.Contains(t, out.String(), "TRACE ID")
+	assert.Regexp(t, `get-user\s+GET\s+/users/1\s+staging`, out.String())
+	assert.NotContains(t, out.String(), "/health")
+	assert.Contains(t, out.String(), "2 tests")
+}
+
+func TestOutputTestPlanEmptyJSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, OutputTestPlan(&out, nil, "json"))
+	assert.JSONEq(t, `{"count": 0, "tests": []}`, out.String())
+}
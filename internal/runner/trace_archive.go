@@ -0,0 +1,125 @@
+package runner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/Use-Tusk/tusk-cli/internal/log"
+	"github.com/Use-Tusk/tusk-cli/internal/utils"
+)
+
+// IsTraceArchive reports whether p names a supported archive of trace files
+// rather than a directory.
+func IsTraceArchive(p string) bool {
+	lower := strings.ToLower(p)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz") || strings.HasSuffix(lower, ".zip")
+}
+
+// LoadTestsFromArchive loads tests from the .jsonl entries of a .tar.gz/.tgz
+// or .zip archive. Entries are parsed in memory; nothing is extracted to disk,
+// so every test comes with its spans already loaded.
+func (e *Executor) LoadTestsFromArchive(archivePath string) ([]Test, error) {
+	if strings.HasSuffix(strings.ToLower(archivePath), ".zip") {
+		return loadTestsFromZip(archivePath)
+	}
+
+	f, err := os.Open(archivePath) // #nosec G304
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Test{}, fmt.Errorf("traces archive not found: %s", archivePath)
+		}
+		return nil, err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Warn("Failed to close file", "error", err, "filename", archivePath)
+		}
+	}()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", archivePath, err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	return loadTestsFromTar(gz, archivePath)
+}
+
+// loadTestsFromTar loads tests from an uncompressed tar stream.
+func loadTestsFromTar(r io.Reader, archiveName string) ([]Test, error) {
+	var tests []Test
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return tests, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", archiveName, err)
+		}
+		if hdr.Typeflag != tar.TypeReg || !isTraceEntry(hdr.Name) {
+			continue
+		}
+
+		test, err := loadTestFromArchiveEntry(tr, archiveName, hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		if test != nil {
+			tests = append(tests, *test)
+		}
+	}
+}
+
+func loadTestsFromZip(archivePath string) ([]Test, error) {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Test{}, fmt.Errorf("traces archive not found: %s", archivePath)
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", archivePath, err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	var tests []Test
+	for _, entry := range zr.File {
+		if entry.FileInfo().IsDir() || !isTraceEntry(entry.Name) {
+			continue
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s in %s: %w", entry.Name, archivePath, err)
+		}
+		test, err := loadTestFromArchiveEntry(rc, archivePath, entry.Name)
+		_ = rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		if test != nil {
+			tests = append(tests, *test)
+		}
+	}
+	return tests, nil
+}
+
+func loadTestFromArchiveEntry(r io.Reader, archiveName, entryName string) (*Test, error) {
+	spans, err := utils.ParseSpansFromReader(r, archiveName+":"+entryName, nil)
+	if err != nil {
+		return nil, err
+	}
+	return testFromSpans(spans, path.Base(entryName)), nil
+}
+
+// isTraceEntry skips non-trace files and macOS resource forks ("._name").
+func isTraceEntry(name string) bool {
+	base := path.Base(name)
+	return strings.HasSuffix(base, ".jsonl") && !strings.HasPrefix(base, "._")
+}
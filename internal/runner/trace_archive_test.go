@@ -0,0 +1,119 @@
+package runner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func traceFileContents(t *testing.T, traceID string) []byte {
+	t.Helper()
+
+	var data []byte
+	for _, span := range []map[string]any{
+		{"traceId": traceID, "spanId": traceID + "-root", "name": "GET /users", "packageName": "http", "isRootSpan": true},
+		{"traceId": traceID, "spanId": traceID + "-child", "name": "pg.query", "packageName": "pg"},
+	} {
+		line, err := json.Marshal(span)
+		require.NoError(t, err)
+		data = append(data, line...)
+		data = append(data, '\n')
+	}
+	return data
+}
+
+func archiveEntries(t *testing.T) map[string][]byte {
+	return map[string][]byte{
+		"traces/trace-a.jsonl":   traceFileContents(t, "trace-a"),
+		"traces/trace-b.jsonl":   traceFileContents(t, "trace-b"),
+		"traces/README.md":       []byte("not a trace"),
+		"traces/._trace-a.jsonl": []byte("\x00\x05\x16\x07"),
+	}
+}
+
+func sortedTraceIDs(tests []Test) []string {
+	var ids []string
+	for _, test := range tests {
+		ids = append(ids, test.TraceID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func writeTarGz(t *testing.T, entries map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "traces/", Typeflag: tar.TypeDir, Mode: 0o750}))
+	for name, data := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o600, Size: int64(len(data))}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestLoadTestsFromTar_InMemory(t *testing.T) {
+	gzData := writeTarGz(t, archiveEntries(t))
+	gz, err := gzip.NewReader(bytes.NewReader(gzData))
+	require.NoError(t, err)
+
+	tests, err := loadTestsFromTar(gz, "traces.tar.gz")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"trace-a", "trace-b"}, sortedTraceIDs(tests))
+	for _, test := range tests {
+		assert.Len(t, test.Spans, 2, "spans are loaded with the test")
+		assert.Equal(t, test.TraceID+".jsonl", test.FileName)
+	}
+}
+
+func TestLoadTestsFromFolder_DetectsArchives(t *testing.T) {
+	dir := t.TempDir()
+	executor := &Executor{}
+
+	tarPath := filepath.Join(dir, "traces.tgz")
+	require.NoError(t, os.WriteFile(tarPath, writeTarGz(t, archiveEntries(t)), 0o600))
+
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	for name, data := range archiveEntries(t) {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	zipPath := filepath.Join(dir, "traces.zip")
+	require.NoError(t, os.WriteFile(zipPath, zipBuf.Bytes(), 0o600))
+
+	for _, p := range []string{tarPath, zipPath} {
+		tests, err := executor.LoadTestsFromFolder(p)
+		require.NoError(t, err, p)
+		assert.Equal(t, []string{"trace-a", "trace-b"}, sortedTraceIDs(tests), p)
+	}
+
+	_, err := executor.LoadTestsFromFolder(filepath.Join(dir, "missing.tar.gz"))
+	assert.ErrorContains(t, err, "traces archive not found")
+}
+
+func TestLoadTestsFromTar_MalformedEntry(t *testing.T) {
+	gzData := writeTarGz(t, map[string][]byte{"bad.jsonl": []byte("{not json\n")})
+	gz, err := gzip.NewReader(bytes.NewReader(gzData))
+	require.NoError(t, err)
+
+	_, err = loadTestsFromTar(gz, "traces.tar.gz")
+	assert.ErrorContains(t, err, "traces.tar.gz:bad.jsonl")
+}
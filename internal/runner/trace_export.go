@@ -0,0 +1,165 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+
+	"github.com/Use-Tusk/tusk-cli/internal/utils"
+)
+
+const (
+	// Matches the *trace*.jsonl files FetchLocalPreAppStartSpans reads
+	bundlePreAppStartFile = "pre_app_start_traces.jsonl"
+	bundleGlobalSpansFile = "global_spans.jsonl"
+)
+
+// TraceBundle describes the files ExportTraceBundle wrote.
+type TraceBundle struct {
+	Dir       string `json:"dir"`
+	TraceFile string `json:"traceFile"`
+	Spans     int    `json:"spans"`
+	// PreAppStartFile holds pre-app-start spans of other traces; empty when
+	// there are none
+	PreAppStartFile  string `json:"preAppStartFile,omitempty"`
+	PreAppStartSpans int    `json:"preAppStartSpans"`
+	// GlobalSpansFile is a copy of the global spans file, to replay with
+	// --global-spans-file; empty when none was given
+	GlobalSpansFile string `json:"globalSpansFile,omitempty"`
+}
+
+// ExportTraceBundle writes test's spans, and the spans of other traces its
+// replay can be mocked with, into outDir as trace files. The directory
+// replays on its own with --trace-dir, so it can be attached to a bug
+// report. Other traces' spans are those BuildSuiteSpansForRun loads for the
+// test from the traces directories, i.e. their pre-app-start spans. Global
+// spans only come from a file in local runs, so globalSpansFile, when set,
+// is copied as is.
+func ExportTraceBundle(ctx context.Context, test Test, outDir string, globalSpansFile string) (*TraceBundle, error) {
+	tracePath, err := utils.FindTraceFile(test.TraceID, test.FileName)
+	if err != nil {
+		return nil, err
+	}
+	suite, err := BuildSuiteSpansForRun(ctx, SuiteSpanOptions{Quiet: true}, []Test{test})
+	if err != nil {
+		return nil, err
+	}
+	others := make(map[string]struct{})
+	for _, s := range suite.SuiteSpans {
+		if s.TraceId != test.TraceID {
+			others[s.TraceId+"|"+s.SpanId] = struct{}{}
+		}
+	}
+
+	if err := os.MkdirAll(outDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", outDir, err)
+	}
+	bundle := &TraceBundle{Dir: outDir, TraceFile: filepath.Join(outDir, filepath.Base(tracePath))}
+	if sameFile(bundle.TraceFile, tracePath) {
+		return nil, fmt.Errorf("%s is the traces directory of trace %s; export to another directory", outDir, test.TraceID)
+	}
+
+	bundle.Spans, err = writeSpanLines(bundle.TraceFile, []string{tracePath}, func(s *core.Span) bool {
+		return s.TraceId == test.TraceID
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(others) > 0 {
+		var sources []string
+		for _, dir := range utils.GetPossibleTraceDirs() {
+			matches, _ := filepath.Glob(filepath.Join(dir, "*trace*.jsonl"))
+			sources = append(sources, matches...)
+		}
+		bundle.PreAppStartFile = filepath.Join(outDir, bundlePreAppStartFile)
+		bundle.PreAppStartSpans, err = writeSpanLines(bundle.PreAppStartFile, sources, func(s *core.Span) bool {
+			key := s.TraceId + "|" + s.SpanId
+			if _, ok := others[key]; !ok {
+				return false
+			}
+			// Spans found in several files are written once
+			delete(others, key)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if globalSpansFile != "" {
+		bundle.GlobalSpansFile = filepath.Join(outDir, bundleGlobalSpansFile)
+		if err := copyFile(globalSpansFile, bundle.GlobalSpansFile); err != nil {
+			return nil, err
+		}
+	}
+	return bundle, nil
+}
+
+// writeSpanLines writes the lines of the source trace files whose span keep
+// accepts to path, unchanged, and returns how many it wrote. Lines are copied
+// rather than re-encoded so the bundle replays exactly as the originals do.
+func writeSpanLines(path string, sources []string, keep func(*core.Span) bool) (int, error) {
+	out, err := os.Create(path) // #nosec G304
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer func() { _ = out.Close() }()
+
+	written := 0
+	for _, source := range sources {
+		if sameFile(source, path) {
+			continue
+		}
+		in, err := os.Open(source) // #nosec G304
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 0, 64*1024), 15*1024*1024) // As in utils.ParseSpansFromReader
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			span, err := utils.ParseProtobufSpanFromJSON([]byte(line))
+			if err != nil || !keep(span) {
+				continue
+			}
+			if _, err := io.WriteString(out, line+"\n"); err != nil {
+				_ = in.Close()
+				return 0, fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			written++
+		}
+		err = scanner.Err()
+		_ = in.Close()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", source, err)
+		}
+	}
+	return written, out.Close()
+}
+
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src) // #nosec G304
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	if err := os.WriteFile(dst, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+	return nil
+}
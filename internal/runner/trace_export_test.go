@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Use-Tusk/tusk-cli/internal/utils"
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+)
+
+func TestExportTraceBundle_RoundTripsThroughLoadTestsFromFolder(t *testing.T) {
+	dir := t.TempDir()
+	writeTraceFile(t, dir, "trace-a.jsonl",
+		map[string]any{"traceId": "trace-a", "spanId": "a-preapp", "isPreAppStart": true},
+		map[string]any{"traceId": "trace-a", "spanId": "a-outbound", "packageName": "pg"},
+		map[string]any{"traceId": "trace-a", "spanId": "a-root", "isRootSpan": true, "packageName": "http",
+			"inputValue": map[string]any{"method": "GET", "target": "/users/1"}},
+	)
+	writeTraceFile(t, dir, "trace-b.jsonl",
+		map[string]any{"traceId": "trace-b", "spanId": "b-preapp", "isPreAppStart": true},
+		map[string]any{"traceId": "trace-b", "spanId": "b-outbound", "packageName": "pg"},
+		map[string]any{"traceId": "trace-b", "spanId": "b-root", "isRootSpan": true, "packageName": "http",
+			"inputValue": map[string]any{"method": "GET", "target": "/users/2"}},
+	)
+	globalSpansFile := writeTraceFile(t, t.TempDir(), "global.jsonl",
+		map[string]any{"traceId": "recorded-elsewhere", "spanId": "global-token"})
+	utils.SetTracesDirOverride(dir)
+	t.Cleanup(func() { utils.SetTracesDirOverride("") })
+
+	executor := NewExecutor()
+	tests, err := executor.LoadTestsFromFolder(dir)
+	require.NoError(t, err)
+	var test Test
+	for _, tt := range tests {
+		if tt.TraceID == "trace-a" {
+			test = tt
+		}
+	}
+	require.Equal(t, "trace-a", test.TraceID)
+
+	out := filepath.Join(t.TempDir(), "bundle")
+	bundle, err := ExportTraceBundle(context.Background(), test, out, globalSpansFile)
+	require.NoError(t, err)
+	assert.Equal(t, 3, bundle.Spans)
+	assert.Equal(t, 1, bundle.PreAppStartSpans)
+
+	// The bundle loads as the one test, with all of its spans
+	bundleTests, err := executor.LoadTestsFromFolder(out)
+	require.NoError(t, err)
+	require.Len(t, bundleTests, 1)
+	assert.Equal(t, "trace-a", bundleTests[0].TraceID)
+	assert.Equal(t, test.Request, bundleTests[0].Request)
+	assert.Len(t, bundleTests[0].Spans, 3)
+
+	// Only the other trace's pre-app-start span comes along
+	others, err := utils.ParseSpansFromFile(bundle.PreAppStartFile, nil)
+	require.NoError(t, err)
+	require.Len(t, others, 1)
+	assert.Equal(t, "b-preapp", others[0].SpanId)
+
+	globals, err := LoadGlobalSpansFile(bundle.GlobalSpansFile)
+	require.NoError(t, err)
+	require.Len(t, globals, 1)
+
+	// Replaying from the bundle prepares the same suite spans
+	utils.SetTracesDirOverride(out)
+	suite, err := BuildSuiteSpansForRun(context.Background(), SuiteSpanOptions{}, bundleTests)
+	require.NoError(t, err)
+	var spanIDs []string
+	for _, s := range suite.SuiteSpans {
+		spanIDs = append(spanIDs, s.SpanId)
+	}
+	assert.ElementsMatch(t, []string{"a-preapp", "a-outbound", "a-root", "b-preapp"}, spanIDs)
+}
+
+func TestExportTraceBundle_RefusesTracesDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeTraceFile(t, dir, "trace-a.jsonl",
+		map[string]any{"traceId": "trace-a", "spanId": "a-root", "isRootSpan": true})
+	utils.SetTracesDirOverride(dir)
+	t.Cleanup(func() { utils.SetTracesDirOverride("") })
+
+	_, err := ExportTraceBundle(context.Background(), Test{TraceID: "trace-a", FileName: "trace-a.jsonl", Spans: []*core.Span{{TraceId: "trace-a"}}}, dir, "")
+	require.ErrorContains(t, err, "export to another directory")
+
+	data, err := os.ReadFile(filepath.Join(dir, "trace-a.jsonl"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "a-root", "the trace file must be left intact")
+}
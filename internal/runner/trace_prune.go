@@ -0,0 +1,185 @@
+package runner
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Use-Tusk/tusk-cli/internal/utils"
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// routeParamPattern matches path parameters written as :name or {name}.
+var routeParamPattern = regexp.MustCompile(`^(:[^/]+|\{[^/}]+\})$`)
+
+// Route is an API route that recorded traces are checked against.
+type Route struct {
+	// Method is the upper-case HTTP method, or empty to match any method
+	Method string
+	// pattern is the path as a doublestar pattern
+	pattern string
+	raw     string
+}
+
+func (r Route) String() string {
+	return r.raw
+}
+
+// ParseRoute parses "[METHOD] /path". Path segments may be :name or {name}
+// parameters, * for any single segment or ** for any number of segments.
+func ParseRoute(s string) (Route, error) {
+	raw := strings.TrimSpace(s)
+	fields := strings.Fields(raw)
+	var method, path string
+	switch len(fields) {
+	case 1:
+		path = fields[0]
+	case 2:
+		method, path = strings.ToUpper(fields[0]), fields[1]
+	default:
+		return Route{}, fmt.Errorf("invalid route %q: expected \"[METHOD] /path\"", s)
+	}
+	if !strings.HasPrefix(path, "/") {
+		return Route{}, fmt.Errorf("invalid route %q: path must start with /", s)
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if routeParamPattern.MatchString(segment) {
+			segments[i] = "*"
+		}
+	}
+	pattern := strings.Join(segments, "/")
+	if !doublestar.ValidatePattern(pattern) {
+		return Route{}, fmt.Errorf("invalid route %q: bad path pattern", s)
+	}
+	return Route{Method: method, pattern: pattern, raw: raw}, nil
+}
+
+// ParseRoutesFile reads one route per line. Blank lines and lines starting
+// with # are ignored.
+func ParseRoutesFile(path string) ([]Route, error) {
+	data, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routes file: %w", err)
+	}
+	var routes []Route
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		route, err := ParseRoute(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, i+1, err)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// Matches reports whether a request to method and target (a path with an
+// optional query string) is served by the route.
+func (r Route) Matches(method, target string) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	path := target
+	if u, err := url.ParseRequestURI(target); err == nil {
+		path = u.Path
+	}
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	matched, _ := doublestar.Match(r.pattern, path)
+	return matched
+}
+
+// StaleTrace is a trace file whose root request matches none of the routes.
+type StaleTrace struct {
+	FilePath string `json:"filePath"`
+	TraceID  string `json:"traceId"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+}
+
+// FindStaleTraces returns the trace files under tracesDir whose HTTP root span
+// matches none of routes. Files whose root span is not an HTTP request, or
+// that cannot be parsed, are never reported.
+func FindStaleTraces(tracesDir string, routes []Route) ([]StaleTrace, error) {
+	var stale []StaleTrace
+	err := filepath.WalkDir(tracesDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".jsonl") {
+			return nil
+		}
+
+		spans, err := utils.ParseSpansFromFile(path, func(s *core.Span) bool { return s.IsRootSpan })
+		if err != nil || len(spans) == 0 {
+			return nil
+		}
+		root := spans[0]
+		if root.GetPackageType() != core.PackageType_PACKAGE_TYPE_HTTP {
+			return nil
+		}
+		test := spanToTest(root, filepath.Base(path))
+		if test.Path == "" {
+			return nil
+		}
+
+		for _, route := range routes {
+			if route.Matches(test.Method, test.Path) {
+				return nil
+			}
+		}
+		stale = append(stale, StaleTrace{
+			FilePath: path,
+			TraceID:  test.TraceID,
+			Method:   test.Method,
+			Path:     test.Path,
+		})
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("traces folder not found: %s", tracesDir)
+		}
+		return nil, err
+	}
+
+	sort.Slice(stale, func(i, j int) bool { return stale[i].FilePath < stale[j].FilePath })
+	return stale, nil
+}
+
+// RemoveTraceFiles deletes the given trace files. It refuses to delete
+// anything that is not a .jsonl file inside tracesDir.
+func RemoveTraceFiles(tracesDir string, files []string) error {
+	root, err := filepath.Abs(tracesDir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+			return fmt.Errorf("refusing to delete %s: not inside traces folder %s", file, tracesDir)
+		}
+		if !strings.HasSuffix(abs, ".jsonl") {
+			return fmt.Errorf("refusing to delete %s: not a trace file", file)
+		}
+		if err := os.Remove(abs); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", file, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func httpRootSpan(traceID, method, target string) map[string]any {
+	return map[string]any{
+		"traceId":     traceID,
+		"spanId":      traceID + "-root",
+		"name":        method + " " + target,
+		"packageName": "http",
+		"isRootSpan":  true,
+		"packageType": int(core.PackageType_PACKAGE_TYPE_HTTP),
+		"inputValue": map[string]any{
+			"method": method,
+			"target": target,
+		},
+	}
+}
+
+func mustParseRoutes(t *testing.T, specs ...string) []Route {
+	t.Helper()
+	routes := make([]Route, 0, len(specs))
+	for _, spec := range specs {
+		route, err := ParseRoute(spec)
+		require.NoError(t, err)
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+func TestRouteMatches(t *testing.T) {
+	tests := []struct {
+		route  string
+		method string
+		target string
+		want   bool
+	}{
+		{route: "GET /users/:id", method: "GET", target: "/users/42", want: true},
+		{route: "GET /users/{id}", method: "GET", target: "/users/42?expand=true", want: true},
+		{route: "get /users/:id", method: "GET", target: "/users/42/", want: true},
+		{route: "GET /users/:id", method: "POST", target: "/users/42", want: false},
+		{route: "GET /users/:id", method: "GET", target: "/users/42/posts", want: false},
+		{route: "/health", method: "HEAD", target: "/health", want: true},
+		{route: "/admin/**", method: "DELETE", target: "/admin/users/1/roles", want: true},
+		{route: "/files/*/raw", method: "GET", target: "/files/a.txt/raw", want: true},
+		{route: "/", method: "GET", target: "/", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.route+" "+tt.method+" "+tt.target, func(t *testing.T) {
+			route, err := ParseRoute(tt.route)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, route.Matches(tt.method, tt.target))
+		})
+	}
+}
+
+func TestParseRouteRejectsInvalidRoutes(t *testing.T) {
+	for _, spec := range []string{"", "users/:id", "GET /users extra", "GET /users/[id"} {
+		_, err := ParseRoute(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestParseRoutesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# public API\nGET /users/:id\n\n  POST /users  \n"), 0o600))
+
+	routes, err := ParseRoutesFile(path)
+	require.NoError(t, err)
+	require.Len(t, routes, 2)
+	assert.Equal(t, "GET /users/:id", routes[0].String())
+	assert.Equal(t, "POST", routes[1].Method)
+
+	require.NoError(t, os.WriteFile(path, []byte("GET /users\nusers\n"), 0o600))
+	_, err = ParseRoutesFile(path)
+	assert.ErrorContains(t, err, "routes.txt:2")
+}
+
+func TestFindStaleTraces(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "2024-01-01")
+	require.NoError(t, os.MkdirAll(nested, 0o750))
+
+	writeTraceFile(t, dir, "current.jsonl", httpRootSpan("current", "GET", "/users/1"))
+	writeTraceFile(t, nested, "removed.jsonl", httpRootSpan("removed", "GET", "/legacy/report"))
+	writeTraceFile(t, dir, "wrong-method.jsonl", httpRootSpan("wrong-method", "DELETE", "/users/1"))
+	writeTraceFile(t, dir, "grpc.jsonl", map[string]any{
+		"traceId": "grpc", "spanId": "grpc-root", "packageName": "grpc", "isRootSpan": true,
+		"inputValue": map[string]any{"target": "/grpc/users"},
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.jsonl"), []byte("{not json\n"), 0o600))
+
+	stale, err := FindStaleTraces(dir, mustParseRoutes(t, "GET /users/:id"))
+	require.NoError(t, err)
+
+	require.Len(t, stale, 2)
+	assert.Equal(t, StaleTrace{FilePath: filepath.Join(nested, "removed.jsonl"), TraceID: "removed", Method: "GET", Path: "/legacy/report"}, stale[0])
+	assert.Equal(t, "wrong-method", stale[1].TraceID)
+
+	_, err = FindStaleTraces(filepath.Join(dir, "missing"), nil)
+	assert.ErrorContains(t, err, "traces folder not found")
+}
+
+func TestRemoveTraceFiles(t *testing.T) {
+	dir := t.TempDir()
+	tracesDir := filepath.Join(dir, "traces")
+	require.NoError(t, os.MkdirAll(tracesDir, 0o750))
+	inside := filepath.Join(tracesDir, "stale.jsonl")
+	outside := filepath.Join(dir, "outside.jsonl")
+	notTrace := filepath.Join(tracesDir, "notes.txt")
+	for _, p := range []string{inside, outside, notTrace} {
+		require.NoError(t, os.WriteFile(p, []byte("{}\n"), 0o600))
+	}
+
+	err := RemoveTraceFiles(tracesDir, []string{outside})
+	assert.ErrorContains(t, err, "not inside traces folder")
+	assert.FileExists(t, outside)
+
+	err = RemoveTraceFiles(tracesDir, []string{filepath.Join(tracesDir, "..", "outside.jsonl")})
+	assert.ErrorContains(t, err, "not inside traces folder")
+	assert.FileExists(t, outside)
+
+	err = RemoveTraceFiles(tracesDir, []string{notTrace})
+	assert.ErrorContains(t, err, "not a trace file")
+	assert.FileExists(t, notTrace)
+
+	require.NoError(t, RemoveTraceFiles(tracesDir, []string{inside}))
+	assert.NoFileExists(t, inside)
+}
@@ -0,0 +1,136 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Use-Tusk/tusk-cli/internal/utils"
+)
+
+// RehashedFile is a trace file with spans whose input hashes were stale.
+type RehashedFile struct {
+	FilePath     string `json:"filePath"`
+	SpansChanged int    `json:"spansChanged"`
+}
+
+// RehashResult summarizes a RehashTraces run.
+type RehashResult struct {
+	Files        int            `json:"files"`
+	Spans        int            `json:"spans"`
+	SpansChanged int            `json:"spansChanged"`
+	Changed      []RehashedFile `json:"changed"`
+}
+
+// RehashTraces recomputes inputValueHash and inputSchemaHash of every span in
+// the .jsonl files under tracesDir from its inputValue and inputSchema, and
+// rewrites the files whose hashes changed unless dryRun is set. Lines that
+// are not valid JSON are left untouched, as are the lines of unchanged spans.
+func RehashTraces(tracesDir string, dryRun bool) (RehashResult, error) {
+	var result RehashResult
+	err := filepath.WalkDir(tracesDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".jsonl") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path) // #nosec G304
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		lines := strings.Split(string(data), "\n")
+		spans, changed := 0, 0
+		for i, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			rehashed, ok, err := rehashSpanLine(line)
+			if err != nil {
+				continue
+			}
+			spans++
+			if ok {
+				lines[i] = rehashed
+				changed++
+			}
+		}
+
+		result.Files++
+		result.Spans += spans
+		if changed == 0 {
+			return nil
+		}
+		result.SpansChanged += changed
+		result.Changed = append(result.Changed, RehashedFile{FilePath: path, SpansChanged: changed})
+		if dryRun {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return RehashResult{}, fmt.Errorf("traces folder not found: %s", tracesDir)
+		}
+		return RehashResult{}, err
+	}
+
+	sort.Slice(result.Changed, func(i, j int) bool { return result.Changed[i].FilePath < result.Changed[j].FilePath })
+	return result, nil
+}
+
+// rehashSpanLine returns the span JSON in line with recomputed input hashes,
+// and whether either hash changed.
+func rehashSpanLine(line string) (string, bool, error) {
+	// Hash the values as the matcher sees them (numbers as float64), but
+	// rewrite the line from a decode that keeps numbers exact
+	var hashed map[string]any
+	if err := json.Unmarshal([]byte(line), &hashed); err != nil {
+		return "", false, err
+	}
+	var span map[string]any
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&span); err != nil {
+		return "", false, err
+	}
+
+	changed := false
+	rehash := func(field, hashField string) {
+		value, ok := hashed[field].(map[string]any)
+		if !ok {
+			return
+		}
+		hash := utils.GenerateDeterministicHash(value)
+		if old, _ := span[hashField].(string); old != hash {
+			span[hashField] = hash
+			changed = true
+		}
+	}
+	rehash("inputValue", "inputValueHash")
+	rehash("inputSchema", "inputSchemaHash")
+	if !changed {
+		return line, false, nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(span); err != nil {
+		return "", false, err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), true, nil
+}
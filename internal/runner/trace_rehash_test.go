@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Use-Tusk/tusk-cli/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRehashTraces(t *testing.T) {
+	dir := t.TempDir()
+	inputValue := map[string]any{"method": "GET", "target": "/users/1", "id": 12345678901234567}
+	inputSchema := map[string]any{"properties": map[string]any{"method": map[string]any{"type": 6.0}}}
+
+	current := httpRootSpan("current", "GET", "/users/1")
+	current["inputValueHash"] = utils.GenerateDeterministicHash(current["inputValue"])
+	stale := map[string]any{
+		"traceId":         "stale",
+		"spanId":          "stale-call",
+		"packageName":     "http",
+		"inputValue":      inputValue,
+		"inputSchema":     inputSchema,
+		"inputValueHash":  "old-value-hash",
+		"inputSchemaHash": "old-schema-hash",
+	}
+	currentPath := writeTraceFile(t, dir, "current.jsonl", current)
+	stalePath := writeTraceFile(t, dir, "stale.jsonl", current, stale)
+	brokenPath := filepath.Join(dir, "broken.jsonl")
+	require.NoError(t, os.WriteFile(brokenPath, []byte("{not json\n"), 0o600))
+
+	before, err := os.ReadFile(stalePath)
+	require.NoError(t, err)
+
+	result, err := RehashTraces(dir, true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Files)
+	assert.Equal(t, 3, result.Spans)
+	assert.Equal(t, 1, result.SpansChanged)
+	assert.Equal(t, []RehashedFile{{FilePath: stalePath, SpansChanged: 1}}, result.Changed)
+
+	after, err := os.ReadFile(stalePath)
+	require.NoError(t, err)
+	assert.Equal(t, before, after, "dry run should not rewrite files")
+
+	result, err = RehashTraces(dir, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.SpansChanged)
+
+	spans, err := utils.ParseSpansFromFile(stalePath, nil)
+	require.NoError(t, err)
+	require.Len(t, spans, 2)
+	assert.Equal(t, utils.GenerateDeterministicHash(spans[1].InputValue.AsMap()), spans[1].InputValueHash)
+	assert.Equal(t, utils.GenerateDeterministicHash(inputSchema), spans[1].InputSchemaHash)
+
+	after, err = os.ReadFile(stalePath)
+	require.NoError(t, err)
+	lines := strings.Split(string(after), "\n")
+	assert.Equal(t, strings.Split(string(before), "\n")[0], lines[0], "unchanged spans should keep their line")
+	assert.Contains(t, lines[1], "12345678901234567", "numbers should be rewritten exactly")
+
+	// Everything is up to date now
+	result, err = RehashTraces(dir, false)
+	require.NoError(t, err)
+	assert.Zero(t, result.SpansChanged)
+	assert.FileExists(t, currentPath)
+
+	_, err = RehashTraces(filepath.Join(dir, "missing"), false)
+	assert.ErrorContains(t, err, "traces folder not found")
+}
@@ -1,6 +1,10 @@
 package runner
 
-import core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+import (
+	"fmt"
+
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+)
 
 type Test struct {
 	FileName    string         `json:"file_name"`
@@ -44,6 +48,21 @@ type TestResult struct {
 	Duration          int         `json:"duration"`                      // In milliseconds
 	Deviations        []Deviation `json:"deviations,omitempty"`
 	Error             string      `json:"error,omitempty"`
+	// Set when the test was run more than once (--repeat)
+	Runs      int  `json:"runs,omitempty"`
+	PassCount int  `json:"pass_count,omitempty"`
+	Flaky     bool `json:"flaky,omitempty"` // Some runs passed and some failed
+	// Environment group the test was replayed in, when replaying by environment
+	Environment string `json:"environment,omitempty"`
+}
+
+// RunCountLabel returns "passed/runs" (e.g. "3/5") for repeated tests, or ""
+// when the test ran once.
+func (r TestResult) RunCountLabel() string {
+	if r.Runs <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", r.PassCount, r.Runs)
 }
 
 type Trace struct {
@@ -58,6 +77,16 @@ type Deviation struct {
 	Expected    any    `json:"expected"`
 	Actual      any    `json:"actual"`
 	Description string `json:"description"`
+	// Severity is "info", "warn" or "error", from deviations.severity_rules
+	Severity string `json:"severity,omitempty"`
+}
+
+// Label is "Deviation", followed by the severity when it has been classified.
+func (d Deviation) Label() string {
+	if d.Severity == "" {
+		return "Deviation"
+	}
+	return fmt.Sprintf("Deviation [%s]", d.Severity)
 }
 
 type matchScope int
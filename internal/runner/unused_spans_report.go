@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+)
+
+// UnusedSpan describes a recorded outbound span that no call matched during
+// replay.
+type UnusedSpan struct {
+	SpanID      string `json:"spanId"`
+	PackageName string `json:"packageName"`
+	Name        string `json:"name"`
+}
+
+// UnusedSpansReport collects, per trace, the recorded outbound spans left
+// unused after replay. Those usually mean the service took a different code
+// path than when it was recorded. Like MatchReport, spans must be recorded
+// before the server cleans up the trace.
+type UnusedSpansReport struct {
+	mu     sync.Mutex
+	traces map[string][]UnusedSpan
+}
+
+func NewUnusedSpansReport() *UnusedSpansReport {
+	return &UnusedSpansReport{traces: make(map[string][]UnusedSpan)}
+}
+
+// Record stores the unused spans of a trace, replacing anything recorded
+// earlier. Traces without unused spans are not stored.
+func (r *UnusedSpansReport) Record(traceID string, spans []*core.Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(spans) == 0 {
+		delete(r.traces, traceID)
+		return
+	}
+	entries := make([]UnusedSpan, 0, len(spans))
+	for _, span := range spans {
+		entries = append(entries, UnusedSpan{SpanID: span.SpanId, PackageName: span.PackageName, Name: span.Name})
+	}
+	r.traces[traceID] = entries
+}
+
+// Counts returns the number of unused spans and of traces that have any.
+func (r *UnusedSpansReport) Counts() (spans, traces int) {
+	if r == nil {
+		return 0, 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, entries := range r.traces {
+		spans += len(entries)
+	}
+	return spans, len(r.traces)
+}
+
+// WriteText writes the unused spans grouped by trace ID, sorted.
+func (r *UnusedSpansReport) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, traceID := range slices.Sorted(maps.Keys(r.traces)) {
+		entries := r.traces[traceID]
+		if _, err := fmt.Fprintf(w, "%s (%d unused)\n", traceID, len(entries)); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if _, err := fmt.Fprintf(w, "  - [%s] %s  span=%s\n", e.PackageName, e.Name, e.SpanID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
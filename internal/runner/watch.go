@@ -0,0 +1,212 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/Use-Tusk/tusk-cli/internal/log"
+)
+
+// DefaultWatchDebounce is how long source changes must settle before tests re-run.
+const DefaultWatchDebounce = 500 * time.Millisecond
+
+// watchSkipDirs are never watched: VCS metadata, dependencies and Tusk's own
+// output would otherwise re-trigger runs.
+var watchSkipDirs = map[string]bool{
+	".git":         true,
+	".tusk":        true,
+	"node_modules": true,
+	"vendor":       true,
+	"__pycache__":  true,
+}
+
+// WatchSourceChanges watches dir recursively and sends the changed files
+// (relative to dir, sorted) once no change has arrived for delay. The channel
+// is closed when ctx is done or the watcher fails.
+func WatchSourceChanges(ctx context.Context, dir string, delay time.Duration) (<-chan []string, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	if err := addWatchDirs(watcher, dir); err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+
+	paths := make(chan string)
+	go func() {
+		defer close(paths)
+		defer func() { _ = watcher.Close() }()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op == fsnotify.Chmod {
+					continue
+				}
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						if err := addWatchDirs(watcher, event.Name); err != nil {
+							log.Debug("Failed to watch new directory", "path", event.Name, "error", err)
+						}
+						continue
+					}
+				}
+				rel, err := filepath.Rel(dir, event.Name)
+				if err != nil {
+					rel = event.Name
+				}
+				select {
+				case paths <- filepath.ToSlash(rel):
+				case <-ctx.Done():
+					return
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Warn("File watcher error", "error", err)
+			}
+		}
+	}()
+
+	return debounceChanges(ctx, paths, delay), nil
+}
+
+func addWatchDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (watchSkipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// debounceChanges batches paths from in and sends each batch once no path has
+// arrived for delay. Paths arriving while a batch waits to be received are
+// added to it, so a slow consumer gets one batch per run instead of a backlog.
+// The returned channel is closed when ctx is done or in is closed.
+func debounceChanges(ctx context.Context, in <-chan string, delay time.Duration) <-chan []string {
+	out := make(chan []string)
+	go func() {
+		defer close(out)
+		timer := time.NewTimer(delay)
+		timer.Stop()
+		defer timer.Stop()
+
+		pending := make(map[string]struct{})
+		ready := make(map[string]struct{})
+		for {
+			var send chan<- []string
+			var batch []string
+			if len(ready) > 0 {
+				send = out
+				batch = sortedKeys(ready)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case path, ok := <-in:
+				if !ok {
+					return
+				}
+				pending[path] = struct{}{}
+				timer.Reset(delay)
+			case <-timer.C:
+				for path := range pending {
+					ready[path] = struct{}{}
+				}
+				pending = make(map[string]struct{})
+			case send <- batch:
+				ready = make(map[string]struct{})
+			}
+		}
+	}()
+	return out
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WatchAndReplay starts the environment for group once, runs its tests, and
+// re-runs the tests chosen by selectTests for every batch of changed files
+// until ctx is done or changes is closed. The service is not restarted
+// between runs, so it must reload source changes itself (e.g. a dev server).
+// onRun is called with the results of each run.
+func WatchAndReplay(ctx context.Context, executor *Executor, group *EnvironmentGroup, changes <-chan []string, selectTests func(files []string) []Test, onRun func([]TestResult)) error {
+	cleanup, err := PrepareReplayEnvironmentGroup(executor, group)
+	if err != nil {
+		return fmt.Errorf("failed to set env vars for %s: %w", group.Name, err)
+	}
+	defer cleanup()
+
+	if err := executor.StartEnvironment(); err != nil {
+		return fmt.Errorf("failed to start environment: %w", err)
+	}
+	defer func() {
+		if err := executor.StopEnvironment(); err != nil {
+			log.Warn("Failed to stop environment", "error", err)
+		}
+	}()
+
+	run := func(tests []Test) error {
+		executor.ResetSpanUsage()
+		results, err := executor.RunTests(tests)
+		if err != nil {
+			return fmt.Errorf("test execution failed: %w", err)
+		}
+		onRun(results)
+		return nil
+	}
+
+	if err := run(group.Tests); err != nil {
+		return err
+	}
+	for {
+		log.Stderrln("\n👀 Watching for changes (Ctrl-C to stop)...")
+		select {
+		case <-ctx.Done():
+			return nil
+		case files, ok := <-changes:
+			if !ok {
+				return nil
+			}
+			tests := selectTests(files)
+			if len(tests) == 0 {
+				log.Stderrln(fmt.Sprintf("➤ %d files changed, no tests affected", len(files)))
+				continue
+			}
+			log.Stderrln(fmt.Sprintf("➤ %d files changed, re-running %d tests...\n", len(files), len(tests)))
+			if err := run(tests); err != nil {
+				return err
+			}
+		}
+	}
+}
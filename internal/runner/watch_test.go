@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receiveBatch(t *testing.T, batches <-chan []string) []string {
+	t.Helper()
+	select {
+	case batch, ok := <-batches:
+		require.True(t, ok, "batches closed early")
+		return batch
+	case <-time.After(2 * time.Second):
+		t.Fatal("no batch received")
+		return nil
+	}
+}
+
+func assertNoBatch(t *testing.T, batches <-chan []string, wait time.Duration) {
+	t.Helper()
+	select {
+	case batch := <-batches:
+		t.Fatalf("unexpected batch %v", batch)
+	case <-time.After(wait):
+	}
+}
+
+func TestDebounceChangesBatchesBurst(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	in := make(chan string)
+	batches := debounceChanges(ctx, in, 50*time.Millisecond)
+
+	// A burst of saves, including the same file twice, triggers one run
+	for _, path := range []string{"src/users.ts", "src/api.ts", "src/users.ts"} {
+		in <- path
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, []string{"src/api.ts", "src/users.ts"}, receiveBatch(t, batches))
+	assertNoBatch(t, batches, 150*time.Millisecond)
+
+	// A later change triggers another run
+	in <- "src/orders.ts"
+	assert.Equal(t, []string{"src/orders.ts"}, receiveBatch(t, batches))
+}
+
+func TestDebounceChangesWaitsForQuiet(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	in := make(chan string)
+	batches := debounceChanges(ctx, in, 100*time.Millisecond)
+
+	// Changes keep arriving within the delay, so nothing fires until they stop
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		in <- "src/users.ts"
+		time.Sleep(40 * time.Millisecond)
+	}
+	assert.Equal(t, []string{"src/users.ts"}, receiveBatch(t, batches))
+	assert.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond)
+}
+
+func TestDebounceChangesMergesWhileConsumerBusy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	in := make(chan string)
+	batches := debounceChanges(ctx, in, 20*time.Millisecond)
+
+	// Nobody receives while tests run, so settled batches accumulate into one
+	in <- "src/a.ts"
+	time.Sleep(60 * time.Millisecond)
+	in <- "src/b.ts"
+	time.Sleep(60 * time.Millisecond)
+
+	assert.Equal(t, []string{"src/a.ts", "src/b.ts"}, receiveBatch(t, batches))
+	assertNoBatch(t, batches, 60*time.Millisecond)
+}
+
+func TestDebounceChangesStops(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan string)
+	batches := debounceChanges(ctx, in, time.Hour)
+
+	in <- "src/a.ts"
+	cancel()
+	select {
+	case _, ok := <-batches:
+		assert.False(t, ok, "pending changes should be dropped on cancel")
+	case <-time.After(2 * time.Second):
+		t.Fatal("batches not closed after cancel")
+	}
+
+	batches = debounceChanges(context.Background(), in, time.Hour)
+	close(in)
+	_, ok := <-batches
+	assert.False(t, ok)
+}
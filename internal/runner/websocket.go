@@ -0,0 +1,164 @@
+package runner
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/Use-Tusk/tusk-cli/internal/log"
+)
+
+// The SDK speaks the same length-prefixed protobuf stream over WebSocket as
+// over Unix/TCP; binary frames just carry the bytes, so handleConnection can
+// consume a websocketConn exactly like any other net.Conn. Framing, masking
+// and control frames are handled by golang.org/x/net/websocket.
+
+// websocketFrameOverhead leaves room for the 4-byte length prefix when a
+// client sends prefix and message in a single frame.
+const websocketFrameOverhead = 4
+
+// websocketListener adapts an HTTP server performing WebSocket upgrades to
+// net.Listener, so acceptConnections works unchanged.
+type websocketListener struct {
+	tcp             net.Listener
+	srv             *http.Server
+	ws              websocket.Server
+	maxPayloadBytes int
+	conns           chan net.Conn
+	done            chan struct{}
+	closeOnce       sync.Once
+}
+
+// newWebSocketListener serves WebSocket upgrades on tcp. Incoming frames larger
+// than maxMessageBytes (plus the length prefix) close the connection.
+func newWebSocketListener(tcp net.Listener, maxMessageBytes uint32) *websocketListener {
+	l := &websocketListener{
+		tcp:             tcp,
+		maxPayloadBytes: int(maxMessageBytes) + websocketFrameOverhead,
+		conns:           make(chan net.Conn),
+		done:            make(chan struct{}),
+	}
+	l.ws = websocket.Server{
+		// SDKs are not browsers and send no Origin header
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler:   l.handleConn,
+	}
+	l.srv = &http.Server{
+		Handler:           http.HandlerFunc(l.handleUpgrade),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := l.srv.Serve(tcp); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Debug("WebSocket server stopped", "error", err)
+		}
+	}()
+	return l
+}
+
+func (l *websocketListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *websocketListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.done)
+		err = l.srv.Close()
+	})
+	return err
+}
+
+func (l *websocketListener) Addr() net.Addr {
+	return l.tcp.Addr()
+}
+
+func (l *websocketListener) handleUpgrade(w http.ResponseWriter, r *http.Request) {
+	if !headerContainsToken(r.Header, "Connection", "upgrade") || !headerContainsToken(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "expected WebSocket upgrade", http.StatusBadRequest)
+		return
+	}
+	l.ws.ServeHTTP(w, r)
+}
+
+// handleConn hands the upgraded connection to Accept and blocks until it is
+// closed, since x/net closes the underlying connection once the handler returns.
+func (l *websocketListener) handleConn(ws *websocket.Conn) {
+	ws.PayloadType = websocket.BinaryFrame
+	ws.MaxPayloadBytes = l.maxPayloadBytes
+
+	conn := &websocketConn{Conn: ws, closed: make(chan struct{})}
+	select {
+	case l.conns <- conn:
+	case <-l.done:
+		_ = conn.Close()
+		return
+	}
+
+	select {
+	case <-conn.closed:
+	case <-l.done:
+		_ = conn.Close()
+	}
+}
+
+func headerContainsToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for part := range strings.SplitSeq(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// websocketConn exposes the concatenated payload of incoming data frames as a
+// byte stream. Each Write call is sent as a single binary frame.
+type websocketConn struct {
+	*websocket.Conn
+
+	// Unread payload of the last received frame
+	pending []byte
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *websocketConn) Read(p []byte) (int, error) {
+	for len(c.pending) == 0 {
+		var frame []byte
+		if err := websocket.Message.Receive(c.Conn, &frame); err != nil {
+			if errors.Is(err, websocket.ErrFrameTooLarge) {
+				// Skipping the frame would desynchronise the length-prefixed
+				// stream, so drop the connection instead.
+				log.Warn("WebSocket frame too large, closing connection", "limit", c.MaxPayloadBytes)
+				_ = c.Close()
+			}
+			return 0, err
+		}
+		c.pending = frame
+	}
+
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+func (c *websocketConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		err = c.Conn.Close()
+		close(c.closed)
+	})
+	return err
+}
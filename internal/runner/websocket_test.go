@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Use-Tusk/tusk-cli/internal/config"
+	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestServerWebSocketMode(t *testing.T) {
+	config.Invalidate()
+
+	testServiceConfig := &config.ServiceConfig{
+		ID:   "test-ws-service",
+		Port: 3000,
+		Start: config.StartConfig{
+			Command: "npm run dev",
+		},
+		Communication: config.CommunicationConfig{
+			Type:    "websocket",
+			TCPPort: 0, // let OS pick a port
+		},
+	}
+
+	server, err := NewServer("test-ws-service", testServiceConfig)
+	require.NoError(t, err)
+	defer func() { _ = server.Stop() }()
+	assert.Equal(t, CommunicationWebSocket, server.GetCommunicationType())
+
+	require.NoError(t, server.Start())
+
+	wsURL, port := server.GetConnectionInfo()
+	require.NotZero(t, port)
+	assert.Equal(t, fmt.Sprintf("ws://localhost:%d/", port), wsURL)
+
+	conn, err := websocket.Dial(wsURL, "", "http://localhost/")
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	// Send a length-prefixed mock request, split across two frames
+	msg := &core.SDKMessage{
+		Type:      core.MessageType_MESSAGE_TYPE_MOCK_REQUEST,
+		RequestId: "req-1",
+		Payload: &core.SDKMessage_GetMockRequest{
+			GetMockRequest: &core.GetMockRequest{
+				RequestId:    "req-1",
+				OutboundSpan: &core.Span{PackageName: "http"},
+			},
+		},
+	}
+	data, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	lengthPrefix := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	require.NoError(t, websocket.Message.Send(conn, lengthPrefix))
+	require.NoError(t, websocket.Message.Send(conn, data))
+
+	// The response comes back as binary frames carrying the same stream
+	respLength := make([]byte, 4)
+	_, err = io.ReadFull(conn, respLength)
+	require.NoError(t, err)
+	respData := make([]byte, binary.BigEndian.Uint32(respLength))
+	_, err = io.ReadFull(conn, respData)
+	require.NoError(t, err)
+
+	var cliMsg core.CLIMessage
+	require.NoError(t, proto.Unmarshal(respData, &cliMsg))
+	assert.Equal(t, "req-1", cliMsg.RequestId)
+	mockResp := cliMsg.GetGetMockResponse()
+	require.NotNil(t, mockResp)
+	assert.False(t, mockResp.Found)
+}
+
+func TestServerWebSocketMode_RejectsPlainHTTP(t *testing.T) {
+	config.Invalidate()
+
+	server, err := NewServer("test-ws-plain", &config.ServiceConfig{
+		ID:            "test-ws-plain",
+		Communication: config.CommunicationConfig{Type: "websocket"},
+	})
+	require.NoError(t, err)
+	defer func() { _ = server.Stop() }()
+	require.NoError(t, server.Start())
+
+	_, port := server.GetConnectionInfo()
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/", port))
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.True(t, strings.Contains(string(body), "expected WebSocket upgrade"))
+}
+
+func TestServerWebSocketMode_ClosesOnOversizedFrame(t *testing.T) {
+	config.Invalidate()
+
+	server, err := NewServer("test-ws-oversized", &config.ServiceConfig{
+		ID: "test-ws-oversized",
+		Communication: config.CommunicationConfig{
+			Type:            "websocket",
+			MaxMessageBytes: 64,
+		},
+	})
+	require.NoError(t, err)
+	defer func() { _ = server.Stop() }()
+	require.NoError(t, server.Start())
+
+	wsURL, _ := server.GetConnectionInfo()
+	conn, err := websocket.Dial(wsURL, "", "http://localhost/")
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	// The frame cap is max_message_bytes plus the 4-byte length prefix
+	require.NoError(t, websocket.Message.Send(conn, make([]byte, 64+websocketFrameOverhead+1)))
+
+	_, err = conn.Read(make([]byte, 1))
+	assert.Error(t, err, "server should close the connection")
+}
@@ -136,6 +136,12 @@ func (tt *TestTableComponent) updateViewportContent() {
 					status = "❌ Server crashed"
 				case err != nil:
 					status = "❌ Error"
+				case result.Runs > 1 && result.Passed:
+					status = "✅ " + result.RunCountLabel()
+				case result.Flaky:
+					status = "🟡 Flaky " + result.RunCountLabel()
+				case result.Runs > 1:
+					status = "🟠 Deviation " + result.RunCountLabel()
 				case result.Passed:
 					status = "✅ No deviation"
 				default:
@@ -143,8 +143,18 @@ func init() {
 		Background(lipgloss.Color(SecondaryColor))
 }
 
+// noColor is set by the global --no-color flag
+var noColor bool
+
+// SetNoColor disables color in all output, as the NO_COLOR env var does.
+func SetNoColor(disabled bool) {
+	noColor = disabled
+}
+
+// NoColor reports whether output should be uncolored: --no-color was passed
+// or NO_COLOR is set.
 func NoColor() bool {
-	return termenv.EnvNoColor()
+	return noColor || termenv.EnvNoColor()
 }
 
 // HuhTheme returns a huh theme using our style system
@@ -134,6 +134,26 @@ func (m *testExecutorModel) LogToCurrentTest(testID, message string) {
 	}
 }
 
+// runCountSuffix returns " [3/5 passed]" for tests run with --repeat.
+func runCountSuffix(result runner.TestResult) string {
+	if label := result.RunCountLabel(); label != "" {
+		return fmt.Sprintf(" [%s passed]", label)
+	}
+	return ""
+}
+
+// severityIcon colors a deviation line by its severity.
+func severityIcon(severity string) string {
+	switch severity {
+	case runner.SeverityInfo:
+		return "⚪"
+	case runner.SeverityWarn:
+		return "🟠"
+	default:
+		return "🔴"
+	}
+}
+
 // formatDriftCloudCTA returns the CTA text for Tusk Drift Cloud as a boxed message
 func formatDriftCloudCTA() []string {
 	lines := []string{
@@ -575,28 +595,42 @@ func (m *testExecutorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case msg.err != nil:
 			m.addTestLog(test.TraceID, fmt.Sprintf("❌ %s %s - ERROR: %v", test.Method, test.Path, msg.err))
 		case msg.result.Passed:
-			m.addTestLog(test.TraceID, fmt.Sprintf("✅ %s %s - NO DEVIATION (%dms)", test.Method, test.Path, msg.result.Duration))
+			m.addTestLog(test.TraceID, fmt.Sprintf("✅ %s %s - NO DEVIATION (%dms)%s", test.Method, test.Path, msg.result.Duration, runCountSuffix(msg.result)))
 		default:
-			m.addTestLog(test.TraceID, fmt.Sprintf("🟠 %s %s - DEVIATION DETECTED (%dms)", test.Method, test.Path, msg.result.Duration))
+			if msg.result.Flaky {
+				m.addTestLog(test.TraceID, fmt.Sprintf("🟡 %s %s - FLAKY (%dms)%s", test.Method, test.Path, msg.result.Duration, runCountSuffix(msg.result)))
+			} else {
+				m.addTestLog(test.TraceID, fmt.Sprintf("🟠 %s %s - DEVIATION DETECTED (%dms)%s", test.Method, test.Path, msg.result.Duration, runCountSuffix(msg.result)))
+			}
 
 			// Check for mock-not-found events first
-			if m.executor != nil && m.executor.GetServer() != nil && m.executor.GetServer().HasMockNotFoundEvents(test.TraceID) {
-				mockNotFoundEvents := m.executor.GetServer().GetMockNotFoundEvents(test.TraceID)
+			var server *runner.Server
+			if m.executor != nil {
+				server = m.executor.ExecutorForTrace(test.TraceID).GetServer()
+			}
+			if server != nil && server.HasMockNotFoundEvents(test.TraceID) {
+				mockNotFoundEvents := server.GetMockNotFoundEvents(test.TraceID)
 				for _, ev := range mockNotFoundEvents {
 					m.addTestLog(test.TraceID, fmt.Sprintf("  🔴 Mock not found: %s %s", ev.PackageName, ev.Operation))
 					if ev.SpanName != "" {
 						m.addTestLog(test.TraceID, fmt.Sprintf("    Request: %s", ev.SpanName))
 					}
+					if closest := ev.ClosestDescription(); closest != "" {
+						m.addTestLog(test.TraceID, fmt.Sprintf("    Hint: %s", closest))
+					}
 					if ev.StackTrace != "" {
 						m.addTestLog(test.TraceID, fmt.Sprintf("    Stack trace:\n%s", ev.StackTrace))
 					}
 				}
 			} else if len(msg.result.Deviations) > 0 {
 				for _, dev := range msg.result.Deviations {
-					m.addTestLog(test.TraceID, fmt.Sprintf("  Deviation: %s", dev.Description))
+					m.addTestLog(test.TraceID, fmt.Sprintf("  %s %s: %s", severityIcon(dev.Severity), dev.Label(), dev.Description))
 
-					// For JSON response body mismatches, use git-style diff formatting
-					if dev.Field == "response.body" {
+					// Response body mismatches use git-style diff formatting, line by
+					// line for text bodies
+					if expected, actual, ok := utils.TextBodies(dev.Expected, dev.Actual); ok && dev.Field == "response.body" {
+						m.addTestLog(test.TraceID, utils.FormatTextDiff(expected, actual))
+					} else if dev.Field == "response.body" {
 						m.addTestLog(test.TraceID, utils.FormatJSONDiff(dev.Expected, dev.Actual))
 					} else {
 						m.addTestLog(test.TraceID, fmt.Sprintf("    Expected: %v", dev.Expected))
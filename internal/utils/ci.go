@@ -6,6 +6,8 @@ import (
 	"os"
 	"os/exec"
 	"time"
+
+	"github.com/Use-Tusk/tusk-cli/internal/tui/styles"
 )
 
 // CIWarning emits a warning annotation visible in the CI provider's UI.
@@ -36,6 +38,10 @@ func CIWarning(message string) {
 
 	case os.Getenv("GITLAB_CI") == "true":
 		// GitLab has no annotation API — use ANSI yellow to stand out in job logs
-		fmt.Fprintf(os.Stderr, "\033[33mWarning: %s\033[0m\n", message)
+		if styles.NoColor() {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", message)
+		} else {
+			fmt.Fprintf(os.Stderr, "\033[33mWarning: %s\033[0m\n", message)
+		}
 	}
 }
@@ -1,10 +1,12 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -15,8 +17,12 @@ const (
 	ConfigFileName = "config.yaml"
 )
 
-// Optional override for local traces directory (set by config or CLI flag)
-var tracesDirOverride string
+// ErrTraceFileNotFound is returned by FindTraceFile when no traces directory
+// has a file for the trace.
+var ErrTraceFileNotFound = errors.New("no trace file found")
+
+// Optional override for local traces directories (set by config or CLI flag)
+var tracesDirOverrides []string
 
 // List of directories to search for trace files
 var PossibleTraceDirs = []string{
@@ -101,28 +107,43 @@ func ResolveTuskPath(path string) string {
 	return filepath.Join(root, path)
 }
 
-// GetTracesDir returns the traces directory path
+// GetTracesDir returns the traces directory path. When several directories
+// were set with SetTracesDirOverride, this is the first.
 func GetTracesDir() string {
-	if tracesDirOverride != "" {
-		return tracesDirOverride
+	if len(tracesDirOverrides) > 0 {
+		return tracesDirOverrides[0]
 	}
 	return filepath.Join(GetTuskDir(), TracesSubDir)
 }
 
-// SetTracesDirOverride sets an explicit traces directory to use.
-func SetTracesDirOverride(dir string) {
-	tracesDirOverride = dir
+// GetTracesDirs returns every traces directory set with SetTracesDirOverride,
+// or the default traces directory.
+func GetTracesDirs() []string {
+	if len(tracesDirOverrides) > 0 {
+		return slices.Clone(tracesDirOverrides)
+	}
+	return []string{GetTracesDir()}
 }
 
-// GetPossibleTraceDirs returns the list of directories to search for trace files, preferring override first.
+// SetTracesDirOverride sets explicit traces directories to use. Empty
+// strings are ignored, so SetTracesDirOverride("") clears the override.
+func SetTracesDirOverride(dirs ...string) {
+	tracesDirOverrides = nil
+	for _, dir := range dirs {
+		if dir != "" && !slices.Contains(tracesDirOverrides, dir) {
+			tracesDirOverrides = append(tracesDirOverrides, dir)
+		}
+	}
+}
+
+// GetPossibleTraceDirs returns the list of directories to search for trace files, preferring overrides first.
 func GetPossibleTraceDirs() []string {
-	if tracesDirOverride == "" {
+	if len(tracesDirOverrides) == 0 {
 		return PossibleTraceDirs
 	}
-	out := []string{tracesDirOverride}
-	seen := map[string]struct{}{tracesDirOverride: {}}
+	out := slices.Clone(tracesDirOverrides)
 	for _, d := range PossibleTraceDirs {
-		if _, ok := seen[d]; !ok {
+		if !slices.Contains(out, d) {
 			out = append(out, d)
 		}
 	}
@@ -150,15 +171,33 @@ func GetGitRootDir() (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
-// FindTraceFile searches for a JSONL trace file containing the given trace ID.
-// If filename is provided, it tries that first before searching
+// FindTraceFile searches the traces directories for a JSONL trace file
+// containing the given trace ID. If filename is provided, it tries that first
+// before searching
 func FindTraceFile(traceID string, filename string) (string, error) {
-	tracesDir := GetTracesDir()
+	tracesDirs := GetTracesDirs()
+	found := false
+	for _, tracesDir := range tracesDirs {
+		if _, err := os.Stat(tracesDir); os.IsNotExist(err) {
+			continue
+		}
+		found = true
 
-	if _, err := os.Stat(tracesDir); os.IsNotExist(err) {
-		return "", fmt.Errorf("traces directory not found: %s", tracesDir)
+		path, err := findTraceFileInDir(tracesDir, traceID, filename)
+		if err != nil || path != "" {
+			return path, err
+		}
+	}
+
+	if !found {
+		return "", fmt.Errorf("traces directory not found: %s", strings.Join(tracesDirs, ", "))
 	}
+	return "", fmt.Errorf("%w for trace ID: %s", ErrTraceFileNotFound, traceID)
+}
 
+// findTraceFileInDir is FindTraceFile for one traces directory. It returns
+// an empty path when the trace isn't there.
+func findTraceFileInDir(tracesDir, traceID, filename string) (string, error) {
 	if filename != "" {
 		var fullPath string
 
@@ -198,9 +237,5 @@ func FindTraceFile(traceID string, filename string) (string, error) {
 		return "", fmt.Errorf("error searching for trace file: %w", err)
 	}
 
-	if foundFile == "" {
-		return "", fmt.Errorf("no trace file found for trace ID: %s", traceID)
-	}
-
 	return foundFile, nil
 }
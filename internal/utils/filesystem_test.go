@@ -328,3 +328,37 @@ func TestFindTraceFile_NotFound(t *testing.T) {
 	_, err := FindTraceFile("nope", "")
 	require.Error(t, err)
 }
+
+func TestSetTracesDirOverride_MultipleDirs(t *testing.T) {
+	t.Cleanup(func() { SetTracesDirOverride("") })
+
+	SetTracesDirOverride("traces/a", "", "traces/b", "traces/a")
+	assert.Equal(t, "traces/a", GetTracesDir())
+	assert.Equal(t, []string{"traces/a", "traces/b"}, GetTracesDirs())
+	assert.Equal(t, []string{"traces/a", "traces/b", ".tusk/traces", "traces", "tmp", "."}, GetPossibleTraceDirs())
+
+	SetTracesDirOverride("")
+	assert.Equal(t, []string{GetTracesDir()}, GetTracesDirs())
+}
+
+func TestFindTraceFile_SearchesEveryTracesDir(t *testing.T) {
+	t.Cleanup(func() { SetTracesDirOverride("") })
+
+	first := t.TempDir()
+	second := t.TempDir()
+	full := filepath.Join(second, "2025-01-01_trace-abc123.jsonl")
+	require.NoError(t, os.WriteFile(full, []byte("{}\n"), 0o600))
+
+	SetTracesDirOverride(filepath.Join(first, "missing"), first, second)
+	got, err := FindTraceFile("abc123", "")
+	require.NoError(t, err)
+	assert.Equal(t, full, got)
+
+	got, err = FindTraceFile("irrelevant", filepath.Base(full))
+	require.NoError(t, err)
+	assert.Equal(t, full, got)
+
+	_, err = FindTraceFile("missing-trace", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no trace file found")
+}
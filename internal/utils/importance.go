@@ -1,7 +1,11 @@
 package utils
 
 import (
+	"fmt"
+	"strings"
+
 	core "github.com/Use-Tusk/tusk-drift-schemas/generated/go/core"
+	"google.golang.org/protobuf/proto"
 )
 
 // ReduceByMatchImportance returns a copy of 'value' keeping only fields with matchImportance != 0.
@@ -106,3 +110,107 @@ func ReduceSchemaByMatchImportance(schema *core.JsonSchema) *core.JsonSchema {
 
 	return reduced
 }
+
+// IgnoreFieldsInSchema returns a copy of schema with matchImportance set to 0 at
+// each path, creating schema nodes for fields the schema does not describe.
+// Paths are dot-separated field names, optionally prefixed with "$." and with
+// "[]" or "[*]" after a field to address its array items, e.g. "$.conn.id" or
+// "args[*].clientId". The original schema is never modified.
+func IgnoreFieldsInSchema(schema *core.JsonSchema, paths []string) *core.JsonSchema {
+	if len(paths) == 0 {
+		return schema
+	}
+
+	var out *core.JsonSchema
+	if schema == nil {
+		out = &core.JsonSchema{}
+	} else {
+		out = proto.Clone(schema).(*core.JsonSchema)
+	}
+
+	zero := 0.0
+	for _, path := range paths {
+		node := out
+		for _, segment := range splitSchemaPath(path) {
+			if segment == "[]" {
+				if node.Items == nil {
+					node.Items = &core.JsonSchema{}
+				}
+				node = node.Items
+				continue
+			}
+			if node.Properties == nil {
+				node.Properties = make(map[string]*core.JsonSchema)
+			}
+			child, ok := node.Properties[segment]
+			if !ok || child == nil {
+				child = &core.JsonSchema{}
+				node.Properties[segment] = child
+			}
+			node = child
+		}
+		if node != out {
+			node.MatchImportance = &zero
+		}
+	}
+	return out
+}
+
+// ValidateSchemaPath reports whether path has the syntax IgnoreFieldsInSchema
+// accepts: dot-separated field names, optionally prefixed with "$.", with "[]"
+// or "[*]" after a field to address its array items.
+func ValidateSchemaPath(path string) error {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	trimmed = strings.TrimPrefix(trimmed, ".")
+	if trimmed == "" {
+		return fmt.Errorf("path %q names no field", path)
+	}
+	for i, part := range strings.Split(trimmed, ".") {
+		name, brackets, hasBrackets := strings.Cut(part, "[")
+		// Only the root may be addressed without a field name, as in "$[*].id"
+		if name == "" && (!hasBrackets || i > 0) {
+			return fmt.Errorf("path %q has an empty field name", path)
+		}
+		if !hasBrackets {
+			continue
+		}
+		for rest := "[" + brackets; rest != ""; {
+			switch {
+			case strings.HasPrefix(rest, "[]"):
+				rest = rest[len("[]"):]
+			case strings.HasPrefix(rest, "[*]"):
+				rest = rest[len("[*]"):]
+			default:
+				return fmt.Errorf("path %q: only [] or [*] may follow a field name", path)
+			}
+		}
+	}
+	return nil
+}
+
+// splitSchemaPath splits "$.a.b[*].c" into ["a", "b", "[]", "c"].
+func splitSchemaPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	path = strings.TrimPrefix(path, ".")
+
+	var segments []string
+	for part := range strings.SplitSeq(path, ".") {
+		for part != "" {
+			idx := strings.Index(part, "[")
+			if idx < 0 {
+				segments = append(segments, part)
+				break
+			}
+			if idx > 0 {
+				segments = append(segments, part[:idx])
+			}
+			end := strings.Index(part[idx:], "]")
+			if end < 0 {
+				break
+			}
+			segments = append(segments, "[]")
+			part = part[idx+end+1:]
+		}
+	}
+	return segments
+}
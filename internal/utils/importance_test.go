@@ -775,3 +775,46 @@ func TestGetFieldSchema(t *testing.T) {
 		})
 	}
 }
+
+func TestIgnoreFieldsInSchema(t *testing.T) {
+	t.Parallel()
+
+	schema := &core.JsonSchema{
+		Properties: map[string]*core.JsonSchema{
+			"command": {},
+			"args": {
+				Items: &core.JsonSchema{Properties: map[string]*core.JsonSchema{"key": {}}},
+			},
+		},
+	}
+
+	out := IgnoreFieldsInSchema(schema, []string{"$.connectionId", "args[*].key", "meta.client.id"})
+
+	// Original schema is untouched
+	require.Nil(t, schema.Properties["args"].Items.Properties["key"].MatchImportance)
+	require.NotContains(t, schema.Properties, "connectionId")
+
+	require.NotNil(t, out.Properties["connectionId"].MatchImportance)
+	require.Equal(t, 0.0, *out.Properties["connectionId"].MatchImportance)
+	require.Equal(t, 0.0, *out.Properties["args"].Items.Properties["key"].MatchImportance)
+	require.Equal(t, 0.0, *out.Properties["meta"].Properties["client"].Properties["id"].MatchImportance)
+	require.Nil(t, out.Properties["command"].MatchImportance)
+	require.Nil(t, out.Properties["meta"].MatchImportance, "only the leaf is ignored")
+
+	value := map[string]any{"command": "GET", "connectionId": "conn-1", "meta": map[string]any{"client": map[string]any{"id": 7, "name": "x"}}}
+	reduced := ReduceByMatchImportance(value, out)
+	require.Equal(t, map[string]any{"command": "GET", "meta": map[string]any{"client": map[string]any{"name": "x"}}}, reduced)
+
+	require.Same(t, schema, IgnoreFieldsInSchema(schema, nil))
+}
+
+func TestValidateSchemaPath(t *testing.T) {
+	t.Parallel()
+
+	for _, path := range []string{"$.connectionId", "conn.id", "args[*].clientId", "args[].id", "$[*].id", "a[][*]"} {
+		require.NoError(t, ValidateSchemaPath(path), path)
+	}
+	for _, path := range []string{"", "$", "$.", "a..b", "a.", "args[0]", "args[*", "a.[*]"} {
+		require.Error(t, ValidateSchemaPath(path), path)
+	}
+}
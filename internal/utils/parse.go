@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -32,8 +33,14 @@ func ParseSpansFromFile(filename string, filter SpanFilter) ([]*core.Span, error
 		}
 	}()
 
+	return ParseSpansFromReader(file, filename, filter)
+}
+
+// ParseSpansFromReader reads JSONL spans from r, e.g. a trace file inside an
+// archive. filename is only used in error messages.
+func ParseSpansFromReader(r io.Reader, filename string, filter SpanFilter) ([]*core.Span, error) {
 	var spans []*core.Span
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 	scanner.Buffer(make([]byte, 0, 64*1024), 15*1024*1024) // Initial 64KB, max 15MB
 
 	lineNum := 0
@@ -9,6 +9,8 @@ import (
 
 	"github.com/mattn/go-runewidth"
 	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/Use-Tusk/tusk-cli/internal/tui/styles"
 )
 
 const NoWrapMarker = "\x00NOWRAP\x00"
@@ -276,33 +278,42 @@ func FormatJSONForDiff(v any) string {
 
 // FormatJSONDiff creates a git-style unified diff between two JSON values
 func FormatJSONDiff(expected, actual any) string {
-	expectedJSON := formatJSONForDiff(expected)
-	actualJSON := formatJSONForDiff(actual)
+	return formatColoredDiff(formatJSONForDiff(expected), formatJSONForDiff(actual), 5)
+}
 
-	if expectedJSON == actualJSON {
+// FormatTextDiff creates a git-style unified diff between two text bodies
+// (see TextBodies), compared line by line.
+func FormatTextDiff(expected, actual string) string {
+	// A final newline would show up as an extra, blank line
+	return formatColoredDiff(strings.TrimSuffix(expected, "\n"), strings.TrimSuffix(actual, "\n"), 3)
+}
+
+// formatColoredDiff renders a unified diff of two texts with ANSI colors in a
+// box, for the TUI.
+func formatColoredDiff(expectedText, actualText string, context int) string {
+	if expectedText == actualText {
 		return "No differences found"
 	}
 
 	// Generate unified diff
 	diff := difflib.UnifiedDiff{
-		A:        difflib.SplitLines(expectedJSON),
-		B:        difflib.SplitLines(actualJSON),
+		A:        difflib.SplitLines(expectedText),
+		B:        difflib.SplitLines(actualText),
 		FromFile: "Expected",
 		ToFile:   "Actual",
-		Context:  5,
+		Context:  context,
 	}
 
 	result, err := difflib.GetUnifiedDiffString(diff)
 	if err != nil {
 		// Fallback to simple side-by-side if diff fails
-		return fmt.Sprintf("Expected:\n%s\n\nActual:\n%s", expectedJSON, actualJSON)
+		return fmt.Sprintf("Expected:\n%s\n\nActual:\n%s", expectedText, actualText)
 	}
 
-	red := "\033[31m"
-	green := "\033[32m"
-	cyan := "\033[36m"
-	gray := "\033[38;5;250m"
-	reset := "\033[0m"
+	red, green, cyan, gray, reset := "\033[31m", "\033[32m", "\033[36m", "\033[38;5;250m", "\033[0m"
+	if styles.NoColor() {
+		red, green, cyan, gray, reset = "", "", "", "", ""
+	}
 
 	lines := strings.Split(result, "\n")
 	var indentedLines []string
@@ -360,6 +371,55 @@ func FormatJSONDiffPlain(expected, actual any) string {
 	return result
 }
 
+// FormatTextDiffPlain creates a plain unified diff between two text bodies
+// (see TextBodies), without ANSI colors or box borders. Returns "" when they
+// are equal.
+func FormatTextDiffPlain(expected, actual string) string {
+	if expected == actual {
+		return ""
+	}
+
+	diff := difflib.UnifiedDiff{
+		A:        difflib.SplitLines(strings.TrimSuffix(expected, "\n")),
+		B:        difflib.SplitLines(strings.TrimSuffix(actual, "\n")),
+		FromFile: "Expected",
+		ToFile:   "Actual",
+		Context:  3,
+	}
+
+	result, err := difflib.GetUnifiedDiffString(diff)
+	if err != nil {
+		return fmt.Sprintf("Expected:\n%s\n\nActual:\n%s", expected, actual)
+	}
+	return result
+}
+
+// TextBodies returns expected and actual as text when neither is a JSON
+// document, as with plain-text and HTML response bodies. Both must be strings;
+// nil, such as a missing body, counts as empty text.
+func TextBodies(expected, actual any) (string, string, bool) {
+	expectedText, ok := nonJSONText(expected)
+	if !ok {
+		return "", "", false
+	}
+	actualText, ok := nonJSONText(actual)
+	if !ok || (expected == nil && actual == nil) {
+		return "", "", false
+	}
+	return expectedText, actualText, true
+}
+
+func nonJSONText(v any) (string, bool) {
+	if v == nil {
+		return "", true
+	}
+	str, ok := v.(string)
+	if !ok || json.Valid([]byte(str)) {
+		return "", false
+	}
+	return str, true
+}
+
 // formatJSONForDiff is a helper that formats JSON without the extra indentation prefix
 func formatJSONForDiff(v any) string {
 	if v == nil {
@@ -194,6 +194,47 @@ func TestFormatJSONDiffPlain_Different(t *testing.T) {
 	assert.NotContains(t, got, "╰")
 }
 
+func TestFormatTextDiffPlain_MultiLineBodies(t *testing.T) {
+	expected := "<html>\n<body>\n<h1>Hello</h1>\n<p>Total: 3</p>\n</body>\n</html>\n"
+	actual := "<html>\n<body>\n<h1>Hello</h1>\n<p>Total: 4</p>\n</body>\n</html>\n"
+
+	got := FormatTextDiffPlain(expected, actual)
+	assert.Equal(t, `--- Expected
++++ Actual
+@@ -1,6 +1,6 @@
+ <html>
+ <body>
+ <h1>Hello</h1>
+-<p>Total: 3</p>
++<p>Total: 4</p>
+ </body>
+ </html>
+`, got)
+	assert.Equal(t, "", FormatTextDiffPlain(expected, expected))
+}
+
+func TestFormatTextDiff_Colored(t *testing.T) {
+	got := FormatTextDiff("line one\nline two\n", "line one\nline 2\n")
+	plain := StripNoWrapMarker(StripANSI(got))
+	assert.Contains(t, plain, "-line two")
+	assert.Contains(t, plain, "+line 2")
+	assert.Contains(t, got, "╭")
+}
+
+func TestTextBodies(t *testing.T) {
+	expected, actual, ok := TextBodies("<p>a</p>", nil)
+	assert.True(t, ok)
+	assert.Equal(t, "<p>a</p>", expected)
+	assert.Equal(t, "", actual)
+
+	_, _, ok = TextBodies(map[string]any{"a": 1.0}, "text")
+	assert.False(t, ok, "JSON objects are not text")
+	_, _, ok = TextBodies(`{"a":1}`, "text")
+	assert.False(t, ok, "JSON strings are not text")
+	_, _, ok = TextBodies(nil, nil)
+	assert.False(t, ok)
+}
+
 func TestTruncateWithEllipsis_NoTruncation(t *testing.T) {
 	text := "hello world"
 	got := TruncateWithEllipsis(text, 20)
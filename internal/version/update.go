@@ -37,10 +37,14 @@ type LatestRelease struct {
 // CheckForUpdate checks if a newer version is available.
 // Returns the latest release info if an update is available, nil otherwise.
 func CheckForUpdate(ctx context.Context) (*LatestRelease, error) {
+	return checkForUpdate(ctx, latestVersionURL)
+}
+
+func checkForUpdate(ctx context.Context, url string) (*LatestRelease, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, latestVersionURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -83,6 +87,33 @@ func CheckForUpdate(ctx context.Context) (*LatestRelease, error) {
 	return &release, nil
 }
 
+// PrintUpdateCheck reports whether a newer release is available (version
+// --check). A failed check is reported rather than returned, so it never
+// fails the command.
+func PrintUpdateCheck(ctx context.Context, w io.Writer) {
+	if Version == "dev" {
+		_, _ = fmt.Fprintln(w, "Development build; not compared against released versions.")
+		return
+	}
+	release, err := CheckForUpdate(ctx)
+	writeUpdateCheck(w, release, err)
+}
+
+func writeUpdateCheck(w io.Writer, release *LatestRelease, err error) {
+	switch {
+	case err != nil:
+		_, _ = fmt.Fprintf(w, "Could not check for updates: %v\n", err)
+	case release == nil:
+		_, _ = fmt.Fprintf(w, "Tusk CLI %s is the latest version.\n", Version)
+	default:
+		_, _ = fmt.Fprintf(w, "A new version of Tusk CLI is available: %s (current: %s)\n", release.Version, Version)
+		_, _ = fmt.Fprintf(w, "Release notes: %s\n", release.URL)
+		if installedViaHomebrew() {
+			_, _ = fmt.Fprintf(w, "Update with: %s\n", homebrewUpgradeCmd)
+		}
+	}
+}
+
 // PromptAndUpdate prompts the user to update and performs the update if confirmed.
 // Returns true if an update was performed.
 func PromptAndUpdate(release *LatestRelease) bool {
@@ -1,6 +1,14 @@
 package version
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
 
 func TestIsHomebrewPath(t *testing.T) {
 	t.Parallel()
@@ -70,3 +78,59 @@ func TestGetDownloadURLDarwinARM64(t *testing.T) {
 		t.Fatalf("getDownloadURLForPlatform() = %q, want %q", got, want)
 	}
 }
+
+func TestCheckForUpdate(t *testing.T) {
+	origVersion := Version
+	Version = "1.2.0"
+	t.Cleanup(func() { Version = origVersion })
+
+	tests := []struct {
+		name       string
+		latest     string
+		wantUpdate bool
+		wantOutput string
+	}{
+		{name: "newer release", latest: "v1.3.0", wantUpdate: true, wantOutput: "A new version of Tusk CLI is available: v1.3.0 (current: 1.2.0)\nRelease notes: https://example.com/releases/v1.3.0\n"},
+		{name: "same release", latest: "v1.2.0", wantOutput: "Tusk CLI 1.2.0 is the latest version.\n"},
+		{name: "older release", latest: "1.1.9", wantOutput: "Tusk CLI 1.2.0 is the latest version.\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = fmt.Fprintf(w, `{"version": %q, "url": "https://example.com/releases/v1.3.0"}`, tc.latest)
+			}))
+			defer server.Close()
+
+			release, err := checkForUpdate(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("checkForUpdate() error = %v", err)
+			}
+			if got := release != nil; got != tc.wantUpdate {
+				t.Fatalf("checkForUpdate() found update = %v, want %v", got, tc.wantUpdate)
+			}
+
+			var out bytes.Buffer
+			writeUpdateCheck(&out, release, err)
+			if !strings.HasPrefix(out.String(), tc.wantOutput) {
+				t.Fatalf("writeUpdateCheck() = %q, want prefix %q", out.String(), tc.wantOutput)
+			}
+		})
+	}
+
+	t.Run("unreachable endpoint is reported", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		release, err := checkForUpdate(context.Background(), server.URL)
+		if err == nil {
+			t.Fatal("checkForUpdate() error = nil, want an error")
+		}
+
+		var out bytes.Buffer
+		writeUpdateCheck(&out, release, err)
+		if !strings.HasPrefix(out.String(), "Could not check for updates: ") {
+			t.Fatalf("writeUpdateCheck() = %q", out.String())
+		}
+	})
+}